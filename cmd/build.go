@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"syscall"
 	"time"
@@ -13,17 +14,39 @@ import (
 	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
 
+	"github.com/mpjhorner/superralph/internal/coverage"
 	"github.com/mpjhorner/superralph/internal/git"
 	"github.com/mpjhorner/superralph/internal/notify"
 	"github.com/mpjhorner/superralph/internal/orchestrator"
 	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/publish"
+	"github.com/mpjhorner/superralph/internal/tamper"
 	"github.com/mpjhorner/superralph/internal/tui"
 	"github.com/mpjhorner/superralph/internal/tui/components"
 )
 
 var (
-	buildDebug  bool
-	buildResume bool
+	buildDebug       bool
+	buildResume      bool
+	buildRepoMap     bool
+	buildFlakeReruns int
+	buildMaxAttempts int
+	buildTamper      string
+	buildAgentCommit bool
+	buildSign        string
+	buildSigningKey  string
+	buildBranches    bool
+	buildPrefix      string
+	buildMerge       string
+	buildKeep        bool
+	buildIntegration string
+	buildPublish     string
+	buildRemote      string
+	buildPRBase      string
+	buildDraft       bool
+	buildGitHubURL   string
+	buildGitHubRepo  string
+	buildStrategy    string
 )
 
 var buildCmd = &cobra.Command{
@@ -33,15 +56,75 @@ var buildCmd = &cobra.Command{
 
 The agent will:
   1. Read the PRD and progress file to understand current state
-  2. Pick the highest-priority incomplete feature
+  2. Pick the next incomplete feature (see Scheduling)
   3. Implement the feature
   4. Run tests (must pass before committing)
   5. Update prd.json and progress.txt
-  6. Commit changes
-  7. Repeat until all features pass
+  6. Repeat until all features pass
 
 Tests MUST pass before any commit. This is non-negotiable.
 
+Commits:
+  SuperRalph commits each iteration that passes the gates itself. The message
+  is a conventional commit typed by the feature's category (feat, perf, ...)
+  with trailers naming the feature, iteration and run:
+
+    Feature-Id: feat-004
+    SuperRalph-Status: passing
+    SuperRalph-Iteration: 7
+    SuperRalph-Run: <session id>
+
+  Find a feature's commits with: git log --grep "Feature-Id: feat-004"
+  Use --sign gpg|ssh to sign them, or --agent-commits to let the agent commit.
+
+Feature Branches:
+  With --branch-per-feature each feature is built on its own branch
+  (ralph/feat-004) created from the integration branch (the current branch
+  unless --integration-branch is given). Once the feature passes the gates it
+  is squash-merged into the integration branch as one summary commit, or
+  fast-forwarded with --merge ff, and the branch is deleted unless
+  --keep-branches is set.
+
+Publishing:
+  With --publish push, the branch is pushed to --remote after each finished
+  feature. With --publish pr, a GitHub pull request is also opened into
+  --pr-base, describing the feature's steps, progress entry, diff stats and
+  gate results. A feature with a pull request is merged there rather than
+  locally: its branch is kept and it waits as needs_review. The token is read
+  from GITHUB_TOKEN (or GH_TOKEN); use --github-url for GitHub Enterprise.
+  Failures are retried and recorded in .superralph/publish.json without
+  stopping the build.
+
+Test Gate:
+  After each iteration SuperRalph runs the PRD's test command itself. Failing
+  tests are rerun to tell flakes from real failures; flakes are recorded in
+  .superralph/flakes.json and don't count against the feature's 3 attempts.
+  Run 'superralph flakes' to list them.
+
+  If prd.json has a "coverage" block, the gate also collects a coverage profile
+  and compares it with the baseline from the last accepted feature. A drop
+  beyond the threshold reopens the feature and counts as a failed attempt.
+
+  Performance features with a "benchmark" block are benchmarked before work
+  starts and again when marked as passing. They are reopened if they miss
+  their target.
+
+  The changes of each iteration are checked for weakened tests: deleted test
+  files or tests, fewer tests running, new skip or .only markers, removed
+  assertions, and edited expectations in unrelated test files. By default
+  such iterations are flagged in .superralph/review.json; with
+  --tamper-policy reject they are rejected like a failed gate.
+
+Scheduling:
+  By default the highest-priority feature whose dependencies pass is built
+  next. Set "strategy" in prd.json, or --strategy for one run, to schedule
+  differently:
+    priority        high > medium > low, then ID order
+    critical-path   the start of the longest chain of remaining features
+    unblock         the feature the most unfinished features depend on
+    round-robin     the category with the fewest passing features
+    value-effort    the best "value" / "effort" ratio of each feature
+
 Graceful Shutdown:
   Press Ctrl+C to gracefully stop the build. The current action will complete
   before saving state. Use --resume to continue from where you left off.`,
@@ -51,29 +134,155 @@ Graceful Shutdown:
 func init() {
 	buildCmd.Flags().BoolVar(&buildDebug, "debug", false, "Show Claude's thinking process")
 	buildCmd.Flags().BoolVar(&buildResume, "resume", false, "Resume from saved state after interruption")
+	buildCmd.Flags().BoolVar(&buildRepoMap, "repo-map", false, "Show Claude an outline of exported symbols instead of the directory tree")
+	buildCmd.Flags().IntVar(&buildFlakeReruns, "flake-reruns", 2, "Times to rerun failing tests after a failed test gate to detect flakes (0 disables)")
+	buildCmd.Flags().IntVar(&buildMaxAttempts, "max-attempts", 3, "Failed gates a feature may use before the build stops and marks it stuck")
+	buildCmd.Flags().StringVar(&buildTamper, "tamper-policy", "warn", "What to do when an iteration weakens the tests: off, warn (flag for review) or reject")
+	buildCmd.Flags().BoolVar(&buildAgentCommit, "agent-commits", false, "Let the agent make its own commits instead of the harness")
+	buildCmd.Flags().StringVar(&buildSign, "sign", "", "Sign harness commits: gpg or ssh")
+	buildCmd.Flags().StringVar(&buildSigningKey, "signing-key", "", "Key to sign harness commits with (default: git's user.signingkey)")
+	buildCmd.Flags().BoolVar(&buildBranches, "branch-per-feature", false, "Build each feature on its own branch and merge it when it passes")
+	buildCmd.Flags().StringVar(&buildPrefix, "branch-prefix", orchestrator.DefaultBranchPrefix, "Prefix of feature branch names")
+	buildCmd.Flags().StringVar(&buildMerge, "merge", string(orchestrator.MergeSquash), "How to merge finished feature branches: squash or ff")
+	buildCmd.Flags().BoolVar(&buildKeep, "keep-branches", false, "Keep feature branches after merging them")
+	buildCmd.Flags().StringVar(&buildIntegration, "integration-branch", "", "Branch features are created from and merged into (default: the current branch)")
+	buildCmd.Flags().StringVar(&buildPublish, "publish", "", "Publish finished features: push, or pr to also open a pull request")
+	buildCmd.Flags().StringVar(&buildRemote, "remote", "origin", "Remote to push finished features to")
+	buildCmd.Flags().StringVar(&buildPRBase, "pr-base", "", "Branch pull requests merge into (default: the integration branch)")
+	buildCmd.Flags().BoolVar(&buildDraft, "draft", false, "Open pull requests as drafts")
+	buildCmd.Flags().StringVar(&buildGitHubURL, "github-url", publish.DefaultGitHubURL, "GitHub API URL, e.g. https://github.example.com/api/v3")
+	buildCmd.Flags().StringVar(&buildGitHubRepo, "github-repo", "", "GitHub repository as owner/name (default: from the remote's URL)")
+	buildCmd.Flags().StringVar(&buildStrategy, "strategy", "", "Strategy picking the next feature: priority, critical-path, unblock, round-robin or value-effort (default: prd.json's)")
 	rootCmd.AddCommand(buildCmd)
 }
 
+// newPublishConfig builds the publishing configuration from the flags, or nil
+// if publishing is off
+func newPublishConfig() (*orchestrator.PublishConfig, error) {
+	switch buildPublish {
+	case "":
+		return nil, nil
+	case "push", "pr":
+	default:
+		return nil, fmt.Errorf("invalid --publish %q: use push or pr", buildPublish)
+	}
+
+	config := &orchestrator.PublishConfig{Remote: buildRemote, Base: buildPRBase, Draft: buildDraft}
+	if buildPublish == "push" {
+		return config, nil
+	}
+	if !buildBranches && buildPRBase == "" {
+		return nil, fmt.Errorf("--publish pr needs --branch-per-feature or a --pr-base other than the current branch")
+	}
+
+	repo := buildGitHubRepo
+	if repo == "" {
+		dir, err := projectDir()
+		if err != nil {
+			return nil, err
+		}
+		url, err := git.RemoteURL(dir, buildRemote)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if repo, ok = publish.ParseGitHubRepo(url); !ok {
+			return nil, fmt.Errorf("can't tell the GitHub repository from %s; use --github-repo owner/name", url)
+		}
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("--publish pr needs a token in GITHUB_TOKEN or GH_TOKEN")
+	}
+	provider, err := publish.NewGitHub(buildGitHubURL, repo, token)
+	if err != nil {
+		return nil, err
+	}
+	config.Provider = provider
+	return config, nil
+}
+
 func runBuild(cmd *cobra.Command, args []string) {
-	// Check if prd.json exists
-	if !prd.ExistsInCurrentDir() {
-		fmt.Println(errorStyle.Render("x") + " prd.json not found in current directory")
-		fmt.Println(dimStyle.Render("  Run 'superralph plan' to create one"))
+	tamperPolicy, err := tamper.ParsePolicy(buildTamper)
+	if err != nil {
+		fmt.Println(errorStyle.Render("x") + " " + err.Error())
+		os.Exit(1)
+	}
+	if buildMaxAttempts < 1 {
+		fmt.Println(errorStyle.Render("x") + " --max-attempts must be at least 1")
+		os.Exit(1)
+	}
+	var strategy prd.Strategy
+	if buildStrategy != "" {
+		if strategy, err = prd.ParseStrategy(buildStrategy); err != nil {
+			fmt.Println(errorStyle.Render("x") + " " + err.Error())
+			os.Exit(1)
+		}
+	}
+	if !slices.Contains(git.ValidSignModes(), buildSign) {
+		fmt.Println(errorStyle.Render("x") + fmt.Sprintf(" invalid --sign %q: use gpg or ssh", buildSign))
+		os.Exit(1)
+	}
+	if buildAgentCommit && (buildSign != "" || buildSigningKey != "") {
+		fmt.Println(errorStyle.Render("x") + " --sign and --signing-key only apply to harness commits, not --agent-commits")
+		os.Exit(1)
+	}
+	var branchPolicy *orchestrator.BranchPolicy
+	if buildBranches {
+		merge, err := orchestrator.ParseMergeStrategy(buildMerge)
+		if err != nil {
+			fmt.Println(errorStyle.Render("x") + " " + err.Error())
+			os.Exit(1)
+		}
+		branchPolicy = &orchestrator.BranchPolicy{
+			Prefix:      buildPrefix,
+			Integration: buildIntegration,
+			Merge:       merge,
+			Keep:        buildKeep,
+		}
+	}
+	publishConfig, err := newPublishConfig()
+	if err != nil {
+		fmt.Println(errorStyle.Render("x") + " " + err.Error())
+		os.Exit(1)
+	}
+
+	path := requirePRD("x")
+	name := displayPath(path)
+	dir, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("x") + " " + err.Error())
 		os.Exit(1)
 	}
 
+	// Upgrade the PRD from older schema versions before the build writes to it
+	migration, err := prd.MigrateFile(path)
+	if err != nil {
+		fmt.Println(errorStyle.Render("x") + " Failed to migrate " + name)
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+	if migration.NeedsMigration() {
+		fmt.Printf("%s Upgraded %s from schema version %d to %d\n", successStyle.Render("ok"), name, migration.From, migration.To)
+		fmt.Println(dimStyle.Render("  Backed up the original to " + migration.Backup))
+	}
+
 	// Load the PRD
-	p, err := prd.LoadFromCurrentDir()
+	p, err := prd.Load(path)
 	if err != nil {
-		fmt.Println(errorStyle.Render("x") + " Failed to load prd.json")
+		fmt.Println(errorStyle.Render("x") + " Failed to load " + name)
 		fmt.Println(dimStyle.Render("  " + err.Error()))
 		os.Exit(1)
 	}
 
 	// Validate the PRD
 	result := prd.Validate(p)
+	result.Merge(prd.ValidateContext(p, dir))
 	if !result.Valid {
-		fmt.Println(errorStyle.Render("x") + " prd.json has validation errors:\n")
+		fmt.Println(errorStyle.Render("x") + " " + name + " has validation errors:\n")
 		for _, e := range result.Errors {
 			fmt.Printf("  %s %s\n", errorStyle.Render("*"), e.Error())
 		}
@@ -91,7 +300,7 @@ func runBuild(cmd *cobra.Command, args []string) {
 	}
 
 	// Ensure git repo exists
-	created, err := git.EnsureRepoCurrentDir()
+	created, err := git.EnsureRepo(dir)
 	if err != nil {
 		fmt.Println(errorStyle.Render("x") + " Failed to initialize git repository")
 		fmt.Println(dimStyle.Render("  " + err.Error()))
@@ -101,19 +310,12 @@ func runBuild(cmd *cobra.Command, args []string) {
 		fmt.Println(successStyle.Render("ok") + " Initialized git repository")
 	}
 
-	// Get working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		fmt.Println(errorStyle.Render("x") + " Failed to get current directory")
-		os.Exit(1)
-	}
-
 	// Check for resume state
 	var startIteration = 1
 	var resumeFeature string
 	var maxIterations = 50
 
-	tempOrch := orchestrator.New(cwd)
+	tempOrch := orchestrator.New(dir).SetPRDPath(path)
 	resumeState, err := tempOrch.LoadResumeState()
 	if err != nil {
 		fmt.Println(errorStyle.Render("x") + " Failed to load resume state")
@@ -166,7 +368,7 @@ func runBuild(cmd *cobra.Command, args []string) {
 	}
 
 	// Create the TUI model
-	model := tui.NewModel(p, "prd.json", maxIterations)
+	model := tui.NewModel(p, name, maxIterations)
 	model.SetDebugMode(buildDebug)
 
 	// Create the Bubble Tea program with alternate screen buffer
@@ -183,8 +385,10 @@ func runBuild(cmd *cobra.Command, args []string) {
 	currentIteration := 0
 
 	// Create the orchestrator with callbacks that send messages to the TUI
-	orch := orchestrator.New(cwd).
+	orch := orchestrator.New(dir).
+		SetPRDPath(path).
 		SetDebug(buildDebug).
+		SetUseRepoMap(buildRepoMap).
 		OnMessage(func(role, content string) {
 			if role == "assistant" && content != "" {
 				program.Send(tui.LogMsg(content))
@@ -234,6 +438,12 @@ func runBuild(cmd *cobra.Command, args []string) {
 		OnStep(func(step orchestrator.Step) {
 			program.Send(tui.StepChangeMsg{Step: step})
 		}).
+		OnTestResult(func(run *orchestrator.TestRun) {
+			program.Send(tui.TestResultMsg{Run: run})
+		}).
+		OnCoverage(func(c *coverage.Comparison) {
+			program.Send(tui.CoverageMsg{Result: c})
+		}).
 		OnAction(func(action orchestrator.Action, params orchestrator.ActionParams) {
 			switch action {
 			case orchestrator.ActionReadFiles:
@@ -279,7 +489,7 @@ func runBuild(cmd *cobra.Command, args []string) {
 					var feature *prd.Feature
 					if bs.CurrentFeature != "" {
 						// Reload PRD to get current state
-						if currentPRD, err := prd.LoadFromCurrentDir(); err == nil {
+						if currentPRD, err := prd.Load(path); err == nil {
 							for i := range currentPRD.Features {
 								if currentPRD.Features[i].ID == bs.CurrentFeature {
 									feature = &currentPRD.Features[i]
@@ -360,6 +570,14 @@ func runBuild(cmd *cobra.Command, args []string) {
 			DelayBetweenIterations: 3 * time.Second,
 			StartIteration:         startIteration,
 			ResumeFeature:          resumeFeature,
+			FlakeReruns:            buildFlakeReruns,
+			MaxFeatureAttempts:     buildMaxAttempts,
+			TamperPolicy:           tamperPolicy,
+			HarnessCommits:         !buildAgentCommit,
+			Signing:                git.CommitOptions{Sign: buildSign, SigningKey: buildSigningKey},
+			FeatureBranches:        branchPolicy,
+			Publish:                publishConfig,
+			Strategy:               strategy,
 		}
 
 		// Run the build with config
@@ -379,7 +597,7 @@ func runBuild(cmd *cobra.Command, args []string) {
 			}
 		} else {
 			// Success - reload PRD to check status
-			p, err := prd.LoadFromCurrentDir()
+			p, err := prd.Load(path)
 			if err == nil {
 				program.Send(tui.PRDUpdateMsg{PRD: p, Stats: p.Stats()})
 				if p.IsComplete() {
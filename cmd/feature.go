@@ -0,0 +1,603 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+var (
+	featureID          string
+	featureDescription string
+	featureCategory    string
+	featurePriority    string
+	featureSteps       []string
+	featureAddSteps    []string
+	featureDependsOn   []string
+	featurePosition    int
+	featureBefore      string
+	featureAfter       string
+	featureYes         bool
+	featureAddDeps     []string
+	featureRemoveDeps  []string
+	featureClearDeps   bool
+	featureReason      string
+)
+
+var featureCmd = &cobra.Command{
+	Use:   "feature",
+	Short: "Add, edit and reorder features in prd.json",
+	Long: `Edit the features in prd.json without hand-writing JSON.
+
+Each subcommand asks for what it needs in a form, or takes it from flags so
+it can run in scripts. Every change is validated before prd.json is written,
+dependencies must name existing features and can't form a cycle, and the file
+keeps its formatting. prd.json is replaced atomically, so a running build never
+reads a half-written file.`,
+}
+
+var featureAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a feature",
+	Long: `Add a feature. Without --description, a form asks for its details.
+
+Examples:
+  superralph feature add
+  superralph feature add -d "Export orders as CSV" --step "Add an export button" --step "Download a CSV"
+  superralph feature add -d "Audit log" --priority high --depends-on feat-003 --position 1`,
+	Args: cobra.NoArgs,
+	Run:  runFeatureAdd,
+}
+
+var featureEditCmd = &cobra.Command{
+	Use:   "edit <feature-id>",
+	Short: "Edit a feature",
+	Long: `Edit a feature's ID, description, category, priority or steps. Without
+flags, a form shows the current values. Renaming a feature updates the
+dependencies that refer to it.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runFeatureEdit,
+}
+
+var featureRemoveCmd = &cobra.Command{
+	Use:   "remove <feature-id>",
+	Short: "Remove a feature",
+	Long:  `Remove a feature. Features that depend on it must drop the dependency first.`,
+	Args:  cobra.ExactArgs(1),
+	Run:   runFeatureRemove,
+}
+
+var featureMoveCmd = &cobra.Command{
+	Use:   "move <feature-id> [position]",
+	Short: "Move a feature to another position",
+	Long: `Move a feature to a 1-based position, or before or after another feature.
+Without a position, a form asks where to put it.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runFeatureMove,
+}
+
+var featurePriorityCmd = &cobra.Command{
+	Use:   "priority <feature-id> [priority]",
+	Short: "Change a feature's priority",
+	Long: `Set a feature's priority to one of the PRD's levels (high, medium or low
+unless it declares its own) or to an integer weight. Without a priority, a form
+asks for one.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runFeaturePriority,
+}
+
+var featureDependsCmd = &cobra.Command{
+	Use:   "depends <feature-id> [dependency-id...]",
+	Short: "Set the features a feature depends on",
+	Long: `Set a feature's dependencies to the given features, or change them with
+--add, --remove and --clear. Without either, a form lists the features to
+choose from.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runFeatureDepends,
+}
+
+var featureReopenCmd = &cobra.Command{
+	Use:   "reopen <feature-id...>",
+	Short: "Move features back to todo so the build works on them again",
+	Long: `Move passing, blocked, stuck, needs_review or skipped features back to todo,
+so the next build picks them up again.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runFeatureReopen,
+}
+
+var featureStatusCmd = &cobra.Command{
+	Use:   "status <feature-id> <status>",
+	Short: "Set a feature's status",
+	Long: `Set a feature's status: ` + joinValues(prd.ValidStatuses()) + `.
+
+Builds only work on todo and in_progress features. Blocked, stuck and
+needs_review features wait for someone to act, and skipped features won't be
+built. passes is kept in step: it's true only for passing features.
+
+Examples:
+  superralph feature status feat-004 blocked --reason "Needs Stripe API keys"
+  superralph feature status feat-007 skipped
+  superralph feature status feat-002 passing`,
+	Args: cobra.ExactArgs(2),
+	Run:  runFeatureStatus,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{featureAddCmd, featureEditCmd} {
+		c.Flags().StringVarP(&featureDescription, "description", "d", "", "What the feature does")
+		c.Flags().StringVar(&featureCategory, "category", "", "Category: one of the PRD's categories ("+joinValues(prd.ValidCategories())+" unless it declares its own)")
+		c.Flags().StringVar(&featurePriority, "priority", "", "Priority: one of the PRD's levels ("+joinValues(prd.ValidPriorities())+" unless it declares its own), or an integer weight")
+		c.Flags().StringArrayVar(&featureSteps, "step", nil, "A step to verify the feature (repeatable; replaces the steps on edit)")
+	}
+	featureAddCmd.Flags().StringVar(&featureID, "id", "", "Feature ID (default: the next free ID)")
+	featureAddCmd.Flags().StringSliceVar(&featureDependsOn, "depends-on", nil, "Features that must pass first")
+	featureAddCmd.Flags().IntVar(&featurePosition, "position", 0, "1-based position in the feature list (default: last)")
+	featureEditCmd.Flags().StringVar(&featureID, "id", "", "Rename the feature")
+	featureEditCmd.Flags().StringArrayVar(&featureAddSteps, "add-step", nil, "Append a step (repeatable)")
+
+	featureRemoveCmd.Flags().BoolVarP(&featureYes, "yes", "y", false, "Don't ask for confirmation")
+
+	featureMoveCmd.Flags().StringVar(&featureBefore, "before", "", "Move the feature before this feature")
+	featureMoveCmd.Flags().StringVar(&featureAfter, "after", "", "Move the feature after this feature")
+
+	featureDependsCmd.Flags().StringSliceVar(&featureAddDeps, "add", nil, "Add dependencies")
+	featureDependsCmd.Flags().StringSliceVar(&featureRemoveDeps, "remove", nil, "Remove dependencies")
+	featureDependsCmd.Flags().BoolVar(&featureClearDeps, "clear", false, "Remove every dependency")
+
+	featureStatusCmd.Flags().StringVar(&featureReason, "reason", "", "Why the feature is blocked")
+
+	featureCmd.AddCommand(featureAddCmd, featureEditCmd, featureRemoveCmd, featureMoveCmd,
+		featurePriorityCmd, featureDependsCmd, featureReopenCmd, featureStatusCmd)
+	rootCmd.AddCommand(featureCmd)
+}
+
+func runFeatureAdd(cmd *cobra.Command, args []string) {
+	f := prd.Feature{
+		ID:          featureID,
+		Description: featureDescription,
+		Category:    prd.Category(featureCategory),
+		Priority:    prd.Priority(featurePriority),
+		Steps:       featureSteps,
+		DependsOn:   featureDependsOn,
+	}
+
+	if !cmd.Flags().Changed("description") {
+		current := loadFeaturePRD()
+		if f.ID == "" {
+			f.ID = current.NextFeatureID()
+		}
+		if f.Category == "" {
+			f.Category = current.DefaultCategory()
+		}
+		if f.Priority == "" {
+			f.Priority = current.DefaultPriority()
+		}
+		steps := strings.Join(f.Steps, "\n")
+		fields := []huh.Field{huh.NewInput().
+			Title("ID").
+			Value(&f.ID).
+			Validate(func(s string) error {
+				if current.GetFeature(strings.TrimSpace(s)) != nil {
+					return fmt.Errorf("%s already exists", s)
+				}
+				return nil
+			})}
+		fields = append(fields, featureFields(current, &f, &steps)...)
+		if len(current.Features) > 0 {
+			fields = append(fields, huh.NewMultiSelect[string]().
+				Title("Depends on").
+				Description("Features that must pass first").
+				Options(featureOptions(current, "")...).
+				Value(&f.DependsOn))
+		}
+		runFeatureForm(huh.NewForm(huh.NewGroup(fields...)))
+		f.ID = strings.TrimSpace(f.ID)
+		f.Steps = splitSteps(steps)
+	}
+
+	updatePRD(func(p *prd.PRD) error {
+		if f.ID == "" {
+			f.ID = p.NextFeatureID()
+		}
+		if f.Category == "" {
+			f.Category = p.DefaultCategory()
+		}
+		if f.Priority == "" {
+			f.Priority = p.DefaultPriority()
+		}
+		return p.AddFeature(f, featurePosition)
+	})
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Added %s: %s", f.ID, f.Description))
+}
+
+func runFeatureEdit(cmd *cobra.Command, args []string) {
+	id := args[0]
+	edited := false
+	for _, name := range []string{"id", "description", "category", "priority", "step", "add-step"} {
+		edited = edited || cmd.Flags().Changed(name)
+	}
+
+	if !edited {
+		current := loadFeaturePRD()
+		existing := current.GetFeature(id)
+		if existing == nil {
+			fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Feature %s not found in the PRD", id))
+			os.Exit(1)
+		}
+		f := *existing
+		steps := strings.Join(f.Steps, "\n")
+		runFeatureForm(huh.NewForm(huh.NewGroup(featureFields(current, &f, &steps)...)))
+		featureDescription, featureCategory, featurePriority = f.Description, string(f.Category), string(f.Priority)
+		featureSteps = splitSteps(steps)
+	}
+
+	newID := id
+	updatePRD(func(p *prd.PRD) error {
+		f := p.GetFeature(id)
+		if f == nil {
+			return fmt.Errorf("feature %s not found", id)
+		}
+		if featureDescription != "" {
+			f.Description = featureDescription
+		}
+		if featureCategory != "" {
+			f.Category = prd.Category(featureCategory)
+		}
+		if featurePriority != "" {
+			f.Priority = prd.Priority(featurePriority)
+		}
+		if len(featureSteps) > 0 {
+			f.Steps = featureSteps
+		}
+		f.Steps = append(f.Steps, featureAddSteps...)
+		if featureID != "" {
+			newID = featureID
+			return p.RenameFeature(id, featureID)
+		}
+		return nil
+	})
+	if newID != id {
+		fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Updated %s (now %s)", id, newID))
+		return
+	}
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Updated %s", id))
+}
+
+func runFeatureRemove(cmd *cobra.Command, args []string) {
+	id := args[0]
+	if !featureYes {
+		current := loadFeaturePRD()
+		f := current.GetFeature(id)
+		if f == nil {
+			fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Feature %s not found in the PRD", id))
+			os.Exit(1)
+		}
+		var confirm bool
+		runFeatureForm(huh.NewForm(huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Remove %s?", id)).
+				Description(f.Description).
+				Affirmative("Remove").
+				Negative("Cancel").
+				Value(&confirm),
+		)))
+		if !confirm {
+			fmt.Println("Canceled")
+			return
+		}
+	}
+
+	updatePRD(func(p *prd.PRD) error { return p.RemoveFeature(id) })
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Removed %s", id))
+}
+
+func runFeatureMove(cmd *cobra.Command, args []string) {
+	id := args[0]
+	position := 0
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Invalid position %q", args[1]))
+			os.Exit(1)
+		}
+		position = n
+	}
+
+	if position == 0 && featureBefore == "" && featureAfter == "" {
+		current := loadFeaturePRD()
+		options := featureOptions(current, id)
+		if len(options) == 0 {
+			fmt.Println(dimStyle.Render("  There are no other features to move around"))
+			return
+		}
+		var target string
+		runFeatureForm(huh.NewForm(huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(fmt.Sprintf("Move %s before", id)).
+				Options(append(options, huh.NewOption("(the end of the list)", ""))...).
+				Value(&target),
+		)))
+		if target == "" {
+			position = len(current.Features)
+		}
+		featureBefore = target
+	}
+
+	updatePRD(func(p *prd.PRD) error {
+		if position == 0 {
+			target, offset := featureBefore, 1
+			if featureAfter != "" {
+				target, offset = featureAfter, 2
+			}
+			if target == id {
+				return fmt.Errorf("cannot move %s relative to itself", id)
+			}
+			var others []string
+			for _, f := range p.Features {
+				if f.ID != id {
+					others = append(others, f.ID)
+				}
+			}
+			i := slices.Index(others, target)
+			if i < 0 {
+				return fmt.Errorf("feature %s not found", target)
+			}
+			position = i + offset
+		}
+		return p.MoveFeature(id, position)
+	})
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Moved %s to position %d", id, position))
+}
+
+func runFeaturePriority(cmd *cobra.Command, args []string) {
+	id := args[0]
+	var priority prd.Priority
+	if len(args) == 2 {
+		priority = prd.Priority(args[1])
+	} else {
+		current := loadFeaturePRD()
+		if f := current.GetFeature(id); f != nil {
+			priority = f.Priority
+		}
+		runFeatureForm(huh.NewForm(huh.NewGroup(
+			priorityField(current, fmt.Sprintf("Priority of %s", id), &priority),
+		)))
+	}
+
+	updatePRD(func(p *prd.PRD) error {
+		f := p.GetFeature(id)
+		if f == nil {
+			return fmt.Errorf("feature %s not found", id)
+		}
+		f.Priority = priority
+		return nil
+	})
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" %s is now %s priority", id, priority))
+}
+
+func runFeatureDepends(cmd *cobra.Command, args []string) {
+	id, deps := args[0], args[1:]
+	set := len(deps) > 0 || featureClearDeps
+
+	if !set && len(featureAddDeps) == 0 && len(featureRemoveDeps) == 0 {
+		current := loadFeaturePRD()
+		f := current.GetFeature(id)
+		if f == nil {
+			fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Feature %s not found in the PRD", id))
+			os.Exit(1)
+		}
+		deps = slices.Clone(f.DependsOn)
+		runFeatureForm(huh.NewForm(huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title(fmt.Sprintf("%s depends on", id)).
+				Description("Features that must pass first").
+				Options(featureOptions(current, id)...).
+				Value(&deps),
+		)))
+		set = true
+	}
+
+	var result []string
+	updatePRD(func(p *prd.PRD) error {
+		f := p.GetFeature(id)
+		if f == nil {
+			return fmt.Errorf("feature %s not found", id)
+		}
+		next := deps
+		if !set {
+			next = slices.Clone(f.DependsOn)
+		}
+		next = append(next, featureAddDeps...)
+		next = slices.DeleteFunc(next, func(dep string) bool { return slices.Contains(featureRemoveDeps, dep) })
+		if err := p.SetDependencies(id, next); err != nil {
+			return err
+		}
+		result = f.DependsOn
+		return nil
+	})
+	if len(result) == 0 {
+		fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" %s has no dependencies", id))
+		return
+	}
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" %s depends on %s", id, strings.Join(result, ", ")))
+}
+
+func runFeatureReopen(cmd *cobra.Command, args []string) {
+	var reopened []string
+	updatePRD(func(p *prd.PRD) error {
+		for _, id := range args {
+			ok, err := p.Reopen(id)
+			if err != nil {
+				return err
+			}
+			if ok {
+				reopened = append(reopened, id)
+			}
+		}
+		return nil
+	})
+	for _, id := range args {
+		if slices.Contains(reopened, id) {
+			fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Reopened %s", id))
+		} else {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  %s is already open", id)))
+		}
+	}
+}
+
+func runFeatureStatus(cmd *cobra.Command, args []string) {
+	id := args[0]
+	status, err := prd.ParseStatus(args[1])
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+
+	var summary string
+	updatePRD(func(p *prd.PRD) error {
+		if err := p.SetFeatureStatus(id, status, featureReason); err != nil {
+			return err
+		}
+		summary = p.GetFeature(id).StatusSummary()
+		return nil
+	})
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" %s is now %s", id, summary))
+}
+
+// featureFields are the form fields for a feature's description, category,
+// priority and steps, offering the PRD's categories and priorities
+func featureFields(current *prd.PRD, f *prd.Feature, steps *string) []huh.Field {
+	var categories []huh.Option[prd.Category]
+	for _, c := range current.AllowedCategories() {
+		categories = append(categories, huh.NewOption(string(c), c))
+	}
+	return []huh.Field{
+		huh.NewInput().
+			Title("Description").
+			Description("What the feature does").
+			Value(&f.Description).
+			Validate(func(s string) error {
+				if strings.TrimSpace(s) == "" {
+					return errors.New("a description is required")
+				}
+				return nil
+			}),
+		huh.NewSelect[prd.Category]().
+			Title("Category").
+			Options(categories...).
+			Value(&f.Category),
+		priorityField(current, "Priority", &f.Priority),
+		huh.NewText().
+			Title("Steps").
+			Description("How to verify the feature, one step per line").
+			Value(steps).
+			Validate(func(s string) error {
+				if len(splitSteps(s)) == 0 {
+					return errors.New("at least one step is required")
+				}
+				return nil
+			}),
+	}
+}
+
+// priorityField is a select of the PRD's priority levels, or an input for
+// the weight when the PRD ranks features by weight
+func priorityField(current *prd.PRD, title string, priority *prd.Priority) huh.Field {
+	if current.UsesWeights() {
+		weight := string(*priority)
+		return huh.NewInput().
+			Title(title).
+			Description("Integer weight; higher is built first").
+			Value(&weight).
+			Validate(func(s string) error {
+				if _, ok := prd.Priority(strings.TrimSpace(s)).Weight(); !ok {
+					return errors.New("the weight must be a whole number")
+				}
+				*priority = prd.Priority(strings.TrimSpace(s))
+				return nil
+			})
+	}
+	var options []huh.Option[prd.Priority]
+	for _, p := range current.AllowedPriorities() {
+		options = append(options, huh.NewOption(string(p), p))
+	}
+	return huh.NewSelect[prd.Priority]().
+		Title(title).
+		Options(options...).
+		Value(priority)
+}
+
+// featureOptions lists the PRD's features as form options, leaving out one
+func featureOptions(p *prd.PRD, except string) []huh.Option[string] {
+	var options []huh.Option[string]
+	for _, f := range p.Features {
+		if f.ID != except {
+			options = append(options, huh.NewOption(f.ID+" - "+f.Description, f.ID))
+		}
+	}
+	return options
+}
+
+// splitSteps turns one step per line into a step list
+func splitSteps(text string) []string {
+	var steps []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			steps = append(steps, line)
+		}
+	}
+	return steps
+}
+
+// joinValues lists a set of string values for help text
+func joinValues[T ~string](values []T) string {
+	var s []string
+	for _, v := range values {
+		s = append(s, string(v))
+	}
+	return strings.Join(s, ", ")
+}
+
+// loadFeaturePRD loads the PRD to fill in a form
+func loadFeaturePRD() *prd.PRD {
+	path := requirePRD("✗")
+	p, err := prd.Load(path)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to load " + displayPath(path))
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+	return p
+}
+
+// runFeatureForm runs a form, exiting if it is canceled or can't run
+func runFeatureForm(form *huh.Form) {
+	if err := form.Run(); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			fmt.Println("Canceled")
+			os.Exit(0)
+		}
+		fmt.Println(errorStyle.Render("✗") + " Interactive input failed; use flags instead")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+}
+
+// updatePRD applies an edit to the PRD, exiting if it fails or would leave
+// the PRD invalid
+func updatePRD(edit func(p *prd.PRD) error) {
+	path := requirePRD("✗")
+	if _, err := prd.Update(path, edit); err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to update " + displayPath(path))
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/flakes"
+)
+
+var flakesAll bool
+
+var flakesCmd = &cobra.Command{
+	Use:   "flakes",
+	Short: "List flaky tests detected during builds",
+	Long: `Flakes lists tests that failed the test gate and then passed when rerun.
+
+SuperRalph records every test that fails the gate in .superralph/flakes.json,
+along with whether it passed on a rerun. Known flakes don't count against a
+feature's attempt budget, and are listed in Claude's context so it reruns them
+instead of changing unrelated code.`,
+	Run: runFlakes,
+}
+
+func init() {
+	flakesCmd.Flags().BoolVar(&flakesAll, "all", false, "Include tests that failed deterministically")
+	rootCmd.AddCommand(flakesCmd)
+}
+
+func runFlakes(cmd *cobra.Command, args []string) {
+	dir, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+
+	history, err := flakes.Load(dir)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to load flake history")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	records := history.Flaky()
+	if flakesAll {
+		records = history.All()
+	}
+
+	if len(records) == 0 {
+		fmt.Println(successStyle.Render("✓") + " No flaky tests recorded")
+		return
+	}
+
+	title := fmt.Sprintf("%d flaky tests", len(records))
+	if flakesAll {
+		title = fmt.Sprintf("%d tests have failed the test gate", len(records))
+	}
+	fmt.Println(boldStyle.Render(title) + "\n")
+
+	for _, r := range records {
+		icon := warnStyle.Render("~")
+		if !r.IsFlaky() {
+			icon = errorStyle.Render("✗")
+		}
+		fmt.Printf("  %s %s\n", icon, r.String())
+		fmt.Printf("    %s\n", dimStyle.Render(fmt.Sprintf("%d flaky, %d failed, last seen %s",
+			r.Flakes, r.Failures, r.LastSeen.Local().Format("2006-01-02 15:04"))))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/graph"
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+var (
+	graphFormat string
+	graphOutput string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Analyze and draw the feature dependency graph",
+	Long: `Graph analyzes the depends_on relationships in prd.json:
+  - the dependency tree, with each feature's status
+  - a build order that puts every feature after its dependencies
+  - the critical path: the longest chain of features still to build
+  - dependency cycles and features that can never be built
+
+Use --format dot for Graphviz or --format mermaid for Markdown documents,
+and --output to write to a file (the format follows a .dot, .gv or .mmd
+extension).
+
+Examples:
+  superralph graph
+  superralph graph --format dot | dot -Tsvg > graph.svg
+  superralph graph -o graph.mmd`,
+	Run: runGraph,
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "", "Output format: text, dot or mermaid (default: text)")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "Write the graph to a file instead of stdout")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) {
+	format := graphFormat
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(graphOutput)) {
+		case ".dot", ".gv":
+			format = "dot"
+		case ".mmd", ".mermaid":
+			format = "mermaid"
+		default:
+			format = "text"
+		}
+	}
+	if format != "text" && format != "dot" && format != "mermaid" {
+		fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Invalid format %q (must be text, dot or mermaid)", format))
+		os.Exit(1)
+	}
+
+	path := requirePRD("✗")
+	p, err := prd.Load(path)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to load " + displayPath(path))
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	switch format {
+	case "dot":
+		writeGraph(graph.DOT(p), format)
+	case "mermaid":
+		writeGraph(graph.Mermaid(p), format)
+	default:
+		if graphOutput != "" {
+			fmt.Println(errorStyle.Render("✗") + " --output needs --format dot or mermaid")
+			os.Exit(1)
+		}
+		printGraph(p)
+	}
+}
+
+// writeGraph prints the graph, or writes it to --output
+func writeGraph(out, format string) {
+	if graphOutput == "" {
+		fmt.Print(out)
+		return
+	}
+	if err := os.WriteFile(graphOutput, []byte(out), 0644); err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to write the graph")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Wrote %s graph to %s", format, graphOutput))
+}
+
+// printGraph shows the dependency tree and the analysis
+func printGraph(p *prd.PRD) {
+	statuses := graph.Statuses(p, "")
+	icons := map[graph.Status]string{
+		graph.StatusPassing:     successStyle.Render("✓"),
+		graph.StatusReady:       "○",
+		graph.StatusBlocked:     warnStyle.Render("✗"),
+		graph.StatusHeld:        warnStyle.Render("‖"),
+		graph.StatusUnreachable: errorStyle.Render("⊘"),
+	}
+	descriptions := make(map[string]string, len(p.Features))
+	for _, f := range p.Features {
+		descriptions[f.ID] = f.Description
+	}
+
+	fmt.Println(boldStyle.Render("Dependencies"))
+	for _, n := range graph.Tree(p) {
+		detail := descriptions[n.ID]
+		if n.Repeat {
+			detail = "(see above)"
+		}
+		fmt.Printf("  %s%s %s %s\n", dimStyle.Render(n.Prefix), icons[statuses[n.ID]], n.ID, dimStyle.Render(detail))
+	}
+	fmt.Println()
+
+	cycles := p.Cycles()
+	for _, cycle := range cycles {
+		fmt.Println(errorStyle.Render("✗") + " Dependency cycle: " + strings.Join(cycle, " -> "))
+	}
+	if unreachable := p.Unreachable(); len(unreachable) > 0 {
+		fmt.Println(errorStyle.Render("✗") + " Can never be built: " + strings.Join(unreachable, ", "))
+	}
+	if len(cycles) > 0 {
+		fmt.Println()
+	}
+
+	if order, err := p.TopologicalOrder(); err == nil {
+		fmt.Printf("%s %s\n", boldStyle.Render("Build order:"), strings.Join(order, ", "))
+	}
+	if path := p.CriticalPath(); len(path) > 0 {
+		fmt.Printf("%s %s %s\n", boldStyle.Render("Critical path:"), strings.Join(path, " -> "),
+			dimStyle.Render(fmt.Sprintf("(at least %d more iterations)", len(path))))
+	} else if p.IsComplete() {
+		fmt.Println(successStyle.Render("All features complete!"))
+	}
+
+	if len(cycles) > 0 {
+		os.Exit(1)
+	}
+}
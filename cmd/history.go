@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/history"
+	"github.com/mpjhorner/superralph/internal/tui/components"
+)
+
+var (
+	historyJSON bool
+	historyTUI  bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [feature-id...]",
+	Short: "Show a timeline of the work on each feature",
+	Long: `History builds a timeline for each feature from:
+  - git commits, by Feature-Id trailer or by mentioning the feature ID
+  - progress.txt entries
+  - saved build sessions and interrupted build state
+  - test integrity reviews and published pull requests
+
+It shows when work started, how many iterations it took, how long until the
+feature passed, its commits, test outcomes and current status.
+
+Give feature IDs to limit the output; a single ID shows its full timeline.
+Use --json for machine-readable output or --tui to browse interactively.`,
+	Run: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Print the timelines as JSON")
+	historyCmd.Flags().BoolVar(&historyTUI, "tui", false, "Browse the timelines interactively")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) {
+	path := requirePRD("✗")
+	dir, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+
+	src, err := history.Load(dir, path)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to load history")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	timelines := history.Build(src)
+	if len(args) > 0 {
+		var selected []*history.Timeline
+		for _, id := range args {
+			t := history.Find(timelines, id)
+			if t == nil {
+				fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Feature %s not found in %s", id, displayPath(path)))
+				os.Exit(1)
+			}
+			selected = append(selected, t)
+		}
+		timelines = selected
+	}
+
+	switch {
+	case historyJSON:
+		data, err := json.MarshalIndent(timelines, "", "  ")
+		if err != nil {
+			fmt.Println(errorStyle.Render("✗") + " Failed to encode history: " + err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	case historyTUI:
+		program := tea.NewProgram(historyModel{view: components.NewHistoryView(timelines, 80, 24)}, tea.WithAltScreen())
+		if _, err := program.Run(); err != nil {
+			fmt.Println("Error running history TUI:", err)
+			os.Exit(1)
+		}
+	case len(timelines) == 1:
+		printTimeline(timelines[0])
+	default:
+		printHistoryTable(timelines)
+	}
+}
+
+// printHistoryTable prints one line per feature
+func printHistoryTable(timelines []*history.Timeline) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FEATURE\tSTATUS\tSTARTED\tDURATION\tITERATIONS\tCOMMITS\tTESTS")
+	for _, t := range timelines {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			t.FeatureID, t.Status, formatHistoryTime(t.Started), formatHistoryDuration(t),
+			t.Attempts, len(t.Commits), formatHistoryTests(t))
+	}
+	_ = w.Flush()
+}
+
+// printTimeline prints a feature's summary and every event
+func printTimeline(t *history.Timeline) {
+	fmt.Println(boldStyle.Render(t.FeatureID+": "+t.Description) + "\n")
+	fmt.Printf("  Status:     %s\n", t.Status)
+	fmt.Printf("  Started:    %s\n", formatHistoryTime(t.Started))
+	fmt.Printf("  Finished:   %s\n", formatHistoryTime(t.Finished))
+	fmt.Printf("  Duration:   %s\n", formatHistoryDuration(t))
+	fmt.Printf("  Iterations: %d\n", t.Attempts)
+	fmt.Printf("  Tests:      %s\n", formatHistoryTests(t))
+
+	if len(t.Commits) > 0 {
+		fmt.Println("\n" + boldStyle.Render("Commits"))
+		for _, c := range t.Commits {
+			hash := c.Hash
+			if len(hash) > 7 {
+				hash = hash[:7]
+			}
+			fmt.Printf("  %s %s\n", warnStyle.Render(hash), c.Subject)
+		}
+	}
+
+	fmt.Println("\n" + boldStyle.Render("Timeline"))
+	if len(t.Events) == 0 {
+		fmt.Println(dimStyle.Render("  No recorded activity"))
+	}
+	for _, e := range t.Events {
+		when := strings.Repeat(" ", 16)
+		if !e.Time.IsZero() {
+			when = e.Time.Local().Format("2006-01-02 15:04")
+		}
+		iteration := ""
+		if e.Iteration > 0 {
+			iteration = dimStyle.Render(fmt.Sprintf(" (iteration %d)", e.Iteration))
+		}
+		fmt.Printf("  %s  %-11s %s%s\n", dimStyle.Render(when), e.Kind, e.Detail, iteration)
+	}
+	fmt.Println()
+}
+
+func formatHistoryTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func formatHistoryDuration(t *history.Timeline) string {
+	if t.Finished == nil || t.Started == nil {
+		return "-"
+	}
+	return t.Duration().Round(time.Minute).String()
+}
+
+func formatHistoryTests(t *history.Timeline) string {
+	if len(t.Tests) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d passed", t.TestsPassed(), len(t.Tests))
+}
+
+// historyModel browses timelines in the TUI
+type historyModel struct {
+	view *components.HistoryView
+}
+
+func (m historyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m historyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.view.SetSize(msg.Width, msg.Height)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			m.view.MoveUp()
+		case "down", "j":
+			m.view.MoveDown()
+		}
+	}
+	return m, nil
+}
+
+func (m historyModel) View() string {
+	return m.view.Render()
+}
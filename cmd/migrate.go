@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade prd.json to the current schema version",
+	Long: `Migrate upgrades prd.json in place to the schema version this superralph
+uses, recorded in its "schemaVersion" field. Files without one are version 0.
+The original is backed up to .superralph/backups first.
+
+Older files are always read correctly, and 'superralph build' upgrades them
+when it starts, so running this is only needed to upgrade files ahead of time.
+
+Examples:
+  superralph migrate --dry-run
+  superralph migrate`,
+	Run: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show the migrations without changing prd.json")
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func runMigrate(cmd *cobra.Command, args []string) {
+	path := requirePRD("✗")
+	name := displayPath(path)
+
+	var result *prd.MigrationResult
+	var err error
+	if migrateDryRun {
+		result, err = prd.CheckMigrations(path)
+	} else {
+		result, err = prd.MigrateFile(path)
+	}
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to migrate " + name)
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	if !result.NeedsMigration() {
+		fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" %s is up to date (schema version %d)", name, result.From))
+		return
+	}
+	if migrateDryRun {
+		fmt.Printf("%s would be upgraded from schema version %d to %d:\n", name, result.From, result.To)
+	} else {
+		fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Upgraded %s from schema version %d to %d:", name, result.From, result.To))
+	}
+	for _, applied := range result.Applied {
+		fmt.Println("  • " + applied)
+	}
+	if result.Backup != "" {
+		fmt.Println(dimStyle.Render("  Backed up the original to " + result.Backup))
+	}
+}
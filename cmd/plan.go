@@ -28,12 +28,12 @@ Claude will:
   1. Ask what you're building
   2. Explore your existing code
   3. Help you think through features
-  4. Create a well-structured prd.json
+  4. Create a well-structured prd.json (or the file named by --prd)
 
 Before starting, you can tag files with @ syntax to include them in Claude's context.
 This helps Claude understand your codebase better.
 
-If a prd.json already exists, you'll be asked to confirm before replacing it.`,
+If the PRD already exists, you'll be asked to confirm before replacing it.`,
 	Run: runPlan,
 }
 
@@ -52,13 +52,25 @@ var (
 )
 
 func runPlan(cmd *cobra.Command, args []string) {
-	// Check if prd.json already exists
-	if prd.ExistsInCurrentDir() {
+	cwd, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+	path, err := prdPath()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+	name := displayPath(path)
+
+	// Check if a PRD already exists
+	if existing := path; prd.Exists(existing) {
 		var confirm bool
 		form := huh.NewForm(
 			huh.NewGroup(
 				huh.NewConfirm().
-					Title("prd.json already exists").
+					Title(displayPath(existing) + " already exists").
 					Description("Do you want to replace it with a new PRD?").
 					Affirmative("Yes, start fresh").
 					Negative("No, cancel").
@@ -72,25 +84,17 @@ func runPlan(cmd *cobra.Command, args []string) {
 			os.Exit(0)
 		}
 
-		// Back up existing PRD
-		existingPRD, err := prd.LoadFromCurrentDir()
-		if err == nil {
-			backupPath := "prd.json.backup"
-			if err := prd.Save(existingPRD, backupPath); err == nil {
-				fmt.Println(dimStyle.Render("  Backed up existing PRD to " + backupPath))
+		// Back up existing PRD, as written
+		if data, err := os.ReadFile(existing); err == nil {
+			backupPath := existing + ".backup"
+			if err := os.WriteFile(backupPath, data, 0644); err == nil {
+				fmt.Println(dimStyle.Render("  Backed up existing PRD to " + displayPath(backupPath)))
 			}
 		}
 	}
 
-	// Get working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		fmt.Println(errorStyle.Render("✗") + " Failed to get current directory")
-		os.Exit(1)
-	}
-
 	// Create the orchestrator (needed for file listing)
-	orch := orchestrator.New(cwd).SetDebug(planDebug)
+	orch := orchestrator.New(cwd).SetPRDPath(path).SetDebug(planDebug)
 
 	// File tagging step (unless skipped)
 	var taggedFiles []string
@@ -194,11 +198,11 @@ func runPlan(cmd *cobra.Command, args []string) {
 
 	fmt.Println()
 
-	// Check if prd.json was created
-	if prd.ExistsInCurrentDir() {
-		p, err := prd.LoadFromCurrentDir()
+	// Check if the PRD was created
+	if prd.Exists(path) {
+		p, err := prd.Load(path)
 		if err != nil {
-			fmt.Println(warnStyle.Render("⚠") + " prd.json was created but has errors")
+			fmt.Println(warnStyle.Render("⚠") + " " + name + " was created but has errors")
 			fmt.Println(dimStyle.Render("  Run 'superralph validate' to see details"))
 			os.Exit(1)
 		}
@@ -206,7 +210,7 @@ func runPlan(cmd *cobra.Command, args []string) {
 		// Validate
 		result := prd.Validate(p)
 		if !result.Valid {
-			fmt.Println(warnStyle.Render("⚠") + " prd.json was created but has validation errors:")
+			fmt.Println(warnStyle.Render("⚠") + " " + name + " was created but has validation errors:")
 			for _, e := range result.Errors {
 				fmt.Printf("  %s %s\n", errorStyle.Render("•"), e.Error())
 			}
@@ -215,7 +219,7 @@ func runPlan(cmd *cobra.Command, args []string) {
 			os.Exit(1)
 		}
 
-		fmt.Println(successStyle.Render("✓") + " prd.json created successfully!")
+		fmt.Println(successStyle.Render("✓") + " " + name + " created successfully!")
 		fmt.Println()
 
 		stats := p.Stats()
@@ -225,7 +229,7 @@ func runPlan(cmd *cobra.Command, args []string) {
 		fmt.Println()
 		fmt.Println(dimStyle.Render("  Run 'superralph build' to start implementing features"))
 	} else {
-		fmt.Println(warnStyle.Render("⚠") + " No prd.json was created")
+		fmt.Println(warnStyle.Render("⚠") + " No " + name + " was created")
 		fmt.Println(dimStyle.Render("  The planning session ended without creating a PRD"))
 	}
 }
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+var (
+	prdFlag string
+	dirFlag string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&prdFlag, "prd", "", "PRD file (json, yaml, toml or md), or a directory holding one (default: the PRD in the work directory); saving prd.toml keeps only its top comment block")
+	rootCmd.PersistentFlags().StringVarP(&dirFlag, "dir", "C", "", "Work directory, where the agent works and .superralph/ state is kept (default: the PRD's directory, or the current directory)")
+}
+
+// projectDir returns the work directory: --dir, the directory of --prd, or
+// the current directory
+func projectDir() (string, error) {
+	if dirFlag != "" {
+		return filepath.Abs(dirFlag)
+	}
+	if prdFlag != "" {
+		path, err := prd.Resolve(prdFlag)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Dir(path), nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return cwd, nil
+}
+
+// prdPath returns the PRD file: --prd, or the PRD in the work directory
+func prdPath() (string, error) {
+	if prdFlag != "" {
+		return prd.Resolve(prdFlag)
+	}
+	dir, err := projectDir()
+	if err != nil {
+		return "", err
+	}
+	return prd.PathInDir(dir), nil
+}
+
+// displayPath shortens a path to be relative to the current directory when
+// it is inside it
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	return path
+}
+
+// requirePRD returns the PRD file, or exits with a hint if there isn't one.
+// In a monorepo root the hint lists the PRDs in its subprojects.
+func requirePRD(icon string) string {
+	path, err := prdPath()
+	if err != nil {
+		fmt.Println(errorStyle.Render(icon) + " " + err.Error())
+		os.Exit(1)
+	}
+	if prd.Exists(path) {
+		return path
+	}
+
+	fmt.Println(errorStyle.Render(icon) + fmt.Sprintf(" %s not found", displayPath(path)))
+	if prdFlag == "" {
+		if dir, err := projectDir(); err == nil {
+			if found, err := prd.Discover(dir); err == nil && len(found) > 0 {
+				fmt.Println(dimStyle.Render("  Found PRDs in subprojects; pick one with --prd:"))
+				for _, p := range found {
+					fmt.Println(dimStyle.Render("    " + displayPath(p)))
+				}
+				os.Exit(1)
+			}
+		}
+	}
+	fmt.Println(dimStyle.Render("  Run 'superralph plan' to create one"))
+	os.Exit(1)
+	return ""
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "List the PRDs in this directory and its subprojects",
+	Long: `Projects finds every PRD under the current directory (or --dir), for monorepos
+with one PRD per service, and shows how far along each one is.
+
+Each project is the directory holding its PRD: builds run there and keep their
+state in that directory's .superralph/, so projects don't share resume state,
+flake history or run logs. Point any command at a project with --prd:
+
+  superralph --prd services/api build
+  superralph --prd services/web/prd.md status
+
+Hidden directories and dependency directories such as node_modules are not
+searched.`,
+	Run: runProjects,
+}
+
+func init() {
+	rootCmd.AddCommand(projectsCmd)
+}
+
+func runProjects(cmd *cobra.Command, args []string) {
+	root := dirFlag
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+
+	found, err := prd.Discover(root)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+	if len(found) == 0 {
+		fmt.Println(warnStyle.Render("⚠") + " No PRDs found")
+		fmt.Println(dimStyle.Render("  Run 'superralph plan' in a project directory to create one"))
+		return
+	}
+
+	noun := "projects"
+	if len(found) == 1 {
+		noun = "project"
+	}
+	fmt.Println(boldStyle.Render(fmt.Sprintf("%d %s", len(found), noun)) + "\n")
+	for _, path := range found {
+		p, err := prd.Load(path)
+		if err != nil {
+			fmt.Printf("  %s %s\n", errorStyle.Render("✗"), displayPath(path))
+			fmt.Printf("    %s\n", dimStyle.Render(err.Error()))
+			continue
+		}
+
+		stats := p.Stats()
+		icon := warnStyle.Render("○")
+		if p.IsComplete() {
+			icon = successStyle.Render("✓")
+		}
+		fmt.Printf("  %s %s %s\n", icon, p.Name, dimStyle.Render(displayPath(path)))
+		status := fmt.Sprintf("%d/%d features passing", stats.PassingFeatures, stats.TotalFeatures)
+		if next := p.NextFeature(); next != nil {
+			status += fmt.Sprintf(", next: %s", next.ID)
+		}
+		fmt.Printf("    %s\n", dimStyle.Render(status))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/report"
+	"github.com/mpjhorner/superralph/internal/runs"
+)
+
+var (
+	reportRun    string
+	reportFormat string
+	reportOutput string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a report of a build run",
+	Long: `Report summarizes a build run for people who weren't watching it:
+  - features completed and features stuck, with their failing gates
+  - every iteration, with its time, cost and gate results
+  - time and cost per feature
+  - files changed, with diff stats and the diffs themselves
+  - the agent's notes for the next session
+
+The report covers the latest run unless --run names one; a unique prefix of
+the run ID is enough. Runs are recorded in .superralph/runs/.
+
+Markdown is printed by default. Use --format html for a self-contained page,
+and --output to write to a file (the format follows a .html extension).`,
+	Run: runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportRun, "run", "", "Run ID to report on (default: the latest run)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "", "Output format: markdown or html (default: markdown)")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) {
+	path := requirePRD("✗")
+	dir, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+
+	format := reportFormat
+	if format == "" {
+		format = "markdown"
+		if ext := strings.ToLower(filepath.Ext(reportOutput)); ext == ".html" || ext == ".htm" {
+			format = "html"
+		}
+	}
+	if format == "md" {
+		format = "markdown"
+	}
+	if format != "markdown" && format != "html" {
+		fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Invalid format %q (must be markdown or html)", format))
+		os.Exit(1)
+	}
+
+	var run *runs.Run
+	if reportRun != "" {
+		run, err = runs.Load(dir, reportRun)
+	} else {
+		run, err = runs.Latest(dir)
+	}
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to find the run")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	r, err := report.Build(dir, path, run)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to build the report")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	out := report.Markdown(r)
+	if format == "html" {
+		if out, err = report.HTML(r); err != nil {
+			fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if reportOutput == "" {
+		fmt.Print(out)
+		return
+	}
+	if err := os.WriteFile(reportOutput, []byte(out), 0644); err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to write the report")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Wrote %s report for run %s to %s", format, run.ID, reportOutput))
+}
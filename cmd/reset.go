@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/reset"
+)
+
+var (
+	resetDependents bool
+	resetRevert     bool
+	resetReason     string
+	resetDryRun     bool
+	resetSign       string
+	resetSigningKey string
+)
+
+var resetCmd = &cobra.Command{
+	Use:   "reset <feature-id>",
+	Short: "Put a finished feature back in the build queue",
+	Long: `Reset marks a feature as not passing so the next build implements it again,
+and appends a note to progress.txt saying why.
+
+  --dependents  also reopens every feature that depends on it, transitively
+  --revert      reverts the commits that implemented it, found by their
+                Feature-Id trailers or the commits listed in progress.txt.
+                Commits that also implement other features are left alone.
+                The reverts are committed with the updated prd.json and
+                progress.txt; a revert that conflicts outside those files
+                stops the reset without changing anything.
+
+Use --dry-run to see exactly what would change. Without --reason you are
+asked for one.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReset,
+}
+
+func init() {
+	resetCmd.Flags().BoolVar(&resetDependents, "dependents", false, "Also reopen features that depend on it")
+	resetCmd.Flags().BoolVar(&resetRevert, "revert", false, "Revert the feature's commits")
+	resetCmd.Flags().StringVarP(&resetReason, "reason", "m", "", "Why the feature is being reset")
+	resetCmd.Flags().BoolVar(&resetDryRun, "dry-run", false, "Show what would change without changing anything")
+	resetCmd.Flags().StringVar(&resetSign, "sign", "", "Sign the revert commit: gpg or ssh")
+	resetCmd.Flags().StringVar(&resetSigningKey, "signing-key", "", "Key to sign the revert commit with (default: git's user.signingkey)")
+	rootCmd.AddCommand(resetCmd)
+}
+
+func runReset(cmd *cobra.Command, args []string) {
+	path := requirePRD("✗")
+	dir, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+	if !slices.Contains(git.ValidSignModes(), resetSign) {
+		fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Invalid --sign %q: use gpg or ssh", resetSign))
+		os.Exit(1)
+	}
+
+	if resetReason == "" && !resetDryRun {
+		runFeatureForm(huh.NewForm(huh.NewGroup(
+			huh.NewInput().
+				Title(fmt.Sprintf("Why reset %s?", args[0])).
+				Description("Recorded in progress.txt for the next session").
+				Value(&resetReason),
+		)))
+	}
+
+	plan, err := reset.New(dir, args[0], reset.Options{
+		Dependents: resetDependents,
+		Revert:     resetRevert,
+		Reason:     strings.TrimSpace(resetReason),
+		PRD:        path,
+	})
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to plan the reset")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	if resetDryRun {
+		printResetPlan(plan)
+		return
+	}
+
+	hash, err := reset.Apply(dir, plan, git.CommitOptions{Sign: resetSign, SigningKey: resetSigningKey})
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to reset " + plan.Feature.ID)
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	for _, f := range plan.Reopen {
+		if f.Passes {
+			fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Reopened %s", f.ID))
+		} else {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  %s was already not passing", f.ID)))
+		}
+	}
+	if hash != "" {
+		fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Reverted %d commit(s) in %s", len(plan.Revert), hash[:7]))
+	} else if resetRevert {
+		fmt.Println(dimStyle.Render("  No commits to revert"))
+	}
+	for _, c := range plan.Shared {
+		fmt.Println(warnStyle.Render("⚠") + fmt.Sprintf(" Kept %s %s (it also implements other features)", c.ShortHash(), c.Subject))
+	}
+	fmt.Println(successStyle.Render("✓") + " Added a note to progress.txt")
+}
+
+// printResetPlan shows everything a reset would change
+func printResetPlan(plan *reset.Plan) {
+	fmt.Println(boldStyle.Render(displayPath(plan.PRD)))
+	for _, f := range plan.Reopen {
+		if f.Passes {
+			fmt.Printf("  %s: passes true -> false\n", f.ID)
+		} else {
+			fmt.Println(dimStyle.Render(fmt.Sprintf("  %s: already not passing", f.ID)))
+		}
+	}
+
+	if resetRevert {
+		fmt.Println()
+		fmt.Println(boldStyle.Render("Commits to revert"))
+		if len(plan.Revert) == 0 {
+			fmt.Println(dimStyle.Render("  none"))
+		}
+		for _, c := range plan.Revert {
+			fmt.Printf("  %s %s\n", c.ShortHash(), c.Subject)
+		}
+		if len(plan.Shared) > 0 {
+			fmt.Println()
+			fmt.Println(boldStyle.Render("Commits kept (they also implement other features)"))
+			for _, c := range plan.Shared {
+				fmt.Printf("  %s %s\n", c.ShortHash(), c.Subject)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(boldStyle.Render("progress.txt note"))
+	for _, line := range strings.Split(strings.TrimRight(plan.Note().String(), "\n"), "\n") {
+		fmt.Println(dimStyle.Render("  " + line))
+	}
+
+	fmt.Println()
+	fmt.Println(dimStyle.Render("Dry run: nothing was changed"))
+}
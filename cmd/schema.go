@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+var schemaOutput string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for prd.json",
+	Long: `Schema prints a JSON Schema for the current prd.json format, generated
+from the same types superralph reads. It covers required fields, the
+category, priority and strategy values, and the depends_on references.
+
+Save it next to prd.json and point prd.json at it to have editors check the
+file as you type:
+
+  superralph schema -o prd.schema.json
+
+  {
+    "$schema": "./prd.schema.json",
+    "schemaVersion": 2,
+    ...
+  }
+
+Regenerate the schema after upgrading superralph.`,
+	Run: runSchema,
+}
+
+func init() {
+	schemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "Write the schema to a file instead of stdout")
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(cmd *cobra.Command, args []string) {
+	data, err := json.MarshalIndent(prd.JSONSchema(), "", "  ")
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to generate the schema")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if schemaOutput == "" {
+		fmt.Print(string(data))
+		return
+	}
+	if err := os.WriteFile(schemaOutput, data, 0644); err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to write the schema")
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render("✓") + fmt.Sprintf(" Wrote the prd.json schema (version %d) to %s", prd.CurrentSchemaVersion, schemaOutput))
+}
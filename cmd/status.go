@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/mpjhorner/superralph/internal/coverage"
 	"github.com/mpjhorner/superralph/internal/prd"
 	"github.com/mpjhorner/superralph/internal/progress"
 	"github.com/mpjhorner/superralph/internal/tui"
@@ -22,8 +23,10 @@ var statusCmd = &cobra.Command{
 
 It shows:
   - Overall progress (features passing/total)
+  - How many features are todo, in progress, blocked, stuck, awaiting review or skipped
   - Breakdown by category and priority
   - Recent activity from progress.txt
+  - Coverage recorded at the last accepted feature (if the coverage gate is on)
   - Current working state`,
 	Run: runStatus,
 }
@@ -33,17 +36,17 @@ func init() {
 }
 
 func runStatus(cmd *cobra.Command, args []string) {
-	// Check if prd.json exists
-	if !prd.ExistsInCurrentDir() {
-		fmt.Println(errorStyle.Render("✗") + " prd.json not found in current directory")
-		fmt.Println(dimStyle.Render("  Run 'superralph plan' to create one"))
+	path := requirePRD("✗")
+	dir, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
 		os.Exit(1)
 	}
 
 	// Load the PRD
-	p, err := prd.LoadFromCurrentDir()
+	p, err := prd.Load(path)
 	if err != nil {
-		fmt.Println(errorStyle.Render("✗") + " Failed to load prd.json")
+		fmt.Println(errorStyle.Render("✗") + " Failed to load " + displayPath(path))
 		fmt.Println(dimStyle.Render("  " + err.Error()))
 		os.Exit(1)
 	}
@@ -51,20 +54,17 @@ func runStatus(cmd *cobra.Command, args []string) {
 	// Validate the PRD
 	result := prd.Validate(p)
 	if !result.Valid {
-		fmt.Println(errorStyle.Render("✗") + " prd.json has validation errors")
+		fmt.Println(errorStyle.Render("✗") + " " + displayPath(path) + " has validation errors")
 		fmt.Println(dimStyle.Render("  Run 'superralph validate' for details"))
 		os.Exit(1)
 	}
 
-	// Get PRD path
-	prdPath, _ := prd.GetPath()
-
 	// Create status model (read-only mode, no iterations)
-	model := tui.NewModel(p, prdPath, 0)
+	model := tui.NewModel(p, displayPath(path), 0)
 	model.State = tui.StateIdle
 
 	// Try to load recent progress
-	progressContent, err := progress.ReadFromCurrentDir()
+	progressContent, err := progress.Read(progress.GetPath(dir))
 	if err == nil && progressContent != "" {
 		// Add last few lines to log view
 		lines := splitLines(progressContent)
@@ -80,9 +80,14 @@ func runStatus(cmd *cobra.Command, args []string) {
 		model.LogView.AddLine("No progress.txt found - run 'superralph build' to start")
 	}
 
+	// Show the coverage baseline, if one has been recorded
+	if baseline, err := coverage.LoadBaseline(dir); err == nil {
+		model.CoveragePanel.SetBaseline(baseline)
+	}
+
 	// Run the TUI with auto-refresh
 	program := tea.NewProgram(
-		statusModel{Model: model},
+		statusModel{Model: model, path: path, dir: dir},
 		tea.WithAltScreen(),
 	)
 
@@ -95,6 +100,8 @@ func runStatus(cmd *cobra.Command, args []string) {
 // statusModel wraps the TUI model for status-only mode
 type statusModel struct {
 	tui.Model
+	path string // PRD file
+	dir  string // Work directory
 }
 
 func (m statusModel) Init() tea.Cmd {
@@ -116,11 +123,14 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case refreshMsg:
 		// Reload PRD to get updated stats
-		p, err := prd.LoadFromCurrentDir()
+		p, err := prd.Load(m.path)
 		if err == nil {
 			m.PRD = p
 			m.PRDStats = p.Stats()
 		}
+		if baseline, err := coverage.LoadBaseline(m.dir); err == nil {
+			m.CoveragePanel.SetBaseline(baseline)
+		}
 		return m, refreshTick()
 
 	case tea.KeyMsg:
@@ -129,7 +139,7 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "r":
 			// Manual refresh
-			p, err := prd.LoadFromCurrentDir()
+			p, err := prd.Load(m.path)
 			if err == nil {
 				m.PRD = p
 				m.PRDStats = p.Stats()
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -12,22 +14,60 @@ import (
 
 var validateCmd = &cobra.Command{
 	Use:   "validate",
-	Short: "Validate the prd.json file in the current directory",
-	Long: `Validate checks that the prd.json file exists and has the correct structure.
+	Short: "Validate the PRD in the current directory",
+	Long: `Validate checks that the PRD (prd.json, or the file --prd names) exists and
+has the correct structure.
 
 It verifies:
   - All required fields are present
-  - Categories are valid (functional, ui, integration, performance, security)
-  - Priorities are valid (high, medium, low)
+  - Categories are valid (functional, ui, integration, performance, security,
+    or the PRD's own categories)
+  - Priorities are valid (high, medium, low, the PRD's own levels, or integer
+    weights)
   - Feature IDs are unique
-  - All features have at least one step`,
+  - All features have at least one step
+  - Feature context patterns and docs match at least one file
+  - Acceptance checks have a command, a valid output pattern and step
+
+Files from an older schema version are read as if migrated; run
+'superralph migrate' to upgrade them.
+
+It also lints the PRD for likely problems and prints them as warnings:
+` + lintRuleHelp() + `
+A feature suppresses rules with "lintIgnore": ["too-many-steps"]. Warnings
+don't fail validation unless --strict is set. Use --format json for scripts
+and CI.`,
 	Run: runValidate,
 }
 
+var (
+	validateStrict bool
+	validateFormat string
+)
+
 func init() {
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Fail if there are lint warnings")
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format: text or json")
 	rootCmd.AddCommand(validateCmd)
 }
 
+// validateReport is the JSON output of validate
+type validateReport struct {
+	File     string            `json:"file"`
+	Valid    bool              `json:"valid"`
+	Errors   []string          `json:"errors"`
+	Warnings []prd.LintWarning `json:"warnings"`
+}
+
+// lintRuleHelp lists the lint rules for the command's help
+func lintRuleHelp() string {
+	var sb strings.Builder
+	for _, rule := range prd.LintRules() {
+		sb.WriteString(fmt.Sprintf("  %-25s %s\n", rule, rule.Description()))
+	}
+	return sb.String()
+}
+
 var (
 	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
 	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
@@ -37,35 +77,86 @@ var (
 )
 
 func runValidate(cmd *cobra.Command, args []string) {
-	// Check if prd.json exists
-	if !prd.ExistsInCurrentDir() {
-		fmt.Println(errorStyle.Render("✗") + " prd.json not found in current directory")
-		fmt.Println(dimStyle.Render("  Run 'superralph plan' to create one"))
+	if validateFormat != "text" && validateFormat != "json" {
+		fmt.Println(errorStyle.Render("✗") + fmt.Sprintf(" Invalid format %q (must be text or json)", validateFormat))
 		os.Exit(1)
 	}
 
+	// Scripts get a report even when there is no PRD to validate
+	if validateFormat == "json" {
+		path, err := prdPath()
+		if err == nil && !prd.Exists(path) {
+			err = fmt.Errorf("%s not found", displayPath(path))
+		}
+		if err != nil {
+			printValidateReport(validateReport{File: displayPath(path), Errors: []string{err.Error()}, Warnings: []prd.LintWarning{}})
+			os.Exit(1)
+		}
+	}
+
+	path := requirePRD("✗")
+	name := displayPath(path)
+
 	// Load the PRD
-	p, err := prd.LoadFromCurrentDir()
+	p, err := prd.Load(path)
 	if err != nil {
-		fmt.Println(errorStyle.Render("✗") + " Failed to load prd.json")
+		if validateFormat == "json" {
+			printValidateReport(validateReport{File: name, Errors: []string{err.Error()}, Warnings: []prd.LintWarning{}})
+			os.Exit(1)
+		}
+		fmt.Println(errorStyle.Render("✗") + " Failed to load " + name)
 		fmt.Println(dimStyle.Render("  " + err.Error()))
 		os.Exit(1)
 	}
 
-	// Validate the PRD
+	// Validate and lint the PRD
 	result := prd.Validate(p)
+	if dir, err := projectDir(); err == nil {
+		result.Merge(prd.ValidateContext(p, dir))
+	}
+	warnings := prd.Lint(p)
+	failed := !result.Valid || (validateStrict && len(warnings) > 0)
+
+	if validateFormat == "json" {
+		report := validateReport{File: name, Valid: result.Valid, Errors: []string{}, Warnings: warnings}
+		for _, e := range result.Errors {
+			report.Errors = append(report.Errors, e.Error())
+		}
+		if report.Warnings == nil {
+			report.Warnings = []prd.LintWarning{}
+		}
+		printValidateReport(report)
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
 
 	if !result.Valid {
-		fmt.Println(errorStyle.Render("✗") + " prd.json has validation errors:\n")
+		fmt.Println(errorStyle.Render("✗") + " " + name + " has validation errors:\n")
 		for _, e := range result.Errors {
 			fmt.Printf("  %s %s\n", errorStyle.Render("•"), e.Error())
 		}
 		fmt.Println()
+		printLintWarnings(warnings)
+		os.Exit(1)
+	}
+
+	if failed {
+		fmt.Println(errorStyle.Render("✗") + " " + name + " has lint warnings (--strict):\n")
+		printLintWarnings(warnings)
 		os.Exit(1)
 	}
 
 	// Success - show summary
-	fmt.Println(successStyle.Render("✓") + " prd.json is valid\n")
+	fmt.Println(successStyle.Render("✓") + " " + name + " is valid\n")
+	if len(warnings) > 0 {
+		printLintWarnings(warnings)
+	}
+	if migration, err := prd.CheckMigrations(path); err == nil && migration.NeedsMigration() {
+		fmt.Println(warnStyle.Render("⚠") + fmt.Sprintf(" %s uses schema version %d; run 'superralph migrate' to upgrade it to %d\n",
+			name, migration.From, migration.To))
+	}
 
 	stats := p.Stats()
 
@@ -80,7 +171,7 @@ func runValidate(cmd *cobra.Command, args []string) {
 
 	// Show breakdown by category
 	fmt.Println(dimStyle.Render("  By Category:"))
-	for _, cat := range prd.ValidCategories() {
+	for _, cat := range stats.Categories {
 		cs := stats.ByCategory[cat]
 		if cs.Total > 0 {
 			fmt.Printf("    %-12s %d/%d\n", cat, cs.Passing, cs.Total)
@@ -91,7 +182,7 @@ func runValidate(cmd *cobra.Command, args []string) {
 
 	// Show breakdown by priority
 	fmt.Println(dimStyle.Render("  By Priority:"))
-	for _, pri := range prd.ValidPriorities() {
+	for _, pri := range stats.Priorities {
 		ps := stats.ByPriority[pri]
 		if ps.Total > 0 {
 			fmt.Printf("    %-12s %d/%d\n", pri, ps.Passing, ps.Total)
@@ -101,9 +192,34 @@ func runValidate(cmd *cobra.Command, args []string) {
 	fmt.Println()
 
 	// Show next feature to work on
-	if next := p.NextFeature(); next != nil {
+	if next, reason := p.NextFeatureWithReason(); next != nil {
 		fmt.Printf("  %s %s \"%s\"\n", boldStyle.Render("Next:"), next.ID, next.Description)
+		fmt.Println(dimStyle.Render(fmt.Sprintf("  %s (%s strategy)", reason, p.Scheduler().Strategy())))
 	} else if p.IsComplete() {
 		fmt.Println(successStyle.Render("  All features complete!"))
 	}
 }
+
+// printLintWarnings lists lint warnings with their rule IDs
+func printLintWarnings(warnings []prd.LintWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Println(warnStyle.Render("⚠") + fmt.Sprintf(" %d lint warnings:\n", len(warnings)))
+	for _, w := range warnings {
+		fmt.Printf("  %s %s: %s %s\n", warnStyle.Render("•"), w.Field, w.Message, dimStyle.Render("["+string(w.Rule)+"]"))
+	}
+	fmt.Println()
+	fmt.Println(dimStyle.Render("  Suppress a rule for a feature with \"lintIgnore\": [\"<rule>\"]"))
+	fmt.Println()
+}
+
+// printValidateReport prints the JSON output of validate
+func printValidateReport(report validateReport) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to encode the report: " + err.Error())
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
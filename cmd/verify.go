@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/verify"
+)
+
+var verifyReopen bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [feature-id...]",
+	Short: "Re-run acceptance checks for passing features",
+	Long: `Verify re-runs the executable acceptance checks of every passing feature
+(or only the given features) and reports regressions.
+
+Checks are declared per feature in prd.json:
+  - verify: a feature-level command, e.g. "make e2e"
+  - checks: per-step commands with an expected exitCode and output pattern
+
+Features without checks are skipped. With --reopen, features whose checks
+fail are marked as passes: false so the next build picks them up again.`,
+	Run: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyReopen, "reopen", false, "Mark features with failing checks as not passing")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) {
+	path := requirePRD("✗")
+
+	// Load the PRD
+	p, err := prd.Load(path)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " Failed to load " + displayPath(path))
+		fmt.Println(dimStyle.Render("  " + err.Error()))
+		os.Exit(1)
+	}
+
+	dir, err := projectDir()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗") + " " + err.Error())
+		os.Exit(1)
+	}
+
+	// Select features: the given IDs, or every passing feature
+	var features []*prd.Feature
+	if len(args) > 0 {
+		for _, id := range args {
+			f := p.GetFeature(id)
+			if f == nil {
+				fmt.Println(errorStyle.Render("✗") + " Unknown feature: " + id)
+				os.Exit(1)
+			}
+			features = append(features, f)
+		}
+	} else {
+		for i := range p.Features {
+			if p.Features[i].Passes {
+				features = append(features, &p.Features[i])
+			}
+		}
+	}
+
+	runner := verify.New(dir)
+	var verified, skipped int
+	var regressions []string
+
+	for _, f := range features {
+		if !f.HasChecks() {
+			skipped++
+			continue
+		}
+
+		result := runner.RunFeature(context.Background(), f)
+		if result.Passed() {
+			verified++
+			fmt.Printf("%s %s %s\n", successStyle.Render("✓"), f.ID, dimStyle.Render(fmt.Sprintf("(%d checks)", len(result.Results))))
+			continue
+		}
+
+		regressions = append(regressions, f.ID)
+		fmt.Printf("%s %s %s\n", errorStyle.Render("✗"), f.ID, f.Description)
+		for _, r := range result.Failed() {
+			fmt.Printf("  %s %s\n", errorStyle.Render("•"), r.Check.String())
+			fmt.Printf("    %s\n", warnStyle.Render(r.Reason))
+			for _, line := range strings.Split(r.Output, "\n") {
+				if line != "" {
+					fmt.Printf("    %s\n", dimStyle.Render(line))
+				}
+			}
+		}
+		if verifyReopen && f.Passes {
+			f.SetStatus(prd.StatusTodo, time.Now().UTC())
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("  %s %d verified, %d failed, %d without checks\n",
+		boldStyle.Render("Summary:"), verified, len(regressions), skipped)
+
+	if len(regressions) == 0 {
+		return
+	}
+
+	if verifyReopen {
+		if err := prd.Save(p, path); err != nil {
+			fmt.Println(errorStyle.Render("✗") + " Failed to save " + displayPath(path))
+			fmt.Println(dimStyle.Render("  " + err.Error()))
+			os.Exit(1)
+		}
+		fmt.Printf("  %s %s\n", boldStyle.Render("Reopened:"), strings.Join(regressions, ", "))
+	} else {
+		fmt.Println(dimStyle.Render("  Run 'superralph verify --reopen' to mark them as not passing"))
+	}
+	os.Exit(1)
+}
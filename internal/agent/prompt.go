@@ -26,10 +26,9 @@ func BuildPrompt(p *prd.PRD, iteration int) string {
 2. **SMART FEATURE SELECTION**
    - Select the next feature using this logic:
      1. Skip features with passes: true (already done)
-     2. Skip features blocked by unmet dependencies (depends_on field)
-     3. Pick the highest priority (high > medium > low)
-     4. Within same priority, pick first by ID order
-   - Implement ONLY that one feature
+     2. Skip features whose status is blocked, stuck, needs_review or skipped
+     3. Skip features blocked by unmet dependencies (depends_on field)
+%s   - Implement ONLY that one feature
    - Report which feature you selected and WHY in your response
    - Do not move to another feature until this one passes all tests
 
@@ -45,7 +44,7 @@ func BuildPrompt(p *prd.PRD, iteration int) string {
 2. Run tests first to verify starting state: %s
 3. If tests are failing, FIX THEM FIRST before implementing new features
 4. Find the next feature to implement using smart selection:
-   - Skip passes: true, skip blocked by unmet depends_on, pick highest priority, then ID order
+   - Skip passes: true, skip held statuses, skip blocked by unmet depends_on, then follow the %s strategy above
 5. Implement the feature
 6. Run tests: %s
 7. If tests fail:
@@ -96,7 +95,16 @@ If ALL features have passes: true and all tests pass, output exactly:
 <promise>COMPLETE</promise>
 
 Remember: NEVER COMMIT WITH FAILING TESTS. This is non-negotiable.
-`, p.TestCommand, p.TestCommand, p.TestCommand, iteration, p.TestCommand)
+`, p.TestCommand, selectionRules(p), p.TestCommand, p.Scheduler().Strategy(), p.TestCommand, iteration, p.TestCommand)
+}
+
+// selectionRules numbers the PRD scheduler's rules after the three skip rules
+func selectionRules(p *prd.PRD) string {
+	var sb strings.Builder
+	for i, rule := range p.Scheduler().Rules(p) {
+		sb.WriteString(fmt.Sprintf("     %d. %s\n", i+4, rule))
+	}
+	return sb.String()
 }
 
 // BuildPlanPrompt builds the system prompt for the planning phase
@@ -114,6 +122,7 @@ Your job is to help the user create a prd.json file for their project through co
 The prd.json file MUST follow this exact structure:
 
 {
+  "schemaVersion": ` + fmt.Sprint(prd.CurrentSchemaVersion) + `,
   "name": "Project Name",
   "description": "High-level description of the project",
   "testCommand": "command to run tests (e.g., go test ./..., npm test, pytest)",
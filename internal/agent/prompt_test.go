@@ -42,6 +42,10 @@ func TestBuildPrompt(t *testing.T) {
 	for _, part := range expectedParts {
 		assert.Contains(t, prompt, part, "Prompt missing expected part")
 	}
+	assert.Contains(t, prompt, "     4. Pick the highest priority first (high > medium > low)\n")
+
+	p.Strategy = prd.StrategyCriticalPath
+	assert.Contains(t, BuildPrompt(p, 5), "     4. Pick the feature that starts the longest chain")
 }
 
 func TestBuildPlanPrompt(t *testing.T) {
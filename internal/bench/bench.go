@@ -0,0 +1,204 @@
+// Package bench measures benchmark targets declared on performance features.
+//
+// Benchmarks are read from Go benchmark output, the format printed by
+// `go test -bench` and understood by tools like benchstat:
+//
+//	BenchmarkParse-8   	  120000	      9841 ns/op	    2048 B/op	      12 allocs/op
+//
+// The command runs several times and each benchmark's metric is compared as
+// the median of those runs.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+const (
+	// DefaultRuns is how many times the benchmark command runs
+	DefaultRuns = 3
+
+	// DefaultTolerance is the noise allowance, in percent
+	DefaultTolerance = 5.0
+
+	// DefaultTimeout bounds a single run of the benchmark command
+	DefaultTimeout = 10 * time.Minute
+)
+
+// Result is one benchmark line
+type Result struct {
+	Name       string             // Benchmark name without the GOMAXPROCS suffix
+	Iterations int                // Number of iterations
+	Metrics    map[string]float64 // Value by unit, e.g. "ns/op" -> 9841
+}
+
+var (
+	benchLine  = regexp.MustCompile(`^(Benchmark\S*)\s+(\d+)\s+(.+)$`)
+	procSuffix = regexp.MustCompile(`-\d+$`)
+)
+
+// Parse reads benchmark results from command output, ignoring other lines
+func Parse(output string) []Result {
+	var results []Result
+	for _, line := range strings.Split(output, "\n") {
+		m := benchLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		iterations, _ := strconv.Atoi(m[2])
+		r := Result{
+			Name:       procSuffix.ReplaceAllString(m[1], ""),
+			Iterations: iterations,
+			Metrics:    make(map[string]float64),
+		}
+
+		// The rest of the line is value/unit pairs
+		fields := strings.Fields(m[3])
+		for i := 0; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				break
+			}
+			r.Metrics[fields[i+1]] = value
+		}
+		if len(r.Metrics) > 0 {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// Measurement holds a metric's value from each run, by benchmark name
+type Measurement struct {
+	Metric  string               `json:"metric"`
+	Samples map[string][]float64 `json:"samples"`
+}
+
+// Names returns the benchmark names in sorted order
+func (m *Measurement) Names() []string {
+	names := make([]string, 0, len(m.Samples))
+	for name := range m.Samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Median returns the median value of a benchmark, or 0 if it wasn't measured
+func (m *Measurement) Median(name string) float64 {
+	values := append([]float64{}, m.Samples[name]...)
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// Spread returns the range of a benchmark's values as a percentage of its median
+func (m *Measurement) Spread(name string) float64 {
+	values := m.Samples[name]
+	median := m.Median(name)
+	if len(values) < 2 || median == 0 {
+		return 0
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	return (hi - lo) * 100 / median
+}
+
+// Runner runs benchmark commands from a project directory
+type Runner struct {
+	workDir string
+	Timeout time.Duration
+}
+
+// New creates a runner for the project in workDir
+func New(workDir string) *Runner {
+	return &Runner{
+		workDir: workDir,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// Measure runs the benchmark command spec.Runs times and collects the metric
+// of every benchmark selected by spec.Benchmark
+func (r *Runner) Measure(ctx context.Context, spec *prd.BenchmarkSpec) (*Measurement, error) {
+	var filter *regexp.Regexp
+	if spec.Benchmark != "" {
+		var err error
+		if filter, err = regexp.Compile(spec.Benchmark); err != nil {
+			return nil, fmt.Errorf("invalid benchmark pattern: %w", err)
+		}
+	}
+
+	runs := spec.Runs
+	if runs <= 0 {
+		runs = DefaultRuns
+	}
+
+	m := &Measurement{Metric: spec.MetricName(), Samples: make(map[string][]float64)}
+	for i := 0; i < runs; i++ {
+		output, err := r.run(ctx, spec.Command)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range Parse(output) {
+			if filter != nil && !filter.MatchString(res.Name) {
+				continue
+			}
+			if value, ok := res.Metrics[m.Metric]; ok {
+				m.Samples[res.Name] = append(m.Samples[res.Name], value)
+			}
+		}
+	}
+
+	if len(m.Samples) == 0 {
+		return nil, fmt.Errorf("no benchmark reported %s", m.Metric)
+	}
+	return m, nil
+}
+
+// run runs the command once and returns its combined output
+func (r *Runner) run(ctx context.Context, command string) (string, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = r.workDir
+	cmd.WaitDelay = time.Second // Don't wait on children that outlive the shell
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("benchmark timed out after %s", r.Timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("benchmark command failed: %w\n%s", err, lastLines(string(output), 10))
+	}
+	return string(output), nil
+}
+
+// lastLines keeps the last n lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
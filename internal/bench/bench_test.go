@@ -0,0 +1,129 @@
+package bench
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: example.com/app/parser
+cpu: AMD EPYC
+BenchmarkParse-8          120000              9841 ns/op            2048 B/op         12 allocs/op
+BenchmarkParse/large-8      1000           1203000 ns/op          104.5 MB/s
+BenchmarkEmpty
+PASS
+ok      example.com/app/parser  3.210s
+`
+
+func TestParse(t *testing.T) {
+	results := Parse(benchOutput)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "BenchmarkParse", results[0].Name)
+	assert.Equal(t, 120000, results[0].Iterations)
+	assert.Equal(t, map[string]float64{"ns/op": 9841, "B/op": 2048, "allocs/op": 12}, results[0].Metrics)
+
+	assert.Equal(t, "BenchmarkParse/large", results[1].Name)
+	assert.Equal(t, 104.5, results[1].Metrics["MB/s"])
+}
+
+func TestMeasurementStats(t *testing.T) {
+	m := &Measurement{Metric: "ns/op", Samples: map[string][]float64{
+		"BenchmarkA": {110, 90, 100},
+		"BenchmarkB": {10, 20},
+	}}
+
+	assert.Equal(t, []string{"BenchmarkA", "BenchmarkB"}, m.Names())
+	assert.Equal(t, 100.0, m.Median("BenchmarkA"))
+	assert.Equal(t, 15.0, m.Median("BenchmarkB"))
+	assert.Equal(t, 0.0, m.Median("BenchmarkMissing"))
+	assert.InDelta(t, 20.0, m.Spread("BenchmarkA"), 0.001)
+}
+
+func TestMeasure(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bench.txt"), []byte(benchOutput), 0644))
+
+	r := New(dir)
+	m, err := r.Measure(context.Background(), &prd.BenchmarkSpec{Command: "cat bench.txt", Benchmark: "^BenchmarkParse$", Runs: 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]float64{"BenchmarkParse": {9841, 9841}}, m.Samples)
+
+	_, err = r.Measure(context.Background(), &prd.BenchmarkSpec{Command: "cat bench.txt", Metric: "ops/s"})
+	assert.ErrorContains(t, err, "no benchmark reported ops/s")
+
+	_, err = r.Measure(context.Background(), &prd.BenchmarkSpec{Command: "echo broken; exit 1"})
+	assert.ErrorContains(t, err, "benchmark command failed")
+	assert.ErrorContains(t, err, "broken")
+}
+
+func TestEvaluate(t *testing.T) {
+	baseline := &Measurement{Metric: "ns/op", Samples: map[string][]float64{"BenchmarkParse": {1000, 1000, 1000}}}
+	current := &Measurement{Metric: "ns/op", Samples: map[string][]float64{"BenchmarkParse": {790, 800, 810}}}
+
+	tests := []struct {
+		name     string
+		spec     *prd.BenchmarkSpec
+		baseline *Measurement
+		met      bool
+		reason   string
+	}{
+		{"target met", &prd.BenchmarkSpec{Target: 850}, nil, true, ""},
+		{"target within tolerance", &prd.BenchmarkSpec{Target: 780, Tolerance: 5}, nil, true, ""},
+		{"target missed", &prd.BenchmarkSpec{Target: 700}, nil, false, "800 ns/op is above the target of 700"},
+		{"improvement met", &prd.BenchmarkSpec{Improvement: 20}, baseline, true, ""},
+		{"improvement within tolerance", &prd.BenchmarkSpec{Improvement: 22, Tolerance: 5}, baseline, true, ""},
+		{"improvement missed", &prd.BenchmarkSpec{Improvement: 40}, baseline, false, "improved 20.0%, needs 40%"},
+		{"improvement without baseline", &prd.BenchmarkSpec{Improvement: 10}, nil, false, "no baseline to measure the improvement against"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Evaluate("feat-001", tt.spec, tt.baseline, current)
+			require.Len(t, e.Comparisons, 1)
+			assert.Equal(t, tt.met, e.Met())
+			assert.Equal(t, tt.reason, e.Comparisons[0].Reason)
+		})
+	}
+}
+
+func TestEvaluateHigherIsBetter(t *testing.T) {
+	baseline := &Measurement{Metric: "MB/s", Samples: map[string][]float64{"BenchmarkCopy": {100}}}
+	current := &Measurement{Metric: "MB/s", Samples: map[string][]float64{"BenchmarkCopy": {125}}}
+
+	e := Evaluate("feat-001", &prd.BenchmarkSpec{Metric: "MB/s", Target: 120, Improvement: 25}, baseline, current)
+	assert.True(t, e.Met())
+	assert.InDelta(t, 25.0, e.Comparisons[0].Improvement, 0.001)
+	assert.Equal(t, "BenchmarkCopy: 125 MB/s (was 100, 25.0% better, ±0.0%)", e.Comparisons[0].Describe(e.Metric))
+
+	e = Evaluate("feat-001", &prd.BenchmarkSpec{Metric: "MB/s", Target: 200}, nil, current)
+	assert.False(t, e.Met())
+	assert.Equal(t, "125 MB/s is below the target of 200", e.Comparisons[0].Reason)
+}
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+
+	history, err := LoadHistory(dir)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	current := &Measurement{Metric: "ns/op", Samples: map[string][]float64{"BenchmarkParse": {800}}}
+	require.NoError(t, AppendHistory(dir, Evaluate("feat-001", &prd.BenchmarkSpec{Target: 900}, nil, current)))
+	require.NoError(t, AppendHistory(dir, Evaluate("feat-002", &prd.BenchmarkSpec{Target: 700}, nil, current)))
+
+	history, err = LoadHistory(dir)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "feat-001", history[0].FeatureID)
+	assert.True(t, history[0].Met())
+	assert.False(t, history[1].Met())
+}
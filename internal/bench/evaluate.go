@@ -0,0 +1,143 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+// HistoryFile is where benchmark evaluations are kept, relative to the project directory
+const HistoryFile = ".superralph/benchmarks.json"
+
+// Comparison is one benchmark measured against the feature's targets
+type Comparison struct {
+	Name        string  `json:"name"`
+	Baseline    float64 `json:"baseline,omitempty"` // Median before the feature; 0 if not measured
+	Current     float64 `json:"current"`            // Median after the feature
+	Spread      float64 `json:"spread"`             // Range of the current runs, in percent of the median
+	Improvement float64 `json:"improvement"`        // Change over the baseline in percent; positive is better
+	Met         bool    `json:"met"`
+	Reason      string  `json:"reason,omitempty"` // Why the target wasn't met
+}
+
+// Evaluation is the result of checking a feature's benchmark targets
+type Evaluation struct {
+	FeatureID   string       `json:"feature_id"`
+	Metric      string       `json:"metric"`
+	Recorded    time.Time    `json:"recorded"`
+	Comparisons []Comparison `json:"comparisons"`
+}
+
+// Met returns true if every benchmark met its targets
+func (e *Evaluation) Met() bool {
+	if len(e.Comparisons) == 0 {
+		return false
+	}
+	for _, c := range e.Comparisons {
+		if !c.Met {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe describes a comparison, e.g. "BenchmarkParse: 7420 ns/op (was 9841, 24.6% better, ±2.1%)"
+func (c Comparison) Describe(metric string) string {
+	s := fmt.Sprintf("%s: %.4g %s", c.Name, c.Current, metric)
+	if c.Baseline > 0 {
+		s += fmt.Sprintf(" (was %.4g, %.1f%% better, ±%.1f%%)", c.Baseline, c.Improvement, c.Spread/2)
+	} else {
+		s += fmt.Sprintf(" (±%.1f%%)", c.Spread/2)
+	}
+	return s
+}
+
+// Evaluate compares the current measurement with the spec's targets. The
+// tolerance lets a median miss a target by that many percent, to absorb
+// noise between runs. Relative targets need a baseline for the benchmark.
+func Evaluate(featureID string, spec *prd.BenchmarkSpec, baseline, current *Measurement) *Evaluation {
+	tolerance := spec.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	higher := spec.HigherIsBetter()
+
+	e := &Evaluation{FeatureID: featureID, Metric: current.Metric, Recorded: time.Now().UTC()}
+	for _, name := range current.Names() {
+		c := Comparison{
+			Name:    name,
+			Current: current.Median(name),
+			Spread:  current.Spread(name),
+			Met:     true,
+		}
+		if baseline != nil {
+			c.Baseline = baseline.Median(name)
+		}
+		if c.Baseline > 0 {
+			c.Improvement = (c.Baseline - c.Current) * 100 / c.Baseline
+			if higher {
+				c.Improvement = -c.Improvement
+			}
+		}
+
+		switch {
+		case spec.Target > 0 && !higher && c.Current > spec.Target*(1+tolerance/100):
+			c.Met = false
+			c.Reason = fmt.Sprintf("%.4g %s is above the target of %g", c.Current, e.Metric, spec.Target)
+		case spec.Target > 0 && higher && c.Current < spec.Target*(1-tolerance/100):
+			c.Met = false
+			c.Reason = fmt.Sprintf("%.4g %s is below the target of %g", c.Current, e.Metric, spec.Target)
+		case spec.Improvement > 0 && c.Baseline == 0:
+			c.Met = false
+			c.Reason = "no baseline to measure the improvement against"
+		case spec.Improvement > 0 && c.Improvement < spec.Improvement-tolerance:
+			c.Met = false
+			c.Reason = fmt.Sprintf("improved %.1f%%, needs %g%%", c.Improvement, spec.Improvement)
+		}
+		e.Comparisons = append(e.Comparisons, c)
+	}
+	return e
+}
+
+// LoadHistory reads the recorded evaluations for the project in dir, oldest first
+func LoadHistory(dir string) ([]*Evaluation, error) {
+	data, err := os.ReadFile(filepath.Join(dir, HistoryFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read benchmark history: %w", err)
+	}
+
+	var history []*Evaluation
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse benchmark history: %w", err)
+	}
+	return history, nil
+}
+
+// AppendHistory adds an evaluation to the project's benchmark history
+func AppendHistory(dir string, e *Evaluation) error {
+	history, err := LoadHistory(dir)
+	if err != nil {
+		return err
+	}
+	history = append(history, e)
+
+	path := filepath.Join(dir, HistoryFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .superralph directory: %w", err)
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal benchmark history: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write benchmark history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Package commitmsg writes the messages of harness-managed commits: a
+// conventional-commit header derived from the feature's category, and
+// trailers that tie the commit to its features, iteration and run, so that
+// `git log --grep "Feature-Id: feat-004"` finds a feature's commits.
+package commitmsg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+// Trailer keys
+const (
+	TrailerFeature   = "Feature-Id"
+	TrailerIteration = "SuperRalph-Iteration"
+	TrailerRun       = "SuperRalph-Run"
+	TrailerStatus    = "SuperRalph-Status"
+	TrailerBranch    = "SuperRalph-Branch"
+)
+
+// Values of the status trailer
+const (
+	StatusPassing    = "passing"     // The commit's features pass
+	StatusInProgress = "in-progress" // Work in progress
+	StatusReset      = "reset"       // The feature was reopened and its commits reverted
+)
+
+// maxHeaderLength keeps headers readable in `git log --oneline`
+const maxHeaderLength = 72
+
+// Trailer is a "Key: Value" line at the end of the message
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// Message is a conventional commit message
+type Message struct {
+	Type     string // e.g. "feat", "perf"
+	Scope    string // Optional, e.g. "ui"
+	Subject  string
+	Body     string
+	Trailers []Trailer
+}
+
+// Header returns the first line, e.g. "feat(ui): add dark mode toggle"
+func (m Message) Header() string {
+	header := m.Type
+	if m.Scope != "" {
+		header += "(" + m.Scope + ")"
+	}
+	return header + ": " + m.Subject
+}
+
+// String returns the full message
+func (m Message) String() string {
+	var sb strings.Builder
+	sb.WriteString(m.Header())
+	sb.WriteString("\n")
+	if m.Body != "" {
+		sb.WriteString("\n")
+		sb.WriteString(strings.TrimRight(m.Body, "\n"))
+		sb.WriteString("\n")
+	}
+	if len(m.Trailers) > 0 {
+		sb.WriteString("\n")
+		for _, t := range m.Trailers {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", t.Key, t.Value))
+		}
+	}
+	return sb.String()
+}
+
+// TypeFor returns the conventional-commit type and scope for a category
+func TypeFor(c prd.Category) (string, string) {
+	switch c {
+	case prd.CategoryPerformance:
+		return "perf", ""
+	case prd.CategoryUI:
+		return "feat", "ui"
+	case prd.CategoryIntegration:
+		return "feat", "integration"
+	case prd.CategorySecurity:
+		return "feat", "security"
+	default:
+		return "feat", ""
+	}
+}
+
+// Params describes the commit to write
+type Params struct {
+	Features  []*prd.Feature // Features the commit implements; the first sets the type
+	Iteration int
+	RunID     string
+	Complete  bool // The features now pass; false for work in progress
+
+	// Branch and Squashed describe a squash merge of a feature branch: the
+	// branch name and the subjects of its commits, listed in the body
+	Branch   string
+	Squashed []string
+}
+
+// New writes the message for an iteration's commit
+func New(p Params) Message {
+	var m Message
+	if len(p.Features) > 0 {
+		m.Type, m.Scope = TypeFor(p.Features[0].Category)
+	} else {
+		m.Type = "chore"
+	}
+
+	var body strings.Builder
+	switch len(p.Features) {
+	case 0:
+		m.Subject = fmt.Sprintf("iteration %d", p.Iteration)
+	case 1:
+		f := p.Features[0]
+		m.Subject = subject(f.Description)
+		for _, step := range f.Steps {
+			body.WriteString("- " + step + "\n")
+		}
+	default:
+		ids := make([]string, 0, len(p.Features))
+		for _, f := range p.Features {
+			ids = append(ids, f.ID)
+			body.WriteString(fmt.Sprintf("- %s: %s\n", f.ID, f.Description))
+		}
+		m.Subject = "implement " + strings.Join(ids, ", ")
+	}
+	m.fit()
+	if len(p.Squashed) > 0 {
+		body.Reset()
+		for _, s := range p.Squashed {
+			body.WriteString("- " + s + "\n")
+		}
+	}
+	m.Body = body.String()
+
+	for _, f := range p.Features {
+		m.Trailers = append(m.Trailers, Trailer{TrailerFeature, f.ID})
+	}
+	status := StatusInProgress
+	if p.Complete {
+		status = StatusPassing
+	}
+	m.Trailers = append(m.Trailers,
+		Trailer{TrailerStatus, status},
+		Trailer{TrailerIteration, fmt.Sprintf("%d", p.Iteration)},
+	)
+	if p.RunID != "" {
+		m.Trailers = append(m.Trailers, Trailer{TrailerRun, p.RunID})
+	}
+	if p.Branch != "" {
+		m.Trailers = append(m.Trailers, Trailer{TrailerBranch, p.Branch})
+	}
+	return m
+}
+
+// Revert writes the message for the commit that reverts a reset feature's
+// commits, given newest first, with the reason the feature was reset
+func Revert(f *prd.Feature, reason string, reverted []string) Message {
+	m := Message{Type: "revert", Subject: subject(f.Description)}
+	m.fit()
+
+	var body strings.Builder
+	if reason != "" {
+		body.WriteString(reason + "\n\n")
+	}
+	for _, hash := range reverted {
+		body.WriteString(fmt.Sprintf("This reverts commit %s.\n", hash))
+	}
+	m.Body = body.String()
+	m.Trailers = []Trailer{{TrailerFeature, f.ID}, {TrailerStatus, StatusReset}}
+	return m
+}
+
+// fit shortens the subject so the header stays within maxHeaderLength
+func (m *Message) fit() {
+	if limit := maxHeaderLength - len(m.Header()) + len(m.Subject); len(m.Subject) > limit {
+		m.Subject = strings.TrimSpace(m.Subject[:limit-3]) + "..."
+	}
+}
+
+// subject turns a feature description into a commit subject: one line,
+// lower-case first letter, no trailing period
+func subject(description string) string {
+	s, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	s = strings.TrimSuffix(strings.TrimSpace(s), ".")
+	if len(s) > 1 && s[0] >= 'A' && s[0] <= 'Z' && !(s[1] >= 'A' && s[1] <= 'Z') {
+		s = strings.ToLower(s[:1]) + s[1:]
+	}
+	return s
+}
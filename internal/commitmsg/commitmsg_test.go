@@ -0,0 +1,118 @@
+package commitmsg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+func TestTypeFor(t *testing.T) {
+	tests := []struct {
+		category  prd.Category
+		wantType  string
+		wantScope string
+	}{
+		{prd.CategoryFunctional, "feat", ""},
+		{prd.CategoryUI, "feat", "ui"},
+		{prd.CategoryIntegration, "feat", "integration"},
+		{prd.CategoryPerformance, "perf", ""},
+		{prd.CategorySecurity, "feat", "security"},
+	}
+	for _, tt := range tests {
+		typ, scope := TypeFor(tt.category)
+		assert.Equal(t, tt.wantType, typ, tt.category)
+		assert.Equal(t, tt.wantScope, scope, tt.category)
+	}
+}
+
+func TestNewSingleFeature(t *testing.T) {
+	m := New(Params{
+		Features: []*prd.Feature{{
+			ID:          "feat-004",
+			Category:    prd.CategoryUI,
+			Description: "User can delete messages.",
+			Steps:       []string{"Open a message", "Click delete"},
+		}},
+		Iteration: 7,
+		RunID:     "run-123",
+		Complete:  true,
+	})
+
+	assert.Equal(t, `feat(ui): user can delete messages
+
+- Open a message
+- Click delete
+
+Feature-Id: feat-004
+SuperRalph-Status: passing
+SuperRalph-Iteration: 7
+SuperRalph-Run: run-123
+`, m.String())
+}
+
+func TestNewMultipleFeatures(t *testing.T) {
+	m := New(Params{
+		Features: []*prd.Feature{
+			{ID: "feat-001", Category: prd.CategoryPerformance, Description: "Faster parsing"},
+			{ID: "feat-002", Category: prd.CategoryFunctional, Description: "Parse comments"},
+		},
+		Iteration: 2,
+	})
+
+	assert.Equal(t, "perf: implement feat-001, feat-002", m.Header())
+	assert.Contains(t, m.String(), "- feat-001: Faster parsing\n- feat-002: Parse comments\n")
+	assert.Contains(t, m.String(), "Feature-Id: feat-001\nFeature-Id: feat-002\nSuperRalph-Status: in-progress\n")
+	assert.NotContains(t, m.String(), TrailerRun)
+}
+
+func TestNewLongSubject(t *testing.T) {
+	m := New(Params{Features: []*prd.Feature{{
+		ID:          "feat-001",
+		Category:    prd.CategoryIntegration,
+		Description: "API clients " + strings.Repeat("handle retries ", 10),
+	}}})
+
+	assert.LessOrEqual(t, len(m.Header()), maxHeaderLength)
+	assert.True(t, strings.HasPrefix(m.Header(), "feat(integration): API clients handle"), m.Header())
+	assert.True(t, strings.HasSuffix(m.Header(), "..."))
+}
+
+func TestNewSquash(t *testing.T) {
+	m := New(Params{
+		Features:  []*prd.Feature{{ID: "feat-004", Category: prd.CategoryFunctional, Description: "Delete messages", Steps: []string{"Step"}}},
+		Iteration: 9,
+		Complete:  true,
+		Branch:    "ralph/feat-004",
+		Squashed:  []string{"feat: delete messages", "feat: delete messages"},
+	})
+
+	assert.Equal(t, `feat: delete messages
+
+- feat: delete messages
+- feat: delete messages
+
+Feature-Id: feat-004
+SuperRalph-Status: passing
+SuperRalph-Iteration: 9
+SuperRalph-Branch: ralph/feat-004
+`, m.String())
+}
+
+func TestRevert(t *testing.T) {
+	f := &prd.Feature{ID: "feat-004", Category: prd.CategoryUI, Description: "Delete messages."}
+	m := Revert(f, "Deleted messages came back after a reload", []string{"bbb222", "aaa111"})
+
+	assert.Equal(t, `revert: delete messages
+
+Deleted messages came back after a reload
+
+This reverts commit bbb222.
+This reverts commit aaa111.
+
+Feature-Id: feat-004
+SuperRalph-Status: reset
+`, m.String())
+}
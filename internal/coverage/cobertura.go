@@ -0,0 +1,76 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// CoberturaParser reads Cobertura XML reports, as written by coverage.py,
+// gocover-cobertura and many JVM and .NET tools
+type CoberturaParser struct{}
+
+// Name returns the format name
+func (CoberturaParser) Name() string {
+	return "cobertura"
+}
+
+// Detect checks for a <coverage> root with line-rate attributes
+func (CoberturaParser) Detect(data []byte) bool {
+	return bytes.Contains(data, []byte("<coverage")) && bytes.Contains(data, []byte("line-rate"))
+}
+
+type coberturaReport struct {
+	XMLName  xml.Name           `xml:"coverage"`
+	Packages []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name    string           `xml:"name,attr"`
+	Classes []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Filename string          `xml:"filename,attr"`
+	Lines    []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// Parse counts lines per package. Lines listed under several classes of the
+// same file are counted once.
+func (CoberturaParser) Parse(data []byte) (*Profile, error) {
+	var report coberturaReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{Packages: make(map[string]Counts)}
+	for _, pkg := range report.Packages {
+		name := pkg.Name
+		if name == "" {
+			name = "."
+		}
+
+		seen := make(map[string]bool)
+		c := profile.Packages[name]
+		for _, class := range pkg.Classes {
+			for _, line := range class.Lines {
+				key := fmt.Sprintf("%s:%d", class.Filename, line.Number)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				c.Total++
+				if line.Hits > 0 {
+					c.Covered++
+				}
+			}
+		}
+		profile.Packages[name] = c
+	}
+	return profile, nil
+}
@@ -0,0 +1,176 @@
+// Package coverage parses coverage profiles and compares them against a
+// recorded baseline, so a drop in coverage can fail the test gate.
+//
+// Go cover profiles, lcov and Cobertura XML are supported. Other formats can
+// be added by registering a Parser.
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultThreshold is the drop in percentage points allowed before the gate fails
+const DefaultThreshold = 0.5
+
+// BaselineFile is where the baseline is recorded, relative to the project directory
+const BaselineFile = ".superralph/coverage.json"
+
+// DefaultProfile is where `go test` commands write their profile when the PRD names none
+const DefaultProfile = ".superralph/cover.out"
+
+// Counts is the number of covered and total statements (or lines)
+type Counts struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the coverage percentage, or 0 if there is nothing to cover
+func (c Counts) Percent() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Covered) * 100 / float64(c.Total)
+}
+
+// Profile is coverage grouped by package (or directory, for formats without packages)
+type Profile struct {
+	Format   string            `json:"format"`
+	Packages map[string]Counts `json:"packages"`
+}
+
+// Total returns the combined counts of all packages
+func (p *Profile) Total() Counts {
+	var total Counts
+	for _, c := range p.Packages {
+		total.Covered += c.Covered
+		total.Total += c.Total
+	}
+	return total
+}
+
+// Percent returns the total coverage percentage
+func (p *Profile) Percent() float64 {
+	return p.Total().Percent()
+}
+
+// PackageNames returns the package names in sorted order
+func (p *Profile) PackageNames() []string {
+	names := make([]string, 0, len(p.Packages))
+	for name := range p.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Baseline is the coverage recorded when a feature was last accepted
+type Baseline struct {
+	FeatureID string    `json:"feature_id,omitempty"`
+	Recorded  time.Time `json:"recorded"`
+	Profile   *Profile  `json:"profile"`
+}
+
+// LoadBaseline reads the baseline for the project in dir.
+// Returns nil if no baseline has been recorded yet.
+func LoadBaseline(dir string) (*Baseline, error) {
+	data, err := os.ReadFile(filepath.Join(dir, BaselineFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read coverage baseline: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse coverage baseline: %w", err)
+	}
+	if b.Profile == nil {
+		return nil, fmt.Errorf("failed to parse coverage baseline: missing profile")
+	}
+	return &b, nil
+}
+
+// SaveBaseline writes the baseline for the project in dir
+func SaveBaseline(dir string, b *Baseline) error {
+	path := filepath.Join(dir, BaselineFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .superralph directory: %w", err)
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal coverage baseline: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write coverage baseline: %w", err)
+	}
+	return nil
+}
+
+// PackageDelta is a change in a package's coverage
+type PackageDelta struct {
+	Package string  `json:"package"`
+	Before  float64 `json:"before"`
+	After   float64 `json:"after"`
+}
+
+// Delta returns the change in percentage points
+func (d PackageDelta) Delta() float64 {
+	return d.After - d.Before
+}
+
+// Comparison is the result of comparing a profile against the baseline
+type Comparison struct {
+	Baseline    *Baseline      `json:"baseline,omitempty"` // nil if there was no baseline
+	Current     *Profile       `json:"current"`
+	Threshold   float64        `json:"threshold"`
+	Regressions []PackageDelta `json:"regressions,omitempty"` // Packages that dropped by more than Threshold
+}
+
+// TotalDelta returns the change in total coverage, or 0 without a baseline
+func (c *Comparison) TotalDelta() float64 {
+	if c.Baseline == nil {
+		return 0
+	}
+	return c.Current.Percent() - c.Baseline.Profile.Percent()
+}
+
+// Rejected returns true if total or package coverage dropped by more than the threshold
+func (c *Comparison) Rejected() bool {
+	return -c.TotalDelta() > c.Threshold || len(c.Regressions) > 0
+}
+
+// Summary returns the total coverage and its change, e.g. "81.2% (-1.4 from 82.6%)"
+func (c *Comparison) Summary() string {
+	if c.Baseline == nil {
+		return fmt.Sprintf("%.1f%% (no baseline)", c.Current.Percent())
+	}
+	return fmt.Sprintf("%.1f%% (%+.1f from %.1f%%)", c.Current.Percent(), c.TotalDelta(), c.Baseline.Profile.Percent())
+}
+
+// Compare checks current coverage against the baseline. Packages that are
+// new, removed, or have nothing to cover are not compared.
+func Compare(baseline *Baseline, current *Profile, threshold float64) *Comparison {
+	c := &Comparison{Baseline: baseline, Current: current, Threshold: threshold}
+	if baseline == nil {
+		return c
+	}
+
+	for _, name := range current.PackageNames() {
+		after := current.Packages[name]
+		before, ok := baseline.Profile.Packages[name]
+		if !ok || before.Total == 0 || after.Total == 0 {
+			continue
+		}
+		delta := PackageDelta{Package: name, Before: before.Percent(), After: after.Percent()}
+		if -delta.Delta() > threshold {
+			c.Regressions = append(c.Regressions, delta)
+		}
+	}
+	return c
+}
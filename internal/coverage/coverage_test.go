@@ -0,0 +1,130 @@
+package coverage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func profile(pkgs map[string]Counts) *Profile {
+	return &Profile{Format: "go", Packages: pkgs}
+}
+
+func TestProfileTotal(t *testing.T) {
+	p := profile(map[string]Counts{
+		"app/api":   {Covered: 30, Total: 40},
+		"app/store": {Covered: 10, Total: 60},
+	})
+
+	assert.Equal(t, Counts{Covered: 40, Total: 100}, p.Total())
+	assert.InDelta(t, 40.0, p.Percent(), 0.001)
+	assert.Equal(t, []string{"app/api", "app/store"}, p.PackageNames())
+	assert.Equal(t, 0.0, Counts{}.Percent())
+}
+
+func TestBaselineSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	missing, err := LoadBaseline(dir)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	recorded := time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC)
+	b := &Baseline{
+		FeatureID: "feat-003",
+		Recorded:  recorded,
+		Profile:   profile(map[string]Counts{"app/api": {Covered: 3, Total: 4}}),
+	}
+	require.NoError(t, SaveBaseline(dir, b))
+
+	loaded, err := LoadBaseline(dir)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, "feat-003", loaded.FeatureID)
+	assert.True(t, loaded.Recorded.Equal(recorded))
+	assert.Equal(t, b.Profile, loaded.Profile)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Baseline{Profile: profile(map[string]Counts{
+		"app/api":     {Covered: 80, Total: 100},
+		"app/store":   {Covered: 50, Total: 100},
+		"app/removed": {Covered: 10, Total: 10},
+	})}
+
+	tests := []struct {
+		name        string
+		current     map[string]Counts
+		rejected    bool
+		regressions []string
+	}{
+		{
+			name: "unchanged",
+			current: map[string]Counts{
+				"app/api":     {Covered: 80, Total: 100},
+				"app/store":   {Covered: 50, Total: 100},
+				"app/removed": {Covered: 10, Total: 10},
+			},
+		},
+		{
+			name: "drop within threshold",
+			current: map[string]Counts{
+				"app/api":     {Covered: 80, Total: 100},
+				"app/store":   {Covered: 49, Total: 100},
+				"app/removed": {Covered: 10, Total: 10},
+			},
+		},
+		{
+			name: "package regression",
+			current: map[string]Counts{
+				"app/api":     {Covered: 60, Total: 100},
+				"app/store":   {Covered: 70, Total: 100},
+				"app/removed": {Covered: 10, Total: 10},
+			},
+			rejected:    true,
+			regressions: []string{"app/api"},
+		},
+		{
+			name: "new and removed packages are not compared",
+			current: map[string]Counts{
+				"app/api":   {Covered: 80, Total: 100},
+				"app/store": {Covered: 50, Total: 100},
+				"app/new":   {Covered: 0, Total: 5},
+			},
+			rejected: true, // total drops from 66.7% to 62.9%
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compare(baseline, profile(tt.current), 1.5)
+			assert.Equal(t, tt.rejected, c.Rejected())
+
+			var names []string
+			for _, r := range c.Regressions {
+				names = append(names, r.Package)
+			}
+			assert.Equal(t, tt.regressions, names)
+		})
+	}
+}
+
+func TestCompareWithoutBaseline(t *testing.T) {
+	c := Compare(nil, profile(map[string]Counts{"app": {Covered: 1, Total: 4}}), 0)
+	assert.False(t, c.Rejected())
+	assert.Equal(t, 0.0, c.TotalDelta())
+	assert.Equal(t, "25.0% (no baseline)", c.Summary())
+}
+
+func TestComparisonSummary(t *testing.T) {
+	baseline := &Baseline{Profile: profile(map[string]Counts{"app": {Covered: 80, Total: 100}})}
+	c := Compare(baseline, profile(map[string]Counts{"app": {Covered: 75, Total: 100}}), 0.5)
+
+	assert.True(t, c.Rejected())
+	assert.InDelta(t, -5.0, c.TotalDelta(), 0.001)
+	assert.Equal(t, "75.0% (-5.0 from 80.0%)", c.Summary())
+	require.Len(t, c.Regressions, 1)
+	assert.InDelta(t, -5.0, c.Regressions[0].Delta(), 0.001)
+}
@@ -0,0 +1,76 @@
+package coverage
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// GoParser reads profiles written by `go test -coverprofile`
+type GoParser struct{}
+
+// Name returns the format name
+func (GoParser) Name() string {
+	return "go"
+}
+
+// Detect checks for the "mode:" header
+func (GoParser) Detect(data []byte) bool {
+	return strings.HasPrefix(firstLine(data), "mode:")
+}
+
+// Parse counts statements per package. Blocks listed more than once, as
+// happens with -coverpkg, are counted once and covered if any run hit them.
+func (GoParser) Parse(data []byte) (*Profile, error) {
+	type block struct {
+		stmts   int
+		covered bool
+	}
+	blocks := make(map[string]*block)
+	var order []string
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		// Format: name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected 3 fields, got %d", i+1, len(fields))
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid statement count %q", i+1, fields[1])
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid hit count %q", i+1, fields[2])
+		}
+
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{stmts: stmts}
+			blocks[fields[0]] = b
+			order = append(order, fields[0])
+		}
+		b.covered = b.covered || count > 0
+	}
+
+	profile := &Profile{Packages: make(map[string]Counts)}
+	for _, key := range order {
+		file, _, _ := strings.Cut(key, ":")
+		pkg := path.Dir(file)
+		b := blocks[key]
+
+		c := profile.Packages[pkg]
+		c.Total += b.stmts
+		if b.covered {
+			c.Covered += b.stmts
+		}
+		profile.Packages[pkg] = c
+	}
+	return profile, nil
+}
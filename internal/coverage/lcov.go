@@ -0,0 +1,82 @@
+package coverage
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LCOVParser reads lcov tracefiles, as written by jest, c8, nyc and
+// cargo-llvm-cov. Coverage is grouped by source directory.
+type LCOVParser struct{}
+
+// Name returns the format name
+func (LCOVParser) Name() string {
+	return "lcov"
+}
+
+// Detect checks for a source file record
+func (LCOVParser) Detect(data []byte) bool {
+	first := firstLine(data)
+	return strings.HasPrefix(first, "TN:") || strings.HasPrefix(first, "SF:")
+}
+
+// Parse counts lines per directory, using the LF/LH totals when present
+// and the DA line records otherwise
+func (LCOVParser) Parse(data []byte) (*Profile, error) {
+	profile := &Profile{Packages: make(map[string]Counts)}
+
+	var file string
+	var found, hit, daTotal, daHit int
+	hasTotals := false
+
+	flush := func() {
+		if file == "" {
+			return
+		}
+		c := Counts{Covered: daHit, Total: daTotal}
+		if hasTotals {
+			c = Counts{Covered: hit, Total: found}
+		}
+		dir := path.Dir(filepath.ToSlash(file))
+		existing := profile.Packages[dir]
+		existing.Covered += c.Covered
+		existing.Total += c.Total
+		profile.Packages[dir] = existing
+
+		file = ""
+		found, hit, daTotal, daHit = 0, 0, 0, 0
+		hasTotals = false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "SF":
+			flush()
+			file = value
+		case "LF":
+			found, _ = strconv.Atoi(value)
+			hasTotals = true
+		case "LH":
+			hit, _ = strconv.Atoi(value)
+			hasTotals = true
+		case "DA":
+			// DA:<line>,<hits>[,<checksum>]
+			parts := strings.Split(value, ",")
+			if len(parts) >= 2 {
+				daTotal++
+				if n, err := strconv.Atoi(parts[1]); err == nil && n > 0 {
+					daHit++
+				}
+			}
+		case "end_of_record":
+			flush()
+		}
+	}
+	flush()
+
+	return profile, nil
+}
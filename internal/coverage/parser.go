@@ -0,0 +1,99 @@
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Parser reads one coverage profile format
+type Parser interface {
+	// Name returns the format name (e.g., "go", "lcov")
+	Name() string
+
+	// Detect reports whether data looks like this format
+	Detect(data []byte) bool
+
+	// Parse reads a profile
+	Parse(data []byte) (*Profile, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Parser
+)
+
+func init() {
+	Register(CoberturaParser{})
+	Register(LCOVParser{})
+	Register(GoParser{})
+}
+
+// Register adds a parser. Parsers registered later are tried first.
+func Register(p Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append([]Parser{p}, registry...)
+}
+
+// Parsers returns the registered parsers in the order they are tried
+func Parsers() []Parser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Parser{}, registry...)
+}
+
+// Parse detects the format of a profile and parses it
+func Parse(data []byte) (*Profile, error) {
+	for _, p := range Parsers() {
+		if !p.Detect(data) {
+			continue
+		}
+		profile, err := p.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s coverage profile: %w", p.Name(), err)
+		}
+		profile.Format = p.Name()
+		return profile, nil
+	}
+	return nil, fmt.Errorf("unrecognized coverage profile format")
+}
+
+// ParseFile reads and parses a profile
+func ParseFile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+	return Parse(data)
+}
+
+// coverprofileFlag matches an existing -coverprofile flag and its path
+var coverprofileFlag = regexp.MustCompile(`-coverprofile[= ](\S+)`)
+
+// GoCommand adds -coverprofile to a plain `go test` command. If the command
+// already writes a profile, its path is returned instead. Returns false for
+// commands that aren't `go test`.
+func GoCommand(command, profilePath string) (string, string, bool) {
+	trimmed := strings.TrimSpace(command)
+	if !strings.HasPrefix(trimmed, "go test") || strings.ContainsAny(trimmed, ";&|<>`$()") {
+		return "", "", false
+	}
+	if m := coverprofileFlag.FindStringSubmatch(trimmed); m != nil {
+		return trimmed, strings.Trim(m[1], `'"`), true
+	}
+	return trimmed + " -coverprofile=" + profilePath, profilePath, true
+}
+
+// firstLine returns the first non-blank line of data
+func firstLine(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if s := strings.TrimSpace(string(line)); s != "" {
+			return s
+		}
+	}
+	return ""
+}
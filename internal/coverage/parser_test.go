@@ -0,0 +1,165 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goProfile = `mode: set
+example.com/app/api/handler.go:10.20,12.2 2 1
+example.com/app/api/handler.go:14.20,16.2 3 0
+example.com/app/store/store.go:5.10,9.2 4 1
+example.com/app/store/store.go:5.10,9.2 4 0
+`
+
+const lcovProfile = `TN:
+SF:src/api/handler.ts
+DA:1,1
+DA:2,0
+DA:3,4
+LF:3
+LH:2
+end_of_record
+SF:src/api/routes.ts
+DA:1,0
+end_of_record
+SF:src/util.ts
+DA:1,1
+DA:2,1
+end_of_record
+`
+
+const coberturaProfile = `<?xml version="1.0" ?>
+<coverage version="7.4" line-rate="0.6" branch-rate="0">
+  <packages>
+    <package name="app.api" line-rate="0.5">
+      <classes>
+        <class name="handler.py" filename="app/api/handler.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+    <package name="app.store" line-rate="0.66">
+      <classes>
+        <class name="Store" filename="app/store/store.py">
+          <lines>
+            <line number="1" hits="3"/>
+            <line number="2" hits="1"/>
+          </lines>
+        </class>
+        <class name="Store$Inner" filename="app/store/store.py">
+          <lines>
+            <line number="2" hits="1"/>
+            <line number="9" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>
+`
+
+func TestParseGo(t *testing.T) {
+	p, err := Parse([]byte(goProfile))
+	require.NoError(t, err)
+
+	assert.Equal(t, "go", p.Format)
+	assert.Equal(t, Counts{Covered: 2, Total: 5}, p.Packages["example.com/app/api"])
+	// The duplicated block is counted once and covered by either run
+	assert.Equal(t, Counts{Covered: 4, Total: 4}, p.Packages["example.com/app/store"])
+}
+
+func TestParseGoInvalid(t *testing.T) {
+	_, err := Parse([]byte("mode: set\nhandler.go:1.1,2.2 x 1\n"))
+	assert.Error(t, err)
+}
+
+func TestParseLCOV(t *testing.T) {
+	p, err := Parse([]byte(lcovProfile))
+	require.NoError(t, err)
+
+	assert.Equal(t, "lcov", p.Format)
+	// handler.ts uses LF/LH, routes.ts falls back to DA records
+	assert.Equal(t, Counts{Covered: 2, Total: 4}, p.Packages["src/api"])
+	assert.Equal(t, Counts{Covered: 2, Total: 2}, p.Packages["src"])
+}
+
+func TestParseCobertura(t *testing.T) {
+	p, err := Parse([]byte(coberturaProfile))
+	require.NoError(t, err)
+
+	assert.Equal(t, "cobertura", p.Format)
+	assert.Equal(t, Counts{Covered: 1, Total: 2}, p.Packages["app.api"])
+	assert.Equal(t, Counts{Covered: 2, Total: 3}, p.Packages["app.store"])
+}
+
+func TestParseUnknown(t *testing.T) {
+	_, err := Parse([]byte("PASS\nok  example.com/app 0.1s\n"))
+	assert.Error(t, err)
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cover.out")
+	require.NoError(t, os.WriteFile(path, []byte(goProfile), 0644))
+
+	p, err := ParseFile(path)
+	require.NoError(t, err)
+	assert.Len(t, p.Packages, 2)
+
+	_, err = ParseFile(filepath.Join(t.TempDir(), "missing.out"))
+	assert.Error(t, err)
+}
+
+type fixedParser struct{}
+
+func (fixedParser) Name() string            { return "fixed" }
+func (fixedParser) Detect(data []byte) bool { return string(data) == "fixed" }
+func (fixedParser) Parse([]byte) (*Profile, error) {
+	return &Profile{Packages: map[string]Counts{".": {Covered: 1, Total: 1}}}, nil
+}
+
+func TestRegister(t *testing.T) {
+	saved := Parsers()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+
+	Register(fixedParser{})
+	assert.Equal(t, "fixed", Parsers()[0].Name())
+
+	p, err := Parse([]byte("fixed"))
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", p.Format)
+}
+
+func TestGoCommand(t *testing.T) {
+	tests := []struct {
+		command     string
+		wantCommand string
+		wantProfile string
+		ok          bool
+	}{
+		{"go test ./...", "go test ./... -coverprofile=.superralph/cover.out", ".superralph/cover.out", true},
+		{"go test -coverprofile=c.out ./...", "go test -coverprofile=c.out ./...", "c.out", true},
+		{"go test ./... && npm test", "", "", false},
+		{"npm test", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			command, profile, ok := GoCommand(tt.command, ".superralph/cover.out")
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.wantCommand, command)
+			assert.Equal(t, tt.wantProfile, profile)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Package fileset enumerates the files that make up a project.
+//
+// It is the single place that decides which files SuperRalph can see: tag
+// resolution, autocomplete, the directory tree in iteration prompts and
+// relevance retrieval all share it. Files ignored by .gitignore,
+// .git/info/exclude or a project .superralphignore are left out, along with
+// dependency and build directories such as node_modules.
+//
+// Inside a git repository the list comes from `git ls-files` and is cached
+// until the git index or an ignore file changes. Elsewhere the tree is walked
+// concurrently on every call.
+package fileset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/samber/lo"
+)
+
+// IgnoreFilename is the project-specific ignore file, using gitignore syntax
+const IgnoreFilename = ".superralphignore"
+
+// DefaultExcludeDirs are directory names that are always skipped, even when a
+// project's ignore files don't mention them
+var DefaultExcludeDirs = []string{
+	".git",
+	".superralph",
+	"node_modules",
+	"vendor",
+	"__pycache__",
+	".venv",
+	"venv",
+	"target",
+	"build",
+	"dist",
+}
+
+// visibleDotfiles are hidden names that are still shown in listings
+var visibleDotfiles = []string{
+	".gitignore",
+	IgnoreFilename,
+	".github",
+}
+
+// Enumerator lists project files relative to a working directory
+type Enumerator struct {
+	workDir     string
+	excludeDirs []string
+
+	mu       sync.Mutex
+	detected bool     // Whether git detection has run
+	useGit   bool     // Whether workDir is inside a git working tree
+	watched  []string // Files whose stat forms the cache stamp (git mode only)
+	stamp    string   // Stamp the cached list was built with
+	files    []string // Cached file list, nil when not cached
+}
+
+// New creates an Enumerator for the given working directory
+func New(workDir string) *Enumerator {
+	return &Enumerator{
+		workDir:     workDir,
+		excludeDirs: append([]string(nil), DefaultExcludeDirs...),
+	}
+}
+
+// SetExcludeDirs sets the directory names that are always skipped
+func (e *Enumerator) SetExcludeDirs(dirs []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.excludeDirs = dirs
+	e.files = nil
+}
+
+// ExcludeDirs returns the directory names that are always skipped
+func (e *Enumerator) ExcludeDirs() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.excludeDirs
+}
+
+// Invalidate drops the cached file list so the next call re-enumerates
+func (e *Enumerator) Invalidate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.files = nil
+	e.detected = false
+}
+
+// Files returns every non-ignored file under the working directory, relative
+// to it and sorted in tree order. Hidden files are included.
+func (e *Enumerator) Files() ([]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.detected {
+		e.detect()
+	}
+
+	var stamp string
+	if e.useGit {
+		stamp = e.currentStamp()
+		if e.files != nil && stamp == e.stamp {
+			return append([]string(nil), e.files...), nil
+		}
+	}
+
+	files, err := e.enumerate()
+	if err != nil {
+		return nil, err
+	}
+	sortTreeOrder(files)
+
+	if e.useGit {
+		e.files = append([]string(nil), files...)
+		e.stamp = stamp
+	}
+	return files, nil
+}
+
+// List returns files and directories up to maxDepth levels deep for display,
+// such as tag autocomplete. Directories have a trailing slash and come
+// immediately before their contents. Hidden files and directories are left
+// out, except for a few well-known ones such as .gitignore.
+func (e *Enumerator) List(maxDepth int) ([]string, error) {
+	files, err := e.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	seenDirs := make(map[string]bool)
+	for _, file := range files {
+		parts := strings.Split(file, string(filepath.Separator))
+		if lo.SomeBy(parts, IsHidden) {
+			continue
+		}
+
+		// Emit parent directories the first time they are seen
+		for depth := 1; depth < len(parts) && depth <= maxDepth; depth++ {
+			dir := strings.Join(parts[:depth], string(filepath.Separator)) + "/"
+			if !seenDirs[dir] {
+				seenDirs[dir] = true
+				result = append(result, dir)
+			}
+		}
+
+		if len(parts) <= maxDepth {
+			result = append(result, file)
+		}
+	}
+
+	return result, nil
+}
+
+// IsHidden reports whether a file or directory name is hidden from listings
+func IsHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && !lo.Contains(visibleDotfiles, name)
+}
+
+// detect checks whether the working directory is inside a git repository and,
+// if so, which files make up the cache stamp
+func (e *Enumerator) detect() {
+	e.detected = true
+	e.useGit = git.IsInsideWorkTree(e.workDir)
+	e.watched = nil
+	if !e.useGit {
+		return
+	}
+
+	for _, name := range []string{"index", "info/exclude"} {
+		if path, err := git.GitPath(e.workDir, name); err == nil {
+			e.watched = append(e.watched, path)
+		}
+	}
+	e.watched = append(e.watched,
+		filepath.Join(e.workDir, ".gitignore"),
+		filepath.Join(e.workDir, IgnoreFilename),
+	)
+}
+
+// currentStamp summarizes the modification state of the watched files.
+// Staging, committing and checking out all rewrite the git index.
+func (e *Enumerator) currentStamp() string {
+	var sb strings.Builder
+	for _, path := range e.watched {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&sb, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+		} else {
+			fmt.Fprintf(&sb, "%s:missing;", path)
+		}
+	}
+	return sb.String()
+}
+
+// enumerate lists files using git when available, falling back to a walk
+func (e *Enumerator) enumerate() ([]string, error) {
+	if e.useGit {
+		files, err := e.listGit()
+		if err == nil {
+			return files, nil
+		}
+		// git is installed but failed (e.g. a corrupt index); walk instead
+	}
+	return e.walk()
+}
+
+// listGit lists files with git ls-files, then applies the exclude dirs and
+// .superralphignore, which git doesn't know about
+func (e *Enumerator) listGit() ([]string, error) {
+	listed, err := git.ListFiles(e.workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := NewMatcher()
+	if err := matcher.AddFile("", filepath.Join(e.workDir, IgnoreFilename)); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFilename, err)
+	}
+
+	files := make([]string, 0, len(listed))
+	for _, file := range listed {
+		parts := strings.Split(file, string(filepath.Separator))
+		if lo.SomeBy(parts[:len(parts)-1], e.isExcludedDir) {
+			continue
+		}
+		if matcher.Ignored(filepath.ToSlash(file), false) {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// isExcludedDir checks if a directory name should always be skipped
+func (e *Enumerator) isExcludedDir(name string) bool {
+	return lo.Contains(e.excludeDirs, name)
+}
+
+// sortTreeOrder sorts paths so that each directory's contents are contiguous
+// and ordered by name, matching a depth-first walk
+func sortTreeOrder(paths []string) {
+	sep := string(filepath.Separator)
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.ReplaceAll(paths[i], sep, "\x00") < strings.ReplaceAll(paths[j], sep, "\x00")
+	})
+}
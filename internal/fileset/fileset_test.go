@@ -0,0 +1,176 @@
+package fileset
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTree creates files under dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+}
+
+// runGit runs a git command in dir, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+}
+
+// projectFiles is a small project with ignored build output
+var projectFiles = map[string]string{
+	".gitignore":           "*.log\nout/\n",
+	".superralphignore":    "fixtures/\n",
+	".env":                 "SECRET=1",
+	"main.go":              "package main",
+	"src/util.go":          "package src",
+	"src/web/.gitignore":   "*.min.js\n",
+	"src/web/app.js":       "app",
+	"src/web/app.min.js":   "minified",
+	"debug.log":            "log",
+	"out/bundle.js":        "bundle",
+	"fixtures/big.json":    "{}",
+	"node_modules/dep.js":  "dep",
+	"vendor/lib/lib.go":    "package lib",
+	".superralph/state.js": "{}",
+}
+
+// expectedFiles are the projectFiles that should be enumerated
+var expectedFiles = []string{
+	".env",
+	".gitignore",
+	".superralphignore",
+	"main.go",
+	filepath.Join("src", "util.go"),
+	filepath.Join("src", "web", ".gitignore"),
+	filepath.Join("src", "web", "app.js"),
+}
+
+func TestFilesWalk(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, projectFiles)
+
+	files, err := New(dir).Files()
+	require.NoError(t, err)
+	assert.Equal(t, expectedFiles, files)
+}
+
+func TestFilesGit(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, projectFiles)
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "add", "main.go")
+
+	files, err := New(dir).Files()
+	require.NoError(t, err)
+	assert.Equal(t, expectedFiles, files)
+}
+
+func TestFilesGitInfoExclude(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"main.go": "package main", "scratch.txt": "notes"})
+	runGit(t, dir, "init", "-q")
+	writeTree(t, dir, map[string]string{".git/info/exclude": "scratch.txt\n"})
+
+	files, err := New(dir).Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, files)
+}
+
+func TestFilesGitCacheInvalidatedByIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.go": "package a"})
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "add", "a.go")
+
+	e := New(dir)
+	files, err := e.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, files)
+
+	// An untracked file doesn't touch the index, so the cached list is reused
+	writeTree(t, dir, map[string]string{"b.go": "package a"})
+	files, err = e.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, files)
+
+	// Staging it rewrites the index
+	time.Sleep(10 * time.Millisecond)
+	runGit(t, dir, "add", "b.go")
+	files, err = e.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "b.go"}, files)
+
+	// Invalidate forces a fresh listing
+	writeTree(t, dir, map[string]string{"c.go": "package a"})
+	e.Invalidate()
+	files, err = e.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "b.go", "c.go"}, files)
+}
+
+func TestFilesMissingDir(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing")).Files()
+	assert.Error(t, err)
+}
+
+func TestSetExcludeDirs(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"main.go": "package main", "vendor/lib.go": "package lib", "gen/api.go": "package gen"})
+
+	e := New(dir)
+	assert.Equal(t, DefaultExcludeDirs, e.ExcludeDirs())
+
+	e.SetExcludeDirs([]string{"gen"})
+	files, err := e.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go", filepath.Join("vendor", "lib.go")}, files)
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore":           "*.log",
+		".hidden":              "hidden",
+		".github/workflows/ci": "ci",
+		".idea/workspace.xml":  "ide",
+		"main.go":              "package main",
+		"src/util.go":          "package src",
+		"src/deep/nested/x.go": "package nested",
+		"zz.go":                "package main",
+	})
+
+	files, err := New(dir).List(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		".github/",
+		filepath.Join(".github", "workflows") + "/",
+		".gitignore",
+		"main.go",
+		"src/",
+		filepath.Join("src", "deep") + "/",
+		filepath.Join("src", "util.go"),
+		"zz.go",
+	}, files)
+}
+
+func TestIsHidden(t *testing.T) {
+	assert.True(t, IsHidden(".env"))
+	assert.True(t, IsHidden(".idea"))
+	assert.False(t, IsHidden(".gitignore"))
+	assert.False(t, IsHidden(".superralphignore"))
+	assert.False(t, IsHidden("main.go"))
+}
@@ -0,0 +1,150 @@
+package fileset
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreRule is a single line from a gitignore-style file
+type ignoreRule struct {
+	base     string // Directory containing the ignore file, relative to the root ("" for the root)
+	pattern  string // Glob pattern with any leading "/" and trailing "/" removed
+	negate   bool   // Pattern started with "!" and re-includes matching paths
+	dirOnly  bool   // Pattern ended with "/" and only matches directories
+	anchored bool   // Pattern contains a "/" and is matched against the path relative to base
+}
+
+// Matcher decides whether paths are ignored using gitignore semantics.
+// Rules are evaluated in order and the last matching rule wins, so later files
+// (and deeper .gitignore files) override earlier ones.
+type Matcher struct {
+	rules []ignoreRule
+}
+
+// NewMatcher creates an empty matcher that ignores nothing
+func NewMatcher() *Matcher {
+	return &Matcher{}
+}
+
+// Add parses gitignore-style content whose patterns are relative to base,
+// a slash-separated directory relative to the root ("" for the root)
+func (m *Matcher) Add(base, content string) {
+	for _, line := range strings.Split(content, "\n") {
+		if rule, ok := parseIgnoreLine(base, line); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// AddFile reads an ignore file and adds its patterns relative to base.
+// A missing file is not an error.
+func (m *Matcher) AddFile(base, filename string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	m.Add(base, string(content))
+	return nil
+}
+
+// Len returns the number of rules in the matcher
+func (m *Matcher) Len() int {
+	return len(m.rules)
+}
+
+// clone returns a copy of the matcher that can be extended independently
+func (m *Matcher) clone() *Matcher {
+	rules := make([]ignoreRule, len(m.rules))
+	copy(rules, m.rules)
+	return &Matcher{rules: rules}
+}
+
+// Match reports whether relPath (slash-separated, relative to the root) is
+// ignored by its own name. Parent directories are not considered.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.matches(relPath, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// Ignored reports whether relPath is ignored, either directly or because one
+// of its parent directories is. As in git, a file cannot be re-included when
+// its parent directory is ignored.
+func (m *Matcher) Ignored(relPath string, isDir bool) bool {
+	if len(m.rules) == 0 {
+		return false
+	}
+	for i := 0; i < len(relPath); i++ {
+		if relPath[i] == '/' && m.Match(relPath[:i], true) {
+			return true
+		}
+	}
+	return m.Match(relPath, isDir)
+}
+
+// matches checks a single rule against a path
+func (r ignoreRule) matches(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	if r.base != "" {
+		if !strings.HasPrefix(relPath, r.base+"/") {
+			return false
+		}
+		relPath = relPath[len(r.base)+1:]
+	}
+
+	if !r.anchored {
+		relPath = path.Base(relPath)
+	}
+
+	matched, err := doublestar.Match(r.pattern, relPath)
+	return err == nil && matched
+}
+
+// parseIgnoreLine parses one line of an ignore file. Returns false for blank
+// lines and comments.
+func parseIgnoreLine(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, "\r")
+	// Trailing spaces are ignored unless escaped
+	if trimmed := strings.TrimRight(line, " "); !strings.HasSuffix(trimmed, "\\") {
+		line = trimmed
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	rule.pattern = line
+	return rule, true
+}
@@ -0,0 +1,81 @@
+package fileset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcherPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"basename glob", "*.log", "debug.log", false, true},
+		{"basename glob nested", "*.log", "a/b/debug.log", false, true},
+		{"no match", "*.log", "main.go", false, false},
+		{"dir only matches dir", "build/", "build", true, true},
+		{"dir only skips file", "build/", "build", false, false},
+		{"anchored with leading slash", "/out", "out", false, true},
+		{"anchored not nested", "/out", "src/out", false, false},
+		{"anchored path", "docs/generated", "docs/generated", true, true},
+		{"anchored path not nested", "docs/generated", "x/docs/generated", true, false},
+		{"double star prefix", "**/fixtures", "a/b/fixtures", true, true},
+		{"double star suffix", "tmp/**", "tmp/a/b.txt", false, true},
+		{"negation re-includes", "*.log\n!keep.log", "keep.log", false, false},
+		{"later rule wins", "!keep.log\n*.log", "keep.log", false, true},
+		{"comments and blanks", "# *.go\n\n", "main.go", false, false},
+		{"escaped hash", "\\#notes", "#notes", false, true},
+		{"trailing spaces trimmed", "*.tmp   ", "a.tmp", false, true},
+		{"crlf line endings", "*.tmp\r\n", "a.tmp", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMatcher()
+			m.Add("", tt.content)
+			assert.Equal(t, tt.want, m.Match(tt.path, tt.isDir))
+		})
+	}
+}
+
+func TestMatcherBase(t *testing.T) {
+	m := NewMatcher()
+	m.Add("web", "dist/\n/local.txt")
+
+	assert.True(t, m.Match("web/dist", true))
+	assert.True(t, m.Match("web/app/dist", true), "unanchored patterns match below the base")
+	assert.True(t, m.Match("web/local.txt", false))
+	assert.False(t, m.Match("local.txt", false), "rules don't apply outside their base")
+	assert.False(t, m.Match("web/app/local.txt", false), "anchored patterns are relative to the base")
+}
+
+func TestMatcherIgnoredParents(t *testing.T) {
+	m := NewMatcher()
+	m.Add("", "generated/\n!generated/keep.go")
+
+	assert.True(t, m.Ignored("generated/api.go", false))
+	assert.True(t, m.Ignored("generated/keep.go", false), "files in ignored directories can't be re-included")
+	assert.True(t, m.Ignored("src/generated/x/y.go", false))
+	assert.False(t, m.Ignored("src/main.go", false))
+}
+
+func TestMatcherAddFile(t *testing.T) {
+	dir := t.TempDir()
+	m := NewMatcher()
+
+	// Missing files are fine
+	require.NoError(t, m.AddFile("", filepath.Join(dir, "missing")))
+	assert.Equal(t, 0, m.Len())
+
+	path := filepath.Join(dir, ".gitignore")
+	require.NoError(t, os.WriteFile(path, []byte("*.log\n# comment\nbin/\n"), 0644))
+	require.NoError(t, m.AddFile("", path))
+	assert.Equal(t, 2, m.Len())
+}
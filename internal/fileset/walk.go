@@ -0,0 +1,100 @@
+package fileset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// walker reads directories concurrently, applying ignore rules as it goes
+type walker struct {
+	root        string
+	isExcluded  func(name string) bool
+	concurrency chan struct{} // Bounds the number of directories read at once
+
+	wg    sync.WaitGroup
+	mu    sync.Mutex
+	files []string
+}
+
+// walk enumerates files without git. Nested .gitignore files apply to their
+// own directory and below, and ignored directories are never descended into.
+func (e *Enumerator) walk() ([]string, error) {
+	matcher := NewMatcher()
+	for _, ignoreFile := range []string{
+		filepath.Join(e.workDir, ".git", "info", "exclude"),
+		filepath.Join(e.workDir, IgnoreFilename),
+	} {
+		if err := matcher.AddFile("", ignoreFile); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", ignoreFile, err)
+		}
+	}
+
+	// Read the root synchronously so a missing work dir is reported
+	if _, err := os.ReadDir(e.workDir); err != nil {
+		return nil, err
+	}
+
+	w := &walker{
+		root:        e.workDir,
+		isExcluded:  e.isExcludedDir,
+		concurrency: make(chan struct{}, runtime.NumCPU()*2),
+	}
+	w.wg.Add(1)
+	go w.visit("", matcher)
+	w.wg.Wait()
+
+	return w.files, nil
+}
+
+// visit reads one directory (relative to the root, slash-separated) and
+// schedules its subdirectories. Unreadable directories are skipped.
+func (w *walker) visit(relDir string, matcher *Matcher) {
+	defer w.wg.Done()
+
+	w.concurrency <- struct{}{}
+	entries, err := os.ReadDir(filepath.Join(w.root, filepath.FromSlash(relDir)))
+	<-w.concurrency
+	if err != nil {
+		return
+	}
+
+	// A .gitignore applies to everything in its directory
+	for _, entry := range entries {
+		if entry.Name() == ".gitignore" && !entry.IsDir() {
+			nested := matcher.clone()
+			if err := nested.AddFile(relDir, filepath.Join(w.root, filepath.FromSlash(relDir), ".gitignore")); err == nil {
+				matcher = nested
+			}
+			break
+		}
+	}
+
+	var files []string
+	for _, entry := range entries {
+		relPath := entry.Name()
+		if relDir != "" {
+			relPath = relDir + "/" + entry.Name()
+		}
+
+		if entry.IsDir() {
+			if w.isExcluded(entry.Name()) || matcher.Match(relPath, true) {
+				continue
+			}
+			w.wg.Add(1)
+			go w.visit(relPath, matcher)
+			continue
+		}
+
+		if matcher.Match(relPath, false) {
+			continue
+		}
+		files = append(files, filepath.FromSlash(relPath))
+	}
+
+	w.mu.Lock()
+	w.files = append(w.files, files...)
+	w.mu.Unlock()
+}
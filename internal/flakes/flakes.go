@@ -0,0 +1,224 @@
+// Package flakes keeps a history of tests that fail intermittently.
+//
+// When the test gate fails, the failing tests are rerun. A test that passes
+// on a rerun is a flake; one that fails every rerun is a deterministic
+// failure. The history is stored in .superralph/flakes.json so known flakes
+// are recognized across builds.
+package flakes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/testresult"
+)
+
+// Filename is the flake history file, relative to the project directory
+const Filename = ".superralph/flakes.json"
+
+// Record is the failure history of a single test
+type Record struct {
+	Name        string    `json:"name"`
+	Package     string    `json:"package,omitempty"`
+	Flakes      int       `json:"flakes"`   // Failures that passed on a rerun
+	Failures    int       `json:"failures"` // Failures that failed every rerun
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	LastExcerpt string    `json:"last_excerpt,omitempty"`
+}
+
+// IsFlaky returns true if the test has ever passed on a rerun
+func (r *Record) IsFlaky() bool {
+	return r.Flakes > 0
+}
+
+// String returns the test as "package: name", or just the name
+func (r *Record) String() string {
+	return testresult.Failure{Name: r.Name, Package: r.Package}.String()
+}
+
+// History is the flake history of a project
+type History struct {
+	Tests map[string]*Record `json:"tests"`
+
+	path string
+}
+
+// New creates an empty history for the project in dir
+func New(dir string) *History {
+	return &History{
+		Tests: make(map[string]*Record),
+		path:  filepath.Join(dir, Filename),
+	}
+}
+
+// Load reads the flake history for the project in dir.
+// Returns an empty history if none has been recorded yet.
+func Load(dir string) (*History, error) {
+	h := New(dir)
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read flake history: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse flake history: %w", err)
+	}
+	if h.Tests == nil {
+		h.Tests = make(map[string]*Record)
+	}
+	return h, nil
+}
+
+// Save writes the history to disk
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create .superralph directory: %w", err)
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal flake history: %w", err)
+	}
+	if err := os.WriteFile(h.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write flake history: %w", err)
+	}
+	return nil
+}
+
+// Path returns the path to the history file
+func (h *History) Path() string {
+	return h.path
+}
+
+// Record adds a classified failure to the history
+func (h *History) Record(f testresult.Failure, flaky bool, now time.Time) {
+	key := f.String()
+	r, ok := h.Tests[key]
+	if !ok {
+		r = &Record{Name: f.Name, Package: f.Package, FirstSeen: now}
+		h.Tests[key] = r
+	}
+	if flaky {
+		r.Flakes++
+	} else {
+		r.Failures++
+	}
+	r.LastSeen = now
+	if f.Excerpt != "" {
+		r.LastExcerpt = f.Excerpt
+	}
+}
+
+// IsKnownFlake returns true if the failing test has flaked before
+func (h *History) IsKnownFlake(f testresult.Failure) bool {
+	r, ok := h.Tests[f.String()]
+	return ok && r.IsFlaky()
+}
+
+// Flaky returns the known flakes, most frequent first
+func (h *History) Flaky() []*Record {
+	var records []*Record
+	for _, r := range h.Tests {
+		if r.IsFlaky() {
+			records = append(records, r)
+		}
+	}
+	sortRecords(records)
+	return records
+}
+
+// All returns every recorded test, most flaky first
+func (h *History) All() []*Record {
+	records := make([]*Record, 0, len(h.Tests))
+	for _, r := range h.Tests {
+		records = append(records, r)
+	}
+	sortRecords(records)
+	return records
+}
+
+// sortRecords orders records by flake count, then failures, then most recent
+func sortRecords(records []*Record) {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Flakes != b.Flakes {
+			return a.Flakes > b.Flakes
+		}
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		if !a.LastSeen.Equal(b.LastSeen) {
+			return a.LastSeen.After(b.LastSeen)
+		}
+		return a.String() < b.String()
+	})
+}
+
+// Classify splits failures into flakes and deterministic failures using the
+// reports from rerunning them. A failure is a flake if any rerun ran tests
+// without it failing again. Reruns whose output wasn't recognized, or that
+// didn't build, prove nothing.
+func Classify(failures []testresult.Failure, reruns []*testresult.Report) (flaky, failing []testresult.Failure) {
+	for _, f := range failures {
+		if passedOnRerun(f, reruns) {
+			flaky = append(flaky, f)
+		} else {
+			failing = append(failing, f)
+		}
+	}
+	return flaky, failing
+}
+
+// passedOnRerun checks whether any rerun ran tests without f failing
+func passedOnRerun(f testresult.Failure, reruns []*testresult.Report) bool {
+	for _, report := range reruns {
+		if !ranTests(report) {
+			continue
+		}
+		failedAgain := false
+		for _, other := range report.Failures {
+			if sameTest(f, other) {
+				failedAgain = true
+				break
+			}
+		}
+		if !failedAgain {
+			return true
+		}
+	}
+	return false
+}
+
+// ranTests checks whether a rerun actually ran tests. Plain go test output
+// doesn't list passing tests, so a failing test is also evidence; a build
+// failure is not.
+func ranTests(report *testresult.Report) bool {
+	if report == nil {
+		return false
+	}
+	if report.Passed > 0 {
+		return true
+	}
+	for _, f := range report.Failures {
+		if !strings.HasPrefix(f.Name, "[") {
+			return true
+		}
+	}
+	return false
+}
+
+// sameTest compares failures by name, and by package when both have one,
+// since reruns of plain go test output only learn packages at the end
+func sameTest(a, b testresult.Failure) bool {
+	if a.Name != b.Name {
+		return false
+	}
+	return a.Package == "" || b.Package == "" || a.Package == b.Package
+}
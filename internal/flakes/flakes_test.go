@@ -0,0 +1,95 @@
+package flakes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/testresult"
+)
+
+func TestLoadMissing(t *testing.T) {
+	h, err := Load(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, h.Tests)
+	assert.Empty(t, h.Flaky())
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".superralph"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, Filename), []byte("{"), 0644))
+
+	_, err := Load(dir)
+	assert.Error(t, err)
+}
+
+func TestRecordSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC)
+	later := first.Add(time.Hour)
+
+	integration := testresult.Failure{Name: "TestSync", Package: "app/integration", Excerpt: "timeout after 5s"}
+	unit := testresult.Failure{Name: "TestParse", Package: "app/parser"}
+
+	h := New(dir)
+	h.Record(integration, true, first)
+	h.Record(integration, false, later)
+	h.Record(unit, false, later)
+	require.NoError(t, h.Save())
+
+	loaded, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, loaded.Tests, 2)
+
+	r := loaded.Tests["app/integration: TestSync"]
+	require.NotNil(t, r)
+	assert.Equal(t, 1, r.Flakes)
+	assert.Equal(t, 1, r.Failures)
+	assert.True(t, r.FirstSeen.Equal(first))
+	assert.True(t, r.LastSeen.Equal(later))
+	assert.Equal(t, "timeout after 5s", r.LastExcerpt)
+
+	assert.True(t, loaded.IsKnownFlake(integration))
+	assert.False(t, loaded.IsKnownFlake(unit))
+	assert.False(t, loaded.IsKnownFlake(testresult.Failure{Name: "TestSync", Package: "app/other"}))
+
+	flaky := loaded.Flaky()
+	require.Len(t, flaky, 1)
+	assert.Equal(t, "app/integration: TestSync", flaky[0].String())
+
+	all := loaded.All()
+	require.Len(t, all, 2)
+	assert.Equal(t, "TestSync", all[0].Name)
+	assert.Equal(t, "TestParse", all[1].Name)
+}
+
+func TestClassify(t *testing.T) {
+	flake := testresult.Failure{Name: "TestSync", Package: "app/integration"}
+	broken := testresult.Failure{Name: "TestParse", Package: "app/parser"}
+	failures := []testresult.Failure{flake, broken}
+
+	reruns := []*testresult.Report{
+		// First rerun: both fail again
+		{Passed: 0, Failed: 2, Failures: []testresult.Failure{flake, broken}},
+		// Second rerun: only the parser test fails; plain go test output
+		// doesn't count passing tests, and the package name may be unknown
+		{Failed: 1, Failures: []testresult.Failure{{Name: "TestParse"}}},
+	}
+
+	flaky, failing := Classify(failures, reruns)
+	assert.Equal(t, []testresult.Failure{flake}, flaky)
+	assert.Equal(t, []testresult.Failure{broken}, failing)
+}
+
+func TestClassifyIgnoresUnrecognizedReruns(t *testing.T) {
+	failures := []testresult.Failure{{Name: "TestSync"}}
+
+	flaky, failing := Classify(failures, []*testresult.Report{nil, {Failed: 1, Failures: []testresult.Failure{{Name: "[build failed]"}}}})
+	assert.Empty(t, flaky)
+	assert.Equal(t, failures, failing)
+}
@@ -1,11 +1,14 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 // IsRepo checks if the given directory is a git repository
@@ -135,3 +138,537 @@ func GetStatusCurrentDir() (string, error) {
 	}
 	return GetStatus(cwd)
 }
+
+// IsInsideWorkTree checks if dir is anywhere inside a git working tree
+func IsInsideWorkTree(dir string) bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "true"
+}
+
+// GitPath resolves a path inside the repository's git directory (e.g. "index")
+// to an absolute path. This handles worktrees and relocated git directories.
+func GitPath(dir, name string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", name)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+// ListFiles returns the tracked and untracked (but not ignored) files under dir,
+// relative to dir. Tracked files that have been deleted from the working tree
+// are omitted.
+func ListFiles(dir string) ([]string, error) {
+	listed, err := lsFiles(dir, "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := lsFiles(dir, "--deleted")
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(deleted))
+	for _, path := range deleted {
+		skip[path] = true
+	}
+
+	files := make([]string, 0, len(listed))
+	for _, path := range listed {
+		if skip[path] {
+			continue
+		}
+		skip[path] = true // Unmerged files are listed once per stage
+		files = append(files, filepath.FromSlash(path))
+	}
+	return files, nil
+}
+
+// lsFiles runs git ls-files with NUL-separated output so unusual file names
+// are not quoted
+func lsFiles(dir string, args ...string) ([]string, error) {
+	cmd := exec.Command("git", append([]string{"ls-files", "-z"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %w", err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// emptyTree is the hash of git's empty tree, used to diff repositories without commits
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// HeadCommit returns the hash of the current commit, or "" if there are no commits yet
+func HeadCommit(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil // No commits yet
+		}
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Diff returns the changes from base to the working tree, including commits
+// made since base, without context lines. An empty base diffs against the
+// empty tree. Untracked files that aren't ignored are included as new files.
+func Diff(dir, base string) (string, error) {
+	if base == "" {
+		base = emptyTree
+	}
+	cmd := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "-U0", base, "--")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+
+	untracked, err := lsFiles(dir, "--others", "--exclude-standard")
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.Write(output)
+	for _, path := range untracked {
+		// --no-index exits 1 when the files differ, as they always do here
+		cmd := exec.Command("git", "diff", "--no-index", "--no-color", "--no-ext-diff", "-U0", "--", os.DevNull, path)
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return "", fmt.Errorf("git diff failed for %s: %w", path, err)
+		}
+		sb.Write(output)
+	}
+	return sb.String(), nil
+}
+
+// CommitOptions controls how CommitAll and MergeSquash sign commits
+type CommitOptions struct {
+	Sign       string // "gpg" or "ssh" to sign; "" uses the repository's configuration
+	SigningKey string // GPG key ID or SSH key file; defaults to user.signingkey
+}
+
+// ValidSignModes returns the accepted values of CommitOptions.Sign
+func ValidSignModes() []string {
+	return []string{"", "gpg", "ssh"}
+}
+
+// CommitAll stages every change under dir, except SuperRalph's own
+// .superralph directory, and commits it with message.
+// Returns the new commit's hash, or "" if there was nothing to commit.
+func CommitAll(dir, message string, opts CommitOptions) (string, error) {
+	if err := validateCommitOptions(opts); err != nil {
+		return "", err
+	}
+	if err := run(dir, "add", "-A", "--", ".", ":(exclude).superralph"); err != nil {
+		return "", err
+	}
+	return commitStaged(dir, message, opts)
+}
+
+// validateCommitOptions checks the signing mode
+func validateCommitOptions(opts CommitOptions) error {
+	if !slices.Contains(ValidSignModes(), opts.Sign) {
+		return fmt.Errorf("invalid signing mode %q (must be gpg or ssh)", opts.Sign)
+	}
+	return nil
+}
+
+// commitStaged commits the index with message.
+// Returns the new commit's hash, or "" if nothing is staged.
+func commitStaged(dir, message string, opts CommitOptions) (string, error) {
+	// Exit code 1 means there are staged changes
+	staged := exec.Command("git", "diff", "--cached", "--quiet")
+	staged.Dir = dir
+	if err := staged.Run(); err == nil {
+		return "", nil
+	}
+
+	var args []string
+	switch opts.Sign {
+	case "gpg":
+		args = append(args, "-c", "gpg.format=openpgp")
+	case "ssh":
+		args = append(args, "-c", "gpg.format=ssh")
+	}
+	args = append(args, "commit", "--file=-")
+	if opts.SigningKey != "" {
+		args = append(args, "--gpg-sign="+opts.SigningKey)
+	} else if opts.Sign != "" {
+		args = append(args, "--gpg-sign")
+	}
+
+	commit := exec.Command("git", args...)
+	commit.Dir = dir
+	commit.Stdin = strings.NewReader(message)
+	if output, err := commit.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git commit failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return HeadCommit(dir)
+}
+
+// CurrentBranch returns the name of the checked out branch.
+// Returns an error if HEAD is detached.
+func CurrentBranch(dir string) (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("not on a branch (detached HEAD?): %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// BranchExists checks if a local branch exists
+func BranchExists(dir, name string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	cmd.Dir = dir
+	return cmd.Run() == nil
+}
+
+// Checkout switches to an existing branch, carrying over uncommitted changes
+func Checkout(dir, name string) error {
+	return run(dir, "checkout", name)
+}
+
+// CreateBranch creates a branch from start and switches to it
+func CreateBranch(dir, name, start string) error {
+	return run(dir, "checkout", "-b", name, start)
+}
+
+// DeleteBranch deletes a local branch, even if git doesn't consider it merged
+// (squash merges aren't)
+func DeleteBranch(dir, name string) error {
+	return run(dir, "branch", "-D", name)
+}
+
+// CommitSubjects returns the subjects of the commits on to that aren't on
+// from, oldest first
+func CommitSubjects(dir, from, to string) ([]string, error) {
+	cmd := exec.Command("git", "log", "--reverse", "--format=%s", from+".."+to)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// MergeSquash squashes branch into the current branch as a single commit
+// with message. Returns the new commit's hash, or "" if branch had nothing
+// to merge.
+func MergeSquash(dir, branch, message string, opts CommitOptions) (string, error) {
+	if err := validateCommitOptions(opts); err != nil {
+		return "", err
+	}
+	if err := run(dir, "merge", "--squash", branch); err != nil {
+		return "", err
+	}
+	return commitStaged(dir, message, opts)
+}
+
+// MergeFastForward fast-forwards the current branch to branch. Fails if the
+// current branch has commits that branch doesn't.
+func MergeFastForward(dir, branch string) (string, error) {
+	if err := run(dir, "merge", "--ff-only", branch); err != nil {
+		return "", err
+	}
+	return HeadCommit(dir)
+}
+
+// Merge merges branch into the current branch without committing, so
+// conflicts can be resolved first. On conflict, ConflictedFiles lists the
+// files to resolve; commit the result with CommitFiles.
+func Merge(dir, branch string) error {
+	return run(dir, "merge", "--no-commit", "--no-ff", branch)
+}
+
+// AbortMerge abandons a merge started by Merge, restoring the work tree
+func AbortMerge(dir string) error {
+	return run(dir, "merge", "--abort")
+}
+
+// ResolveUnion resolves a conflicted file by keeping the lines of both sides,
+// the current branch's first, and stages it. It suits files that only grow,
+// where both sides appended to the end.
+func ResolveUnion(dir, path string) error {
+	sides := make([]string, 3)
+	for i, stage := range []string{"2", "1", "3"} { // ours, base, theirs
+		cmd := exec.Command("git", "show", ":"+stage+":"+path)
+		cmd.Dir = dir
+		output, _ := cmd.Output() // A file added on both sides has no base
+		f, err := os.CreateTemp("", "merge-*")
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.Write(output); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		f.Close()
+		sides[i] = f.Name()
+	}
+
+	cmd := exec.Command("git", append([]string{"merge-file", "--union", "-p"}, sides...)...)
+	cmd.Dir = dir
+	merged, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git merge-file failed: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, path), merged, 0644); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return run(dir, "add", "--", path)
+}
+
+// HasTrackedChanges reports whether tracked files have uncommitted changes,
+// ignoring untracked files
+func HasTrackedChanges(dir string) (bool, error) {
+	cmd := exec.Command("git", "status", "--porcelain", "--untracked-files=no")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("git status failed: %w", err)
+	}
+	return len(strings.TrimSpace(string(output))) > 0, nil
+}
+
+// Revert applies the inverse of a commit to the work tree and index without
+// committing it. On conflict, ConflictedFiles lists the files to resolve.
+func Revert(dir, hash string) error {
+	return run(dir, "revert", "--no-commit", hash)
+}
+
+// ConflictedFiles returns the files with unresolved merge conflicts
+func ConflictedFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// RestoreFile sets a file in the work tree and index to its content at ref,
+// resolving any conflict in it. Files that don't exist at ref are left alone.
+func RestoreFile(dir, ref, path string) error {
+	exists := exec.Command("git", "cat-file", "-e", ref+":"+path)
+	exists.Dir = dir
+	if exists.Run() != nil {
+		return nil
+	}
+	return run(dir, "checkout", ref, "--", path)
+}
+
+// QuitRevert ends a conflicted revert, keeping its changes staged so the
+// next Revert can run
+func QuitRevert(dir string) error {
+	return run(dir, "revert", "--quit")
+}
+
+// AbortRevert discards the changes staged by Revert, resetting tracked files
+// to HEAD. Only safe if tracked files had no changes before reverting.
+func AbortRevert(dir string) error {
+	_ = QuitRevert(dir)
+	return run(dir, "reset", "--hard", "HEAD")
+}
+
+// CommitFiles stages paths and commits the index with message.
+// Returns the new commit's hash, or "" if there was nothing to commit.
+func CommitFiles(dir, message string, paths []string, opts CommitOptions) (string, error) {
+	if err := validateCommitOptions(opts); err != nil {
+		return "", err
+	}
+	if len(paths) > 0 {
+		if err := run(dir, append([]string{"add", "--"}, paths...)...); err != nil {
+			return "", err
+		}
+	}
+	return commitStaged(dir, message, opts)
+}
+
+// run runs a git command, returning its output in the error if it fails
+func run(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Push pushes branch to remote and sets it as the branch's upstream
+func Push(dir, remote, branch string) error {
+	return run(dir, "push", "--set-upstream", remote, branch)
+}
+
+// RemoteURL returns the URL of a remote
+func RemoteURL(dir, remote string) (string, error) {
+	cmd := exec.Command("git", "remote", "get-url", remote)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("remote %s not found: %w", remote, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DiffStat returns a summary of the files changed between from and to
+func DiffStat(dir, from, to string) (string, error) {
+	cmd := exec.Command("git", "diff", "--stat", "--no-color", from+"..."+to)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// DiffRange returns the full diff between two commits. An empty from diffs
+// against the empty tree.
+func DiffRange(dir, from, to string) (string, error) {
+	if from == "" {
+		from = emptyTree
+	}
+	cmd := exec.Command("git", "diff", "--no-color", "--no-ext-diff", from, to, "--")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// Commit is a commit returned by Log
+type Commit struct {
+	Hash     string              `json:"hash"`
+	Author   string              `json:"author"`
+	Time     time.Time           `json:"time"`
+	Subject  string              `json:"subject"`
+	Body     string              `json:"body,omitempty"`
+	Trailers map[string][]string `json:"trailers,omitempty"` // e.g. "Feature-Id": ["feat-004"]
+}
+
+// ShortHash returns the abbreviated hash
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// Trailer returns the first value of a trailer, or ""
+func (c Commit) Trailer(key string) string {
+	if values := c.Trailers[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// LogOptions filters Log
+type LogOptions struct {
+	Ref   string    // Revision to list (default: HEAD)
+	Max   int       // Maximum number of commits (0 for all)
+	Grep  string    // Only commits whose message matches this regular expression
+	Since time.Time // Only commits after this time
+}
+
+// Field and record separators for Log's format
+const (
+	logFieldSep  = "\x1f"
+	logRecordSep = "\x1e"
+)
+
+// Log returns commits, newest first, with their messages and trailers.
+// A repository without commits has an empty log.
+func Log(dir string, opts LogOptions) ([]Commit, error) {
+	format := strings.Join([]string{"%H", "%an", "%aI", "%s", "%b", "%(trailers:only,unfold)"}, logFieldSep) + logRecordSep
+	args := []string{"log", "--format=" + format}
+	if opts.Max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", opts.Max))
+	}
+	if opts.Grep != "" {
+		args = append(args, "--extended-regexp", "--grep="+opts.Grep)
+	}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since="+opts.Since.Format(time.RFC3339))
+	}
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	args = append(args, ref, "--")
+
+	if head, err := HeadCommit(dir); err != nil {
+		return nil, err
+	} else if head == "" && ref == "HEAD" {
+		return nil, nil // No commits yet
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(output), logRecordSep) {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), logFieldSep)
+		if len(fields) != 6 {
+			continue
+		}
+		c := Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Subject: fields[3],
+			Body:    strings.TrimSpace(fields[4]),
+		}
+		c.Time, _ = time.Parse(time.RFC3339, fields[2])
+		for _, line := range strings.Split(fields[5], "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			if c.Trailers == nil {
+				c.Trailers = make(map[string][]string)
+			}
+			key = strings.TrimSpace(key)
+			c.Trailers[key] = append(c.Trailers[key], strings.TrimSpace(value))
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
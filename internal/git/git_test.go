@@ -2,8 +2,10 @@ package git
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -129,3 +131,419 @@ func TestGetStatus(t *testing.T) {
 	require.NoError(t, err, "GetStatus() error")
 	assert.NotEmpty(t, status, "GetStatus() = empty after creating file")
 }
+
+// runGit runs a git command in dir, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+}
+
+func TestIsInsideWorkTree(t *testing.T) {
+	tmpDir := t.TempDir()
+	assert.False(t, IsInsideWorkTree(tmpDir))
+
+	require.NoError(t, Init(tmpDir))
+	subDir := filepath.Join(tmpDir, "sub")
+	require.NoError(t, os.Mkdir(subDir, 0755))
+
+	assert.True(t, IsInsideWorkTree(tmpDir))
+	assert.True(t, IsInsideWorkTree(subDir), "subdirectories are inside the work tree")
+}
+
+func TestGitPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+
+	path, err := GitPath(tmpDir, "index")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, ".git", "index"), path)
+}
+
+func TestListFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+
+	files := map[string]string{
+		".gitignore":     "*.log\nbuild/\n",
+		"main.go":        "package main",
+		"src/util.go":    "package src",
+		"deleted.go":     "package main",
+		"untracked.go":   "package main",
+		"debug.log":      "ignored",
+		"build/out.bin":  "ignored",
+		"src/nested.log": "ignored",
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	runGit(t, tmpDir, "add", ".gitignore", "main.go", "src/util.go", "deleted.go")
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "deleted.go")))
+
+	listed, err := ListFiles(tmpDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		".gitignore",
+		"main.go",
+		filepath.Join("src", "util.go"),
+		"untracked.go",
+	}, listed)
+
+	// Paths are relative to the directory asked about
+	listed, err = ListFiles(filepath.Join(tmpDir, "src"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"util.go"}, listed)
+}
+
+func TestHeadCommitAndDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+
+	head, err := HeadCommit(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, head, "no commits yet")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("one\n"), 0644))
+	runGit(t, tmpDir, "add", "a.txt")
+
+	diff, err := Diff(tmpDir, "")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+one")
+
+	runGit(t, tmpDir, "commit", "-m", "first")
+	head, err = HeadCommit(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, head, 40)
+
+	// Committed and uncommitted changes since head are both included
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("two\n"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "second")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("three\n"), 0644))
+
+	diff, err = Diff(tmpDir, head)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "-one")
+	assert.Contains(t, diff, "+three")
+	assert.NotContains(t, diff, "two")
+
+	// Untracked files are new files; ignored ones are left out
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("*.log\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b_test.js"), []byte("it.only('works', () => {})\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "debug.log"), []byte("noise\n"), 0644))
+
+	diff, err = Diff(tmpDir, head)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+++ b/b_test.js\n")
+	assert.Contains(t, diff, "+it.only('works', () => {})")
+	assert.NotContains(t, diff, "noise")
+}
+
+func TestCommitAll(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "commit.gpgsign", "false")
+
+	hash, err := CommitAll(tmpDir, "empty", CommitOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, hash, "nothing to commit")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".superralph"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".superralph", "state.json"), []byte("{}"), 0644))
+
+	hash, err = CommitAll(tmpDir, "feat: add main\n\nFeature-Id: feat-001\n", CommitOptions{})
+	require.NoError(t, err)
+	head, err := HeadCommit(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, head, hash)
+
+	cmd := exec.Command("git", "show", "--name-only", "--format=%B", hash)
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "feat: add main\n\nFeature-Id: feat-001\n")
+	assert.Contains(t, string(output), "main.go")
+	assert.NotContains(t, string(output), ".superralph", "harness state isn't committed")
+
+	_, err = CommitAll(tmpDir, "x", CommitOptions{Sign: "pgp"})
+	assert.Error(t, err)
+}
+
+func TestBranchesAndMerges(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "commit.gpgsign", "false")
+	runGit(t, tmpDir, "checkout", "-b", "main")
+	commitFile := func(name, message string) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644))
+		_, err := CommitAll(tmpDir, message, CommitOptions{})
+		require.NoError(t, err)
+	}
+	commitFile("a.txt", "first")
+
+	branch, err := CurrentBranch(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+	assert.True(t, BranchExists(tmpDir, "main"))
+	assert.False(t, BranchExists(tmpDir, "ralph/feat-001"))
+
+	// Squash merge
+	require.NoError(t, CreateBranch(tmpDir, "ralph/feat-001", "main"))
+	commitFile("b.txt", "add b")
+	commitFile("c.txt", "add c")
+	subjects, err := CommitSubjects(tmpDir, "main", "ralph/feat-001")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"add b", "add c"}, subjects)
+
+	require.NoError(t, Checkout(tmpDir, "main"))
+	hash, err := MergeSquash(tmpDir, "ralph/feat-001", "feat: b and c\n", CommitOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+	subjects, err = CommitSubjects(tmpDir, hash+"~1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feat: b and c"}, subjects, "squashed into one commit")
+	assert.FileExists(t, filepath.Join(tmpDir, "c.txt"))
+	require.NoError(t, DeleteBranch(tmpDir, "ralph/feat-001"))
+	assert.False(t, BranchExists(tmpDir, "ralph/feat-001"))
+
+	// Fast-forward
+	require.NoError(t, CreateBranch(tmpDir, "ralph/feat-002", "main"))
+	commitFile("d.txt", "add d")
+	require.NoError(t, Checkout(tmpDir, "main"))
+	hash, err = MergeFastForward(tmpDir, "ralph/feat-002")
+	require.NoError(t, err)
+	subjects, err = CommitSubjects(tmpDir, hash+"~1", hash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"add d"}, subjects)
+
+	// Fast-forward fails once the branches diverge
+	require.NoError(t, CreateBranch(tmpDir, "ralph/feat-003", "main"))
+	commitFile("e.txt", "add e")
+	require.NoError(t, Checkout(tmpDir, "main"))
+	commitFile("f.txt", "add f")
+	_, err = MergeFastForward(tmpDir, "ralph/feat-003")
+	assert.Error(t, err)
+
+	runGit(t, tmpDir, "checkout", "--detach")
+	_, err = CurrentBranch(tmpDir)
+	assert.Error(t, err)
+}
+
+func TestPushAndDiffStat(t *testing.T) {
+	remote := t.TempDir()
+	runGit(t, remote, "init", "--bare")
+
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "commit.gpgsign", "false")
+	runGit(t, tmpDir, "checkout", "-b", "main")
+	runGit(t, tmpDir, "remote", "add", "origin", remote)
+
+	url, err := RemoteURL(tmpDir, "origin")
+	require.NoError(t, err)
+	assert.Equal(t, remote, url)
+	_, err = RemoteURL(tmpDir, "upstream")
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("a\n"), 0644))
+	_, err = CommitAll(tmpDir, "first", CommitOptions{})
+	require.NoError(t, err)
+	require.NoError(t, CreateBranch(tmpDir, "ralph/feat-001", "main"))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("b\nb\n"), 0644))
+	_, err = CommitAll(tmpDir, "second", CommitOptions{})
+	require.NoError(t, err)
+
+	stat, err := DiffStat(tmpDir, "main", "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, stat, "b.txt | 2 ++")
+	assert.NotContains(t, stat, "a.txt")
+
+	patch, err := DiffRange(tmpDir, "main", "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, patch, "+++ b/b.txt")
+	assert.NotContains(t, patch, "a.txt")
+	patch, err = DiffRange(tmpDir, "", "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, patch, "+++ b/a.txt", "empty from diffs the whole tree")
+
+	require.NoError(t, Push(tmpDir, "origin", "ralph/feat-001"))
+	cmd := exec.Command("git", "rev-parse", "refs/heads/ralph/feat-001")
+	cmd.Dir = remote
+	require.NoError(t, cmd.Run(), "branch pushed")
+
+	assert.Error(t, Push(tmpDir, "upstream", "main"))
+}
+
+func TestLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "commit.gpgsign", "false")
+
+	commits, err := Log(tmpDir, LogOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, commits, "no commits yet")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("a"), 0644))
+	_, err = CommitAll(tmpDir, "chore: setup", CommitOptions{})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("b"), 0644))
+	_, err = CommitAll(tmpDir, "feat: add b\n\n- Write b\n\nFeature-Id: feat-001\nFeature-Id: feat-002\nSuperRalph-Iteration: 3\n", CommitOptions{})
+	require.NoError(t, err)
+
+	commits, err = Log(tmpDir, LogOptions{})
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+
+	c := commits[0]
+	assert.Equal(t, "feat: add b", c.Subject)
+	assert.Equal(t, "Test", c.Author)
+	assert.Len(t, c.ShortHash(), 7)
+	assert.WithinDuration(t, time.Now(), c.Time, time.Minute)
+	assert.Contains(t, c.Body, "- Write b")
+	assert.Equal(t, []string{"feat-001", "feat-002"}, c.Trailers["Feature-Id"])
+	assert.Equal(t, "3", c.Trailer("SuperRalph-Iteration"))
+	assert.Equal(t, "", c.Trailer("SuperRalph-Run"))
+	assert.Equal(t, "chore: setup", commits[1].Subject)
+	assert.Empty(t, commits[1].Trailers)
+
+	commits, err = Log(tmpDir, LogOptions{Grep: "^Feature-Id: feat-002$"})
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "feat: add b", commits[0].Subject)
+
+	commits, err = Log(tmpDir, LogOptions{Max: 1})
+	require.NoError(t, err)
+	assert.Len(t, commits, 1)
+}
+
+func TestMergeResolveUnion(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "commit.gpgsign", "false")
+	runGit(t, tmpDir, "checkout", "-b", "main")
+
+	write := func(name, content, message string) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+		_, err := CommitAll(tmpDir, message, CommitOptions{})
+		require.NoError(t, err)
+	}
+	write("notes.txt", "one\n", "first")
+	require.NoError(t, CreateBranch(tmpDir, "ralph/feat-001", "main"))
+	write("notes.txt", "one\nfeature\n", "feature note")
+	require.NoError(t, Checkout(tmpDir, "main"))
+	write("notes.txt", "one\nmain\n", "main note")
+	write("a.txt", "a", "add a")
+	require.NoError(t, Checkout(tmpDir, "ralph/feat-001"))
+
+	require.Error(t, Merge(tmpDir, "main"))
+	conflicts, err := ConflictedFiles(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes.txt"}, conflicts)
+
+	require.NoError(t, ResolveUnion(tmpDir, "notes.txt"))
+	hash, err := CommitFiles(tmpDir, "chore: merge main\n", nil, CommitOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+	data, err := os.ReadFile(filepath.Join(tmpDir, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "one\nfeature\nmain\n", string(data))
+	assert.FileExists(t, filepath.Join(tmpDir, "a.txt"))
+
+	// main can now fast-forward to the branch
+	require.NoError(t, Checkout(tmpDir, "main"))
+	_, err = MergeFastForward(tmpDir, "ralph/feat-001")
+	require.NoError(t, err)
+
+	// An aborted merge leaves the branch as it was
+	require.NoError(t, CreateBranch(tmpDir, "ralph/feat-002", "main"))
+	write("notes.txt", "two\n", "rewrite")
+	require.NoError(t, Checkout(tmpDir, "main"))
+	write("notes.txt", "three\n", "rewrite again")
+	require.NoError(t, Checkout(tmpDir, "ralph/feat-002"))
+	require.Error(t, Merge(tmpDir, "main"))
+	require.NoError(t, AbortMerge(tmpDir))
+	changed, err := HasTrackedChanges(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestRevert(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, Init(tmpDir))
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "commit.gpgsign", "false")
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(tmpDir, name))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	write("notes.txt", "one\n")
+	_, err := CommitAll(tmpDir, "chore: setup", CommitOptions{})
+	require.NoError(t, err)
+	write("notes.txt", "one\ntwo\n")
+	write("a.txt", "a")
+	first, err := CommitAll(tmpDir, "feat: add a", CommitOptions{})
+	require.NoError(t, err)
+	write("notes.txt", "one\ntwo\nthree\n")
+	_, err = CommitAll(tmpDir, "docs: more notes", CommitOptions{})
+	require.NoError(t, err)
+
+	changed, err := HasTrackedChanges(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.Error(t, Revert(tmpDir, first), "notes.txt conflicts")
+	conflicts, err := ConflictedFiles(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes.txt"}, conflicts)
+
+	require.NoError(t, RestoreFile(tmpDir, "HEAD", "notes.txt"))
+	require.NoError(t, RestoreFile(tmpDir, "HEAD", "missing.txt"), "files missing at HEAD are skipped")
+	require.NoError(t, QuitRevert(tmpDir))
+	conflicts, err = ConflictedFiles(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "one\ntwo\nthree\n", read("notes.txt"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "a.txt"), "the rest of the commit is reverted")
+
+	hash, err := CommitFiles(tmpDir, "revert: add a", []string{"notes.txt"}, CommitOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+	changed, err = HasTrackedChanges(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	write("b.txt", "b")
+	_, err = CommitAll(tmpDir, "feat: add b", CommitOptions{})
+	require.NoError(t, err)
+	head, err := HeadCommit(tmpDir)
+	require.NoError(t, err)
+	require.NoError(t, Revert(tmpDir, head))
+	changed, err = HasTrackedChanges(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	require.NoError(t, AbortRevert(tmpDir))
+	assert.Equal(t, "b", read("b.txt"), "abort restores the work tree")
+}
@@ -0,0 +1,257 @@
+// Package graph draws a PRD's feature dependencies: as a tree for the
+// terminal, and as Graphviz DOT or Mermaid for documents.
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+// Status is where a feature stands in the dependency graph
+type Status string
+
+const (
+	StatusPassing     Status = "passing"
+	StatusCurrent     Status = "current"     // Being built now
+	StatusReady       Status = "ready"       // Dependencies met
+	StatusBlocked     Status = "blocked"     // Waiting on dependencies
+	StatusHeld        Status = "held"        // Blocked, stuck, waiting for review or skipped (see prd.Status)
+	StatusUnreachable Status = "unreachable" // Can never be built
+)
+
+// Statuses returns the status of every feature. currentID names the feature
+// being built, if any.
+func Statuses(p *prd.PRD, currentID string) map[string]Status {
+	unreachable := p.Unreachable()
+	statuses := make(map[string]Status, len(p.Features))
+	for i := range p.Features {
+		f := &p.Features[i]
+		switch {
+		case f.Passes:
+			statuses[f.ID] = StatusPassing
+		case f.ID == currentID:
+			statuses[f.ID] = StatusCurrent
+		case !f.IsOpen():
+			statuses[f.ID] = StatusHeld
+		case slices.Contains(unreachable, f.ID):
+			statuses[f.ID] = StatusUnreachable
+		case p.DependenciesMet(f):
+			statuses[f.ID] = StatusReady
+		default:
+			statuses[f.ID] = StatusBlocked
+		}
+	}
+	return statuses
+}
+
+// Node is one line of a dependency tree
+type Node struct {
+	ID     string
+	Prefix string // Tree lines leading up to the feature, e.g. "│  └─ "
+	Depth  int
+	Repeat bool // Already shown under another dependency; its dependents aren't repeated
+}
+
+// Tree lays the features out as a tree, with each feature under the
+// features it depends on. Features without dependencies are the roots.
+// Features only reachable through a cycle become roots of their own.
+func Tree(p *prd.PRD) []Node {
+	var nodes []Node
+	shown := make(map[string]bool)
+
+	var walk func(id, prefix, branch string, depth int)
+	walk = func(id, prefix, branch string, depth int) {
+		if shown[id] {
+			nodes = append(nodes, Node{ID: id, Prefix: prefix + branch, Depth: depth, Repeat: true})
+			return
+		}
+		shown[id] = true
+		nodes = append(nodes, Node{ID: id, Prefix: prefix + branch, Depth: depth})
+
+		childPrefix := prefix
+		switch branch {
+		case "├─ ":
+			childPrefix += "│  "
+		case "└─ ":
+			childPrefix += "   "
+		}
+		dependents := p.Dependents(id)
+		dependents = slices.DeleteFunc(dependents, func(dep string) bool { return dep == id })
+		for i, dep := range dependents {
+			next := "├─ "
+			if i == len(dependents)-1 {
+				next = "└─ "
+			}
+			walk(dep, childPrefix, next, depth+1)
+		}
+	}
+
+	for _, f := range p.Features {
+		if !hasDependencies(p, f) {
+			walk(f.ID, "", "", 0)
+		}
+	}
+	for _, f := range p.Features {
+		if !shown[f.ID] {
+			walk(f.ID, "", "", 0)
+		}
+	}
+	return nodes
+}
+
+// hasDependencies reports whether a feature depends on another feature that exists
+func hasDependencies(p *prd.PRD, f prd.Feature) bool {
+	return slices.ContainsFunc(f.DependsOn, func(dep string) bool {
+		return dep != f.ID && p.GetFeature(dep) != nil
+	})
+}
+
+// fillColors are the node colors for each status in DOT and Mermaid
+var fillColors = map[Status]string{
+	StatusPassing:     "#c8f7d4",
+	StatusCurrent:     "#e0d4ff",
+	StatusReady:       "#ffffff",
+	StatusBlocked:     "#ffe4b3",
+	StatusHeld:        "#e4e4e4",
+	StatusUnreachable: "#ffc9c9",
+}
+
+// edge is a dependency, drawn from the dependency to the feature that needs it
+type edge struct {
+	from, to string
+	critical bool // On the critical path
+	cycle    bool // Part of a dependency cycle
+}
+
+// edges lists the PRD's dependencies in feature order
+func edges(p *prd.PRD) []edge {
+	onPath := make(map[[2]string]bool)
+	path := p.CriticalPath()
+	for i := 1; i < len(path); i++ {
+		onPath[[2]string{path[i-1], path[i]}] = true
+	}
+	inCycle := make(map[[2]string]bool)
+	for _, cycle := range p.Cycles() {
+		for i := 1; i < len(cycle); i++ {
+			inCycle[[2]string{cycle[i], cycle[i-1]}] = true
+		}
+	}
+
+	var result []edge
+	for _, f := range p.Features {
+		for _, dep := range f.DependsOn {
+			if p.GetFeature(dep) == nil {
+				continue
+			}
+			key := [2]string{dep, f.ID}
+			result = append(result, edge{from: dep, to: f.ID, critical: onPath[key], cycle: inCycle[key] || dep == f.ID})
+		}
+	}
+	return result
+}
+
+// DOT renders the dependency graph in Graphviz's DOT language. Arrows point
+// from a dependency to the features that need it; the critical path is bold
+// and cycles are red.
+func DOT(p *prd.PRD) string {
+	statuses := Statuses(p, "")
+	var b strings.Builder
+	b.WriteString("digraph prd {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	if p.Name != "" {
+		b.WriteString(fmt.Sprintf("  label=%s;\n  labelloc=t;\n", dotQuote(p.Name)))
+	}
+	b.WriteString("\n")
+	for _, f := range p.Features {
+		status := statuses[f.ID]
+		b.WriteString(fmt.Sprintf("  %s [label=%s, fillcolor=%s, tooltip=%s];\n",
+			dotQuote(f.ID), dotQuote(f.ID+"\n"+f.Description), dotQuote(fillColors[status]), dotQuote(string(status))))
+	}
+	if es := edges(p); len(es) > 0 {
+		b.WriteString("\n")
+		for _, e := range es {
+			var attrs []string
+			if e.critical {
+				attrs = append(attrs, "penwidth=2.5")
+			}
+			if e.cycle {
+				attrs = append(attrs, "color=\"#d70000\"")
+			}
+			line := fmt.Sprintf("  %s -> %s", dotQuote(e.from), dotQuote(e.to))
+			if len(attrs) > 0 {
+				line += " [" + strings.Join(attrs, ", ") + "]"
+			}
+			b.WriteString(line + ";\n")
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes a DOT identifier
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// Mermaid renders the dependency graph as a Mermaid flowchart, styled like DOT
+func Mermaid(p *prd.PRD) string {
+	statuses := Statuses(p, "")
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, f := range p.Features {
+		b.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", mermaidID(f.ID), mermaidText(f.ID+": "+f.Description)))
+	}
+
+	var critical, cycle []string
+	for i, e := range edges(p) {
+		b.WriteString(fmt.Sprintf("  %s --> %s\n", mermaidID(e.from), mermaidID(e.to)))
+		if e.critical {
+			critical = append(critical, fmt.Sprint(i))
+		}
+		if e.cycle {
+			cycle = append(cycle, fmt.Sprint(i))
+		}
+	}
+	if len(critical) > 0 {
+		b.WriteString(fmt.Sprintf("  linkStyle %s stroke-width:3px\n", strings.Join(critical, ",")))
+	}
+	if len(cycle) > 0 {
+		b.WriteString(fmt.Sprintf("  linkStyle %s stroke:#d70000\n", strings.Join(cycle, ",")))
+	}
+
+	for _, status := range []Status{StatusPassing, StatusReady, StatusBlocked, StatusHeld, StatusUnreachable} {
+		var ids []string
+		for _, f := range p.Features {
+			if statuses[f.ID] == status {
+				ids = append(ids, mermaidID(f.ID))
+			}
+		}
+		if len(ids) > 0 {
+			b.WriteString(fmt.Sprintf("  classDef %s fill:%s,stroke:#585858\n", status, fillColors[status]))
+			b.WriteString(fmt.Sprintf("  class %s %s\n", strings.Join(ids, ","), status))
+		}
+	}
+	return b.String()
+}
+
+// mermaidID turns a feature ID into a Mermaid node ID
+func mermaidID(id string) string {
+	return "f_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// mermaidText escapes a node label
+func mermaidText(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return strings.ReplaceAll(s, "\n", " ")
+}
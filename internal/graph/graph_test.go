@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+func testPRD() *prd.PRD {
+	return &prd.PRD{Name: "Shop", Features: []prd.Feature{
+		{ID: "feat-001", Description: "Product \"catalog\"", Passes: true},
+		{ID: "feat-002", Description: "Cart", DependsOn: []string{"feat-001"}},
+		{ID: "feat-003", Description: "Checkout", DependsOn: []string{"feat-002", "feat-004"}},
+		{ID: "feat-004", Description: "Payments", DependsOn: []string{"feat-001"}},
+		{ID: "feat-005", Description: "Refunds", DependsOn: []string{"feat-006"}},
+		{ID: "feat-006", Description: "Disputes", DependsOn: []string{"feat-005"}},
+	}}
+}
+
+func TestStatuses(t *testing.T) {
+	assert.Equal(t, map[string]Status{
+		"feat-001": StatusPassing,
+		"feat-002": StatusCurrent,
+		"feat-003": StatusBlocked,
+		"feat-004": StatusReady,
+		"feat-005": StatusUnreachable,
+		"feat-006": StatusUnreachable,
+	}, Statuses(testPRD(), "feat-002"))
+}
+
+func TestTree(t *testing.T) {
+	var lines []string
+	for _, n := range Tree(testPRD()) {
+		line := n.Prefix + n.ID
+		if n.Repeat {
+			line += " (repeat)"
+		}
+		lines = append(lines, line)
+	}
+	assert.Equal(t, []string{
+		"feat-001",
+		"├─ feat-002",
+		"│  └─ feat-003",
+		"└─ feat-004",
+		"   └─ feat-003 (repeat)",
+		"feat-005",
+		"└─ feat-006",
+		"   └─ feat-005 (repeat)",
+	}, lines)
+}
+
+func TestDOT(t *testing.T) {
+	out := DOT(testPRD())
+	assert.Contains(t, out, "digraph prd {")
+	assert.Contains(t, out, `"feat-001" [label="feat-001\nProduct \"catalog\"", fillcolor="#c8f7d4", tooltip="passing"];`)
+	assert.Contains(t, out, `"feat-001" -> "feat-002";`, "feat-002 isn't left to build on the critical path")
+	assert.Contains(t, out, `"feat-002" -> "feat-003" [penwidth=2.5];`)
+	assert.Contains(t, out, `"feat-006" -> "feat-005" [color="#d70000"];`)
+}
+
+func TestMermaid(t *testing.T) {
+	out := Mermaid(testPRD())
+	assert.Contains(t, out, "flowchart LR\n")
+	assert.Contains(t, out, `f_feat_001["feat-001: Product #quot;catalog#quot;"]`)
+	assert.Contains(t, out, "f_feat_001 --> f_feat_002\n")
+	assert.Contains(t, out, "linkStyle 1 stroke-width:3px\n")
+	assert.Contains(t, out, "linkStyle 4,5 stroke:#d70000\n")
+	assert.Contains(t, out, "class f_feat_005,f_feat_006 unreachable\n")
+}
@@ -0,0 +1,401 @@
+// Package history builds per-feature timelines from everything a build
+// leaves behind: git commits (by Feature-Id trailer or message match),
+// progress.txt entries, saved sessions and resume state, test integrity
+// reviews and publication records.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/commitmsg"
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/publish"
+	"github.com/mpjhorner/superralph/internal/tamper"
+)
+
+// Feature statuses
+const (
+	StatusPassing     = "passing"
+	StatusInProgress  = "in progress"
+	StatusBlocked     = "blocked"
+	StatusStuck       = "stuck"
+	StatusNeedsReview = "needs review"
+	StatusSkipped     = "skipped"
+	StatusNotStarted  = "not started"
+)
+
+// heldStatuses are the statuses of features that aren't passing and won't
+// be built until someone acts, by their PRD status
+var heldStatuses = map[prd.Status]string{
+	prd.StatusBlocked:     StatusBlocked,
+	prd.StatusStuck:       StatusStuck,
+	prd.StatusNeedsReview: StatusNeedsReview,
+	prd.StatusSkipped:     StatusSkipped,
+}
+
+// Event kinds
+const (
+	EventCommit      = "commit"
+	EventProgress    = "progress"
+	EventSession     = "session"
+	EventInterrupted = "interrupted"
+	EventReview      = "review"
+	EventPublish     = "publish"
+	EventNote        = "note"
+)
+
+// Event is something that happened to a feature
+type Event struct {
+	Time      time.Time `json:"time,omitempty"` // Zero for progress entries without a timestamp
+	Kind      string    `json:"kind"`
+	Iteration int       `json:"iteration,omitempty"`
+	Detail    string    `json:"detail"`
+}
+
+// CommitRef is a commit that worked on a feature
+type CommitRef struct {
+	Hash    string    `json:"hash"`
+	Time    time.Time `json:"time"`
+	Subject string    `json:"subject"`
+	Status  string    `json:"status,omitempty"` // SuperRalph-Status trailer, if any
+}
+
+// TestOutcome is a test result recorded while working on a feature
+type TestOutcome struct {
+	Time   time.Time `json:"time,omitempty"`
+	Passed bool      `json:"passed"`
+	Source string    `json:"source"` // Where the result was recorded
+}
+
+// Timeline is the history of one feature
+type Timeline struct {
+	FeatureID       string        `json:"feature_id"`
+	Description     string        `json:"description"`
+	Status          string        `json:"status"`
+	Started         *time.Time    `json:"started,omitempty"`
+	Finished        *time.Time    `json:"finished,omitempty"`
+	DurationSeconds int64         `json:"duration_seconds,omitempty"`
+	Attempts        int           `json:"attempts"` // Iterations spent on the feature
+	Commits         []CommitRef   `json:"commits,omitempty"`
+	Tests           []TestOutcome `json:"tests,omitempty"`
+	Events          []Event       `json:"events,omitempty"`
+}
+
+// Duration returns the time from the first event to the feature passing,
+// or 0 if it hasn't finished
+func (t *Timeline) Duration() time.Duration {
+	return time.Duration(t.DurationSeconds) * time.Second
+}
+
+// TestsPassed returns how many recorded test outcomes passed
+func (t *Timeline) TestsPassed() int {
+	passed := 0
+	for _, o := range t.Tests {
+		if o.Passed {
+			passed++
+		}
+	}
+	return passed
+}
+
+// Session is the part of a saved session the history uses
+type Session struct {
+	ID        string
+	Mode      string
+	FeatureID string
+	Iteration int
+	Updated   time.Time // When the session file was last written
+}
+
+// Resume is the saved state of an interrupted build
+type Resume struct {
+	FeatureID string    `json:"current_feature"`
+	Iteration int       `json:"iteration"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Sources holds everything a history is built from
+type Sources struct {
+	PRD          *prd.PRD
+	Commits      []git.Commit // Newest first, as returned by git.Log
+	Progress     []progress.ParsedEntry
+	Sessions     []Session
+	Resume       *Resume
+	Reviews      []tamper.Review
+	Publications []publish.Record
+}
+
+// Load reads the sources for the project in dir, with the PRD at prdPath, or
+// the one in dir if prdPath is empty. Only the PRD is required; other sources
+// that are missing or unreadable are left empty.
+func Load(dir, prdPath string) (*Sources, error) {
+	if prdPath == "" {
+		prdPath = prd.PathInDir(dir)
+	}
+	p, err := prd.Load(prdPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filepath.Base(prdPath), err)
+	}
+	src := &Sources{PRD: p}
+
+	if git.IsInsideWorkTree(dir) {
+		if src.Commits, err = git.Log(dir, git.LogOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	if content, err := progress.Read(progress.GetPath(dir)); err == nil {
+		src.Progress = progress.ParseEntries(content)
+	}
+	src.Sessions = loadSessions(dir)
+	src.Resume = loadResume(dir)
+	src.Reviews, _ = tamper.LoadReviews(dir)
+	src.Publications, _ = publish.LoadRecords(dir)
+	return src, nil
+}
+
+// loadSessions reads the saved build sessions
+func loadSessions(dir string) []Session {
+	paths, _ := filepath.Glob(filepath.Join(dir, ".superralph", "sessions", "*.json"))
+	var sessions []Session
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var saved struct {
+			ID    string `json:"id"`
+			Mode  string `json:"mode"`
+			State struct {
+				Iteration      int    `json:"iteration"`
+				CurrentFeature string `json:"current_feature"`
+			} `json:"state"`
+		}
+		if json.Unmarshal(data, &saved) != nil {
+			continue
+		}
+		s := Session{ID: saved.ID, Mode: saved.Mode, FeatureID: saved.State.CurrentFeature, Iteration: saved.State.Iteration}
+		if info, err := os.Stat(path); err == nil {
+			s.Updated = info.ModTime()
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// loadResume reads the saved state of an interrupted build, if any
+func loadResume(dir string) *Resume {
+	data, err := os.ReadFile(filepath.Join(dir, ".superralph", "state.json"))
+	if err != nil {
+		return nil
+	}
+	var r Resume
+	if json.Unmarshal(data, &r) != nil || r.FeatureID == "" {
+		return nil
+	}
+	return &r
+}
+
+// Build returns a timeline for each feature, in PRD order
+func Build(src *Sources) []*Timeline {
+	timelines := make([]*Timeline, 0, len(src.PRD.Features))
+	for i := range src.PRD.Features {
+		timelines = append(timelines, build(src, &src.PRD.Features[i]))
+	}
+	return timelines
+}
+
+// Find returns the timeline of a feature, or nil
+func Find(timelines []*Timeline, featureID string) *Timeline {
+	for _, t := range timelines {
+		if t.FeatureID == featureID {
+			return t
+		}
+	}
+	return nil
+}
+
+func build(src *Sources, f *prd.Feature) *Timeline {
+	t := &Timeline{FeatureID: f.ID, Description: f.Description}
+	mentions := regexp.MustCompile(`\b` + regexp.QuoteMeta(f.ID) + `\b`)
+	var finished time.Time
+
+	// Commits, oldest first
+	commitIterations := make(map[string]bool)
+	for i := len(src.Commits) - 1; i >= 0; i-- {
+		c := src.Commits[i]
+		if !commitFor(c, f.ID, mentions) {
+			continue
+		}
+		status := c.Trailer(commitmsg.TrailerStatus)
+		iteration, _ := strconv.Atoi(c.Trailer(commitmsg.TrailerIteration))
+		t.Commits = append(t.Commits, CommitRef{Hash: c.Hash, Time: c.Time, Subject: c.Subject, Status: status})
+		t.Events = append(t.Events, Event{Time: c.Time, Kind: EventCommit, Iteration: iteration, Detail: c.ShortHash() + " " + c.Subject})
+
+		// A reset reopens the feature; it isn't an attempt at it
+		if status == commitmsg.StatusReset {
+			finished = time.Time{}
+			continue
+		}
+
+		key := c.Hash
+		if iteration > 0 {
+			key = c.Trailer(commitmsg.TrailerRun) + "/" + strconv.Itoa(iteration)
+		}
+		commitIterations[key] = true
+
+		// Harness commits are only made once the gates pass
+		if status != "" {
+			t.Tests = append(t.Tests, TestOutcome{Time: c.Time, Passed: true, Source: "gates at " + c.ShortHash()})
+		}
+		if status == commitmsg.StatusPassing && c.Time.After(finished) {
+			finished = c.Time
+		}
+	}
+
+	// Progress entries
+	progressEntries := 0
+	for _, e := range src.Progress {
+		if e.WorkingOn != f.ID && !mentions.MatchString(e.Text) {
+			continue
+		}
+		if e.Note != "" {
+			detail := e.Note
+			if lines := e.Section(e.Note); len(lines) > 0 {
+				detail += ": " + lines[0]
+			}
+			t.Events = append(t.Events, Event{Time: e.Timestamp, Kind: EventNote, Detail: detail})
+			continue
+		}
+		progressEntries++
+		t.Events = append(t.Events, Event{Time: e.Timestamp, Kind: EventProgress, Iteration: e.Iteration, Detail: summary(e.Text)})
+		if e.TestsPassed != nil {
+			t.Tests = append(t.Tests, TestOutcome{Time: e.Timestamp, Passed: *e.TestsPassed, Source: fmt.Sprintf("progress iteration %d", e.Iteration)})
+		}
+	}
+
+	for _, s := range src.Sessions {
+		if s.FeatureID == f.ID {
+			t.Events = append(t.Events, Event{Time: s.Updated, Kind: EventSession, Iteration: s.Iteration, Detail: fmt.Sprintf("%s session %s", s.Mode, s.ID)})
+		}
+	}
+	if r := src.Resume; r != nil && r.FeatureID == f.ID {
+		t.Events = append(t.Events, Event{Time: r.Timestamp, Kind: EventInterrupted, Iteration: r.Iteration, Detail: "build interrupted; resume with --resume"})
+	}
+	for _, r := range src.Reviews {
+		if r.FeatureID != f.ID {
+			continue
+		}
+		detail := fmt.Sprintf("%d test integrity finding(s) flagged", len(r.Findings))
+		if r.Rejected {
+			detail = fmt.Sprintf("rejected for %d test integrity finding(s)", len(r.Findings))
+		}
+		t.Events = append(t.Events, Event{Time: r.Recorded, Kind: EventReview, Iteration: r.Iteration, Detail: detail})
+	}
+	for _, r := range src.Publications {
+		if !slices.Contains(r.FeatureIDs, f.ID) {
+			continue
+		}
+		detail := "pushed " + r.Branch
+		switch {
+		case r.Error != "":
+			detail = "publish failed: " + r.Error
+		case r.URL != "":
+			detail = fmt.Sprintf("pull request #%d %s", r.Number, r.URL)
+		}
+		t.Events = append(t.Events, Event{Time: r.Recorded, Kind: EventPublish, Detail: detail})
+	}
+
+	// Events without a time go last; the rest in time order
+	sort.SliceStable(t.Events, func(i, j int) bool {
+		a, b := t.Events[i].Time, t.Events[j].Time
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	sort.SliceStable(t.Tests, func(i, j int) bool { return t.Tests[i].Time.Before(t.Tests[j].Time) })
+
+	t.Attempts = max(len(commitIterations), progressEntries)
+	if len(t.Events) > 0 && !t.Events[0].Time.IsZero() {
+		started := t.Events[0].Time
+		t.Started = &started
+	}
+
+	if t.Started == nil && f.StartedAt != nil {
+		t.Started = f.StartedAt
+	}
+
+	switch {
+	case f.Passes:
+		t.Status = StatusPassing
+		if finished.IsZero() && f.CompletedAt != nil {
+			finished = *f.CompletedAt
+		}
+		if finished.IsZero() {
+			for _, e := range t.Events {
+				if e.Time.After(finished) {
+					finished = e.Time
+				}
+			}
+		}
+		if !finished.IsZero() {
+			t.Finished = &finished
+			if t.Started != nil {
+				t.DurationSeconds = int64(finished.Sub(*t.Started).Seconds())
+			}
+		}
+	case heldStatuses[f.CurrentStatus()] != "":
+		t.Status = heldStatuses[f.CurrentStatus()]
+	case len(t.Events) > 0 || f.CurrentStatus() == prd.StatusInProgress:
+		t.Status = StatusInProgress
+	case !src.PRD.DependenciesMet(f):
+		t.Status = StatusBlocked
+	default:
+		t.Status = StatusNotStarted
+	}
+	return t
+}
+
+// commitFor reports whether a commit worked on the feature: by its
+// Feature-Id trailers, or by mentioning the feature in its message
+func commitFor(c git.Commit, featureID string, mentions *regexp.Regexp) bool {
+	if ids, ok := c.Trailers[commitmsg.TrailerFeature]; ok {
+		return slices.Contains(ids, featureID)
+	}
+	return mentions.MatchString(c.Subject) || mentions.MatchString(c.Body)
+}
+
+// summary picks a line describing a progress entry: the first work item,
+// or the first line that isn't a heading
+func summary(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "## Work Done" {
+			continue
+		}
+		for _, work := range lines[i+1:] {
+			if work = strings.TrimSpace(work); strings.HasPrefix(work, "#") {
+				break
+			} else if work != "" {
+				return strings.TrimPrefix(work, "- ")
+			}
+		}
+	}
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/publish"
+	"github.com/mpjhorner/superralph/internal/tamper"
+)
+
+func testPRD() *prd.PRD {
+	return &prd.PRD{Name: "Test", Features: []prd.Feature{
+		{ID: "feat-001", Description: "Export data", Steps: []string{"Step"}, Passes: true},
+		{ID: "feat-002", Description: "Import data", Steps: []string{"Step"}},
+		{ID: "feat-003", Description: "Sync data", Steps: []string{"Step"}, DependsOn: []string{"feat-002"}},
+		{ID: "feat-004", Description: "Delete data", Steps: []string{"Step"}},
+	}}
+}
+
+func TestBuild(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	passed, failed := true, false
+
+	src := &Sources{
+		PRD: testPRD(),
+		Commits: []git.Commit{ // Newest first
+			{Hash: "ccc3333333", Time: at(50), Subject: "feat: import data", Trailers: map[string][]string{
+				"Feature-Id": {"feat-002"}, "SuperRalph-Status": {"in-progress"}, "SuperRalph-Iteration": {"3"}, "SuperRalph-Run": {"run-1"},
+			}},
+			{Hash: "bbb2222222", Time: at(40), Subject: "feat: export data", Trailers: map[string][]string{
+				"Feature-Id": {"feat-001"}, "SuperRalph-Status": {"passing"}, "SuperRalph-Iteration": {"2"}, "SuperRalph-Run": {"run-1"},
+			}},
+			{Hash: "aaa1111111", Time: at(10), Subject: "Start feat-001 exporter"},
+			{Hash: "9990000000", Time: at(5), Subject: "Mention feat-0010 only"},
+		},
+		Progress: []progress.ParsedEntry{
+			{Timestamp: at(0), Iteration: 1, WorkingOn: "feat-001", TestsPassed: &failed, Text: "## Work Done\n- Wrote exporter\n"},
+			{Timestamp: at(30), Iteration: 2, TestsPassed: &passed, Text: "## Work Done\n- Finished feat-001\n"},
+			{Iteration: 3, Text: "Agent notes about feat-002"},
+		},
+		Sessions:     []Session{{ID: "s1", Mode: "build", FeatureID: "feat-002", Iteration: 3, Updated: at(55)}},
+		Resume:       &Resume{FeatureID: "feat-002", Iteration: 4, Timestamp: at(60)},
+		Reviews:      []tamper.Review{{FeatureID: "feat-002", Iteration: 3, Findings: []string{"skip added"}, Recorded: at(51)}},
+		Publications: []publish.Record{{FeatureIDs: []string{"feat-001"}, Branch: "ralph/feat-001", Pushed: true, Number: 4, URL: "https://example.com/pull/4", Recorded: at(45)}},
+	}
+
+	timelines := Build(src)
+	require.Len(t, timelines, 4)
+
+	done := Find(timelines, "feat-001")
+	assert.Equal(t, StatusPassing, done.Status)
+	require.NotNil(t, done.Started)
+	assert.Equal(t, at(0), *done.Started)
+	require.NotNil(t, done.Finished)
+	assert.Equal(t, at(40), *done.Finished, "finished at the passing commit")
+	assert.Equal(t, 40*time.Minute, done.Duration())
+	assert.Equal(t, 2, done.Attempts)
+	require.Len(t, done.Commits, 2, "by trailer and by message, but not feat-0010")
+	assert.Equal(t, "Start feat-001 exporter", done.Commits[0].Subject)
+	assert.Equal(t, "passing", done.Commits[1].Status)
+	assert.Equal(t, 2, done.TestsPassed())
+	assert.Len(t, done.Tests, 3)
+	assert.Equal(t, "Wrote exporter", done.Events[0].Detail)
+	assert.Equal(t, EventPublish, done.Events[len(done.Events)-1].Kind)
+
+	working := Find(timelines, "feat-002")
+	assert.Equal(t, StatusInProgress, working.Status)
+	assert.Nil(t, working.Finished)
+	assert.Zero(t, working.Duration())
+	kinds := []string{}
+	for _, e := range working.Events {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Equal(t, []string{EventCommit, EventReview, EventSession, EventInterrupted, EventProgress}, kinds,
+		"time order, with undated progress entries last")
+
+	assert.Equal(t, StatusBlocked, Find(timelines, "feat-003").Status)
+	assert.Equal(t, StatusNotStarted, Find(timelines, "feat-004").Status)
+	assert.Nil(t, Find(timelines, "feat-999"))
+
+	data, err := json.Marshal(done)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"duration_seconds":2400`)
+}
+
+func TestBuildReset(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	trailers := func(status, iteration string) map[string][]string {
+		return map[string][]string{"Feature-Id": {"feat-001"}, "SuperRalph-Status": {status}, "SuperRalph-Iteration": {iteration}, "SuperRalph-Run": {"run-1"}}
+	}
+
+	src := &Sources{
+		PRD: testPRD(),
+		Commits: []git.Commit{ // Newest first
+			{Hash: "ccc3333333", Time: at(40), Subject: "feat: export data", Trailers: trailers("passing", "5")},
+			{Hash: "bbb2222222", Time: at(20), Subject: "revert: export data", Trailers: map[string][]string{
+				"Feature-Id": {"feat-001"}, "SuperRalph-Status": {"reset"},
+			}},
+			{Hash: "aaa1111111", Time: at(10), Subject: "feat: export data", Trailers: trailers("passing", "1")},
+		},
+		Progress: []progress.ParsedEntry{
+			{Timestamp: at(20), Note: "Reset", Text: "## Reset\n- Reopened feat-001: CSV quoting was wrong\n"},
+		},
+	}
+
+	done := Find(Build(src), "feat-001")
+	assert.Equal(t, 2, done.Attempts, "neither the reset commit nor its note is an attempt")
+	assert.Equal(t, 2, done.TestsPassed())
+	require.NotNil(t, done.Finished)
+	assert.Equal(t, at(40), *done.Finished, "finished when it passed again")
+	require.Len(t, done.Commits, 3)
+
+	var note *Event
+	for i, e := range done.Events {
+		if e.Kind == EventNote {
+			note = &done.Events[i]
+		}
+	}
+	require.NotNil(t, note)
+	assert.Equal(t, "Reset: Reopened feat-001: CSV quoting was wrong", note.Detail)
+}
+
+func TestLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	_, err := Load(tmpDir, "")
+	assert.Error(t, err, "prd.json is required")
+
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		require.NoError(t, cmd.Run())
+	}
+	require.NoError(t, prd.SaveToDir(testPRD(), tmpDir))
+	_, err = git.CommitAll(tmpDir, "feat: export\n\nFeature-Id: feat-001\n", git.CommitOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, progress.NewWriter(tmpDir).Append(progress.Entry{
+		Timestamp:     time.Now(),
+		Iteration:     1,
+		StartingState: progress.State{WorkingOn: &progress.FeatureRef{ID: "feat-002"}},
+	}))
+	sessions := filepath.Join(tmpDir, ".superralph", "sessions")
+	require.NoError(t, os.MkdirAll(sessions, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sessions, "s1.json"),
+		[]byte(`{"id": "s1", "mode": "build", "state": {"iteration": 2, "current_feature": "feat-002"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sessions, "broken.json"), []byte(`{`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".superralph", "state.json"),
+		[]byte(`{"current_feature": "feat-002", "iteration": 3}`), 0644))
+
+	src, err := Load(tmpDir, "")
+	require.NoError(t, err)
+	require.Len(t, src.Commits, 1)
+	require.Len(t, src.Progress, 1)
+	require.Len(t, src.Sessions, 1)
+	assert.Equal(t, "feat-002", src.Sessions[0].FeatureID)
+	require.NotNil(t, src.Resume)
+	assert.Equal(t, 3, src.Resume.Iteration)
+
+	timelines := Build(src)
+	assert.Len(t, Find(timelines, "feat-001").Commits, 1)
+	assert.Len(t, Find(timelines, "feat-002").Events, 3)
+}
@@ -0,0 +1,322 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mpjhorner/superralph/internal/commitmsg"
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/publish"
+)
+
+// MergeStrategy is how a finished feature branch joins the integration branch
+type MergeStrategy string
+
+const (
+	MergeSquash      MergeStrategy = "squash" // One summary commit per feature
+	MergeFastForward MergeStrategy = "ff"     // Keep the branch's commits
+)
+
+// ParseMergeStrategy parses a merge strategy name
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	switch MergeStrategy(s) {
+	case MergeSquash, MergeFastForward:
+		return MergeStrategy(s), nil
+	default:
+		return "", fmt.Errorf("invalid merge strategy %q (must be squash or ff)", s)
+	}
+}
+
+// DefaultBranchPrefix is prepended to feature IDs to name feature branches
+const DefaultBranchPrefix = "ralph/"
+
+// BranchPolicy configures the branch-per-feature workflow: each feature is
+// built on its own branch, created from the integration branch, and merged
+// back once it passes the gates
+type BranchPolicy struct {
+	Prefix      string        // Feature branch prefix (default: "ralph/")
+	Integration string        // Branch to create from and merge into (default: the current branch)
+	Merge       MergeStrategy // How to merge finished features (default: squash)
+	Keep        bool          // Keep feature branches after merging
+}
+
+// BranchName returns the branch a feature is built on
+func (p BranchPolicy) BranchName(featureID string) string {
+	return p.Prefix + featureID
+}
+
+// startFeatureBranches fills in the policy's defaults from the repository
+func (o *Orchestrator) startFeatureBranches(policy BranchPolicy) (*BranchPolicy, error) {
+	if !git.IsInsideWorkTree(o.workDir) {
+		return nil, fmt.Errorf("feature branches need a git repository")
+	}
+	if policy.Prefix == "" {
+		policy.Prefix = DefaultBranchPrefix
+	}
+	if policy.Merge == "" {
+		policy.Merge = MergeSquash
+	}
+	if policy.Integration == "" {
+		current, err := git.CurrentBranch(o.workDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the integration branch: %w", err)
+		}
+		if strings.HasPrefix(current, policy.Prefix) {
+			return nil, fmt.Errorf("%s is a feature branch; check out the integration branch or name it with --integration-branch", current)
+		}
+		policy.Integration = current
+	}
+	if !git.BranchExists(o.workDir, policy.Integration) {
+		return nil, fmt.Errorf("integration branch %s does not exist", policy.Integration)
+	}
+	return &policy, nil
+}
+
+// enterFeatureBranch switches to the feature's branch, creating it from the
+// integration branch if needed. Unfinished work on the feature branch being
+// left is committed there first so it doesn't follow the checkout, as are
+// prd.json and progress.txt on the integration branch. An existing branch
+// is brought up to date with the integration branch so it can merge back.
+// Returns true if the branch changed.
+func (o *Orchestrator) enterFeatureBranch(policy *BranchPolicy, config BuildConfig, p *prd.PRD, feature *prd.Feature, iteration int) (bool, error) {
+	name := policy.BranchName(feature.ID)
+	current, err := git.CurrentBranch(o.workDir)
+	if err != nil {
+		return false, err
+	}
+	if current == name {
+		return false, nil
+	}
+
+	if strings.HasPrefix(current, policy.Prefix) {
+		if left := p.GetFeature(strings.TrimPrefix(current, policy.Prefix)); left != nil {
+			msg := commitmsg.New(commitmsg.Params{Features: []*prd.Feature{left}, Iteration: iteration, RunID: o.session.ID})
+			if _, err := git.CommitAll(o.workDir, msg.String(), config.Signing); err != nil {
+				return false, fmt.Errorf("failed to save work on %s: %w", current, err)
+			}
+		}
+	} else if current == policy.Integration {
+		msg := commitmsg.New(commitmsg.Params{Iteration: iteration, RunID: o.session.ID})
+		if _, err := git.CommitFiles(o.workDir, msg.String(), o.bookkeeping(), config.Signing); err != nil {
+			return false, fmt.Errorf("failed to save progress on %s: %w", current, err)
+		}
+	}
+
+	if git.BranchExists(o.workDir, name) {
+		if err = git.Checkout(o.workDir, name); err == nil {
+			err = o.syncFeatureBranch(policy, config, name)
+		}
+	} else {
+		err = git.CreateBranch(o.workDir, name, policy.Integration)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to switch to %s: %w", name, err)
+	}
+	o.typedOutput(OutputInfo, fmt.Sprintf("On branch %s", name))
+	return true, nil
+}
+
+// bookkeeping returns the files the harness itself writes each iteration
+func (o *Orchestrator) bookkeeping() []string {
+	files := []string{progress.DefaultFilename}
+	if rel := o.prdFile(); !filepath.IsAbs(rel) && !strings.HasPrefix(rel, "..") {
+		files = append([]string{filepath.ToSlash(rel)}, files...)
+	}
+	return slices.DeleteFunc(files, func(f string) bool {
+		_, err := os.Stat(filepath.Join(o.workDir, f))
+		return err != nil
+	})
+}
+
+// syncFeatureBranch merges the integration branch into the checked out
+// feature branch, so features merged since it was created don't conflict
+// when it merges back. Conflicts in progress.txt keep both sides' entries
+// and conflicts in prd.json take the integration branch's, which records
+// every feature's status; conflicts anywhere else abandon the merge.
+func (o *Orchestrator) syncFeatureBranch(policy *BranchPolicy, config BuildConfig, branch string) error {
+	if err := git.Merge(o.workDir, policy.Integration); err != nil {
+		conflicts, cerr := git.ConflictedFiles(o.workDir)
+		if cerr != nil || len(conflicts) == 0 {
+			_ = git.AbortMerge(o.workDir)
+			return fmt.Errorf("failed to merge %s into %s: %w", policy.Integration, branch, err)
+		}
+		kept := o.bookkeeping()
+		others := slices.DeleteFunc(slices.Clone(conflicts), func(f string) bool { return slices.Contains(kept, f) })
+		if len(others) > 0 {
+			_ = git.AbortMerge(o.workDir)
+			return fmt.Errorf("merging %s into %s conflicts in %s; merge it by hand", policy.Integration, branch, strings.Join(others, ", "))
+		}
+		for _, path := range conflicts {
+			if path == progress.DefaultFilename {
+				err = git.ResolveUnion(o.workDir, path)
+			} else {
+				err = git.RestoreFile(o.workDir, policy.Integration, path)
+			}
+			if err != nil {
+				_ = git.AbortMerge(o.workDir)
+				return fmt.Errorf("failed to merge %s into %s: %w", policy.Integration, branch, err)
+			}
+		}
+	}
+
+	msg := commitmsg.Message{Type: "chore", Subject: fmt.Sprintf("merge %s into %s", policy.Integration, branch)}
+	hash, err := git.CommitFiles(o.workDir, msg.String(), nil, config.Signing)
+	if err != nil {
+		_ = git.AbortMerge(o.workDir)
+		return fmt.Errorf("failed to merge %s into %s: %w", policy.Integration, branch, err)
+	}
+	if hash == "" {
+		// Merged commits that changed nothing leave the merge open
+		_ = git.AbortMerge(o.workDir)
+		return nil
+	}
+	o.typedOutput(OutputInfo, fmt.Sprintf("Merged %s into %s: %s", policy.Integration, branch, shortHash(hash)))
+	return nil
+}
+
+// mergeFeatureBranch merges the current feature branch into the integration
+// branch once its feature passes, then deletes it unless the policy keeps
+// branches. Does nothing if the feature isn't finished.
+func (o *Orchestrator) mergeFeatureBranch(policy *BranchPolicy, config BuildConfig, iteration int) error {
+	branch, err := git.CurrentBranch(o.workDir)
+	if err != nil || !strings.HasPrefix(branch, policy.Prefix) {
+		return nil
+	}
+	p, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+	feature := p.GetFeature(strings.TrimPrefix(branch, policy.Prefix))
+	if feature == nil || !feature.Passes {
+		return nil
+	}
+
+	subjects, err := git.CommitSubjects(o.workDir, policy.Integration, branch)
+	if err != nil {
+		return fmt.Errorf("failed to merge %s: %w", branch, err)
+	}
+	if err := git.Checkout(o.workDir, policy.Integration); err != nil {
+		return fmt.Errorf("failed to merge %s: %w", branch, err)
+	}
+
+	var hash, header string
+	switch policy.Merge {
+	case MergeFastForward:
+		hash, err = git.MergeFastForward(o.workDir, branch)
+		header = fmt.Sprintf("fast-forward %s (%d commits)", branch, len(subjects))
+	default:
+		msg := commitmsg.New(commitmsg.Params{
+			Features:  []*prd.Feature{feature},
+			Iteration: iteration,
+			RunID:     o.session.ID,
+			Complete:  true,
+			Branch:    branch,
+			Squashed:  subjects,
+		})
+		hash, err = git.MergeSquash(o.workDir, branch, msg.String(), config.Signing)
+		header = msg.Header()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to merge %s into %s: %w", branch, policy.Integration, err)
+	}
+
+	if hash != "" {
+		o.typedOutput(OutputSuccess, fmt.Sprintf("Merged %s into %s (%s): %s", branch, policy.Integration, policy.Merge, shortHash(hash)))
+		o.AddProgressCommit(shortHash(hash), header)
+	}
+	if !policy.Keep {
+		if err := git.DeleteBranch(o.workDir, branch); err != nil {
+			o.typedOutput(OutputError, fmt.Sprintf("Failed to delete %s: %v", branch, err))
+		}
+	}
+	return nil
+}
+
+// awaitReview leaves the current feature branch to the pull request opened
+// for it: the branch is kept, and the feature is marked needs_review on the
+// integration branch so the build moves on without it. Does nothing if the
+// feature isn't finished.
+func (o *Orchestrator) awaitReview(policy *BranchPolicy, config BuildConfig, pr *publish.Result, iteration int) error {
+	branch, err := git.CurrentBranch(o.workDir)
+	if err != nil || !strings.HasPrefix(branch, policy.Prefix) {
+		return nil
+	}
+	id := strings.TrimPrefix(branch, policy.Prefix)
+	if p, err := prd.Load(o.PRDPath()); err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	} else if f := p.GetFeature(id); f == nil || !f.Passes {
+		return nil
+	}
+
+	if err := o.holdFeature(policy, config, branch, id, prd.StatusNeedsReview, "", iteration); err != nil {
+		return err
+	}
+	o.typedOutput(OutputInfo, fmt.Sprintf("%s awaits review in pull request #%d; not merging %s locally", id, pr.Number, branch))
+	return nil
+}
+
+// leaveHeldFeature moves off the current feature branch if its feature is
+// blocked or stuck, so later iterations don't pick it again. Work on the
+// branch is committed and the branch is kept for when the feature reopens.
+// Does nothing if the feature is open or done.
+func (o *Orchestrator) leaveHeldFeature(policy *BranchPolicy, config BuildConfig, iteration int) error {
+	branch, err := git.CurrentBranch(o.workDir)
+	if err != nil || !strings.HasPrefix(branch, policy.Prefix) {
+		return nil
+	}
+	p, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+	f := p.GetFeature(strings.TrimPrefix(branch, policy.Prefix))
+	if f == nil || f.IsOpen() || f.IsDone() {
+		return nil
+	}
+
+	msg := commitmsg.New(commitmsg.Params{Features: []*prd.Feature{f}, Iteration: iteration, RunID: o.session.ID})
+	if _, err := git.CommitAll(o.workDir, msg.String(), config.Signing); err != nil {
+		return fmt.Errorf("failed to save work on %s: %w", branch, err)
+	}
+	if err := o.holdFeature(policy, config, branch, f.ID, f.CurrentStatus(), f.BlockedReason, iteration); err != nil {
+		return err
+	}
+	o.typedOutput(OutputInfo, fmt.Sprintf("Left %s: %s is %s", branch, f.ID, f.StatusSummary()))
+	return nil
+}
+
+// holdFeature checks out the integration branch and records a feature's
+// held status there, since the integration branch's PRD decides what the
+// build picks next
+func (o *Orchestrator) holdFeature(policy *BranchPolicy, config BuildConfig, branch, id string, status prd.Status, reason string, iteration int) error {
+	if err := git.Checkout(o.workDir, policy.Integration); err != nil {
+		return fmt.Errorf("failed to leave %s: %w", branch, err)
+	}
+	p, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+	if err := p.SetFeatureStatus(id, status, reason); err != nil {
+		return err
+	}
+	if err := prd.Save(p, o.PRDPath()); err != nil {
+		return fmt.Errorf("failed to save %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+	msg := commitmsg.New(commitmsg.Params{Iteration: iteration, RunID: o.session.ID})
+	if _, err := git.CommitFiles(o.workDir, msg.String(), o.bookkeeping(), config.Signing); err != nil {
+		return fmt.Errorf("failed to leave %s: %w", branch, err)
+	}
+	return nil
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
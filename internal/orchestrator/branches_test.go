@@ -0,0 +1,273 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+func TestParseMergeStrategy(t *testing.T) {
+	s, err := ParseMergeStrategy("squash")
+	require.NoError(t, err)
+	assert.Equal(t, MergeSquash, s)
+
+	s, err = ParseMergeStrategy("ff")
+	require.NoError(t, err)
+	assert.Equal(t, MergeFastForward, s)
+
+	_, err = ParseMergeStrategy("rebase")
+	assert.Error(t, err)
+}
+
+func TestStartFeatureBranches(t *testing.T) {
+	orch := New(t.TempDir())
+	_, err := orch.startFeatureBranches(BranchPolicy{})
+	assert.ErrorContains(t, err, "git repository")
+
+	tmpDir := initTestRepo(t)
+	require.NoError(t, prd.SaveToDir(&prd.PRD{Name: "Test"}, tmpDir))
+	_, err = git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	orch = New(tmpDir)
+	policy, err := orch.startFeatureBranches(BranchPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, BranchPolicy{Prefix: "ralph/", Integration: "main", Merge: MergeSquash}, *policy)
+
+	require.NoError(t, git.CreateBranch(tmpDir, "ralph/feat-001", "main"))
+	_, err = orch.startFeatureBranches(BranchPolicy{})
+	assert.ErrorContains(t, err, "ralph/feat-001 is a feature branch")
+
+	policy, err = orch.startFeatureBranches(BranchPolicy{Integration: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, "main", policy.Integration)
+
+	_, err = orch.startFeatureBranches(BranchPolicy{Integration: "develop"})
+	assert.ErrorContains(t, err, "develop does not exist")
+}
+
+func TestFeatureBranchWorkflow(t *testing.T) {
+	for _, merge := range []MergeStrategy{MergeSquash, MergeFastForward} {
+		t.Run(string(merge), func(t *testing.T) {
+			tmpDir := initTestRepo(t)
+			before := &prd.PRD{Name: "Test", Features: []prd.Feature{
+				{ID: "feat-001", Category: prd.CategoryFunctional, Description: "Export data", Steps: []string{"Step"}},
+			}}
+			require.NoError(t, prd.SaveToDir(before, tmpDir))
+			_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+			require.NoError(t, err)
+
+			orch := New(tmpDir)
+			config := DefaultBuildConfig()
+			policy, err := orch.startFeatureBranches(BranchPolicy{Merge: merge})
+			require.NoError(t, err)
+
+			switched, err := orch.enterFeatureBranch(policy, config, before, &before.Features[0], 1)
+			require.NoError(t, err)
+			assert.True(t, switched)
+			branch, err := git.CurrentBranch(tmpDir)
+			require.NoError(t, err)
+			assert.Equal(t, "ralph/feat-001", branch)
+
+			switched, err = orch.enterFeatureBranch(policy, config, before, &before.Features[0], 2)
+			require.NoError(t, err)
+			assert.False(t, switched, "already on the branch")
+
+			// An unfinished feature stays on its branch
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "export.go"), []byte("package export\n"), 0644))
+			require.NoError(t, orch.commitIteration(config, before, &before.Features[0], 1))
+			require.NoError(t, orch.mergeFeatureBranch(policy, config, 1))
+			branch, _ = git.CurrentBranch(tmpDir)
+			assert.Equal(t, "ralph/feat-001", branch)
+
+			// A passing feature is merged and its branch deleted
+			after := *before
+			after.Features = append([]prd.Feature{}, before.Features...)
+			after.Features[0].Passes = true
+			require.NoError(t, prd.SaveToDir(&after, tmpDir))
+			require.NoError(t, orch.commitIteration(config, before, &before.Features[0], 2))
+			require.NoError(t, orch.mergeFeatureBranch(policy, config, 2))
+
+			branch, _ = git.CurrentBranch(tmpDir)
+			assert.Equal(t, "main", branch)
+			assert.False(t, git.BranchExists(tmpDir, "ralph/feat-001"))
+			merged, err := prd.LoadFromDir(tmpDir)
+			require.NoError(t, err)
+			assert.True(t, merged.GetFeature("feat-001").Passes)
+
+			cmd := exec.Command("git", "log", "--format=%s", "main")
+			cmd.Dir = tmpDir
+			output, err := cmd.Output()
+			require.NoError(t, err)
+			if merge == MergeSquash {
+				assert.Equal(t, "feat: export data\ninit\n", string(output))
+			} else {
+				assert.Equal(t, "feat: export data\nfeat: export data\ninit\n", string(output))
+			}
+		})
+	}
+}
+
+func TestFeatureBranchKeep(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	p := &prd.PRD{Name: "Test", Features: []prd.Feature{
+		{ID: "feat-001", Description: "One", Steps: []string{"Step"}},
+		{ID: "feat-002", Description: "Two", Steps: []string{"Step"}},
+	}}
+	require.NoError(t, prd.SaveToDir(p, tmpDir))
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	orch := New(tmpDir)
+	config := DefaultBuildConfig()
+	policy, err := orch.startFeatureBranches(BranchPolicy{Keep: true})
+	require.NoError(t, err)
+
+	_, err = orch.enterFeatureBranch(policy, config, p, &p.Features[0], 1)
+	require.NoError(t, err)
+	p.Features[0].Passes = true
+	require.NoError(t, prd.SaveToDir(p, tmpDir))
+	require.NoError(t, orch.mergeFeatureBranch(policy, config, 1))
+	assert.True(t, git.BranchExists(tmpDir, "ralph/feat-001"))
+
+	// Leaving an unfinished feature commits its work on its own branch
+	_, err = orch.enterFeatureBranch(policy, config, p, &p.Features[1], 2)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "two.go"), []byte("package two\n"), 0644))
+	_, err = orch.enterFeatureBranch(policy, config, p, &p.Features[0], 3)
+	require.NoError(t, err)
+
+	has, err := git.HasUncommittedChanges(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, has)
+	subjects, err := git.CommitSubjects(tmpDir, "main", "ralph/feat-002")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feat: two"}, subjects)
+}
+
+func TestFeatureBranchesInterleave(t *testing.T) {
+	for _, merge := range []MergeStrategy{MergeSquash, MergeFastForward} {
+		t.Run(string(merge), func(t *testing.T) {
+			tmpDir := initTestRepo(t)
+			p := &prd.PRD{Name: "Test", Features: []prd.Feature{
+				{ID: "feat-001", Category: prd.CategoryFunctional, Description: "One", Steps: []string{"Step"}},
+				{ID: "feat-002", Category: prd.CategoryFunctional, Description: "Two", Steps: []string{"Step"}},
+			}}
+			require.NoError(t, prd.SaveToDir(p, tmpDir))
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "progress.txt"), []byte("# Progress\n"), 0644))
+			_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+			require.NoError(t, err)
+
+			orch := New(tmpDir)
+			config := DefaultBuildConfig()
+			policy, err := orch.startFeatureBranches(BranchPolicy{Merge: merge})
+			require.NoError(t, err)
+
+			// iterate works on a feature, optionally finishing it, then
+			// writes a progress entry the way finishIteration does, after
+			// the commit and merge
+			iterate := func(iteration int, id string, finish bool) {
+				current, err := prd.LoadFromDir(tmpDir)
+				require.NoError(t, err)
+				_, err = orch.enterFeatureBranch(policy, config, current, current.GetFeature(id), iteration)
+				require.NoError(t, err)
+
+				current, err = prd.LoadFromDir(tmpDir)
+				require.NoError(t, err)
+				status := prd.StatusInProgress
+				if finish {
+					status = prd.StatusPassing
+				}
+				current.GetFeature(id).SetStatus(status, time.Now().UTC())
+				require.NoError(t, prd.SaveToDir(current, tmpDir))
+				require.NoError(t, os.WriteFile(filepath.Join(tmpDir, id+".go"), []byte(fmt.Sprintf("package f // %d\n", iteration)), 0644))
+
+				before := *current
+				before.Features = append([]prd.Feature{}, current.Features...)
+				before.GetFeature(id).Passes = false
+				require.NoError(t, orch.commitIteration(config, &before, before.GetFeature(id), iteration))
+				require.NoError(t, orch.mergeFeatureBranch(policy, config, iteration))
+
+				f, err := os.OpenFile(filepath.Join(tmpDir, "progress.txt"), os.O_APPEND|os.O_WRONLY, 0644)
+				require.NoError(t, err)
+				_, err = fmt.Fprintf(f, "Iteration %d: %s\n", iteration, id)
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			}
+
+			iterate(1, "feat-001", false)
+			iterate(2, "feat-002", true)
+			iterate(3, "feat-001", true)
+
+			branch, err := git.CurrentBranch(tmpDir)
+			require.NoError(t, err)
+			assert.Equal(t, "main", branch)
+			assert.False(t, git.BranchExists(tmpDir, "ralph/feat-001"))
+			assert.False(t, git.BranchExists(tmpDir, "ralph/feat-002"))
+
+			merged, err := prd.LoadFromDir(tmpDir)
+			require.NoError(t, err)
+			assert.True(t, merged.IsComplete())
+			assert.FileExists(t, filepath.Join(tmpDir, "feat-001.go"))
+			assert.FileExists(t, filepath.Join(tmpDir, "feat-002.go"))
+
+			content, err := os.ReadFile(filepath.Join(tmpDir, "progress.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, "# Progress\nIteration 1: feat-001\nIteration 2: feat-002\nIteration 3: feat-001\n", string(content))
+		})
+	}
+}
+
+func TestLeaveHeldFeature(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	p := &prd.PRD{Name: "Test", Features: []prd.Feature{
+		{ID: "feat-001", Category: prd.CategoryFunctional, Description: "One", Steps: []string{"Step"}},
+	}}
+	require.NoError(t, prd.SaveToDir(p, tmpDir))
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	orch := New(tmpDir)
+	config := DefaultBuildConfig()
+	policy, err := orch.startFeatureBranches(BranchPolicy{})
+	require.NoError(t, err)
+	_, err = orch.enterFeatureBranch(policy, config, p, p.GetFeature("feat-001"), 1)
+	require.NoError(t, err)
+
+	// An open feature stays on its branch
+	require.NoError(t, orch.leaveHeldFeature(policy, config, 1))
+	branch, err := git.CurrentBranch(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "ralph/feat-001", branch)
+
+	require.NoError(t, p.SetFeatureStatus("feat-001", prd.StatusBlocked, "needs an API key"))
+	require.NoError(t, prd.SaveToDir(p, tmpDir))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "feat-001.go"), []byte("package f\n"), 0644))
+	require.NoError(t, orch.leaveHeldFeature(policy, config, 1))
+
+	branch, err = git.CurrentBranch(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+	assert.True(t, git.BranchExists(tmpDir, "ralph/feat-001"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "feat-001.go"))
+
+	held, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	f := held.GetFeature("feat-001")
+	assert.Equal(t, prd.StatusBlocked, f.CurrentStatus())
+	assert.Equal(t, "needs an API key", f.BlockedReason)
+	assert.Nil(t, held.NextFeature())
+
+	dirty, err := git.HasUncommittedChanges(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, dirty)
+}
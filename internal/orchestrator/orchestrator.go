@@ -9,14 +9,27 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/mpjhorner/superralph/internal/bench"
+	"github.com/mpjhorner/superralph/internal/commitmsg"
+	"github.com/mpjhorner/superralph/internal/coverage"
+	"github.com/mpjhorner/superralph/internal/flakes"
+	"github.com/mpjhorner/superralph/internal/git"
 	"github.com/mpjhorner/superralph/internal/prd"
 	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/publish"
+	"github.com/mpjhorner/superralph/internal/repomap"
+	"github.com/mpjhorner/superralph/internal/retrieval"
+	"github.com/mpjhorner/superralph/internal/runs"
 	"github.com/mpjhorner/superralph/internal/tagging"
+	"github.com/mpjhorner/superralph/internal/tamper"
+	"github.com/mpjhorner/superralph/internal/testresult"
+	"github.com/mpjhorner/superralph/internal/verify"
 )
 
 // OutputType represents the type of output for styled/colored display in TUI
@@ -36,13 +49,29 @@ const (
 // Orchestrator manages the agent loop
 type Orchestrator struct {
 	workDir        string
+	prdPath        string // PRD file; empty for the one in workDir
 	claudePath     string
 	session        *Session
 	debug          bool
 	tagger         *tagging.Tagger
 	parallel       *ParallelExecutor
+	relevance      *retrieval.Index
+	repoMap        *repomap.Mapper
+	verifier       *verify.Runner
+	benchRunner    *bench.Runner
 	snapshotConfig SnapshotConfig
 
+	// Benchmark measurements taken before work on a feature started, by feature ID
+	benchBaselines map[string]*bench.Measurement
+
+	// Gate outcomes of the current iteration, for pull request bodies and the run log
+	gates []publish.Gate
+
+	// The build run being recorded, and the cost and text of the last agent session
+	run      *runs.Run
+	lastCost float64
+	lastText string
+
 	// Progress tracking
 	progressWriter *progress.Writer
 	currentEntry   *ProgressEntryBuilder // Builder for the current progress entry
@@ -50,6 +79,9 @@ type Orchestrator struct {
 	// Initial tags for planning context
 	initialTags []string
 
+	// Test command from the PRD, used to recognize test runs by the agent
+	testCommand string
+
 	// Callbacks for UI integration
 	onMessage     func(role, content string)
 	onAction      func(action Action, params ActionParams)
@@ -58,19 +90,27 @@ type Orchestrator struct {
 	onDebug       func(msg string)
 	onOutput      func(line string)
 	onTypedOutput func(outputType OutputType, content string)
-	onActivity    func(activity string) // Current activity summary (e.g., "Reading src/main.go")
-	onStep        func(step Step)       // Current step in the iteration
-	onFileDiff    func(diff *FileDiff)  // File diff callback for visual diffs
+	onActivity    func(activity string)        // Current activity summary (e.g., "Reading src/main.go")
+	onStep        func(step Step)              // Current step in the iteration
+	onFileDiff    func(diff *FileDiff)         // File diff callback for visual diffs
+	onTestResult  func(run *TestRun)           // Parsed results of each test run
+	onCoverage    func(c *coverage.Comparison) // Coverage gate results
 	promptUser    func(question string) (string, error)
 }
 
 // New creates a new Orchestrator
 func New(workDir string) *Orchestrator {
+	tagger := tagging.New(workDir)
 	return &Orchestrator{
 		workDir:        workDir,
 		claudePath:     findClaudeBinary(),
-		tagger:         tagging.New(workDir),
+		tagger:         tagger,
 		parallel:       NewParallelExecutor(workDir),
+		relevance:      retrieval.New(workDir, tagger.Enumerator().Files),
+		repoMap:        repomap.New(workDir, tagger.Enumerator().Files),
+		verifier:       verify.New(workDir),
+		benchRunner:    bench.New(workDir),
+		benchBaselines: make(map[string]*bench.Measurement),
 		snapshotConfig: DefaultSnapshotConfig(),
 		progressWriter: progress.NewWriter(workDir),
 		session: &Session{
@@ -113,6 +153,28 @@ func findClaudeBinary() string {
 	return "claude"
 }
 
+// SetPRDPath sets the PRD file, for PRDs that aren't the one in the work directory
+func (o *Orchestrator) SetPRDPath(path string) *Orchestrator {
+	o.prdPath = path
+	return o
+}
+
+// PRDPath returns the PRD file the orchestrator builds from
+func (o *Orchestrator) PRDPath() string {
+	if o.prdPath == "" {
+		return prd.PathInDir(o.workDir)
+	}
+	return o.prdPath
+}
+
+// prdFile returns the PRD file relative to the work directory, as the agent sees it
+func (o *Orchestrator) prdFile() string {
+	if rel, err := filepath.Rel(o.workDir, o.PRDPath()); err == nil {
+		return rel
+	}
+	return o.PRDPath()
+}
+
 // SetDebug enables debug mode
 func (o *Orchestrator) SetDebug(debug bool) *Orchestrator {
 	o.debug = debug
@@ -179,6 +241,18 @@ func (o *Orchestrator) OnFileDiff(fn func(diff *FileDiff)) *Orchestrator {
 	return o
 }
 
+// OnTestResult sets the callback for parsed test results
+func (o *Orchestrator) OnTestResult(fn func(run *TestRun)) *Orchestrator {
+	o.onTestResult = fn
+	return o
+}
+
+// OnCoverage sets the callback for coverage gate results
+func (o *Orchestrator) OnCoverage(fn func(c *coverage.Comparison)) *Orchestrator {
+	o.onCoverage = fn
+	return o
+}
+
 // SetPromptUser sets the function to prompt the user
 func (o *Orchestrator) SetPromptUser(fn func(question string) (string, error)) *Orchestrator {
 	o.promptUser = fn
@@ -225,6 +299,18 @@ func (o *Orchestrator) SetIncludeKeyFiles(include bool) *Orchestrator {
 	return o
 }
 
+// SetMaxRelevantFiles sets how many relevant files are retrieved per feature (0 disables)
+func (o *Orchestrator) SetMaxRelevantFiles(count int) *Orchestrator {
+	o.snapshotConfig.MaxRelevantFiles = count
+	return o
+}
+
+// SetUseRepoMap sets whether iteration prompts include a symbol outline instead of the directory tree
+func (o *Orchestrator) SetUseRepoMap(use bool) *Orchestrator {
+	o.snapshotConfig.UseRepoMap = use
+	return o
+}
+
 // GetProgressWriter returns the progress writer for external use
 func (o *Orchestrator) GetProgressWriter() *progress.Writer {
 	return o.progressWriter
@@ -256,12 +342,26 @@ func (o *Orchestrator) AddProgressWork(work string) {
 }
 
 // SetProgressTestResult sets the test result for the current progress entry
-func (o *Orchestrator) SetProgressTestResult(command string, passed bool, details string) {
+func (o *Orchestrator) SetProgressTestResult(command string, passed bool, details *testresult.Report) {
 	if o.currentEntry != nil {
 		o.currentEntry.SetTestResult(command, passed, details)
 	}
 }
 
+// SetProgressCoverage sets the coverage gate result for the current progress entry
+func (o *Orchestrator) SetProgressCoverage(c *coverage.Comparison) {
+	if o.currentEntry != nil {
+		o.currentEntry.SetCoverage(c)
+	}
+}
+
+// AddProgressBenchmark adds a benchmark evaluation to the current progress entry
+func (o *Orchestrator) AddProgressBenchmark(e *bench.Evaluation) {
+	if o.currentEntry != nil {
+		o.currentEntry.AddBenchmark(e)
+	}
+}
+
 // AddProgressCommit adds a git commit to the current progress entry
 func (o *Orchestrator) AddProgressCommit(hash, message string) {
 	if o.currentEntry != nil {
@@ -327,7 +427,7 @@ func (o *Orchestrator) SaveResumeState(state *ResumeState) error {
 	state.Timestamp = time.Now().UTC()
 	state.WorkDir = o.workDir
 	if state.PRDPath == "" {
-		state.PRDPath = "prd.json"
+		state.PRDPath = o.prdFile()
 	}
 
 	data, err := json.MarshalIndent(state, "", "  ")
@@ -403,13 +503,15 @@ func (o *Orchestrator) RunPlan(ctx context.Context) error {
 	// Build prompt with optional tagged files context
 	var promptBuilder strings.Builder
 
-	promptBuilder.WriteString(`Help me create a prd.json file for this project.
+	file := o.prdFile()
+	promptBuilder.WriteString(`Help me create a ` + file + ` file for this project.
 
 Ask me what I want to build, explore the existing codebase if there is one,
 and help me define features with clear verification steps.
 
-When done, create the prd.json file with this structure:
+When done, create the ` + file + ` file` + planFormatNote(prd.FormatOf(file)) + ` with this structure:
 {
+  "schemaVersion": ` + fmt.Sprint(prd.CurrentSchemaVersion) + `,
   "name": "Project Name",
   "description": "Description",
   "testCommand": "command to run tests",
@@ -448,6 +550,19 @@ When done, create the prd.json file with this structure:
 	return o.runClaudeInteractive(ctx, promptBuilder.String())
 }
 
+// planFormatNote tells the planning agent how to write a PRD that isn't JSON
+func planFormatNote(f prd.Format) string {
+	switch f {
+	case prd.FormatYAML, prd.FormatTOML:
+		return fmt.Sprintf(", written as %s,", strings.ToUpper(string(f)))
+	case prd.FormatMarkdown:
+		return " in Markdown (project fields in YAML front matter, then a \"## <id>: <description>\" heading per feature" +
+			" with \"- key: value\" bullets for its fields and \"- [ ] step\" checkboxes for its steps)"
+	default:
+		return ""
+	}
+}
+
 // BuildConfig holds configuration for the build loop
 type BuildConfig struct {
 	// MaxIterations is the safety limit for agent loops (default: 50)
@@ -461,6 +576,39 @@ type BuildConfig struct {
 
 	// ResumeFeature is the feature ID to resume from (if resuming)
 	ResumeFeature string
+
+	// FlakeReruns is how many times failing tests are rerun after a failed
+	// test gate to tell flakes from real failures (default: 2, 0 disables)
+	FlakeReruns int
+
+	// MaxFeatureAttempts is how many failed test gates a feature gets before
+	// the build stops (default: 3). Failures on flaky tests don't count.
+	MaxFeatureAttempts int
+
+	// TamperPolicy decides what happens when an iteration weakens the tests
+	// (default: warn)
+	TamperPolicy tamper.Policy
+
+	// HarnessCommits makes the harness commit each iteration that passes the
+	// gates, with Feature-Id trailers, instead of leaving commits to the agent
+	// (default: true)
+	HarnessCommits bool
+
+	// Signing controls how harness commits are signed
+	Signing git.CommitOptions
+
+	// FeatureBranches builds each feature on its own branch and merges it
+	// into the integration branch once it passes (default: nil, work on the
+	// current branch)
+	FeatureBranches *BranchPolicy
+
+	// Publish pushes finished features and opens pull requests for them
+	// (default: nil, nothing leaves the machine)
+	Publish *PublishConfig
+
+	// Strategy decides which feature is built next (default: "", the PRD's
+	// strategy)
+	Strategy prd.Strategy
 }
 
 // DefaultBuildConfig returns the default build configuration
@@ -469,6 +617,10 @@ func DefaultBuildConfig() BuildConfig {
 		MaxIterations:          50,
 		DelayBetweenIterations: 3 * time.Second,
 		StartIteration:         1,
+		FlakeReruns:            2,
+		MaxFeatureAttempts:     3,
+		TamperPolicy:           tamper.PolicyWarn,
+		HarnessCommits:         true,
 	}
 }
 
@@ -491,6 +643,14 @@ func (o *Orchestrator) RunBuild(ctx context.Context) error {
 // Graceful shutdown: On context cancellation, the current action is completed before
 // saving state and exiting. Use --resume to continue from where you left off.
 func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfig) error {
+	o.startRun()
+	err := o.runBuildLoop(ctx, config)
+	o.finishRun(err)
+	return err
+}
+
+// runBuildLoop runs the iterations of RunBuildWithConfig
+func (o *Orchestrator) runBuildLoop(ctx context.Context, config BuildConfig) error {
 	o.session.Mode = "build"
 
 	// Determine starting iteration
@@ -499,10 +659,40 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 		startIteration = 1
 	}
 
+	if config.MaxFeatureAttempts <= 0 {
+		config.MaxFeatureAttempts = 3
+	}
+	if config.TamperPolicy == "" {
+		config.TamperPolicy = tamper.PolicyWarn
+	}
+
 	// Track current state for potential resume
 	var currentFeatureID string
 	var currentPhase Phase
 
+	// Failed test gates per feature, and the flake history shared across builds
+	attempts := make(map[string]int)
+	history, err := flakes.Load(o.workDir)
+	if err != nil {
+		o.typedOutput(OutputError, fmt.Sprintf("Ignoring flake history: %v", err))
+		history = flakes.New(o.workDir)
+	}
+
+	// Test results from the last accepted test gate, to notice tests disappearing
+	var testsBefore *testresult.Report
+
+	// Findings that got the last iteration rejected. Its edits stay in the
+	// working tree, so the next prompt asks the agent to undo them.
+	var revert []string
+
+	var branches *BranchPolicy
+	if config.FeatureBranches != nil {
+		if branches, err = o.startFeatureBranches(*config.FeatureBranches); err != nil {
+			o.typedOutput(OutputError, err.Error())
+			return err
+		}
+	}
+
 	for iteration := startIteration; iteration <= config.MaxIterations; iteration++ {
 		// Check context cancellation at start of each iteration
 		if ctx.Err() != nil {
@@ -515,9 +705,9 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 		o.typedOutput(OutputInfo, fmt.Sprintf("=== Iteration %d/%d ===", iteration, config.MaxIterations))
 		o.activity("Loading PRD...")
 
-		currentPRD, err := prd.LoadFromDir(o.workDir)
+		currentPRD, err := prd.Load(o.PRDPath())
 		if err != nil {
-			return fmt.Errorf("failed to load prd.json: %w", err)
+			return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
 		}
 
 		// === Step 2: Check if all features complete ===
@@ -530,7 +720,11 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 		}
 
 		// Get next feature for display
-		nextFeature, reason := currentPRD.NextFeatureWithReason()
+		scheduler := currentPRD.Scheduler()
+		if config.Strategy != "" {
+			scheduler = config.Strategy.Scheduler()
+		}
+		nextFeature, reason := currentPRD.NextFeatureWith(scheduler)
 		if nextFeature == nil {
 			// All features pass or are blocked - should not happen if IsComplete returned false
 			o.typedOutput(OutputError, "No available features to work on (all may be blocked)")
@@ -541,9 +735,55 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 		currentFeatureID = nextFeature.ID
 		currentPhase = "" // Default phase
 
+		// Start the iteration's progress entry; gates, commits and notes add to it
+		o.StartProgressEntry(iteration, currentPRD)
+
 		stats := currentPRD.Stats()
 		o.typedOutput(OutputInfo, fmt.Sprintf("Progress: %d/%d features complete", stats.PassingFeatures, stats.TotalFeatures))
 		o.typedOutput(OutputInfo, fmt.Sprintf("Next: %s - %s", nextFeature.ID, nextFeature.Description))
+		o.typedOutput(OutputInfo, fmt.Sprintf("%s (%s strategy)", reason, scheduler.Strategy()))
+		o.testCommand = currentPRD.TestCommand
+
+		if branches != nil {
+			switched, err := o.enterFeatureBranch(branches, config, currentPRD, nextFeature, iteration)
+			if err != nil {
+				o.typedOutput(OutputError, err.Error())
+				return err
+			}
+			if switched {
+				// The feature branch may have its own progress on this feature
+				if currentPRD, err = prd.Load(o.PRDPath()); err != nil {
+					return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+				}
+				f := currentPRD.GetFeature(currentFeatureID)
+				if f == nil {
+					return fmt.Errorf("feature %s is not in %s on its branch", currentFeatureID, o.prdFile())
+				}
+				if !f.IsOpen() {
+					// The branch has moved the feature on since the integration
+					// branch heard of it; bring that up to date and pick again
+					o.typedOutput(OutputInfo, fmt.Sprintf("%s is %s on its branch", f.ID, f.StatusSummary()))
+					if err := o.leaveHeldFeature(branches, config, iteration); err != nil {
+						o.typedOutput(OutputError, err.Error())
+						return err
+					}
+					if err := o.mergeFeatureBranch(branches, config, iteration); err != nil {
+						o.typedOutput(OutputError, err.Error())
+						return err
+					}
+					continue
+				}
+				nextFeature = f
+			}
+		}
+
+		// Record that the feature is being worked on
+		if nextFeature.CurrentStatus() != prd.StatusInProgress {
+			nextFeature.SetStatus(prd.StatusInProgress, time.Now().UTC())
+			if err := prd.Save(currentPRD, o.PRDPath()); err != nil {
+				return fmt.Errorf("failed to update %s: %w", o.prdFile(), err)
+			}
+		}
 
 		// === Step 3: Build fresh iteration context (clean slate) ===
 		buildState := &BuildState{
@@ -563,12 +803,37 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 			return fmt.Errorf("failed to build iteration context: %w", err)
 		}
 
+		// The agent selects its own feature in this mode, but the harness already
+		// knows which one is next, so load its declared context and relevant files
+		o.addFeatureContext(iterCtx, NewFeatureContext(nextFeature))
+		iterCtx.HarnessCommits = config.HarnessCommits
+		iterCtx.SelectionRules = scheduler.Rules(currentPRD)
+		iterCtx.RejectedChanges = revert
+
+		for _, r := range history.Flaky() {
+			iterCtx.KnownFlakes = append(iterCtx.KnownFlakes, r.String())
+		}
+
 		// Generate prompt from fresh context
 		prompt := iterCtx.BuildPrompt()
 
 		// Clear any accumulated messages - each iteration is independent
 		o.session.Messages = []Message{}
 
+		// Performance features are compared with a baseline taken before any
+		// work. Every open one is measured, since the agent may finish
+		// features other than the one it was given.
+		for i := range currentPRD.Features {
+			if f := &currentPRD.Features[i]; f.IsOpen() {
+				o.recordBenchmarkBaseline(ctx, f)
+			}
+		}
+		o.gates = nil
+
+		// Remember where the iteration started so its changes can be checked
+		baseCommit, baseErr := git.HeadCommit(o.workDir)
+		record := o.startIteration(iteration, nextFeature.ID, baseCommit)
+
 		// === Step 4: Run Claude once ===
 		o.activity(fmt.Sprintf("Working on %s...", nextFeature.ID))
 		err = o.runClaudeInteractive(ctx, prompt)
@@ -584,7 +849,104 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 			o.typedOutput(OutputError, fmt.Sprintf("Iteration %d error: %v", iteration, err))
 		}
 
-		// === Step 5: Short delay before next iteration ===
+		// === Step 5: Verify features the agent marked as passing ===
+		if err := o.acceptFeatures(ctx, config, currentPRD, attempts); err != nil {
+			if ctx.Err() != nil {
+				o.saveInterruptedState(currentFeatureID, currentPhase, iteration, config.MaxIterations)
+				return ctx.Err()
+			}
+			return o.stopBuild(branches, config, iteration, err)
+		}
+		if blocked, ok := parseBlocked(o.lastText); ok {
+			if err := o.blockFeature(blocked); err != nil {
+				o.typedOutput(OutputError, err.Error())
+				return err
+			}
+		}
+
+		// === Step 6: Run the test gate, then the coverage gate if it passed ===
+		var testsAfter *testresult.Report
+		gatesPassed := true
+		if currentPRD.TestCommand != "" {
+			command, profilePath := o.coverageCommand(currentPRD)
+			gate, err := o.checkTestGate(ctx, config, currentPRD, command, nextFeature.ID, history, attempts)
+			if gate != nil {
+				testsAfter = gate.Run.Report
+				gatesPassed = gate.Run.Passed
+			}
+			if err == nil && gatesPassed && profilePath != "" {
+				var dropped bool
+				dropped, err = o.checkCoverage(config, currentPRD, profilePath, nextFeature.ID, attempts)
+				gatesPassed = !dropped
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					o.saveInterruptedState(currentFeatureID, currentPhase, iteration, config.MaxIterations)
+					return ctx.Err()
+				}
+				return o.stopBuild(branches, config, iteration, err)
+			}
+		}
+
+		// === Step 7: Check the iteration didn't weaken the tests ===
+		rejected := false
+		if config.TamperPolicy != tamper.PolicyOff {
+			input := tamper.Input{TestsBefore: testsBefore, TestsAfter: testsAfter}
+			for path := range iterCtx.TaggedFiles {
+				input.RelatedFiles = append(input.RelatedFiles, path)
+			}
+			if baseErr == nil {
+				if input.Diff, err = git.Diff(o.workDir, baseCommit); err != nil {
+					o.debugLog("Failed to diff iteration: %v", err)
+				}
+			}
+			revert, err = o.checkTestIntegrity(config, currentPRD, iteration, nextFeature.ID, baseCommit, input, attempts)
+			rejected = len(revert) > 0
+			if err != nil {
+				return o.stopBuild(branches, config, iteration, err)
+			}
+		}
+		if testsAfter != nil && !rejected {
+			testsBefore = testsAfter
+		}
+
+		// === Step 8: Commit the iteration if it passed the gates, then publish
+		// and merge its features once they are done ===
+		if gatesPassed && !rejected {
+			if config.HarnessCommits {
+				if err := o.commitIteration(config, currentPRD, nextFeature, iteration); err != nil {
+					o.typedOutput(OutputError, err.Error())
+					return err
+				}
+			}
+			var pr *publish.Result
+			if config.Publish != nil {
+				pr = o.publishFeatures(ctx, config, branches, currentPRD)
+			}
+			if branches != nil {
+				// A feature with a pull request is merged there, not locally
+				var err error
+				if pr != nil {
+					err = o.awaitReview(branches, config, pr, iteration)
+				} else {
+					err = o.mergeFeatureBranch(branches, config, iteration)
+				}
+				if err != nil {
+					o.typedOutput(OutputError, err.Error())
+					return err
+				}
+			}
+		}
+		if branches != nil {
+			// Features the agent blocked stay off the integration branch's queue
+			if err := o.leaveHeldFeature(branches, config, iteration); err != nil {
+				o.typedOutput(OutputError, err.Error())
+				return err
+			}
+		}
+		o.finishIteration(record, currentPRD, !gatesPassed || rejected)
+
+		// === Step 9: Short delay before next iteration ===
 		// This allows file system to settle and prevents hammering
 		if iteration < config.MaxIterations {
 			o.activity("Preparing next iteration...")
@@ -603,7 +965,7 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 	o.typedOutput(OutputInfo, fmt.Sprintf("Reached maximum iterations (%d)", config.MaxIterations))
 
 	// Final check on completion status
-	finalPRD, err := prd.LoadFromDir(o.workDir)
+	finalPRD, err := prd.Load(o.PRDPath())
 	if err == nil {
 		stats := finalPRD.Stats()
 		o.typedOutput(OutputInfo, fmt.Sprintf("Final status: %d/%d features complete", stats.PassingFeatures, stats.TotalFeatures))
@@ -616,6 +978,18 @@ func (o *Orchestrator) RunBuildWithConfig(ctx context.Context, config BuildConfi
 	return nil
 }
 
+// stopBuild ends the build on a gate error. A feature the error left stuck
+// is recorded on the integration branch first, where the next build starts.
+func (o *Orchestrator) stopBuild(branches *BranchPolicy, config BuildConfig, iteration int, err error) error {
+	o.typedOutput(OutputError, err.Error())
+	if branches != nil {
+		if err := o.leaveHeldFeature(branches, config, iteration); err != nil {
+			o.typedOutput(OutputError, err.Error())
+		}
+	}
+	return err
+}
+
 // saveInterruptedState saves the current state for later resumption
 func (o *Orchestrator) saveInterruptedState(featureID string, phase Phase, iteration, maxIterations int) {
 	state := &ResumeState{
@@ -986,6 +1360,49 @@ func parseValidation(output string) ValidationResult {
 	return result
 }
 
+// parseBlocked parses a <feature_blocked> block from Claude's output. The
+// reason may continue over several lines.
+func parseBlocked(output string) (BlockedFeature, bool) {
+	var blocked BlockedFeature
+
+	startTag := "<feature_blocked>"
+	endTag := "</feature_blocked>"
+
+	startIdx := strings.Index(output, startTag)
+	if startIdx == -1 {
+		return blocked, false
+	}
+
+	endIdx := strings.Index(output[startIdx:], endTag)
+	if endIdx == -1 {
+		return blocked, false
+	}
+
+	var reasonLines []string
+	inReason := false
+	for _, line := range strings.Split(output[startIdx+len(startTag):startIdx+endIdx], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "feature:") {
+			blocked.FeatureID = strings.TrimSpace(strings.TrimPrefix(line, "feature:"))
+			inReason = false
+		} else if strings.HasPrefix(line, "reason:") {
+			inReason = true
+			if reason := strings.TrimSpace(strings.TrimPrefix(line, "reason:")); reason != "" {
+				reasonLines = append(reasonLines, reason)
+			}
+		} else if inReason {
+			reasonLines = append(reasonLines, line)
+		}
+	}
+	blocked.Reason = strings.Join(reasonLines, " ")
+
+	return blocked, blocked.FeatureID != ""
+}
+
 // runClaudeInteractive runs Claude in interactive mode, streaming output
 func (o *Orchestrator) runClaudeInteractive(ctx context.Context, prompt string) error {
 	o.debugLog("Starting Claude with prompt (%d chars)", len(prompt))
@@ -1044,10 +1461,17 @@ func (o *Orchestrator) runClaudeInteractive(ctx context.Context, prompt string)
 	buf := make([]byte, 0, 1024*1024)
 	scanner.Buffer(buf, 10*1024*1024)
 
+	// Keep the agent's text for the blocks it declares, such as <feature_blocked>
+	var agentText strings.Builder
+	defer func() { o.lastText = agentText.String() }()
+
 	// Track pending file writes for diff generation
 	// Maps tool_use_id to pending write info
 	pendingWrites := make(map[string]*pendingFileWrite)
 
+	// Track test commands so their results can be parsed
+	pendingTests := make(map[string]*pendingTestRun)
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		if line == "" {
@@ -1081,6 +1505,7 @@ func (o *Orchestrator) runClaudeInteractive(ctx context.Context, prompt string)
 							case "text":
 								if text, ok := blockMap["text"].(string); ok {
 									o.typedOutput(OutputText, text)
+									agentText.WriteString(text + "\n")
 								}
 							case "tool_use":
 								toolUseID, _ := blockMap["id"].(string)
@@ -1093,6 +1518,10 @@ func (o *Orchestrator) runClaudeInteractive(ctx context.Context, prompt string)
 											o.activity(fmt.Sprintf("Running: %s", truncateString(cmdStr, 50)))
 											// Detect step from command
 											o.detectStepFromCommand(cmdStr)
+
+											if toolUseID != "" && o.isTestCommand(cmdStr) {
+												pendingTests[toolUseID] = &pendingTestRun{command: cmdStr, start: time.Now()}
+											}
 										}
 										if path, ok := input["file_path"].(string); ok {
 											o.typedOutput(OutputToolInput, "> "+path)
@@ -1156,6 +1585,18 @@ func (o *Orchestrator) runClaudeInteractive(ctx context.Context, prompt string)
 									delete(pendingWrites, toolUseID)
 								}
 
+								// Parse the output of test commands
+								if pending, ok := pendingTests[toolUseID]; ok {
+									isError, _ := blockMap["is_error"].(bool)
+									o.recordTestRun(&TestRun{
+										Command:  pending.command,
+										Passed:   !isError,
+										Output:   toolResultText(blockMap["content"]),
+										Duration: time.Since(pending.start),
+									})
+									delete(pendingTests, toolUseID)
+								}
+
 								// Show truncated tool result
 								if contentStr, ok := blockMap["content"].(string); ok {
 									lines := strings.Split(contentStr, "\n")
@@ -1189,6 +1630,7 @@ func (o *Orchestrator) runClaudeInteractive(ctx context.Context, prompt string)
 			statsMsg := fmt.Sprintf("%s: %.1fs", subtype, elapsed)
 			if cost, ok := event["total_cost_usd"].(float64); ok {
 				statsMsg += fmt.Sprintf(", $%.4f", cost)
+				o.lastCost = cost
 			}
 			o.typedOutput(OutputInfo, statsMsg)
 
@@ -1244,6 +1686,582 @@ type pendingFileWrite struct {
 	isNewFile  bool
 }
 
+// acceptFeatures runs the checks and benchmarks of features that were marked
+// as passing during the iteration, and records when the others completed.
+// Features that fail are reopened in the PRD and charged an attempt. Returns
+// an error once a feature has used up its attempts, and marks it stuck.
+func (o *Orchestrator) acceptFeatures(ctx context.Context, config BuildConfig, before *prd.PRD, attempts map[string]int) error {
+	after, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+
+	var exhausted error
+	changed := false
+	for i := range after.Features {
+		f := &after.Features[i]
+		if !f.Passes {
+			continue
+		}
+		if prev := before.GetFeature(f.ID); prev != nil && prev.Passes {
+			continue
+		}
+		f.SetStatus(prd.StatusPassing, time.Now().UTC())
+		changed = true
+		if !f.HasChecks() && f.Benchmark == nil {
+			continue
+		}
+
+		o.step(StepTesting)
+		gate, reason := "", ""
+		if f.HasChecks() {
+			o.activity(fmt.Sprintf("Verifying %s...", f.ID))
+			result := o.verifier.RunFeature(ctx, f)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if result.Passed() {
+				o.typedOutput(OutputSuccess, fmt.Sprintf("Verified %s (%d checks passed)", f.ID, len(result.Results)))
+			} else {
+				for _, r := range result.Failed() {
+					o.typedOutput(OutputError, fmt.Sprintf("  FAIL %s: %s", r.Check.String(), r.Reason))
+				}
+				gate, reason = "acceptance checks", "acceptance checks failed"
+			}
+			o.recordGate("Acceptance checks ("+f.ID+")", result.Passed(),
+				fmt.Sprintf("%d of %d passed", len(result.Results)-len(result.Failed()), len(result.Results)))
+		}
+		if reason == "" && f.Benchmark != nil {
+			met := o.checkBenchmark(ctx, f)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !met {
+				gate, reason = "benchmark", "benchmark target not met"
+			}
+			o.recordGate("Benchmark ("+f.ID+")", met, f.Benchmark.String())
+		}
+		if reason == "" {
+			continue
+		}
+
+		attempts[f.ID]++
+		o.typedOutput(OutputError, fmt.Sprintf("Reopened %s: %s (attempt %d/%d)", f.ID, reason, attempts[f.ID], config.MaxFeatureAttempts))
+		if attempts[f.ID] >= config.MaxFeatureAttempts {
+			f.SetStatus(prd.StatusStuck, time.Now().UTC())
+			if exhausted == nil {
+				exhausted = fmt.Errorf("feature %s failed its %s %d times", f.ID, gate, attempts[f.ID])
+			}
+		} else {
+			f.SetStatus(prd.StatusInProgress, time.Now().UTC())
+		}
+	}
+
+	if changed {
+		if err := prd.Save(after, o.PRDPath()); err != nil {
+			return fmt.Errorf("failed to reopen features: %w", err)
+		}
+	}
+	return exhausted
+}
+
+// blockFeature marks a feature the agent declared blocked, so the build moves
+// on to other features until someone reopens it. A feature that passes stays
+// passing.
+func (o *Orchestrator) blockFeature(blocked BlockedFeature) error {
+	p, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+	f := p.GetFeature(blocked.FeatureID)
+	if f == nil {
+		o.typedOutput(OutputError, fmt.Sprintf("Ignoring blocked feature %s: not in %s", blocked.FeatureID, o.prdFile()))
+		return nil
+	}
+	if f.Passes {
+		o.typedOutput(OutputInfo, fmt.Sprintf("Ignoring blocked feature %s: it passes", f.ID))
+		return nil
+	}
+
+	f.SetStatus(prd.StatusBlocked, time.Now().UTC())
+	f.BlockedReason = blocked.Reason
+	if err := prd.Save(p, o.PRDPath()); err != nil {
+		return fmt.Errorf("failed to block %s: %w", f.ID, err)
+	}
+	o.typedOutput(OutputError, fmt.Sprintf("Blocked %s: %s", f.ID, f.StatusSummary()))
+	o.AddProgressNote(fmt.Sprintf("%s blocked: %s", f.ID, blocked.Reason))
+	return nil
+}
+
+// recordBenchmarkBaseline measures a performance feature's benchmark before
+// the agent starts on it. The baseline is taken once per build, so later
+// iterations on the same feature are still compared with the original code.
+// A measurement that fails isn't kept, so the next iteration tries again.
+func (o *Orchestrator) recordBenchmarkBaseline(ctx context.Context, f *prd.Feature) {
+	if f.Benchmark == nil {
+		return
+	}
+	if o.benchBaselines[f.ID] != nil {
+		return
+	}
+
+	o.activity(fmt.Sprintf("Measuring %s baseline...", f.ID))
+	m, err := o.benchRunner.Measure(ctx, f.Benchmark)
+	if err != nil {
+		o.typedOutput(OutputInfo, fmt.Sprintf("No benchmark baseline for %s: %v", f.ID, err))
+		return
+	}
+	for _, name := range m.Names() {
+		o.typedOutput(OutputInfo, fmt.Sprintf("Baseline %s: %.4g %s", name, m.Median(name), m.Metric))
+	}
+	o.benchBaselines[f.ID] = m
+}
+
+// checkBenchmark measures a feature's benchmark and compares it with the
+// baseline and targets. The evaluation is added to the progress entry and
+// the benchmark history. Returns true if every target was met.
+func (o *Orchestrator) checkBenchmark(ctx context.Context, f *prd.Feature) bool {
+	o.activity(fmt.Sprintf("Benchmarking %s...", f.ID))
+	current, err := o.benchRunner.Measure(ctx, f.Benchmark)
+	if err != nil {
+		o.typedOutput(OutputError, fmt.Sprintf("  FAIL benchmark: %v", err))
+		return false
+	}
+
+	result := bench.Evaluate(f.ID, f.Benchmark, o.benchBaselines[f.ID], current)
+	for _, c := range result.Comparisons {
+		if c.Met {
+			o.typedOutput(OutputSuccess, "  "+c.Describe(result.Metric))
+		} else {
+			o.typedOutput(OutputError, fmt.Sprintf("  FAIL %s: %s", c.Describe(result.Metric), c.Reason))
+		}
+	}
+	if result.Met() {
+		o.typedOutput(OutputSuccess, fmt.Sprintf("Benchmark target met for %s", f.ID))
+	}
+
+	o.AddProgressBenchmark(result)
+	if err := bench.AppendHistory(o.workDir, result); err != nil {
+		o.debugLog("Failed to save benchmark history: %v", err)
+	}
+	return result.Met()
+}
+
+// pendingTestRun tracks a test command until its tool result arrives
+type pendingTestRun struct {
+	command string
+	start   time.Time
+}
+
+// toolResultText extracts the text of a tool result, whose content is either
+// a string or a list of content blocks
+func toolResultText(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []any:
+		var parts []string
+		for _, block := range c {
+			if blockMap, ok := block.(map[string]any); ok {
+				if text, ok := blockMap["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
+
+// isTestCommand checks whether a bash command runs the tests, either the
+// PRD's test command or a recognized test runner
+func (o *Orchestrator) isTestCommand(cmd string) bool {
+	if o.testCommand != "" && strings.Contains(cmd, o.testCommand) {
+		return true
+	}
+	return testresult.IsTestCommand(cmd)
+}
+
+// RunTestCommand runs a test command in the working directory and records its
+// parsed results. A command that runs but fails is not an error; check Passed.
+func (o *Orchestrator) RunTestCommand(ctx context.Context, command string) (*TestRun, error) {
+	o.step(StepTesting)
+	o.activity(fmt.Sprintf("Running: %s", truncateString(command, 50)))
+
+	run, err := o.execTestCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	o.recordTestRun(run)
+	return run, nil
+}
+
+// execTestCommand runs a test command and parses its output without
+// reporting it anywhere
+func (o *Orchestrator) execTestCommand(ctx context.Context, command string) (*TestRun, error) {
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = o.workDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok || ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to run test command: %w", err)
+		}
+	}
+
+	run := &TestRun{
+		Command:  command,
+		Passed:   err == nil,
+		Output:   string(output),
+		Duration: time.Since(start),
+		Report:   testresult.Parse(string(output)),
+	}
+	if run.Report != nil && !run.Report.OK() {
+		run.Passed = false
+	}
+	return run, nil
+}
+
+// RunTestGate runs the test command as the harness's check on an iteration.
+// If it fails, the failing tests are rerun up to reruns times to separate
+// flakes from deterministic failures. Reruns stop early once every failure
+// has passed at least once.
+func (o *Orchestrator) RunTestGate(ctx context.Context, command string, reruns int) (*GateResult, error) {
+	run, err := o.RunTestCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	gate := &GateResult{Run: run}
+	if run.Passed || run.Report == nil || len(run.Report.Failures) == 0 || reruns <= 0 {
+		if !run.Passed && run.Report != nil {
+			gate.Failing = run.Report.Failures
+		}
+		return gate, nil
+	}
+
+	failures := run.Report.Failures
+	rerunCommand := testresult.RerunCommand(command, run.Report.Format, failures)
+	if rerunCommand == "" {
+		rerunCommand = command
+	}
+
+	var reports []*testresult.Report
+	for i := 0; i < reruns; i++ {
+		o.activity(fmt.Sprintf("Rerunning %d failing tests (%d/%d)...", len(failures), i+1, reruns))
+		rerun, err := o.execTestCommand(ctx, rerunCommand)
+		if err != nil {
+			return nil, err
+		}
+		gate.Reruns++
+		reports = append(reports, rerun.Report)
+
+		if flaky, _ := flakes.Classify(failures, reports); len(flaky) == len(failures) {
+			break
+		}
+	}
+
+	gate.Flaky, gate.Failing = flakes.Classify(failures, reports)
+	return gate, nil
+}
+
+// checkTestGate runs the test gate after an iteration and records flakes.
+// Deterministic failures reject the iteration: features accepted during it
+// are reopened and charged an attempt (or the current feature, if none were
+// accepted). Returns an error once a feature has used up its attempts.
+func (o *Orchestrator) checkTestGate(ctx context.Context, config BuildConfig, before *prd.PRD, command, featureID string, history *flakes.History, attempts map[string]int) (*GateResult, error) {
+	o.typedOutput(OutputInfo, "Running test gate: "+command)
+
+	gate, err := o.RunTestGate(ctx, command, config.FlakeReruns)
+	if err != nil {
+		return nil, err
+	}
+	detail := command
+	if gate.Run.Report != nil {
+		detail = gate.Run.Report.Summary()
+	}
+	o.recordGate("Tests", gate.Run.Passed, detail)
+	if gate.Run.Passed {
+		o.typedOutput(OutputSuccess, "Test gate passed")
+		return gate, nil
+	}
+
+	now := time.Now().UTC()
+	for _, f := range gate.Flaky {
+		history.Record(f, true, now)
+		o.typedOutput(OutputInfo, fmt.Sprintf("Flaky: %s (passed on rerun)", f.String()))
+	}
+	for _, f := range gate.Failing {
+		known := history.IsKnownFlake(f)
+		history.Record(f, false, now)
+		if known {
+			o.typedOutput(OutputInfo, fmt.Sprintf("Known flake failed: %s", f.String()))
+		}
+	}
+	if len(gate.Flaky)+len(gate.Failing) > 0 {
+		if err := history.Save(); err != nil {
+			o.debugLog("Failed to save flake history: %v", err)
+		}
+	}
+
+	if !gate.CountsAgainstBudget() {
+		o.typedOutput(OutputInfo, "Test gate failed only on tests that passed on rerun; not counted against "+featureID)
+		return gate, nil
+	}
+	return gate, o.rejectIteration(config, before, featureID, "test gate", attempts)
+}
+
+// coverageCommand returns the test gate command and the coverage profile it
+// writes. `go test` commands get -coverprofile added when the PRD names no
+// profile. The profile path is empty when the coverage gate is off.
+func (o *Orchestrator) coverageCommand(p *prd.PRD) (string, string) {
+	if p.Coverage == nil {
+		return p.TestCommand, ""
+	}
+
+	command, profilePath := p.TestCommand, p.Coverage.Profile
+	if profilePath == "" {
+		var ok bool
+		if command, profilePath, ok = coverage.GoCommand(p.TestCommand, coverage.DefaultProfile); !ok {
+			return p.TestCommand, ""
+		}
+	}
+
+	// Remove the previous profile so a run that writes none isn't compared
+	fullPath := filepath.Join(o.workDir, profilePath)
+	_ = os.Remove(fullPath)
+	_ = os.MkdirAll(filepath.Dir(fullPath), 0755)
+	return command, profilePath
+}
+
+// checkCoverage compares the profile written by the test gate with the
+// baseline recorded at the last accepted feature. If coverage dropped by more
+// than the threshold, features accepted this iteration are reopened and
+// charged an attempt (or the current feature, if none were accepted).
+// Otherwise the profile becomes the new baseline. Returns true if the
+// iteration was rejected, and an error once a feature has used up its attempts.
+func (o *Orchestrator) checkCoverage(config BuildConfig, before *prd.PRD, profilePath, featureID string, attempts map[string]int) (bool, error) {
+	profile, err := coverage.ParseFile(filepath.Join(o.workDir, profilePath))
+	if err != nil {
+		o.typedOutput(OutputError, fmt.Sprintf("Coverage gate skipped: %v", err))
+		return false, nil
+	}
+	baseline, err := coverage.LoadBaseline(o.workDir)
+	if err != nil {
+		o.typedOutput(OutputError, fmt.Sprintf("Ignoring coverage baseline: %v", err))
+		baseline = nil
+	}
+
+	threshold := before.Coverage.Threshold
+	if threshold <= 0 {
+		threshold = coverage.DefaultThreshold
+	}
+	result := coverage.Compare(baseline, profile, threshold)
+
+	if o.onCoverage != nil {
+		o.onCoverage(result)
+	}
+	o.SetProgressCoverage(result)
+	o.recordGate("Coverage", !result.Rejected(), result.Summary())
+
+	if !result.Rejected() {
+		o.typedOutput(OutputSuccess, "Coverage: "+result.Summary())
+		accepted, err := o.newlyAccepted(before)
+		if err != nil {
+			return false, err
+		}
+		if baseline == nil || len(accepted) > 0 {
+			b := &coverage.Baseline{Recorded: time.Now().UTC(), Profile: profile}
+			if len(accepted) > 0 {
+				b.FeatureID = accepted[len(accepted)-1]
+			}
+			if err := coverage.SaveBaseline(o.workDir, b); err != nil {
+				o.debugLog("Failed to save coverage baseline: %v", err)
+			}
+		}
+		return false, nil
+	}
+
+	o.typedOutput(OutputError, "Coverage dropped: "+result.Summary())
+	for _, r := range result.Regressions {
+		o.typedOutput(OutputError, fmt.Sprintf("  %s %.1f%% -> %.1f%%", r.Package, r.Before, r.After))
+	}
+	return true, o.rejectIteration(config, before, featureID, "coverage gate", attempts)
+}
+
+// newlyAccepted returns the IDs of features that pass in the PRD but didn't
+// before the iteration
+func (o *Orchestrator) newlyAccepted(before *prd.PRD) ([]string, error) {
+	after, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+	var accepted []string
+	for _, f := range after.Features {
+		if prev := before.GetFeature(f.ID); f.Passes && (prev == nil || !prev.Passes) {
+			accepted = append(accepted, f.ID)
+		}
+	}
+	return accepted, nil
+}
+
+// rejectIteration reopens the features accepted during the iteration and
+// charges each an attempt. If none were accepted, the current feature is
+// charged instead. Returns an error once a feature has used up its attempts,
+// and marks it stuck.
+func (o *Orchestrator) rejectIteration(config BuildConfig, before *prd.PRD, featureID, gate string, attempts map[string]int) error {
+	accepted, err := o.newlyAccepted(before)
+	if err != nil {
+		return err
+	}
+	after, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+
+	charged := accepted
+	if len(accepted) == 0 {
+		charged = []string{featureID}
+	}
+
+	var exhausted error
+	changed := false
+	for _, id := range charged {
+		attempts[id]++
+		o.typedOutput(OutputError, fmt.Sprintf("Rejected %s: %s failed (attempt %d/%d)", id, gate, attempts[id], config.MaxFeatureAttempts))
+		f := after.GetFeature(id)
+		if attempts[id] >= config.MaxFeatureAttempts {
+			if f != nil {
+				f.SetStatus(prd.StatusStuck, time.Now().UTC())
+				changed = true
+			}
+			if exhausted == nil {
+				exhausted = fmt.Errorf("feature %s failed the %s %d times", id, gate, attempts[id])
+			}
+		} else if f != nil && f.Passes {
+			f.SetStatus(prd.StatusInProgress, time.Now().UTC())
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := prd.Save(after, o.PRDPath()); err != nil {
+			return fmt.Errorf("failed to reopen features: %w", err)
+		}
+	}
+	return exhausted
+}
+
+// checkTestIntegrity looks for changes in the iteration that weakened the
+// tests. Findings are recorded for review; with the reject policy the
+// iteration is also rejected like a failed gate. Returns the findings if
+// the iteration was rejected, and an error once a feature has used up its
+// attempts.
+func (o *Orchestrator) checkTestIntegrity(config BuildConfig, before *prd.PRD, iteration int, featureID, baseCommit string, in tamper.Input, attempts map[string]int) ([]string, error) {
+	findings := tamper.Analyze(in)
+	if len(findings) == 0 {
+		o.recordGate("Test integrity", true, "no weakened tests")
+		return nil, nil
+	}
+
+	rejected := config.TamperPolicy == tamper.PolicyReject
+	o.recordGate("Test integrity", !rejected, fmt.Sprintf("%d finding(s), see %s", len(findings), tamper.ReviewFile))
+	lines := make([]string, 0, len(findings))
+	o.typedOutput(OutputError, fmt.Sprintf("Test integrity: %d change(s) weakened the tests", len(findings)))
+	for _, f := range findings {
+		lines = append(lines, f.String())
+		o.typedOutput(OutputError, "  "+f.String())
+	}
+
+	if o.currentEntry != nil {
+		o.currentEntry.SetIntegrity(lines, rejected)
+	}
+	review := tamper.Review{
+		Iteration:  iteration,
+		FeatureID:  featureID,
+		BaseCommit: baseCommit,
+		Findings:   lines,
+		Rejected:   rejected,
+		Recorded:   time.Now().UTC(),
+	}
+	if err := tamper.AppendReview(o.workDir, review); err != nil {
+		o.debugLog("Failed to save review list: %v", err)
+	}
+
+	if !rejected {
+		o.typedOutput(OutputInfo, "Iteration flagged for review in "+tamper.ReviewFile)
+		return nil, nil
+	}
+	return lines, o.rejectIteration(config, before, featureID, "test integrity check", attempts)
+}
+
+// commitIteration commits the iteration's changes with a message naming the
+// features accepted during it, or the current feature as work in progress.
+// Trailers tie the commit to its features, iteration and run.
+func (o *Orchestrator) commitIteration(config BuildConfig, before *prd.PRD, current *prd.Feature, iteration int) error {
+	if !git.IsInsideWorkTree(o.workDir) {
+		return nil
+	}
+
+	after, err := prd.Load(o.PRDPath())
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Base(o.PRDPath()), err)
+	}
+	accepted, err := o.newlyAccepted(before)
+	if err != nil {
+		return err
+	}
+	params := commitmsg.Params{Iteration: iteration, RunID: o.session.ID, Complete: len(accepted) > 0}
+	for _, id := range accepted {
+		params.Features = append(params.Features, after.GetFeature(id))
+	}
+	if len(params.Features) == 0 {
+		params.Features = []*prd.Feature{current}
+	}
+	msg := commitmsg.New(params)
+
+	o.step(StepCommitting)
+	hash, err := git.CommitAll(o.workDir, msg.String(), config.Signing)
+	if err != nil {
+		return fmt.Errorf("failed to commit iteration: %w", err)
+	}
+	if hash == "" {
+		o.debugLog("Nothing to commit after iteration %d", iteration)
+		return nil
+	}
+
+	o.typedOutput(OutputSuccess, fmt.Sprintf("Committed %s: %s", shortHash(hash), msg.Header()))
+	o.AddProgressCommit(shortHash(hash), msg.Header())
+	return nil
+}
+
+// recordTestRun parses a test run's output, then reports it to the UI and the
+// current progress entry
+func (o *Orchestrator) recordTestRun(run *TestRun) {
+	if run.Report == nil {
+		run.Report = testresult.Parse(run.Output)
+	}
+	if run.Report != nil && !run.Report.OK() {
+		run.Passed = false
+	}
+
+	if run.Report != nil {
+		outputType := OutputSuccess
+		if !run.Passed {
+			outputType = OutputError
+		}
+		o.typedOutput(outputType, fmt.Sprintf("Tests: %s", run.Report.Summary()))
+		for _, f := range run.Report.Failures {
+			o.typedOutput(OutputError, fmt.Sprintf("  FAIL %s", f.String()))
+		}
+	}
+
+	if o.onTestResult != nil {
+		o.onTestResult(run)
+	}
+	o.SetProgressTestResult(run.Command, run.Passed, run.Report)
+}
+
 // captureFileContent reads a file's current content for diff generation
 // Returns the content and whether the file exists (is new)
 func (o *Orchestrator) captureFileContent(filePath string) (content string, isNewFile bool) {
@@ -1434,7 +2452,7 @@ func (o *Orchestrator) detectStepFromFileOp(toolName, filePath string) {
 	// Write/Edit operations
 	if toolNameLower == "write" || toolNameLower == "edit" {
 		// Check for PRD/progress file updates
-		if strings.Contains(filePathLower, "prd.json") ||
+		if slices.Contains(prd.Filenames, filepath.Base(filePathLower)) ||
 			strings.Contains(filePathLower, "progress.txt") {
 			o.step(StepUpdating)
 			return
@@ -1467,11 +2485,16 @@ func (o *Orchestrator) BuildIterationContext(iteration int, phase Phase, feature
 		KeyFiles:    make(map[string]string),
 	}
 
-	// Read prd.json
-	prdContent, err := os.ReadFile(filepath.Join(o.workDir, "prd.json"))
+	// The agent may have created, deleted or ignored files since the last
+	// iteration without touching anything the file list cache watches
+	o.tagger.Enumerator().Invalidate()
+
+	// Read the PRD, in whichever format it is written
+	prdContent, err := os.ReadFile(o.PRDPath())
 	if err != nil {
-		return nil, fmt.Errorf("failed to read prd.json: %w", err)
+		return nil, fmt.Errorf("failed to read %s: %w", o.prdFile(), err)
 	}
+	ctx.PRDFile = o.prdFile()
 	ctx.PRDContent = string(prdContent)
 
 	// Read progress.txt if exists
@@ -1480,14 +2503,25 @@ func (o *Orchestrator) BuildIterationContext(iteration int, phase Phase, feature
 		ctx.ProgressContent = string(progressContent)
 	}
 
-	// Generate directory tree with configurable depth
-	maxDepth := o.snapshotConfig.MaxTreeDepth
-	if maxDepth <= 0 {
-		maxDepth = 4 // default
+	// Outline the codebase if enabled, falling back to the directory tree
+	// when no supported language is found
+	if o.snapshotConfig.UseRepoMap {
+		repoMap, err := o.repoMap.Build(o.snapshotConfig.RepoMapBudget)
+		if err == nil {
+			ctx.RepoMap = repoMap
+		}
 	}
-	tree, err := o.generateDirectoryTree(maxDepth)
-	if err == nil {
-		ctx.DirectoryTree = tree
+
+	// Generate directory tree with configurable depth
+	if ctx.RepoMap == "" {
+		maxDepth := o.snapshotConfig.MaxTreeDepth
+		if maxDepth <= 0 {
+			maxDepth = 4 // default
+		}
+		tree, err := o.generateDirectoryTree(maxDepth)
+		if err == nil {
+			ctx.DirectoryTree = tree
+		}
 	}
 
 	// Detect and load key files if enabled
@@ -1498,70 +2532,144 @@ func (o *Orchestrator) BuildIterationContext(iteration int, phase Phase, feature
 	// Set current feature context if provided
 	if feature != nil {
 		ctx.CurrentFeature = feature
+		o.addFeatureContext(ctx, feature)
 	}
 
 	return ctx, nil
 }
 
-// generateDirectoryTree creates a textual representation of the directory structure
-func (o *Orchestrator) generateDirectoryTree(maxDepth int) (string, error) {
-	var sb strings.Builder
-	err := o.walkDir(o.workDir, "", 0, maxDepth, &sb)
-	if err != nil {
-		return "", err
+// addFeatureContext adds the files a feature needs to the iteration context:
+// first the context declared in prd.json, then files ranked by relevance.
+func (o *Orchestrator) addFeatureContext(ctx *IterationContext, feature *FeatureContext) {
+	if feature == nil {
+		return
 	}
-	return sb.String(), nil
+
+	if len(feature.ContextTags) > 0 {
+		if err := o.AddTaggedFilesFromTags(ctx, feature.ContextTags); err != nil {
+			o.debugLog("Failed to load declared context for %s: %v", feature.ID, err)
+		} else {
+			ctx.TagPatterns = append(ctx.TagPatterns, feature.ContextTags...)
+		}
+	}
+
+	o.addRelevantFiles(ctx, feature)
 }
 
-// walkDir recursively walks the directory tree
-func (o *Orchestrator) walkDir(path, prefix string, depth, maxDepth int, sb *strings.Builder) error {
-	if depth > maxDepth {
-		return nil
+// addRelevantFiles ranks the codebase against the feature description and steps
+// and adds the top files to TaggedFiles, within the configured count and size budget.
+// Files that are already tagged, and the PRD and progress.txt (always in the prompt), are skipped.
+func (o *Orchestrator) addRelevantFiles(ctx *IterationContext, feature *FeatureContext) {
+	maxFiles := o.snapshotConfig.MaxRelevantFiles
+	if maxFiles <= 0 || feature == nil {
+		return
+	}
+
+	budget := o.snapshotConfig.RelevantFilesBudget
+	if budget <= 0 {
+		budget = 40 * 1024 // 40KB default
 	}
 
-	entries, err := os.ReadDir(path)
+	query := feature.Description + "\n" + strings.Join(feature.Steps, "\n")
+
+	// Ask for extra candidates since some may be skipped or exceed the budget
+	results, err := o.relevance.Query(query, maxFiles*2)
 	if err != nil {
-		return err
+		o.debugLog("Relevance retrieval failed: %v", err)
+		return
 	}
 
-	// Filter out common ignored directories/files
-	var filtered []os.DirEntry
-	for _, entry := range entries {
-		name := entry.Name()
-		// Skip hidden files, common build/dependency directories
-		if strings.HasPrefix(name, ".") && name != ".gitignore" {
+	added := 0
+	for _, r := range results {
+		if added >= maxFiles {
+			break
+		}
+		if r.Path == o.prdFile() || slices.Contains(prd.Filenames, r.Path) || r.Path == progress.DefaultFilename {
+			continue
+		}
+		if _, exists := ctx.TaggedFiles[r.Path]; exists {
+			continue
+		}
+		if r.Size > budget {
 			continue
 		}
-		if name == "node_modules" || name == "vendor" || name == "__pycache__" ||
-			name == "target" || name == "build" || name == "dist" {
+
+		content, err := os.ReadFile(filepath.Join(o.workDir, r.Path))
+		if err != nil {
+			continue
+		}
+
+		budget -= int64(len(content))
+		ctx.TaggedFiles[r.Path] = string(content)
+		ctx.RelevantFiles = append(ctx.RelevantFiles, r.Path)
+		added++
+	}
+
+	if added > 0 {
+		o.debugLog("Added %d relevant files for %s", added, feature.ID)
+	}
+}
+
+// treeNode is a directory tree entry used to render DirectoryTree
+type treeNode struct {
+	name     string
+	isDir    bool
+	children []*treeNode
+}
+
+// generateDirectoryTree creates a textual representation of the directory structure.
+// It uses the shared file enumerator, so ignored files and build output are left out.
+func (o *Orchestrator) generateDirectoryTree(maxDepth int) (string, error) {
+	entries, err := o.tagger.ListFiles(maxDepth + 1)
+	if err != nil {
+		return "", err
+	}
+
+	root := &treeNode{isDir: true}
+	dirs := map[string]*treeNode{".": root}
+	for _, entry := range entries {
+		isDir := strings.HasSuffix(entry, "/")
+		path := strings.TrimSuffix(entry, "/")
+		parent := dirs[filepath.Dir(path)]
+		if parent == nil {
 			continue
 		}
-		filtered = append(filtered, entry)
+
+		node := &treeNode{name: filepath.Base(path), isDir: isDir}
+		parent.children = append(parent.children, node)
+		if isDir {
+			dirs[path] = node
+		}
 	}
 
-	for i, entry := range filtered {
-		isLast := i == len(filtered)-1
+	var sb strings.Builder
+	writeTree(root, "", &sb)
+	return sb.String(), nil
+}
+
+// writeTree renders the children of node with box-drawing connectors
+func writeTree(node *treeNode, prefix string, sb *strings.Builder) {
+	for i, child := range node.children {
+		isLast := i == len(node.children)-1
 		connector := "├── "
 		if isLast {
 			connector = "└── "
 		}
 
-		sb.WriteString(prefix + connector + entry.Name())
-		if entry.IsDir() {
+		sb.WriteString(prefix + connector + child.name)
+		if child.isDir {
 			sb.WriteString("/")
 		}
 		sb.WriteString("\n")
 
-		if entry.IsDir() {
+		if child.isDir {
 			newPrefix := prefix + "│   "
 			if isLast {
 				newPrefix = prefix + "    "
 			}
-			_ = o.walkDir(filepath.Join(path, entry.Name()), newPrefix, depth+1, maxDepth, sb)
+			writeTree(child, newPrefix, sb)
 		}
 	}
-
-	return nil
 }
 
 // keyFilePatterns defines patterns for automatically detected key files
@@ -3,16 +3,27 @@ package orchestrator
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/mpjhorner/superralph/internal/bench"
+	"github.com/mpjhorner/superralph/internal/coverage"
+	"github.com/mpjhorner/superralph/internal/flakes"
+	"github.com/mpjhorner/superralph/internal/git"
 	"github.com/mpjhorner/superralph/internal/prd"
 	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/reset"
+	"github.com/mpjhorner/superralph/internal/runs"
+	"github.com/mpjhorner/superralph/internal/tamper"
+	"github.com/mpjhorner/superralph/internal/testresult"
 )
 
 func TestResponseParsing(t *testing.T) {
@@ -168,6 +179,30 @@ func TestIterationContextNoOptionalFields(t *testing.T) {
 	assert.NotContains(t, prompt, "## Tagged Files")
 	assert.NotContains(t, prompt, "## Current Feature")
 	assert.NotContains(t, prompt, "## Current Phase")
+	assert.NotContains(t, prompt, "## Known Flaky Tests")
+}
+
+func TestIterationContextKnownFlakes(t *testing.T) {
+	ctx := &IterationContext{
+		PRDContent:  `{"name": "Test"}`,
+		Iteration:   1,
+		KnownFlakes: []string{"app/integration: TestSync"},
+	}
+
+	prompt := ctx.BuildPrompt()
+
+	assert.Contains(t, prompt, "## Known Flaky Tests")
+	assert.Contains(t, prompt, "- app/integration: TestSync\n")
+}
+
+func TestIterationContextSelectionRules(t *testing.T) {
+	ctx := &IterationContext{PRDContent: `{"name": "Test"}`, Iteration: 1}
+	assert.Contains(t, ctx.BuildPrompt(), "- Pick the highest priority first (high > medium > low)\n")
+
+	ctx.SelectionRules = prd.StrategyUnblock.Scheduler().Rules(nil)
+	prompt := ctx.BuildPrompt()
+	assert.Contains(t, prompt, "- "+ctx.SelectionRules[0]+"\n")
+	assert.NotContains(t, prompt, "high > medium > low)\n- Within same priority")
 }
 
 func TestPhaseConstants(t *testing.T) {
@@ -254,6 +289,24 @@ func TestBuildIterationContext(t *testing.T) {
 	assert.Contains(t, ctx.DirectoryTree, "src/")
 }
 
+func TestBuildIterationContextSeesNewFiles(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.json"), []byte(`{"name": "Test", "features": []}`), 0644))
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	orch := New(tmpDir)
+	ctx, err := orch.BuildIterationContext(1, "", nil)
+	require.NoError(t, err)
+	assert.NotContains(t, ctx.DirectoryTree, "export.go")
+
+	// The agent adds an untracked file, which doesn't change the git index
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "export.go"), []byte("package main"), 0644))
+	ctx, err = orch.BuildIterationContext(2, "", nil)
+	require.NoError(t, err)
+	assert.Contains(t, ctx.DirectoryTree, "export.go")
+}
+
 func TestBuildIterationContextWithFeature(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "orchestrator-test-*")
 	require.NoError(t, err)
@@ -278,6 +331,37 @@ func TestBuildIterationContextWithFeature(t *testing.T) {
 	assert.Equal(t, "feat-001", ctx.CurrentFeature.ID)
 }
 
+func TestBuildIterationContextMarkdownPRD(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdContent := "## feat-001: Test feature\n\n- [ ] Step 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte(prdContent), 0644))
+
+	ctx, err := New(tmpDir).BuildIterationContext(1, PhaseExecuting, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prd.md", ctx.PRDFile)
+	assert.Equal(t, prdContent, ctx.PRDContent)
+
+	prompt := ctx.BuildPrompt()
+	assert.Contains(t, prompt, "## prd.md\n")
+	assert.Contains(t, prompt, `Update prd.md to check every one of its step boxes ("- [x]") for this feature`)
+	assert.NotContains(t, prompt, "prd.json")
+}
+
+func TestBuildIterationContextPRDPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "docs"), 0755))
+	prdPath := filepath.Join(tmpDir, "docs", "requirements.yaml")
+	require.NoError(t, os.WriteFile(prdPath, []byte("name: Test\n"), 0644))
+
+	orch := New(tmpDir).SetPRDPath(prdPath)
+	assert.Equal(t, prdPath, orch.PRDPath())
+
+	ctx, err := orch.BuildIterationContext(1, PhaseExecuting, nil)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("docs", "requirements.yaml"), ctx.PRDFile)
+	assert.Contains(t, ctx.BuildPrompt(), "Update docs/requirements.yaml to set passes: true for this feature")
+}
+
 func TestBuildIterationContextMissingPRD(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "orchestrator-test-*")
 	require.NoError(t, err)
@@ -487,6 +571,18 @@ func TestParseValidation(t *testing.T) {
 	}
 }
 
+func TestParseBlocked(t *testing.T) {
+	blocked, ok := parseBlocked("Can't reach the sandbox.\n<feature_blocked>\nfeature: feat-004\nreason: The payment sandbox needs\nan API key in STRIPE_KEY\n</feature_blocked>")
+	assert.True(t, ok)
+	assert.Equal(t, "feat-004", blocked.FeatureID)
+	assert.Equal(t, "The payment sandbox needs an API key in STRIPE_KEY", blocked.Reason)
+
+	_, ok = parseBlocked("<feature_blocked>\nreason: No feature\n</feature_blocked>")
+	assert.False(t, ok)
+	_, ok = parseBlocked("Implemented feat-004")
+	assert.False(t, ok)
+}
+
 func TestValidationResultSerialization(t *testing.T) {
 	result := ValidationResult{
 		Valid:    false,
@@ -1169,6 +1265,87 @@ func TestConfigurableTreeDepth(t *testing.T) {
 	assert.Contains(t, ctx.DirectoryTree, "deep.txt")
 }
 
+func TestDirectoryTreeRespectsIgnoreFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"prd.json":          `{"name": "Test"}`,
+		".gitignore":        "coverage/\n",
+		".superralphignore": "fixtures/\n",
+		"main.go":           "package main",
+		"src/util.go":       "package src",
+		"coverage/lcov.txt": "TN:",
+		"fixtures/big.json": "{}",
+		"dist/app.js":       "bundle",
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	orch := New(tmpDir)
+	ctx, err := orch.BuildIterationContext(1, "", nil)
+	require.NoError(t, err)
+
+	assert.Contains(t, ctx.DirectoryTree, "├── main.go")
+	assert.Contains(t, ctx.DirectoryTree, "└── src/\n    └── util.go")
+	assert.NotContains(t, ctx.DirectoryTree, "coverage")
+	assert.NotContains(t, ctx.DirectoryTree, "fixtures")
+	assert.NotContains(t, ctx.DirectoryTree, "dist")
+}
+
+func TestBuildIterationContextRepoMap(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"prd.json":             `{"name": "Test"}`,
+		"go.mod":               "module example.com/app",
+		"store/store.go":       "package store\n\ntype Store struct{}\n\nfunc (s *Store) Get(id string) string { return id }\n",
+		"store/store_test.go":  "package store\n\nfunc TestGet() {}\n",
+		"internal/internal.go": "package internal\n\nfunc helper() {}\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	orch := New(tmpDir)
+
+	// Disabled by default
+	ctx, err := orch.BuildIterationContext(1, "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, ctx.RepoMap)
+	assert.NotEmpty(t, ctx.DirectoryTree)
+
+	result := orch.SetUseRepoMap(true)
+	assert.Equal(t, orch, result, "Should return orchestrator for chaining")
+
+	ctx, err = orch.BuildIterationContext(1, "", nil)
+	require.NoError(t, err)
+	assert.Contains(t, ctx.RepoMap, "store/store.go (package store)")
+	assert.Contains(t, ctx.RepoMap, "func (s *Store) Get(id string) string")
+	assert.Empty(t, ctx.DirectoryTree, "Repo map replaces the directory tree")
+
+	prompt := ctx.BuildPrompt()
+	assert.Contains(t, prompt, "## Repository Map")
+	assert.NotContains(t, prompt, "## Directory Structure")
+}
+
+func TestBuildIterationContextRepoMapFallsBackToTree(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.json"), []byte(`{"name": "Test"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "app.rb"), []byte("class App; end"), 0644))
+
+	orch := New(tmpDir).SetUseRepoMap(true)
+	ctx, err := orch.BuildIterationContext(1, "", nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, ctx.RepoMap)
+	assert.Contains(t, ctx.DirectoryTree, "app.rb")
+}
+
 func TestKeyFilesInPrompt(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1369,12 +1546,13 @@ func TestProgressEntryBuilderAddWorkDone(t *testing.T) {
 func TestProgressEntryBuilderSetTestResult(t *testing.T) {
 	builder := NewProgressEntryBuilder(1)
 
-	result := builder.SetTestResult("go test ./...", true, "47 tests passed")
+	report := &testresult.Report{Format: testresult.FormatGo, Passed: 47}
+	result := builder.SetTestResult("go test ./...", true, report)
 
 	assert.Equal(t, builder, result)
 	assert.Equal(t, "go test ./...", builder.Testing.Command)
 	assert.True(t, builder.Testing.Passed)
-	assert.Equal(t, "47 tests passed", builder.Testing.Details)
+	assert.Equal(t, report, builder.Testing.Details)
 }
 
 func TestProgressEntryBuilderAddCommit(t *testing.T) {
@@ -1410,7 +1588,7 @@ func TestProgressEntryBuilderBuild(t *testing.T) {
 	builder.SetStartingState(15, 4, feature).
 		AddWorkDone("Added login endpoint").
 		AddWorkDone("Added JWT validation").
-		SetTestResult("go test ./...", true, "All tests passed").
+		SetTestResult("go test ./...", true, nil).
 		AddCommit("abc123", "feat: add authentication").
 		AddNote("Consider adding refresh tokens")
 
@@ -1505,12 +1683,12 @@ func TestOrchestratorSetProgressTestResult(t *testing.T) {
 	orch := New(tmpDir)
 
 	// Without an entry, should not panic
-	orch.SetProgressTestResult("go test ./...", true, "passed")
+	orch.SetProgressTestResult("go test ./...", true, nil)
 
 	// Create an entry
 	orch.currentEntry = NewProgressEntryBuilder(1)
 
-	orch.SetProgressTestResult("go test ./...", true, "47 tests passed")
+	orch.SetProgressTestResult("go test ./...", true, &testresult.Report{Passed: 47})
 
 	assert.Equal(t, "go test ./...", orch.currentEntry.Testing.Command)
 	assert.True(t, orch.currentEntry.Testing.Passed)
@@ -1578,7 +1756,7 @@ func TestOrchestratorFinishProgressEntry(t *testing.T) {
 	orch.currentEntry = NewProgressEntryBuilder(1)
 	orch.currentEntry.SetStartingState(2, 1, &progress.FeatureRef{ID: "feat-002", Description: "Second"})
 	orch.AddProgressWork("Implemented feature")
-	orch.SetProgressTestResult("go test ./...", true, "passed")
+	orch.SetProgressTestResult("go test ./...", true, nil)
 	orch.AddProgressCommit("abc123", "feat: add feature")
 
 	// Finish the entry
@@ -1629,7 +1807,7 @@ func TestOrchestratorProgressFileGrowsAcrossIterations(t *testing.T) {
 
 		orch.StartProgressEntry(i, currentPRD)
 		orch.AddProgressWork("Implemented feature " + currentPRD.NextFeature().ID)
-		orch.SetProgressTestResult("go test ./...", true, "passed")
+		orch.SetProgressTestResult("go test ./...", true, nil)
 		orch.AddProgressCommit("commit"+string(rune('0'+i)), "feat: add feature")
 
 		err = orch.FinishProgressEntry(currentPRD, true)
@@ -1961,3 +2139,884 @@ func TestRunBuildWithConfigStartIteration(t *testing.T) {
 	assert.Equal(t, 5, config.StartIteration)
 	assert.Equal(t, "feat-003", config.ResumeFeature)
 }
+
+func TestBuildIterationContextAddsRelevantFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.json"), []byte(`{"name": "Test"}`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "auth"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "auth", "login.go"),
+		[]byte("package auth\n\n// Login checks a password\nfunc Login(password string) bool { return true }"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "billing.go"),
+		[]byte("package billing\n\ntype Invoice struct{}"), 0644))
+
+	feature := &FeatureContext{
+		ID:          "feat-001",
+		Description: "User can log in with a password",
+		Steps:       []string{"Validate the password"},
+	}
+
+	orch := New(tmpDir)
+	ctx, err := orch.BuildIterationContext(1, PhasePlanning, feature)
+	require.NoError(t, err)
+
+	loginPath := filepath.Join("auth", "login.go")
+	assert.Contains(t, ctx.TaggedFiles, loginPath)
+	assert.NotContains(t, ctx.TaggedFiles, "billing.go")
+	assert.NotContains(t, ctx.TaggedFiles, "prd.json")
+	assert.Equal(t, []string{loginPath}, ctx.RelevantFiles)
+	assert.Contains(t, ctx.BuildPrompt(), "Selected automatically for relevance")
+}
+
+func TestRelevantFilesRespectBudgetAndDisable(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.json"), []byte(`{"name": "Test"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "login.go"),
+		[]byte("// login password "+strings.Repeat("x", 200)), 0644))
+
+	feature := &FeatureContext{ID: "feat-001", Description: "login with password"}
+
+	orch := New(tmpDir)
+	config := orch.GetSnapshotConfig()
+	config.RelevantFilesBudget = 100
+	orch.SetSnapshotConfig(config)
+
+	ctx, err := orch.BuildIterationContext(1, PhasePlanning, feature)
+	require.NoError(t, err)
+	assert.Empty(t, ctx.RelevantFiles, "files larger than the budget are skipped")
+
+	orch.SetMaxRelevantFiles(0)
+	config = orch.GetSnapshotConfig()
+	config.RelevantFilesBudget = 0
+	orch.SetSnapshotConfig(config)
+
+	ctx, err = orch.BuildIterationContext(1, PhasePlanning, feature)
+	require.NoError(t, err)
+	assert.Empty(t, ctx.TaggedFiles)
+}
+
+func TestNewFeatureContext(t *testing.T) {
+	assert.Nil(t, NewFeatureContext(nil))
+
+	fc := NewFeatureContext(&prd.Feature{
+		ID:          "feat-002",
+		Category:    prd.CategoryUI,
+		Priority:    prd.PriorityLow,
+		Description: "Dark mode",
+		Steps:       []string{"Toggle theme"},
+	})
+	require.NotNil(t, fc)
+	assert.Equal(t, "feat-002", fc.ID)
+	assert.Equal(t, "ui", fc.Category)
+	assert.Equal(t, "low", fc.Priority)
+	assert.Equal(t, []string{"Toggle theme"}, fc.Steps)
+	assert.Empty(t, fc.Checks)
+}
+
+func TestFeatureContextChecksInPrompt(t *testing.T) {
+	fc := NewFeatureContext(&prd.Feature{
+		ID:          "feat-003",
+		Description: "Health endpoint",
+		Steps:       []string{"Add /health"},
+		Verify:      &prd.Check{Command: "make e2e"},
+		Checks:      []prd.Check{{Step: 1, Command: "curl -sf localhost:8080/health", Output: "ok"}},
+	})
+	assert.Equal(t, []string{
+		"step 1: curl -sf localhost:8080/health (exit 0, output matches /ok/)",
+		"make e2e (exit 0)",
+	}, fc.Checks)
+
+	prompt := (&IterationContext{PRDContent: "{}", CurrentFeature: fc}).BuildPrompt()
+	assert.Contains(t, prompt, "Acceptance checks")
+	assert.Contains(t, prompt, "  - make e2e (exit 0)\n")
+}
+
+func TestFeatureContextBenchmarkInPrompt(t *testing.T) {
+	fc := NewFeatureContext(&prd.Feature{
+		ID:        "feat-007",
+		Category:  prd.CategoryPerformance,
+		Steps:     []string{"Speed up parsing"},
+		Benchmark: &prd.BenchmarkSpec{Command: "go test -run '^$' -bench Parse ./parser", Improvement: 20},
+	})
+	assert.Equal(t, "go test -run '^$' -bench Parse ./parser: ns/op 20% better than baseline", fc.Benchmark)
+
+	prompt := (&IterationContext{PRDContent: "{}", CurrentFeature: fc}).BuildPrompt()
+	assert.Contains(t, prompt, "Benchmark target")
+	assert.Contains(t, prompt, "  - "+fc.Benchmark+"\n")
+}
+
+func TestBuildIterationContextLoadsDeclaredContext(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.json"), []byte(`{"name": "Test"}`), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "docs", "drafts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "docs", "api.md"), []byte("# API spec"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "docs", "drafts", "old.md"), []byte("# Old"), 0644))
+
+	feature := NewFeatureContext(&prd.Feature{
+		ID:          "feat-001",
+		Description: "Unrelated words",
+		Context: &prd.ContextSpec{
+			Docs:    []string{"docs/**/*.md"},
+			Exclude: []string{"drafts"},
+		},
+	})
+
+	orch := New(tmpDir)
+	orch.SetMaxRelevantFiles(0)
+	ctx, err := orch.BuildIterationContext(1, PhasePlanning, feature)
+	require.NoError(t, err)
+
+	assert.Equal(t, "# API spec", ctx.TaggedFiles[filepath.Join("docs", "api.md")])
+	assert.NotContains(t, ctx.TaggedFiles, filepath.Join("docs", "drafts", "old.md"))
+	assert.Equal(t, []string{"@docs/**/*.md", "@!drafts"}, ctx.TagPatterns)
+}
+
+func TestToolResultText(t *testing.T) {
+	assert.Equal(t, "ok\tpkg\t0.01s", toolResultText("ok\tpkg\t0.01s"))
+	assert.Equal(t, "first\nsecond", toolResultText([]any{
+		map[string]any{"type": "text", "text": "first"},
+		map[string]any{"type": "image"},
+		map[string]any{"type": "text", "text": "second"},
+	}))
+	assert.Equal(t, "", toolResultText(nil))
+}
+
+func TestIsTestCommand(t *testing.T) {
+	orch := New(t.TempDir())
+
+	assert.True(t, orch.isTestCommand("go test ./..."))
+	assert.False(t, orch.isTestCommand("./scripts/check.sh"))
+
+	orch.testCommand = "./scripts/check.sh"
+	assert.True(t, orch.isTestCommand("cd app && ./scripts/check.sh -v"))
+}
+
+func TestRunTestCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	output := "--- FAIL: TestParse (0.00s)\n    parse_test.go:12: unexpected EOF\nFAIL\nFAIL\texample.com/app\t0.01s\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "out.txt"), []byte(output), 0644))
+
+	orch := New(tmpDir)
+	orch.currentEntry = NewProgressEntryBuilder(1)
+
+	var received *TestRun
+	orch.OnTestResult(func(run *TestRun) {
+		received = run
+	})
+
+	run, err := orch.RunTestCommand(context.Background(), "cat out.txt; exit 1")
+	require.NoError(t, err)
+
+	assert.Same(t, run, received)
+	assert.False(t, run.Passed)
+	assert.Equal(t, output, run.Output)
+	require.NotNil(t, run.Report)
+	assert.Equal(t, testresult.FormatGo, run.Report.Format)
+	require.Len(t, run.Report.Failures, 1)
+	assert.Equal(t, "TestParse", run.Report.Failures[0].Name)
+	assert.Equal(t, "example.com/app", run.Report.Failures[0].Package)
+
+	assert.Equal(t, "cat out.txt; exit 1", orch.currentEntry.Testing.Command)
+	assert.False(t, orch.currentEntry.Testing.Passed)
+	assert.Equal(t, run.Report, orch.currentEntry.Testing.Details)
+}
+
+func TestRunTestCommandPassing(t *testing.T) {
+	orch := New(t.TempDir())
+
+	run, err := orch.RunTestCommand(context.Background(), "printf 'ok  \\texample.com/app\\t0.01s\\n'")
+	require.NoError(t, err)
+
+	assert.True(t, run.Passed)
+	require.NotNil(t, run.Report)
+	assert.Equal(t, 1, run.Report.Passed)
+}
+
+func TestRecordTestRunFailingReportOverridesExitCode(t *testing.T) {
+	orch := New(t.TempDir())
+
+	var lines []string
+	orch.OnTypedOutput(func(outputType OutputType, content string) {
+		lines = append(lines, content)
+	})
+
+	// Some runners exit 0 even when tests fail
+	run := &TestRun{
+		Command: "npx jest",
+		Passed:  true,
+		Output:  "FAIL src/a.test.js\n  ● adds\n\n    boom\n\nTests:       1 failed, 2 passed, 3 total\n",
+	}
+	orch.recordTestRun(run)
+
+	assert.False(t, run.Passed)
+	assert.Contains(t, lines, "Tests: 2 passed, 1 failed")
+	assert.Contains(t, lines, "  FAIL src/a.test.js: adds")
+}
+
+// flakyTestScript fails TestSync on its first run only, and always fails
+// TestParse when broken is set
+func flakyTestScript(t *testing.T, dir string, broken bool) string {
+	script := `#!/bin/sh
+if [ ! -f ran ]; then
+	touch ran
+	echo "--- FAIL: TestSync (0.50s)"
+	echo "    sync_test.go:20: timeout"
+	sync=fail
+fi
+if [ "$1" = broken ]; then
+	echo "--- FAIL: TestParse (0.00s)"
+	echo "    parse_test.go:12: unexpected EOF"
+	parse=fail
+fi
+if [ -n "$sync$parse" ]; then
+	echo "FAIL"
+	echo "FAIL	example.com/app	0.51s"
+	exit 1
+fi
+echo "ok  	example.com/app	0.51s"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.sh"), []byte(script), 0755))
+	if broken {
+		return "./test.sh broken"
+	}
+	return "./test.sh"
+}
+
+func TestRunTestGateClassifiesFlakes(t *testing.T) {
+	tmpDir := t.TempDir()
+	command := flakyTestScript(t, tmpDir, true)
+
+	gate, err := New(tmpDir).RunTestGate(context.Background(), command, 2)
+	require.NoError(t, err)
+
+	assert.False(t, gate.Run.Passed)
+	assert.Equal(t, 2, gate.Reruns)
+	require.Len(t, gate.Flaky, 1)
+	assert.Equal(t, "TestSync", gate.Flaky[0].Name)
+	require.Len(t, gate.Failing, 1)
+	assert.Equal(t, "TestParse", gate.Failing[0].Name)
+}
+
+func TestRunTestGateStopsRerunningWhenAllFlaky(t *testing.T) {
+	tmpDir := t.TempDir()
+	command := flakyTestScript(t, tmpDir, false)
+
+	gate, err := New(tmpDir).RunTestGate(context.Background(), command, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, gate.Reruns)
+	assert.Len(t, gate.Flaky, 1)
+	assert.Empty(t, gate.Failing)
+}
+
+func TestRunTestGateWithoutReruns(t *testing.T) {
+	tmpDir := t.TempDir()
+	command := flakyTestScript(t, tmpDir, false)
+
+	gate, err := New(tmpDir).RunTestGate(context.Background(), command, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, gate.Reruns)
+	assert.Empty(t, gate.Flaky)
+	assert.Len(t, gate.Failing, 1)
+}
+
+func TestCheckTestGateAttemptBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	orch := New(tmpDir)
+	config := BuildConfig{FlakeReruns: 1, MaxFeatureAttempts: 2}
+	history := flakes.New(tmpDir)
+	attempts := make(map[string]int)
+	before := &prd.PRD{
+		Name:        "Test",
+		Description: "Test",
+		Features: []prd.Feature{
+			{ID: "feat-001", Category: prd.CategoryFunctional, Priority: prd.PriorityHigh, Description: "First", Steps: []string{"Step"}},
+			{ID: "feat-002", Category: prd.CategoryFunctional, Priority: prd.PriorityHigh, Description: "Second", Steps: []string{"Step"}},
+		},
+	}
+	require.NoError(t, prd.SaveToDir(before, tmpDir))
+
+	// A flake alone doesn't use an attempt, but is remembered
+	_, err := orch.checkTestGate(context.Background(), config, before, flakyTestScript(t, tmpDir, false), "feat-001", history, attempts)
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempts["feat-001"])
+
+	loaded, err := flakes.Load(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, loaded.Flaky(), 1)
+
+	// A known flake that fails every rerun in this gate counts
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "ran")))
+	config.FlakeReruns = 0
+	_, err = orch.checkTestGate(context.Background(), config, before, flakyTestScript(t, tmpDir, false), "feat-001", history, attempts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts["feat-001"])
+
+	// Features accepted in the iteration are reopened and charged instead
+	accepted, err := prd.Load(orch.PRDPath())
+	require.NoError(t, err)
+	accepted.Features[1].SetStatus(prd.StatusPassing, time.Now().UTC())
+	require.NoError(t, prd.Save(accepted, orch.PRDPath()))
+
+	_, err = orch.checkTestGate(context.Background(), config, before, flakyTestScript(t, tmpDir, true), "feat-001", history, attempts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts["feat-001"])
+	assert.Equal(t, 1, attempts["feat-002"])
+	reopened, err := prd.Load(orch.PRDPath())
+	require.NoError(t, err)
+	assert.Equal(t, prd.StatusInProgress, reopened.Features[1].Status)
+
+	// Until the budget runs out and the feature is stuck
+	_, err = orch.checkTestGate(context.Background(), config, before, flakyTestScript(t, tmpDir, true), "feat-001", history, attempts)
+	assert.ErrorContains(t, err, "feature feat-001 failed the test gate 2 times")
+	stuck, err := prd.Load(orch.PRDPath())
+	require.NoError(t, err)
+	assert.Equal(t, prd.StatusStuck, stuck.Features[0].Status)
+}
+
+func TestGateResultCountsAgainstBudget(t *testing.T) {
+	assert.False(t, (&GateResult{Run: &TestRun{Passed: true}}).CountsAgainstBudget())
+	assert.True(t, (&GateResult{Run: &TestRun{}}).CountsAgainstBudget(), "unparsed failures count")
+	assert.False(t, (&GateResult{
+		Run:   &TestRun{},
+		Flaky: []testresult.Failure{{Name: "TestSync"}},
+	}).CountsAgainstBudget())
+	assert.True(t, (&GateResult{
+		Run:     &TestRun{},
+		Failing: []testresult.Failure{{Name: "TestParse"}},
+	}).CountsAgainstBudget())
+	assert.True(t, (&GateResult{
+		Run:     &TestRun{},
+		Flaky:   []testresult.Failure{{Name: "TestSync"}},
+		Failing: []testresult.Failure{{Name: "TestParse"}},
+	}).CountsAgainstBudget())
+}
+
+func TestAcceptFeatures(t *testing.T) {
+	tmpDir := t.TempDir()
+	before := &prd.PRD{
+		Name: "Test",
+		Features: []prd.Feature{
+			{ID: "feat-001", Steps: []string{"Step"}, Passes: true, Verify: &prd.Check{Command: "false"}},
+			{ID: "feat-002", Steps: []string{"Step"}, Verify: &prd.Check{Command: "true"}},
+			{ID: "feat-003", Steps: []string{"Step"}, Checks: []prd.Check{{Step: 1, Command: "exit 1"}}},
+			{ID: "feat-004", Steps: []string{"Step"}},
+		},
+	}
+
+	// The agent marks everything as passing
+	after := *before
+	after.Features = append([]prd.Feature{}, before.Features...)
+	for i := range after.Features {
+		after.Features[i].Passes = true
+	}
+	require.NoError(t, prd.SaveToDir(&after, tmpDir))
+
+	orch := New(tmpDir)
+	attempts := make(map[string]int)
+	err := orch.acceptFeatures(context.Background(), BuildConfig{MaxFeatureAttempts: 2}, before, attempts)
+	require.NoError(t, err)
+
+	saved, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, saved.GetFeature("feat-001").Passes, "features that already passed aren't rechecked")
+	assert.True(t, saved.GetFeature("feat-002").Passes)
+	assert.False(t, saved.GetFeature("feat-003").Passes, "failing checks reopen the feature")
+	assert.Equal(t, prd.StatusInProgress, saved.GetFeature("feat-003").Status)
+	assert.True(t, saved.GetFeature("feat-004").Passes, "features without checks are accepted")
+	assert.NotNil(t, saved.GetFeature("feat-004").CompletedAt)
+	assert.Equal(t, map[string]int{"feat-003": 1}, attempts)
+
+	// A second failure uses up the budget
+	require.NoError(t, prd.SaveToDir(&after, tmpDir))
+	err = orch.acceptFeatures(context.Background(), BuildConfig{MaxFeatureAttempts: 2}, before, attempts)
+	assert.ErrorContains(t, err, "feature feat-003 failed its acceptance checks 2 times")
+	saved, err = prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, prd.StatusStuck, saved.GetFeature("feat-003").Status, "later builds skip it until it's reopened")
+}
+
+func TestBlockFeature(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, prd.SaveToDir(&prd.PRD{
+		Name:        "Test",
+		Description: "Test",
+		TestCommand: "true",
+		Features: []prd.Feature{
+			{ID: "feat-001", Category: prd.CategoryFunctional, Priority: prd.PriorityHigh, Description: "First", Steps: []string{"Step"}},
+			{ID: "feat-002", Category: prd.CategoryFunctional, Priority: prd.PriorityHigh, Description: "Second", Steps: []string{"Step"}, Passes: true},
+		},
+	}, tmpDir))
+
+	orch := New(tmpDir)
+	require.NoError(t, orch.blockFeature(BlockedFeature{FeatureID: "feat-001", Reason: "Needs API keys"}))
+	require.NoError(t, orch.blockFeature(BlockedFeature{FeatureID: "feat-002", Reason: "Too late"}))
+	require.NoError(t, orch.blockFeature(BlockedFeature{FeatureID: "feat-099"}))
+
+	saved, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "blocked: Needs API keys", saved.GetFeature("feat-001").StatusSummary())
+	assert.True(t, saved.GetFeature("feat-002").Passes, "passing features stay passing")
+	assert.Nil(t, saved.NextFeature())
+}
+
+func TestCoverageCommand(t *testing.T) {
+	orch := New(t.TempDir())
+
+	command, profilePath := orch.coverageCommand(&prd.PRD{TestCommand: "go test ./..."})
+	assert.Equal(t, "go test ./...", command)
+	assert.Empty(t, profilePath, "the coverage gate is off without a coverage spec")
+
+	command, profilePath = orch.coverageCommand(&prd.PRD{TestCommand: "go test ./...", Coverage: &prd.CoverageSpec{}})
+	assert.Equal(t, "go test ./... -coverprofile="+coverage.DefaultProfile, command)
+	assert.Equal(t, coverage.DefaultProfile, profilePath)
+
+	command, profilePath = orch.coverageCommand(&prd.PRD{TestCommand: "npm test", Coverage: &prd.CoverageSpec{Profile: "coverage/lcov.info"}})
+	assert.Equal(t, "npm test", command)
+	assert.Equal(t, "coverage/lcov.info", profilePath)
+}
+
+func TestCheckCoverage(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProfile := func(covered int) {
+		profile := fmt.Sprintf("mode: set\nexample.com/app/app.go:1.1,2.2 %d 1\nexample.com/app/app.go:3.1,4.2 %d 0\n", covered, 10-covered)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "cover.out"), []byte(profile), 0644))
+	}
+
+	before := &prd.PRD{
+		Name:     "Test",
+		Coverage: &prd.CoverageSpec{Profile: "cover.out", Threshold: 5},
+		Features: []prd.Feature{
+			{ID: "feat-001", Steps: []string{"Step"}},
+			{ID: "feat-002", Steps: []string{"Step"}},
+		},
+	}
+	accept := func(ids ...string) {
+		after := *before
+		after.Features = append([]prd.Feature{}, before.Features...)
+		for i := range after.Features {
+			for _, id := range ids {
+				if after.Features[i].ID == id {
+					after.Features[i].Passes = true
+				}
+			}
+		}
+		require.NoError(t, prd.SaveToDir(&after, tmpDir))
+	}
+
+	orch := New(tmpDir)
+	var results []*coverage.Comparison
+	orch.OnCoverage(func(c *coverage.Comparison) { results = append(results, c) })
+	config := BuildConfig{MaxFeatureAttempts: 2}
+	attempts := make(map[string]int)
+
+	// The first run records a baseline
+	writeProfile(8)
+	accept("feat-001")
+	rejected, err := orch.checkCoverage(config, before, "cover.out", "feat-001", attempts)
+	require.NoError(t, err)
+	assert.False(t, rejected)
+	baseline, err := coverage.LoadBaseline(tmpDir)
+	require.NoError(t, err)
+	require.NotNil(t, baseline)
+	assert.Equal(t, "feat-001", baseline.FeatureID)
+
+	// A drop within the threshold is accepted
+	writeProfile(8)
+	rejected, err = orch.checkCoverage(config, before, "cover.out", "feat-002", attempts)
+	require.NoError(t, err)
+	assert.False(t, rejected)
+	require.Len(t, results, 2)
+	assert.False(t, results[1].Rejected())
+
+	// A larger drop reopens the accepted feature
+	before.Features[0].Passes = true
+	writeProfile(5)
+	accept("feat-001", "feat-002")
+	rejected, err = orch.checkCoverage(config, before, "cover.out", "feat-002", attempts)
+	require.NoError(t, err)
+	assert.True(t, rejected)
+	assert.True(t, results[2].Rejected())
+
+	saved, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, saved.GetFeature("feat-001").Passes)
+	assert.False(t, saved.GetFeature("feat-002").Passes)
+	assert.Equal(t, map[string]int{"feat-002": 1}, attempts)
+
+	baseline, err = coverage.LoadBaseline(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, baseline.Profile.Percent(), "a rejected run doesn't replace the baseline")
+
+	// A second rejection uses up the budget, even without newly accepted features
+	accept("feat-001")
+	_, err = orch.checkCoverage(config, before, "cover.out", "feat-002", attempts)
+	assert.ErrorContains(t, err, "feature feat-002 failed the coverage gate 2 times")
+}
+
+func TestAcceptFeaturesBenchmark(t *testing.T) {
+	tmpDir := t.TempDir()
+	setNsPerOp := func(ns int) {
+		output := fmt.Sprintf("BenchmarkParse-8   1000   %d ns/op\nPASS\n", ns)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bench.txt"), []byte(output), 0644))
+	}
+
+	feature := prd.Feature{
+		ID:        "feat-001",
+		Category:  prd.CategoryPerformance,
+		Steps:     []string{"Step"},
+		Benchmark: &prd.BenchmarkSpec{Command: "cat bench.txt", Improvement: 20, Runs: 1},
+	}
+	before := &prd.PRD{Name: "Test", Features: []prd.Feature{feature}}
+	after := &prd.PRD{Name: "Test", Features: []prd.Feature{feature}}
+	after.Features[0].Passes = true
+
+	orch := New(tmpDir)
+	orch.currentEntry = NewProgressEntryBuilder(1)
+	config := BuildConfig{MaxFeatureAttempts: 3}
+	attempts := make(map[string]int)
+
+	// A baseline that can't be measured yet is retried
+	orch.recordBenchmarkBaseline(context.Background(), &before.Features[0])
+	assert.Nil(t, orch.benchBaselines["feat-001"])
+
+	setNsPerOp(1000)
+	orch.recordBenchmarkBaseline(context.Background(), &before.Features[0])
+	require.NotNil(t, orch.benchBaselines["feat-001"])
+
+	// Only 10% faster: reopened
+	setNsPerOp(900)
+	require.NoError(t, prd.SaveToDir(after, tmpDir))
+	require.NoError(t, orch.acceptFeatures(context.Background(), config, before, attempts))
+	saved, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, saved.GetFeature("feat-001").Passes)
+	assert.Equal(t, 1, attempts["feat-001"])
+
+	// The baseline isn't retaken on later iterations
+	orch.recordBenchmarkBaseline(context.Background(), &before.Features[0])
+
+	// 25% faster: accepted
+	setNsPerOp(750)
+	require.NoError(t, prd.SaveToDir(after, tmpDir))
+	require.NoError(t, orch.acceptFeatures(context.Background(), config, before, attempts))
+	saved, err = prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, saved.GetFeature("feat-001").Passes)
+
+	require.Len(t, orch.currentEntry.Benchmarks, 2)
+	assert.False(t, orch.currentEntry.Benchmarks[0].Met())
+	assert.True(t, orch.currentEntry.Benchmarks[1].Met())
+
+	history, err := bench.LoadHistory(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	// Running out of attempts names the benchmark, not the checks
+	setNsPerOp(900)
+	require.NoError(t, prd.SaveToDir(after, tmpDir))
+	err = orch.acceptFeatures(context.Background(), BuildConfig{MaxFeatureAttempts: 2}, before, attempts)
+	assert.EqualError(t, err, "feature feat-001 failed its benchmark 2 times")
+}
+
+func TestCheckTestIntegrity(t *testing.T) {
+	tmpDir := t.TempDir()
+	before := &prd.PRD{Name: "Test", Features: []prd.Feature{{ID: "feat-001", Steps: []string{"Step"}}}}
+	after := &prd.PRD{Name: "Test", Features: []prd.Feature{{ID: "feat-001", Steps: []string{"Step"}, Passes: true}}}
+	require.NoError(t, prd.SaveToDir(after, tmpDir))
+
+	skipped := tamper.Input{Diff: `diff --git a/a_test.go b/a_test.go
+--- a/a_test.go
++++ b/a_test.go
+@@ -3,0 +4 @@
++	t.Skip("later")
+`}
+
+	orch := New(tmpDir)
+	orch.currentEntry = NewProgressEntryBuilder(1)
+	attempts := make(map[string]int)
+
+	// Nothing suspicious
+	rejected, err := orch.checkTestIntegrity(BuildConfig{TamperPolicy: tamper.PolicyReject, MaxFeatureAttempts: 3}, before, 1, "feat-001", "", tamper.Input{}, attempts)
+	require.NoError(t, err)
+	assert.Empty(t, rejected)
+	assert.Nil(t, orch.currentEntry.Integrity)
+
+	// Warn only flags the iteration
+	rejected, err = orch.checkTestIntegrity(BuildConfig{TamperPolicy: tamper.PolicyWarn, MaxFeatureAttempts: 3}, before, 1, "feat-001", "abc123", skipped, attempts)
+	require.NoError(t, err)
+	assert.Empty(t, rejected)
+	require.NotNil(t, orch.currentEntry.Integrity)
+	assert.Equal(t, []string{`skip added: a_test.go: t.Skip("later")`}, orch.currentEntry.Integrity.Findings)
+	assert.Empty(t, attempts)
+
+	saved, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, saved.GetFeature("feat-001").Passes)
+
+	// Reject reopens the feature and charges an attempt
+	rejected, err = orch.checkTestIntegrity(BuildConfig{TamperPolicy: tamper.PolicyReject, MaxFeatureAttempts: 3}, before, 2, "feat-001", "abc123", skipped, attempts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`skip added: a_test.go: t.Skip("later")`}, rejected)
+	assert.True(t, orch.currentEntry.Integrity.Rejected)
+	assert.Equal(t, map[string]int{"feat-001": 1}, attempts)
+
+	saved, err = prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, saved.GetFeature("feat-001").Passes)
+
+	reviews, err := tamper.LoadReviews(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, reviews, 2)
+	assert.False(t, reviews[0].Rejected)
+	assert.True(t, reviews[1].Rejected)
+	assert.Equal(t, "abc123", reviews[1].BaseCommit)
+}
+
+// initTestRepo creates a git repository on branch main that commits unsigned
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	require.NoError(t, git.Init(tmpDir))
+	for _, args := range [][]string{
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"config", "commit.gpgsign", "false"},
+		{"checkout", "-b", "main"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		require.NoError(t, cmd.Run())
+	}
+	return tmpDir
+}
+
+func TestCommitIteration(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	gitLog := func() string {
+		cmd := exec.Command("git", "log", "-1", "--format=%B")
+		cmd.Dir = tmpDir
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		return string(output)
+	}
+
+	before := &prd.PRD{Name: "Test", Features: []prd.Feature{
+		{ID: "feat-001", Category: prd.CategoryUI, Description: "Dark mode toggle", Steps: []string{"Step"}},
+		{ID: "feat-002", Category: prd.CategoryFunctional, Description: "Export data", Steps: []string{"Step"}},
+	}}
+	require.NoError(t, prd.SaveToDir(before, tmpDir))
+
+	orch := New(tmpDir)
+	orch.currentEntry = NewProgressEntryBuilder(1)
+	config := DefaultBuildConfig()
+
+	// Work without an accepted feature is committed as in progress
+	require.NoError(t, orch.commitIteration(config, before, &before.Features[1], 1))
+	msg := gitLog()
+	assert.True(t, strings.HasPrefix(msg, "feat: export data\n"), msg)
+	assert.Contains(t, msg, "Feature-Id: feat-002\nSuperRalph-Status: in-progress\nSuperRalph-Iteration: 1\nSuperRalph-Run: "+orch.session.ID)
+
+	// Accepted features are named instead of the current feature
+	after := *before
+	after.Features = append([]prd.Feature{}, before.Features...)
+	after.Features[0].Passes = true
+	require.NoError(t, prd.SaveToDir(&after, tmpDir))
+	require.NoError(t, orch.commitIteration(config, before, &before.Features[1], 2))
+	msg = gitLog()
+	assert.True(t, strings.HasPrefix(msg, "feat(ui): dark mode toggle\n"), msg)
+	assert.Contains(t, msg, "Feature-Id: feat-001\nSuperRalph-Status: passing\nSuperRalph-Iteration: 2\n")
+
+	require.Len(t, orch.currentEntry.Commits, 2)
+	assert.Equal(t, "feat(ui): dark mode toggle", orch.currentEntry.Commits[1].Message)
+
+	// Nothing changed, nothing committed
+	require.NoError(t, orch.commitIteration(config, before, &before.Features[1], 3))
+	assert.Len(t, orch.currentEntry.Commits, 2)
+}
+
+func TestRunRecording(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	before := &prd.PRD{Name: "Test", Features: []prd.Feature{
+		{ID: "feat-001", Category: prd.CategoryFunctional, Description: "Export data", Steps: []string{"Step"}},
+	}}
+	require.NoError(t, prd.SaveToDir(before, tmpDir))
+
+	orch := New(tmpDir)
+	orch.startRun()
+	it := orch.startIteration(1, "feat-001", "")
+	orch.lastCost = 0.75
+	orch.recordGate("Tests", true, "")
+
+	after := *before
+	after.Features = append([]prd.Feature{}, before.Features...)
+	after.Features[0].Passes = true
+	require.NoError(t, prd.SaveToDir(&after, tmpDir))
+	orch.finishIteration(it, before, false)
+
+	saved, err := runs.Load(tmpDir, orch.session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, runs.OutcomeRunning, saved.Outcome, "saved after each iteration")
+	require.Len(t, saved.Iterations, 1)
+	assert.InDelta(t, 0.75, saved.Iterations[0].CostUSD, 0.001)
+	assert.Equal(t, []string{"feat-001"}, saved.Iterations[0].Accepted)
+	require.Len(t, saved.Iterations[0].Gates, 1)
+
+	orch.finishRun(nil)
+	saved, err = runs.Load(tmpDir, orch.session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, runs.OutcomeComplete, saved.Outcome)
+	assert.False(t, saved.Finished.IsZero())
+
+	// A resumed run continues the same record
+	orch.startRun()
+	orch.startIteration(2, "feat-001", "")
+	orch.finishRun(context.Canceled)
+	saved, err = runs.Load(tmpDir, orch.session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, runs.OutcomeInterrupted, saved.Outcome)
+	require.Len(t, saved.Iterations, 2)
+	assert.NotZero(t, saved.Iterations[1].Duration, "cut-short iterations still count their time")
+}
+
+// fakeAgent writes a stand-in for the claude CLI that reads the prompt, runs
+// script in the work directory and reports a result
+func fakeAgent(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claude")
+	content := "#!/bin/sh\ncat > /dev/null\n" + script + "\necho '{\"type\":\"result\",\"result\":\"Done\"}'\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0755))
+	return path
+}
+
+// buildTestPRD returns a PRD with one feature the fake agent can finish, and
+// saves a copy with it passing to done
+func buildTestPRD(t *testing.T, dir string) (done string) {
+	t.Helper()
+	p := &prd.PRD{
+		Name:        "Shop",
+		Description: "An online shop",
+		TestCommand: "echo '==== 3 passed in 0.01s ===='",
+		Features: []prd.Feature{
+			{ID: "feat-001", Category: prd.CategoryFunctional, Priority: prd.PriorityHigh, Description: "Customers can add items to a cart", Steps: []string{"Verify the cart"}},
+		},
+	}
+	require.NoError(t, prd.SaveToDir(p, dir))
+	p.Features[0].Passes = true
+	done = filepath.Join(t.TempDir(), "prd.json")
+	require.NoError(t, prd.Save(p, done))
+	return done
+}
+
+func TestBuildWritesProgressEntry(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	done := buildTestPRD(t, tmpDir)
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	orch := New(tmpDir)
+	orch.claudePath = fakeAgent(t, "cp "+done+" prd.json\necho 'package shop' > cart.go")
+	config := DefaultBuildConfig()
+	config.MaxIterations = 1
+	config.DelayBetweenIterations = 0
+	require.NoError(t, orch.RunBuildWithConfig(context.Background(), config))
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "progress.txt"))
+	require.NoError(t, err)
+	entries := progress.ParseEntries(string(content))
+	require.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].Iteration)
+	assert.Contains(t, entries[0].Section("Starting State"), `Working on: feat-001 "Customers can add items to a cart"`)
+	assert.Equal(t, []string{
+		"Test command: echo '==== 3 passed in 0.01s ===='",
+		"Result: PASSED",
+		"Details: 3 passed",
+	}, entries[0].Section("Testing"))
+	assert.Contains(t, entries[0].Section("Ending State"), "Features passing: 1/1")
+}
+
+func TestBuildRecordsCommitsInProgress(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	done := buildTestPRD(t, tmpDir)
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	orch := New(tmpDir)
+	orch.claudePath = fakeAgent(t, "cp "+done+" prd.json\necho 'package shop' > cart.go")
+	config := DefaultBuildConfig()
+	config.MaxIterations = 1
+	config.DelayBetweenIterations = 0
+	require.NoError(t, orch.RunBuildWithConfig(context.Background(), config))
+
+	commits, err := git.Log(tmpDir, git.LogOptions{Max: 1})
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	head := commits[0]
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "progress.txt"))
+	require.NoError(t, err)
+	entries := progress.ParseEntries(string(content))
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{shortHash(head.Hash) + ": " + head.Subject}, entries[0].Section("Commits"))
+
+	// Reset finds the feature's commits through the entry
+	plan, err := reset.New(tmpDir, "feat-001", reset.Options{Revert: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Revert, 1)
+	assert.Equal(t, head.Hash, plan.Revert[0].Hash)
+}
+
+func TestBuildRejectsFocusInNewTestFile(t *testing.T) {
+	tmpDir := initTestRepo(t)
+	done := buildTestPRD(t, tmpDir)
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	// The agent focuses a test in a new, uncommitted spec file, then removes
+	// it once told the iteration was rejected
+	prompts := t.TempDir()
+	agent := filepath.Join(t.TempDir(), "claude")
+	script := fmt.Sprintf(`#!/bin/sh
+n=$(ls %[1]s | wc -l | tr -d ' ')
+cat > %[1]s/$n.txt
+cp %[2]s prd.json
+if [ "$n" = 0 ]; then
+  echo "it.only('adds items', () => {})" > cart.spec.js
+else
+  rm cart.spec.js
+fi
+echo '{"type":"result","result":"Done"}'
+`, prompts, done)
+	require.NoError(t, os.WriteFile(agent, []byte(script), 0755))
+
+	orch := New(tmpDir)
+	orch.claudePath = agent
+	config := DefaultBuildConfig()
+	config.MaxIterations = 2
+	config.DelayBetweenIterations = 0
+	config.TamperPolicy = tamper.PolicyReject
+	require.NoError(t, orch.RunBuildWithConfig(context.Background(), config))
+
+	reviews, err := tamper.LoadReviews(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.True(t, reviews[0].Rejected)
+	finding := "focus added: cart.spec.js: it.only('adds items', () => {})"
+	assert.Equal(t, []string{finding}, reviews[0].Findings)
+
+	first, err := os.ReadFile(filepath.Join(prompts, "0.txt"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(first), "## Rejected Changes")
+	second, err := os.ReadFile(filepath.Join(prompts, "1.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(second), "## Rejected Changes")
+	assert.Contains(t, string(second), "- "+finding+"\n")
+
+	final, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.True(t, final.GetFeature("feat-001").Passes, "accepted once the focus was removed")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "cart.spec.js"))
+}
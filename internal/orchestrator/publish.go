@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/commitmsg"
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/publish"
+)
+
+// PublishConfig configures pushing finished features and opening pull
+// requests for them
+type PublishConfig struct {
+	Remote     string           // Remote to push to (default: origin)
+	Base       string           // Pull request base (default: the integration branch, or the current branch)
+	Provider   publish.Provider // Opens pull requests; nil only pushes
+	Draft      bool             // Open pull requests as drafts
+	Retries    int              // Attempts per push or request (default: 3)
+	RetryDelay time.Duration    // Delay before the first retry, doubled after each (default: 5s)
+}
+
+// recordGate notes a gate's outcome for the current iteration
+func (o *Orchestrator) recordGate(name string, passed bool, detail string) {
+	o.gates = append(o.gates, publish.Gate{Name: name, Passed: passed, Detail: detail})
+}
+
+// publishFeatures pushes the current branch once the iteration finished
+// features, then opens a pull request for them. Failures are retried,
+// reported and recorded in the publication log, but don't stop the build.
+// Returns the pull request, or nil if none was opened.
+func (o *Orchestrator) publishFeatures(ctx context.Context, config BuildConfig, branches *BranchPolicy, before *prd.PRD) *publish.Result {
+	accepted, err := o.newlyAccepted(before)
+	if err != nil {
+		o.debugLog("Failed to find finished features: %v", err)
+		return nil
+	}
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	branch, err := git.CurrentBranch(o.workDir)
+	if err != nil {
+		o.typedOutput(OutputError, fmt.Sprintf("Not publishing: %v", err))
+		return nil
+	}
+
+	pc := *config.Publish
+	if pc.Remote == "" {
+		pc.Remote = "origin"
+	}
+	if pc.Base == "" {
+		pc.Base = branch
+		if branches != nil {
+			pc.Base = branches.Integration
+		}
+	}
+	if pc.Retries <= 0 {
+		pc.Retries = 3
+	}
+	if pc.RetryDelay <= 0 {
+		pc.RetryDelay = 5 * time.Second
+	}
+	record := publish.Record{FeatureIDs: accepted, Branch: branch, Remote: pc.Remote}
+	defer func() {
+		record.Recorded = time.Now().UTC()
+		if err := publish.AppendRecord(o.workDir, record); err != nil {
+			o.debugLog("Failed to save publication log: %v", err)
+		}
+	}()
+
+	o.activity(fmt.Sprintf("Pushing %s...", branch))
+	n, err := publish.Retry(ctx, pc.Retries, pc.RetryDelay, func() error {
+		return git.Push(o.workDir, pc.Remote, branch)
+	})
+	record.Attempts += n
+	if err != nil {
+		record.Error = fmt.Sprintf("push failed: %v", err)
+		o.typedOutput(OutputError, fmt.Sprintf("Failed to push %s after %d attempt(s): %v", branch, n, err))
+		return nil
+	}
+	record.Pushed = true
+	o.typedOutput(OutputSuccess, fmt.Sprintf("Pushed %s to %s", branch, pc.Remote))
+
+	if pc.Provider == nil {
+		return nil
+	}
+	if branch == pc.Base {
+		o.typedOutput(OutputInfo, fmt.Sprintf("Not opening a pull request: %s is the base branch", branch))
+		return nil
+	}
+
+	after, err := prd.Load(o.PRDPath())
+	if err != nil {
+		record.Error = fmt.Sprintf("failed to load %s: %v", filepath.Base(o.PRDPath()), err)
+		o.typedOutput(OutputError, record.Error)
+		return nil
+	}
+	var features []*prd.Feature
+	for _, id := range accepted {
+		features = append(features, after.GetFeature(id))
+	}
+	pr := publish.PullRequest{
+		Title: commitmsg.New(commitmsg.Params{Features: features, Complete: true}).Header(),
+		Body:  o.pullRequestBody(features, pc),
+		Head:  branch,
+		Base:  pc.Base,
+		Draft: pc.Draft,
+	}
+
+	o.activity(fmt.Sprintf("Opening pull request for %s...", branch))
+	var result *publish.Result
+	n, err = publish.Retry(ctx, pc.Retries, pc.RetryDelay, func() error {
+		var err error
+		result, err = pc.Provider.CreatePullRequest(ctx, pr)
+		return err
+	})
+	record.Attempts += n
+	if err != nil {
+		record.Error = fmt.Sprintf("%s pull request failed: %v", pc.Provider.Name(), err)
+		o.typedOutput(OutputError, fmt.Sprintf("Failed to open a pull request after %d attempt(s): %v", n, err))
+		return nil
+	}
+	record.Number, record.URL = result.Number, result.URL
+	o.typedOutput(OutputSuccess, fmt.Sprintf("Opened pull request #%d: %s", result.Number, result.URL))
+	o.AddProgressNote(fmt.Sprintf("Pull request #%d: %s", result.Number, result.URL))
+	return result
+}
+
+// pullRequestBody describes the features with the iteration's progress
+// entry, diff stats and gate results
+func (o *Orchestrator) pullRequestBody(features []*prd.Feature, pc PublishConfig) string {
+	in := publish.BodyInput{Features: features, Gates: o.gates}
+	if content, err := progress.Read(progress.GetPath(o.workDir)); err == nil {
+		in.Progress = progress.LastEntry(content)
+	}
+	// Prefer the local base, which feature branches start from
+	for _, base := range []string{pc.Base, pc.Remote + "/" + pc.Base} {
+		if stat, err := git.DiffStat(o.workDir, base, "HEAD"); err == nil {
+			in.DiffStat = stat
+			break
+		}
+	}
+	return publish.Body(in)
+}
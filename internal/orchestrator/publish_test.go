@@ -0,0 +1,166 @@
+package orchestrator
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/publish"
+)
+
+// fakeProvider fails its first failures calls with a server error
+type fakeProvider struct {
+	failures int
+	calls    int
+	opened   []publish.PullRequest
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) CreatePullRequest(ctx context.Context, pr publish.PullRequest) (*publish.Result, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, &publish.StatusError{StatusCode: http.StatusBadGateway}
+	}
+	f.opened = append(f.opened, pr)
+	return &publish.Result{Number: 12, URL: "https://example.com/pull/12"}, nil
+}
+
+func TestPublishFeatures(t *testing.T) {
+	remote := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare")
+	cmd.Dir = remote
+	require.NoError(t, cmd.Run())
+
+	tmpDir := initTestRepo(t)
+	cmd = exec.Command("git", "remote", "add", "origin", remote)
+	cmd.Dir = tmpDir
+	require.NoError(t, cmd.Run())
+
+	before := &prd.PRD{Name: "Test", Features: []prd.Feature{
+		{ID: "feat-001", Category: prd.CategoryFunctional, Description: "Export data", Steps: []string{"Write CSV"}},
+	}}
+	require.NoError(t, prd.SaveToDir(before, tmpDir))
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+
+	orch := New(tmpDir)
+	provider := &fakeProvider{failures: 1}
+	config := DefaultBuildConfig()
+	config.Publish = &PublishConfig{Provider: provider, RetryDelay: 1}
+	policy, err := orch.startFeatureBranches(BranchPolicy{})
+	require.NoError(t, err)
+	_, err = orch.enterFeatureBranch(policy, config, before, &before.Features[0], 1)
+	require.NoError(t, err)
+
+	// Nothing finished, nothing published
+	orch.publishFeatures(context.Background(), config, policy, before)
+	assert.Zero(t, provider.calls)
+
+	after := *before
+	after.Features = []prd.Feature{before.Features[0]}
+	after.Features[0].Passes = true
+	require.NoError(t, prd.SaveToDir(&after, tmpDir))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "export.go"), []byte("package export\n"), 0644))
+	require.NoError(t, orch.commitIteration(config, before, &before.Features[0], 1))
+	orch.gates = nil
+	orch.recordGate("Tests", true, "3 passed")
+
+	result := orch.publishFeatures(context.Background(), config, policy, before)
+	require.NotNil(t, result)
+	require.Len(t, provider.opened, 1)
+	pr := provider.opened[0]
+	assert.Equal(t, "feat: export data", pr.Title)
+	assert.Equal(t, "ralph/feat-001", pr.Head)
+	assert.Equal(t, "main", pr.Base)
+	assert.Contains(t, pr.Body, "- [x] Write CSV")
+	assert.Contains(t, pr.Body, "| Tests | passed | 3 passed |")
+	assert.Contains(t, pr.Body, "export.go")
+
+	cmd = exec.Command("git", "rev-parse", "refs/heads/ralph/feat-001")
+	cmd.Dir = remote
+	assert.NoError(t, cmd.Run(), "feature branch pushed")
+
+	records, err := publish.LoadRecords(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Pushed)
+	assert.Equal(t, 12, records[0].Number)
+	assert.Equal(t, 3, records[0].Attempts, "one push, a failed request and its retry")
+	assert.Empty(t, records[0].Error)
+
+	// Failures are recorded without stopping the build
+	config.Publish = &PublishConfig{Remote: "missing", Provider: provider, Retries: 2, RetryDelay: 1}
+	assert.Nil(t, orch.publishFeatures(context.Background(), config, policy, before))
+	records, err = publish.LoadRecords(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.False(t, records[1].Pushed)
+	assert.Equal(t, 2, records[1].Attempts)
+	assert.Contains(t, records[1].Error, "push failed")
+
+	// The pull request merges the feature, so it isn't merged locally: it
+	// waits for review on the integration branch and its branch is kept
+	require.NoError(t, orch.awaitReview(policy, config, result, 1))
+	branch, err := git.CurrentBranch(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+	assert.True(t, git.BranchExists(tmpDir, "ralph/feat-001"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "export.go"))
+	held, err := prd.LoadFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, prd.StatusNeedsReview, held.GetFeature("feat-001").Status)
+	changed, err := git.HasTrackedChanges(tmpDir)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestPublishFeaturesBaseDefaultsToCurrentBranch(t *testing.T) {
+	remote := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare")
+	cmd.Dir = remote
+	require.NoError(t, cmd.Run())
+
+	tmpDir := initTestRepo(t)
+	cmd = exec.Command("git", "remote", "add", "origin", remote)
+	cmd.Dir = tmpDir
+	require.NoError(t, cmd.Run())
+
+	before := &prd.PRD{Name: "Test", Features: []prd.Feature{
+		{ID: "feat-001", Category: prd.CategoryFunctional, Description: "Export data", Steps: []string{"Write CSV"}},
+	}}
+	require.NoError(t, prd.SaveToDir(before, tmpDir))
+	_, err := git.CommitAll(tmpDir, "init", git.CommitOptions{})
+	require.NoError(t, err)
+	require.NoError(t, git.CreateBranch(tmpDir, "develop", "main"))
+
+	after := *before
+	after.Features = []prd.Feature{before.Features[0]}
+	after.Features[0].Passes = true
+	require.NoError(t, prd.SaveToDir(&after, tmpDir))
+	_, err = git.CommitAll(tmpDir, "feat: export data", git.CommitOptions{})
+	require.NoError(t, err)
+
+	// Without feature branches there is nothing to open a pull request from
+	// unless a base is given
+	orch := New(tmpDir)
+	provider := &fakeProvider{}
+	config := DefaultBuildConfig()
+	config.Publish = &PublishConfig{Provider: provider, RetryDelay: 1}
+	assert.Nil(t, orch.publishFeatures(context.Background(), config, nil, before))
+	assert.Empty(t, provider.opened, "develop is the base")
+
+	config.Publish.Base = "main"
+	require.NotNil(t, orch.publishFeatures(context.Background(), config, nil, before))
+	require.Len(t, provider.opened, 1)
+	assert.Equal(t, "develop", provider.opened[0].Head)
+	assert.Equal(t, "main", provider.opened[0].Base)
+}
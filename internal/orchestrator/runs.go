@@ -0,0 +1,101 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/runs"
+)
+
+// startRun begins recording the build run. A resumed run with the same
+// session ID continues its existing record.
+func (o *Orchestrator) startRun() {
+	if r, err := runs.Load(o.workDir, o.session.ID); err == nil && r.ID == o.session.ID {
+		r.Outcome, r.Error, r.Finished = runs.OutcomeRunning, "", time.Time{}
+		o.run = r
+		return
+	}
+	o.run = runs.New(o.session.ID)
+	o.run.BaseCommit, _ = git.HeadCommit(o.workDir)
+	o.run.Branch, _ = git.CurrentBranch(o.workDir)
+}
+
+// finishRun records how the build run ended
+func (o *Orchestrator) finishRun(err error) {
+	r := o.run
+	if r == nil {
+		return
+	}
+	r.Finished = time.Now().UTC()
+	r.HeadCommit, _ = git.HeadCommit(o.workDir)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		r.Outcome = runs.OutcomeInterrupted
+	case err != nil:
+		r.Outcome, r.Error = runs.OutcomeFailed, err.Error()
+	default:
+		r.Outcome = runs.OutcomeMaxIterations
+		if p, err := prd.Load(o.PRDPath()); err == nil && p.IsComplete() {
+			r.Outcome = runs.OutcomeComplete
+		}
+	}
+	// An iteration cut short still counts its time and whatever it spent
+	if n := len(r.Iterations); n > 0 && r.Iterations[n-1].Duration == 0 {
+		last := r.Iterations[n-1]
+		last.Duration = time.Since(last.Started)
+		last.CostUSD = o.lastCost
+		last.Gates = o.gates
+	}
+	o.saveRun()
+}
+
+// startIteration adds an iteration to the run record
+func (o *Orchestrator) startIteration(number int, featureID, baseCommit string) *runs.Iteration {
+	o.lastCost = 0
+	it := &runs.Iteration{Number: number, FeatureID: featureID, Started: time.Now().UTC(), BaseCommit: baseCommit}
+	if o.run != nil {
+		o.run.Iterations = append(o.run.Iterations, it)
+	}
+	return it
+}
+
+// finishIteration records the iteration's cost, gates and accepted features,
+// and writes its progress entry
+func (o *Orchestrator) finishIteration(it *runs.Iteration, before *prd.PRD, rejected bool) {
+	it.Duration = time.Since(it.Started)
+	it.CostUSD = o.lastCost
+	it.Gates = o.gates
+	it.Rejected = rejected
+	it.HeadCommit, _ = git.HeadCommit(o.workDir)
+	if accepted, err := o.newlyAccepted(before); err == nil {
+		it.Accepted = accepted
+	}
+	o.saveRun()
+
+	if entry := o.currentEntry; entry != nil {
+		after, err := prd.Load(o.PRDPath())
+		if err != nil {
+			after = before
+		}
+		testsPassing := !rejected
+		if entry.Testing.Command != "" {
+			testsPassing = entry.Testing.Passed
+		}
+		if err := o.FinishProgressEntry(after, testsPassing); err != nil {
+			o.typedOutput(OutputError, err.Error())
+		}
+	}
+}
+
+// saveRun writes the run record, so interrupted runs can still be reported
+func (o *Orchestrator) saveRun() {
+	if o.run == nil {
+		return
+	}
+	if err := o.run.Save(o.workDir); err != nil {
+		o.debugLog("Failed to save run: %v", err)
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mpjhorner/superralph/internal/bench"
+	"github.com/mpjhorner/superralph/internal/coverage"
+	"github.com/mpjhorner/superralph/internal/prd"
 	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/testresult"
 )
 
 // Action represents what Claude wants to do next
@@ -176,6 +180,13 @@ type ValidationResult struct {
 	Feedback string   `json:"feedback,omitempty"`
 }
 
+// BlockedFeature is a feature the agent declared blocked in a
+// <feature_blocked> block
+type BlockedFeature struct {
+	FeatureID string `json:"feature"`
+	Reason    string `json:"reason"`
+}
+
 // PlanOutput represents the output from the planning phase
 type PlanOutput struct {
 	Plan  string   `json:"plan"`
@@ -190,7 +201,10 @@ type PhaseConfig struct {
 // IterationContext holds fresh, self-contained context for each Claude iteration.
 // This ensures no conversation history accumulates - each call gets exactly what it needs.
 type IterationContext struct {
-	// PRDContent is the raw prd.json content
+	// PRDFile is the PRD file relative to the work directory, e.g. "prd.json" or "prd.md"
+	PRDFile string `json:"prd_file,omitempty"`
+
+	// PRDContent is the raw content of the PRD file
 	PRDContent string `json:"prd_content"`
 
 	// ProgressContent is the raw progress.txt content
@@ -203,9 +217,17 @@ type IterationContext struct {
 	// e.g., ["@src/**/*.go", "@!vendor", "@main.go"]
 	TagPatterns []string `json:"tag_patterns,omitempty"`
 
+	// RelevantFiles lists the TaggedFiles entries that were selected automatically
+	// by ranking the codebase against the current feature
+	RelevantFiles []string `json:"relevant_files,omitempty"`
+
 	// DirectoryTree is the codebase structure
 	DirectoryTree string `json:"directory_tree,omitempty"`
 
+	// RepoMap is an outline of exported symbols by file, used instead of
+	// DirectoryTree when SnapshotConfig.UseRepoMap is set
+	RepoMap string `json:"repo_map,omitempty"`
+
 	// KeyFiles maps file paths to their contents for automatically detected key files
 	// (e.g., go.mod, package.json, Cargo.toml, README.md, main entry points)
 	KeyFiles map[string]string `json:"key_files,omitempty"`
@@ -227,6 +249,21 @@ type IterationContext struct {
 
 	// ValidationAttempt tracks which validation attempt this is (1-3)
 	ValidationAttempt int `json:"validation_attempt,omitempty"`
+
+	// KnownFlakes lists tests that have passed on a rerun after failing
+	KnownFlakes []string `json:"known_flakes,omitempty"`
+
+	// HarnessCommits tells the agent not to commit; the harness commits
+	// each iteration once its changes pass the gates
+	HarnessCommits bool `json:"harness_commits,omitempty"`
+
+	// SelectionRules tell the agent how the configured strategy picks the next
+	// feature (default: highest priority first, then ID order)
+	SelectionRules []string `json:"selection_rules,omitempty"`
+
+	// RejectedChanges lists the test integrity findings that got the previous
+	// iteration rejected; its edits are still in the working tree
+	RejectedChanges []string `json:"rejected_changes,omitempty"`
 }
 
 // SnapshotConfig holds configuration for codebase snapshots
@@ -239,6 +276,20 @@ type SnapshotConfig struct {
 
 	// IncludeKeyFiles enables automatic inclusion of key files (default: true)
 	IncludeKeyFiles bool `json:"include_key_files,omitempty"`
+
+	// MaxRelevantFiles is how many files ranked by relevance to the current feature
+	// are added to TaggedFiles (default: 5, 0 disables relevance retrieval)
+	MaxRelevantFiles int `json:"max_relevant_files,omitempty"`
+
+	// RelevantFilesBudget is the total size in bytes allowed for relevant files (default: 40KB)
+	RelevantFilesBudget int64 `json:"relevant_files_budget,omitempty"`
+
+	// UseRepoMap replaces the directory tree with a symbol outline of the codebase
+	// when a supported language is found (default: false)
+	UseRepoMap bool `json:"use_repo_map,omitempty"`
+
+	// RepoMapBudget is the maximum size in bytes of the repository map (default: 16KB)
+	RepoMapBudget int `json:"repo_map_budget,omitempty"`
 }
 
 // DefaultSnapshotConfig returns the default snapshot configuration
@@ -247,6 +298,11 @@ func DefaultSnapshotConfig() SnapshotConfig {
 		MaxTreeDepth:     3,         // Reduced from 4 to keep prompts smaller
 		MaxFileSizeBytes: 50 * 1024, // 50KB
 		IncludeKeyFiles:  false,     // Disabled by default - Claude can read files on-demand
+
+		MaxRelevantFiles:    5,
+		RelevantFilesBudget: 40 * 1024, // 40KB
+
+		RepoMapBudget: 16 * 1024, // 16KB
 	}
 }
 
@@ -257,6 +313,41 @@ type FeatureContext struct {
 	Steps       []string `json:"steps"`
 	Priority    string   `json:"priority"`
 	Category    string   `json:"category"`
+
+	// ContextTags are the tags declared in the feature's prd.json context block
+	ContextTags []string `json:"context_tags,omitempty"`
+
+	// Checks describe the executable acceptance checks the harness runs
+	// before accepting the feature as passing
+	Checks []string `json:"checks,omitempty"`
+
+	// Benchmark describes the performance target the feature must meet
+	Benchmark string `json:"benchmark,omitempty"`
+}
+
+// NewFeatureContext creates a FeatureContext from a PRD feature
+func NewFeatureContext(f *prd.Feature) *FeatureContext {
+	if f == nil {
+		return nil
+	}
+	var checks []string
+	for _, c := range f.AllChecks() {
+		checks = append(checks, c.String())
+	}
+	var benchmark string
+	if f.Benchmark != nil {
+		benchmark = f.Benchmark.String()
+	}
+	return &FeatureContext{
+		ID:          f.ID,
+		Description: f.Description,
+		Steps:       f.Steps,
+		Priority:    string(f.Priority),
+		Category:    string(f.Category),
+		ContextTags: f.Context.Tags(),
+		Checks:      checks,
+		Benchmark:   benchmark,
+	}
 }
 
 // maxProgressLines is the maximum number of lines to include from progress.txt
@@ -287,13 +378,13 @@ func (ic *IterationContext) BuildPrompt() string {
 	sb.WriteString("You are implementing features from a PRD. Here is the current state:\n\n")
 
 	// PRD content - include full if small, otherwise summarize
-	sb.WriteString("## prd.json\n")
+	sb.WriteString("## " + ic.prdFile() + "\n")
 	if len(ic.PRDContent) <= maxPRDSize {
 		sb.WriteString(ic.PRDContent)
 	} else {
 		// Large PRD - tell Claude to read it
-		sb.WriteString("[PRD is large - use Read tool to read prd.json for full details]\n")
-		sb.WriteString("Summary: This PRD contains multiple features. Read prd.json to see all features and their status.\n")
+		sb.WriteString("[PRD is large - use Read tool to read " + ic.prdFile() + " for full details]\n")
+		sb.WriteString("Summary: This PRD contains multiple features. Read " + ic.prdFile() + " to see all features and their status.\n")
 	}
 	sb.WriteString("\n\n")
 
@@ -313,6 +404,14 @@ func (ic *IterationContext) BuildPrompt() string {
 		sb.WriteString("\n```\n\n")
 	}
 
+	// Repository map if available (replaces the directory tree)
+	if ic.RepoMap != "" {
+		sb.WriteString("## Repository Map\n")
+		sb.WriteString("Exported symbols by file. Use this to find the right code, then Read only what you need:\n```\n")
+		sb.WriteString(ic.RepoMap)
+		sb.WriteString("```\n\n")
+	}
+
 	// Key files if any (automatically detected project files)
 	// Note: Now disabled by default to keep prompts smaller
 	if len(ic.KeyFiles) > 0 {
@@ -326,11 +425,35 @@ func (ic *IterationContext) BuildPrompt() string {
 	// Tagged files if any
 	if len(ic.TaggedFiles) > 0 {
 		sb.WriteString("## Tagged Files\n")
+		if len(ic.RelevantFiles) > 0 {
+			sb.WriteString(fmt.Sprintf("Selected automatically for relevance to the current feature: %s\n\n",
+				strings.Join(ic.RelevantFiles, ", ")))
+		}
 		for path, content := range ic.TaggedFiles {
 			sb.WriteString(fmt.Sprintf("### %s\n```\n%s\n```\n\n", path, content))
 		}
 	}
 
+	// Known flaky tests, so their failures don't send the agent after unrelated code
+	if len(ic.KnownFlakes) > 0 {
+		sb.WriteString("## Known Flaky Tests\n")
+		sb.WriteString("These tests fail intermittently. If one fails, rerun it before changing code to fix it:\n")
+		for _, name := range ic.KnownFlakes {
+			sb.WriteString(fmt.Sprintf("- %s\n", name))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Rejected edits left in the tree would be flagged again every iteration
+	if len(ic.RejectedChanges) > 0 {
+		sb.WriteString("## Rejected Changes\n")
+		sb.WriteString("The previous iteration was rejected for weakening the tests, and its edits are still in the working tree. Revert these changes before anything else:\n")
+		for _, finding := range ic.RejectedChanges {
+			sb.WriteString(fmt.Sprintf("- %s\n", finding))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Current feature context
 	if ic.CurrentFeature != nil {
 		sb.WriteString("## Current Feature\n")
@@ -340,6 +463,16 @@ func (ic *IterationContext) BuildPrompt() string {
 		for i, step := range ic.CurrentFeature.Steps {
 			sb.WriteString(fmt.Sprintf("  %d. %s\n", i+1, step))
 		}
+		if len(ic.CurrentFeature.Checks) > 0 {
+			sb.WriteString("Acceptance checks (the harness runs these before accepting passes: true; run them yourself first):\n")
+			for _, check := range ic.CurrentFeature.Checks {
+				sb.WriteString(fmt.Sprintf("  - %s\n", check))
+			}
+		}
+		if ic.CurrentFeature.Benchmark != "" {
+			sb.WriteString("Benchmark target (the harness measures this against a baseline taken before you started):\n")
+			sb.WriteString(fmt.Sprintf("  - %s\n", ic.CurrentFeature.Benchmark))
+		}
 		sb.WriteString("\n")
 	}
 
@@ -514,10 +647,11 @@ Execute the validated implementation plan step by step.
 When you've finished implementing the feature:
 
 1. Run the test command to verify all tests pass
-2. Update prd.json to set "passes": true for this feature
-3. Make a git commit with a descriptive message
+2. ` + ic.passingInstruction("this feature") + `
+3. ` + ic.commitInstruction() + `
 4. Append a summary to progress.txt
 
+` + blockedInstruction() + `
 ### Output
 
 As you work, explain what you're doing. When complete, output:
@@ -532,6 +666,39 @@ summary: [Brief summary of what was implemented]
 	return sb.String()
 }
 
+// blockedInstruction tells the agent how to hand back a feature it can't
+// finish without a person, such as one waiting on credentials or a decision
+func blockedInstruction() string {
+	return `### If You're Blocked
+
+If the feature can't be finished without a person (missing credentials, an
+external service that's down, a decision the PRD doesn't make), don't mark it
+passing. Explain why and output:
+
+<feature_blocked>
+feature: [Feature ID]
+reason: [What you need, in one or two sentences]
+</feature_blocked>
+
+The build marks the feature blocked and moves on to other features until
+someone reopens it.
+`
+}
+
+// selectionRules lists how to pick among the features that are ready, one
+// bullet per line
+func (ic *IterationContext) selectionRules() string {
+	rules := ic.SelectionRules
+	if len(rules) == 0 {
+		rules = prd.StrategyPriority.Scheduler().Rules(nil)
+	}
+	var sb strings.Builder
+	for _, rule := range rules {
+		sb.WriteString("- " + rule + "\n")
+	}
+	return sb.String()
+}
+
 // buildDefaultTaskInstructions returns the default task instructions (single-feature mode)
 func (ic *IterationContext) buildDefaultTaskInstructions() string {
 	return `## Your Task - Single Feature Implementation
@@ -542,10 +709,9 @@ This is iteration ` + fmt.Sprintf("%d", ic.Iteration) + `. You will implement ON
 
 Look at the PRD and select the next feature using this logic:
 - Skip features with passes: true (already done)
+- Skip features whose status is blocked, stuck, needs_review or skipped (waiting on a person)
 - Skip features blocked by unmet dependencies (check depends_on field)
-- Pick the highest priority first (high > medium > low)
-- Within same priority, pick first by ID order
-
+` + ic.selectionRules() + `
 Report which feature you selected and WHY.
 
 ### Step 2: Implement the Feature
@@ -563,13 +729,14 @@ Run the tests using the testCommand from the PRD.
 
 ### Step 4: Commit and Update (only if tests pass)
 
-1. Update prd.json to set "passes": true for the completed feature
-2. Make a git commit with a descriptive message
+1. ` + ic.passingInstruction("the completed feature") + `
+2. ` + ic.commitInstruction() + `
 3. Append a summary to progress.txt with:
    - Feature ID and description
    - What was implemented
    - Any notes for future iterations
 
+` + blockedInstruction() + `
 ### Step 5: EXIT
 
 After completing (or failing) this ONE feature, you are DONE.
@@ -589,6 +756,27 @@ The orchestrator will restart you with fresh context for the next iteration.
 This "clean slate" approach ensures each iteration starts fresh without accumulated context.`
 }
 
+// prdFile returns the name of the PRD file, prd.json unless another was found
+func (ic *IterationContext) prdFile() string {
+	if ic.PRDFile == "" {
+		return prd.DefaultFilename
+	}
+	return ic.PRDFile
+}
+
+// passingInstruction tells the agent how to mark a feature as passing in the PRD file
+func (ic *IterationContext) passingInstruction(feature string) string {
+	return fmt.Sprintf("Update %s to %s for %s", ic.prdFile(), prd.FormatOf(ic.prdFile()).PassingInstruction(), feature)
+}
+
+// commitInstruction tells the agent how its work gets committed
+func (ic *IterationContext) commitInstruction() string {
+	if ic.HarnessCommits {
+		return "Do NOT run git commit - the harness verifies your changes and commits them for you"
+	}
+	return "Make a git commit with a descriptive message"
+}
+
 // ResumeState holds the state needed to resume a build after interruption.
 // This is saved to .superralph/state.json when the build is interrupted.
 type ResumeState struct {
@@ -638,6 +826,50 @@ type FileDiff struct {
 	IsNewFile bool `json:"is_new_file,omitempty"`
 }
 
+// TestRun is a single run of the test command, by the agent or the harness
+type TestRun struct {
+	// Command is the shell command that ran the tests
+	Command string `json:"command"`
+
+	// Passed is true if the command succeeded and no tests failed
+	Passed bool `json:"passed"`
+
+	// Output is the combined output of the command
+	Output string `json:"-"`
+
+	// Duration is how long the command took
+	Duration time.Duration `json:"duration"`
+
+	// Report holds the parsed results; nil if the output wasn't recognized
+	Report *testresult.Report `json:"report,omitempty"`
+}
+
+// GateResult is the outcome of the harness's test run after an iteration
+type GateResult struct {
+	// Run is the initial run of the test command
+	Run *TestRun
+
+	// Reruns is how many times the failing tests were rerun
+	Reruns int
+
+	// Flaky lists failures that passed on a rerun
+	Flaky []testresult.Failure
+
+	// Failing lists failures that failed every rerun
+	Failing []testresult.Failure
+}
+
+// CountsAgainstBudget reports whether a failed gate should use up one of the
+// feature's attempts. It doesn't if every failure passed on a rerun in this
+// gate; a test that flaked before but failed every rerun now still counts.
+// Failures that couldn't be parsed always count.
+func (g *GateResult) CountsAgainstBudget() bool {
+	if g.Run.Passed {
+		return false
+	}
+	return len(g.Flaky) == 0 || len(g.Failing) > 0
+}
+
 // ProgressEntryBuilder helps construct progress entries incrementally during an iteration.
 // It accumulates work done, test results, and commits throughout the iteration,
 // then produces a complete progress.Entry when the iteration completes.
@@ -657,6 +889,12 @@ type ProgressEntryBuilder struct {
 	// Testing holds the most recent test result
 	Testing progress.TestResult
 
+	// Benchmarks accumulates benchmark evaluations
+	Benchmarks []*bench.Evaluation
+
+	// Integrity holds weakened tests found in the iteration's changes
+	Integrity *progress.IntegrityResult
+
 	// Commits accumulates git commits made during this iteration
 	Commits []progress.Commit
 
@@ -695,12 +933,28 @@ func (b *ProgressEntryBuilder) AddWorkDone(work string) *ProgressEntryBuilder {
 }
 
 // SetTestResult sets the test result
-func (b *ProgressEntryBuilder) SetTestResult(command string, passed bool, details string) *ProgressEntryBuilder {
-	b.Testing = progress.TestResult{
-		Command: command,
-		Passed:  passed,
-		Details: details,
-	}
+func (b *ProgressEntryBuilder) SetTestResult(command string, passed bool, details *testresult.Report) *ProgressEntryBuilder {
+	b.Testing.Command = command
+	b.Testing.Passed = passed
+	b.Testing.Details = details
+	return b
+}
+
+// SetCoverage sets the coverage gate result
+func (b *ProgressEntryBuilder) SetCoverage(c *coverage.Comparison) *ProgressEntryBuilder {
+	b.Testing.Coverage = c
+	return b
+}
+
+// AddBenchmark records a benchmark evaluation
+func (b *ProgressEntryBuilder) AddBenchmark(e *bench.Evaluation) *ProgressEntryBuilder {
+	b.Benchmarks = append(b.Benchmarks, e)
+	return b
+}
+
+// SetIntegrity records weakened tests and whether the iteration was rejected
+func (b *ProgressEntryBuilder) SetIntegrity(findings []string, rejected bool) *ProgressEntryBuilder {
+	b.Integrity = &progress.IntegrityResult{Findings: findings, Rejected: rejected}
 	return b
 }
 
@@ -727,6 +981,8 @@ func (b *ProgressEntryBuilder) Build(endTotal, endPassing int, allTestsPassing b
 		StartingState: b.StartingState,
 		WorkDone:      b.WorkDone,
 		Testing:       b.Testing,
+		Benchmarks:    b.Benchmarks,
+		Integrity:     b.Integrity,
 		Commits:       b.Commits,
 		EndingState: progress.State{
 			FeaturesTotal:   endTotal,
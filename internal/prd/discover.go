@@ -0,0 +1,55 @@
+package prd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mpjhorner/superralph/internal/fileset"
+)
+
+// Resolve returns the absolute path of the PRD a --prd argument names: the
+// file itself, or the PRD in it for a directory
+func Resolve(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	if info, err := os.Stat(abs); err == nil && info.IsDir() {
+		return PathInDir(abs), nil
+	}
+	return abs, nil
+}
+
+// Discover finds the PRDs under root, at most one per directory, for
+// monorepos with a PRD per project. Hidden directories and the directories
+// fileset always excludes, such as node_modules, are skipped. Paths are
+// returned in lexical order.
+func Discover(root string) ([]string, error) {
+	var found []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // Skip what can't be read
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || slices.Contains(fileset.DefaultExcludeDirs, d.Name())) {
+			return filepath.SkipDir
+		}
+		if ExistsInDir(path) {
+			found = append(found, PathInDir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for PRDs: %w", root, err)
+	}
+	return found, nil
+}
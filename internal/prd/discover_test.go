@@ -0,0 +1,50 @@
+package prd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prd.yaml"), []byte("name: Test\n"), 0644))
+
+	path, err := Resolve(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "prd.yaml"), path, "a directory resolves to its PRD")
+
+	path, err = Resolve(filepath.Join(dir, "requirements.md"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "requirements.md"), path, "a file is used as named, even before it exists")
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{
+		"prd.json",
+		"services/api/prd.json",
+		"services/api/prd.md", // prd.json wins
+		"services/web/prd.md",
+		"services/web/node_modules/lib/prd.json",
+		"services/.cache/prd.json",
+		"docs/README.md",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, path), []byte("{}"), 0644))
+	}
+
+	found, err := Discover(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "prd.json"),
+		filepath.Join(root, "services/api/prd.json"),
+		filepath.Join(root, "services/web/prd.md"),
+	}, found)
+
+	_, err = Discover(filepath.Join(root, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,293 @@
+package prd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Update loads the PRD at path, applies edit and saves the result. Nothing is
+// written if edit fails or the edited PRD doesn't validate. The file keeps
+// its format, and its indentation and comments where the format allows, and
+// is replaced atomically so a running build never reads a partial file.
+// Files from an older schema version are upgraded, keeping a backup of the
+// original.
+func Update(path string, edit func(p *PRD) error) (*PRD, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, migration, err := decode(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := edit(p); err != nil {
+		return nil, err
+	}
+	if result := Validate(p); !result.Valid {
+		var problems []string
+		for _, e := range result.Errors {
+			problems = append(problems, e.Error())
+		}
+		return nil, fmt.Errorf("the change would make %s invalid: %s", filepath.Base(path), strings.Join(problems, "; "))
+	}
+
+	if migration.NeedsMigration() {
+		if _, err := backup(path, data, migration.From); err != nil {
+			return nil, err
+		}
+	}
+	out, err := encode(p, path, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(path, out); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// detectIndent returns the indentation of the first indented line, or two
+// spaces if there is none
+func detectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// writeFile replaces path with data through a temporary file and a rename
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".prd-*"+filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// featureIDPattern splits an ID like "feat-012" into its prefix and number
+var featureIDPattern = regexp.MustCompile(`^(.*?)(\d+)$`)
+
+// NextFeatureID returns an unused ID following the PRD's numbering, e.g.
+// "feat-013" after "feat-012"
+func (p *PRD) NextFeatureID() string {
+	prefix, width, highest := "feat-", 3, 0
+	for _, f := range p.Features {
+		m := featureIDPattern.FindStringSubmatch(f.ID)
+		if m == nil {
+			continue
+		}
+		if n, err := strconv.Atoi(m[2]); err == nil && n >= highest {
+			prefix, width, highest = m[1], len(m[2]), n
+		}
+	}
+	for n := highest + 1; ; n++ {
+		id := fmt.Sprintf("%s%0*d", prefix, width, n)
+		if p.GetFeature(id) == nil {
+			return id
+		}
+	}
+}
+
+// AddFeature inserts a feature at a 1-based position, or appends it if
+// position is 0. An empty ID is filled in with NextFeatureID.
+func (p *PRD) AddFeature(f Feature, position int) error {
+	if f.ID == "" {
+		f.ID = p.NextFeatureID()
+	}
+	if p.GetFeature(f.ID) != nil {
+		return fmt.Errorf("feature %s already exists", f.ID)
+	}
+	if position < 0 || position > len(p.Features)+1 {
+		return fmt.Errorf("position %d is out of range (1-%d)", position, len(p.Features)+1)
+	}
+	deps := f.DependsOn
+	f.DependsOn = nil
+	if position == 0 {
+		position = len(p.Features) + 1
+	}
+	p.Features = slices.Insert(p.Features, position-1, f)
+	return p.SetDependencies(f.ID, deps)
+}
+
+// RemoveFeature deletes a feature. Features that depend on it must drop the
+// dependency first.
+func (p *PRD) RemoveFeature(id string) error {
+	i := p.featureIndex(id)
+	if i < 0 {
+		return fmt.Errorf("feature %s not found", id)
+	}
+	if dependents := p.Dependents(id); len(dependents) == 1 {
+		return fmt.Errorf("%s depends on %s; remove the dependency first", dependents[0], id)
+	} else if len(dependents) > 1 {
+		return fmt.Errorf("%s depend on %s; remove those dependencies first", strings.Join(dependents, ", "), id)
+	}
+	p.Features = slices.Delete(p.Features, i, i+1)
+	return nil
+}
+
+// MoveFeature moves a feature to a 1-based position
+func (p *PRD) MoveFeature(id string, position int) error {
+	i := p.featureIndex(id)
+	if i < 0 {
+		return fmt.Errorf("feature %s not found", id)
+	}
+	if position < 1 || position > len(p.Features) {
+		return fmt.Errorf("position %d is out of range (1-%d)", position, len(p.Features))
+	}
+	f := p.Features[i]
+	p.Features = slices.Insert(slices.Delete(p.Features, i, i+1), position-1, f)
+	return nil
+}
+
+// RenameFeature changes a feature's ID and the dependencies that refer to it
+func (p *PRD) RenameFeature(id, newID string) error {
+	f := p.GetFeature(id)
+	if f == nil {
+		return fmt.Errorf("feature %s not found", id)
+	}
+	if newID == id {
+		return nil
+	}
+	if strings.TrimSpace(newID) == "" {
+		return fmt.Errorf("feature ID cannot be empty")
+	}
+	if p.GetFeature(newID) != nil {
+		return fmt.Errorf("feature %s already exists", newID)
+	}
+	f.ID = newID
+	for i := range p.Features {
+		for j, dep := range p.Features[i].DependsOn {
+			if dep == id {
+				p.Features[i].DependsOn[j] = newID
+			}
+		}
+	}
+	return nil
+}
+
+// SetDependencies replaces a feature's depends_on list. Every dependency
+// must exist, and none may lead back to the feature.
+func (p *PRD) SetDependencies(id string, deps []string) error {
+	f := p.GetFeature(id)
+	if f == nil {
+		return fmt.Errorf("feature %s not found", id)
+	}
+	var unique []string
+	for _, dep := range deps {
+		switch {
+		case dep == id:
+			return fmt.Errorf("feature %s cannot depend on itself", id)
+		case p.GetFeature(dep) == nil:
+			return fmt.Errorf("feature %s not found", dep)
+		case !slices.Contains(unique, dep):
+			unique = append(unique, dep)
+		}
+	}
+	for _, dep := range unique {
+		if path := p.dependencyPath(dep, id); path != nil {
+			return fmt.Errorf("depending on %s would create a cycle: %s -> %s", dep, id, strings.Join(path, " -> "))
+		}
+	}
+	f.DependsOn = unique
+	return nil
+}
+
+// Reopen moves a feature back to todo, so the build works on it again:
+// passing, blocked, stuck, waiting for review or skipped. Returns false if
+// it was already open.
+func (p *PRD) Reopen(id string) (bool, error) {
+	f := p.GetFeature(id)
+	if f == nil {
+		return false, fmt.Errorf("feature %s not found", id)
+	}
+	if f.IsOpen() {
+		return false, nil
+	}
+	f.SetStatus(StatusTodo, time.Now().UTC())
+	return true, nil
+}
+
+// SetFeatureStatus moves a feature to a status. Only blocked features take
+// a reason.
+func (p *PRD) SetFeatureStatus(id string, status Status, reason string) error {
+	f := p.GetFeature(id)
+	if f == nil {
+		return fmt.Errorf("feature %s not found", id)
+	}
+	if reason != "" && status != StatusBlocked {
+		return fmt.Errorf("only blocked features have a reason")
+	}
+	f.SetStatus(status, time.Now().UTC())
+	f.BlockedReason = reason
+	return nil
+}
+
+// Dependents returns the IDs of features that depend on id
+func (p *PRD) Dependents(id string) []string {
+	var ids []string
+	for _, f := range p.Features {
+		if slices.Contains(f.DependsOn, id) {
+			ids = append(ids, f.ID)
+		}
+	}
+	return ids
+}
+
+// dependencyPath returns the chain of dependencies from one feature to
+// another, starting with from, or nil if to can't be reached
+func (p *PRD) dependencyPath(from, to string) []string {
+	seen := make(map[string]bool)
+	var walk func(id string) []string
+	walk = func(id string) []string {
+		if id == to {
+			return []string{id}
+		}
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+		if f := p.GetFeature(id); f != nil {
+			for _, dep := range f.DependsOn {
+				if path := walk(dep); path != nil {
+					return append([]string{id}, path...)
+				}
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// featureIndex returns the index of a feature, or -1
+func (p *PRD) featureIndex(id string) int {
+	return slices.IndexFunc(p.Features, func(f Feature) bool { return f.ID == id })
+}
@@ -0,0 +1,135 @@
+package prd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func featureIDs(p *PRD) []string {
+	var ids []string
+	for _, f := range p.Features {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func TestNextFeatureID(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	assert.Equal(t, "feat-011", p.NextFeatureID())
+
+	p.Features = []Feature{{ID: "api-7"}, {ID: "login"}}
+	assert.Equal(t, "api-8", p.NextFeatureID())
+
+	assert.Equal(t, "feat-001", (&PRD{}).NextFeatureID())
+}
+
+func TestAddFeature(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	require.NoError(t, p.AddFeature(Feature{Description: "New", DependsOn: []string{"feat-002", "feat-002"}}, 0))
+	assert.Equal(t, []string{"feat-001", "feat-002", "feat-010", "feat-011"}, featureIDs(p))
+	assert.Equal(t, []string{"feat-002"}, p.GetFeature("feat-011").DependsOn, "deduplicated")
+
+	require.NoError(t, p.AddFeature(Feature{ID: "feat-000"}, 1))
+	assert.Equal(t, "feat-000", p.Features[0].ID)
+
+	assert.ErrorContains(t, p.AddFeature(Feature{ID: "feat-001"}, 0), "already exists")
+	assert.ErrorContains(t, p.AddFeature(Feature{ID: "x"}, 9), "out of range")
+	assert.ErrorContains(t, p.AddFeature(Feature{ID: "y", DependsOn: []string{"missing"}}, 0), "feature missing not found")
+}
+
+func TestRemoveFeature(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	assert.ErrorContains(t, p.RemoveFeature("feat-001"), "feat-002 depends on feat-001")
+	assert.ErrorContains(t, p.RemoveFeature("missing"), "not found")
+
+	require.NoError(t, p.RemoveFeature("feat-002"))
+	require.NoError(t, p.RemoveFeature("feat-001"))
+	assert.Equal(t, []string{"feat-010"}, featureIDs(p))
+}
+
+func TestMoveAndRenameFeature(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	require.NoError(t, p.MoveFeature("feat-010", 1))
+	assert.Equal(t, []string{"feat-010", "feat-001", "feat-002"}, featureIDs(p))
+	require.NoError(t, p.MoveFeature("feat-010", 3))
+	assert.Equal(t, []string{"feat-001", "feat-002", "feat-010"}, featureIDs(p))
+	assert.ErrorContains(t, p.MoveFeature("feat-010", 4), "out of range")
+
+	require.NoError(t, p.RenameFeature("feat-001", "setup"))
+	assert.Equal(t, []string{"setup"}, p.GetFeature("feat-002").DependsOn, "references follow the rename")
+	assert.ErrorContains(t, p.RenameFeature("setup", "feat-002"), "already exists")
+}
+
+func TestSetDependencies(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	require.NoError(t, p.SetDependencies("feat-010", []string{"feat-002"}))
+
+	assert.ErrorContains(t, p.SetDependencies("feat-001", []string{"feat-010"}),
+		"would create a cycle: feat-001 -> feat-010 -> feat-002 -> feat-001")
+	assert.ErrorContains(t, p.SetDependencies("feat-001", []string{"feat-001"}), "itself")
+	assert.ErrorContains(t, p.SetDependencies("feat-001", []string{"missing"}), "not found")
+	assert.Empty(t, p.GetFeature("feat-001").DependsOn, "unchanged after a refused change")
+
+	require.NoError(t, p.SetDependencies("feat-010", nil))
+	assert.Nil(t, p.GetFeature("feat-010").DependsOn)
+	assert.Equal(t, []string{"feat-002"}, p.Dependents("feat-001"))
+}
+
+func TestReopen(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	reopened, err := p.Reopen("feat-001")
+	require.NoError(t, err)
+	assert.True(t, reopened)
+	assert.False(t, p.GetFeature("feat-001").Passes)
+
+	reopened, err = p.Reopen("feat-001")
+	require.NoError(t, err)
+	assert.False(t, reopened, "already open")
+}
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prd.json")
+	original := "{\n    \"schemaVersion\": 2,\n    \"name\": \"Test Project\",\n    \"description\": \"Test description\",\n    \"testCommand\": \"go test ./...\",\n" +
+		"    \"features\": [\n        {\"id\": \"feat-001\", \"category\": \"functional\", \"priority\": \"high\", \"description\": \"First\", \"steps\": [\"Step\"], \"passes\": true}\n    ]\n}\n"
+	require.NoError(t, os.WriteFile(path, []byte(original), 0600))
+
+	p, err := Update(path, func(p *PRD) error {
+		_, err := p.Reopen("feat-001")
+		return err
+	})
+	require.NoError(t, err)
+	assert.False(t, p.Features[0].Passes)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n    \"name\": \"Test Project\"", "keeps the indentation")
+	assert.Contains(t, string(data), "\n        {\n            \"id\": \"feat-001\"")
+	assert.True(t, data[len(data)-1] == '\n', "keeps the trailing newline")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "keeps the file mode")
+
+	// Invalid results and failed edits leave the file alone
+	_, err = Update(path, func(p *PRD) error {
+		p.Features[0].Priority = "urgent"
+		return nil
+	})
+	assert.ErrorContains(t, err, "would make prd.json invalid: features[0].priority")
+	_, err = Update(path, func(p *PRD) error { return p.RemoveFeature("missing") })
+	assert.Error(t, err)
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(after))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files left behind")
+
+	_, err = Update(filepath.Join(dir, "missing.json"), func(p *PRD) error { return nil })
+	assert.ErrorContains(t, err, "not found")
+}
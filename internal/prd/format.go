@@ -0,0 +1,314 @@
+package prd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Format is a file format a PRD can be written in
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatTOML     Format = "toml"
+	FormatMarkdown Format = "markdown"
+)
+
+// Filenames are the PRD files looked for in a directory, in order of preference
+var Filenames = []string{DefaultFilename, "prd.yaml", "prd.yml", "prd.toml", "prd.md"}
+
+// FormatOf returns the format of a PRD file from its extension. Unknown
+// extensions are read as JSON.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	case ".md", ".markdown":
+		return FormatMarkdown
+	default:
+		return FormatJSON
+	}
+}
+
+// PassingInstruction tells the agent how to mark a feature as passing in a
+// file of this format
+func (f Format) PassingInstruction() string {
+	switch f {
+	case FormatYAML:
+		return "set passes: true"
+	case FormatTOML:
+		return "set passes = true"
+	case FormatMarkdown:
+		return `check every one of its step boxes ("- [x]")`
+	default:
+		return `set "passes": true`
+	}
+}
+
+// PathInDir returns the PRD file in dir, or prd.json in dir if there is none
+func PathInDir(dir string) string {
+	for _, name := range Filenames {
+		if path := filepath.Join(dir, name); Exists(path) {
+			return path
+		}
+	}
+	return filepath.Join(dir, DefaultFilename)
+}
+
+// codec reads and writes one format. Files are read into a document shaped
+// like prd.json, so migrations work the same for every format.
+type codec interface {
+	// document parses a file into a JSON-compatible document
+	document(data []byte) (map[string]any, error)
+
+	// encode writes the PRD, keeping what it can of the original file, such
+	// as its comments and indentation. original is nil for new files.
+	encode(p *PRD, original []byte) ([]byte, error)
+}
+
+// codecFor returns the codec for a format
+func codecFor(f Format) codec {
+	switch f {
+	case FormatYAML:
+		return yamlCodec{}
+	case FormatTOML:
+		return tomlCodec{}
+	case FormatMarkdown:
+		return markdownCodec{}
+	default:
+		return jsonCodec{}
+	}
+}
+
+// jsonCodec reads and writes prd.json
+type jsonCodec struct{}
+
+func (jsonCodec) document(data []byte) (map[string]any, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (jsonCodec) encode(p *PRD, original []byte) ([]byte, error) {
+	// Encode without HTML escaping, so "->" isn't written as "-\u003e"
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+	out := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+
+	indent := "  "
+	if original != nil {
+		indent = detectIndent(original)
+		out = keepUnmodeled(out, original)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, out, "", indent); err != nil {
+		return nil, err
+	}
+	if bytes.HasSuffix(original, []byte("\n")) {
+		indented.WriteByte('\n')
+	}
+	return indented.Bytes(), nil
+}
+
+// jsonMember is one member of a JSON object, in the order it was written
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// parseObject splits a JSON object into its members. Returns false if data
+// isn't an object.
+func parseObject(data []byte) ([]jsonMember, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+	var members []jsonMember
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, false
+		}
+		members = append(members, jsonMember{key, value})
+	}
+	return members, true
+}
+
+// writeObject writes members as a compact JSON object
+func writeObject(members []jsonMember) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, m.value); err != nil {
+			buf.Write(m.value)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// keepUnmodeled carries over fields of the PRD and its features that the
+// PRD types don't model from the original prd.json, such as a team's own
+// annotations, each after the field it followed. Fields that migrations
+// rename or drop aren't carried over.
+func keepUnmodeled(encoded, original []byte) []byte {
+	updated, ok := parseObject(encoded)
+	if !ok {
+		return encoded
+	}
+	previous, ok := parseObject(original)
+	if !ok {
+		return encoded
+	}
+	var doc map[string]any
+	if json.Unmarshal(original, &doc) != nil {
+		return encoded
+	}
+	if _, err := Migrate(doc); err != nil {
+		return encoded
+	}
+
+	merged := mergeMembers(updated, previous, reflect.TypeOf(PRD{}), doc)
+	for i, m := range merged {
+		if m.key == "features" {
+			if prev, ok := memberValue(previous, "features"); ok {
+				features, _ := doc["features"].([]any)
+				merged[i].value = mergeFeatures(m.value, prev, features)
+			}
+		}
+	}
+	return writeObject(merged)
+}
+
+// mergeMembers adds the previous members of an object of type t that t has
+// no field for, and that are still in the migrated document, to updated
+func mergeMembers(updated, previous []jsonMember, t reflect.Type, migrated map[string]any) []jsonMember {
+	modeled := make(map[string]bool)
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			modeled[name] = true
+		}
+	}
+
+	merged := slices.Clone(updated)
+	for i, m := range previous {
+		if _, ok := migrated[m.key]; modeled[m.key] || !ok {
+			continue
+		}
+		// Insert after the nearest earlier member that is still there
+		at := 0
+		for j := i - 1; j >= 0 && at == 0; j-- {
+			if k := slices.IndexFunc(merged, func(n jsonMember) bool { return n.key == previous[j].key }); k >= 0 {
+				at = k + 1
+			}
+		}
+		merged = slices.Insert(merged, at, m)
+	}
+	return merged
+}
+
+// mergeFeatures merges each updated feature with the previous feature of
+// the same ID
+func mergeFeatures(updated, previous json.RawMessage, migrated []any) json.RawMessage {
+	var features, before []json.RawMessage
+	if json.Unmarshal(updated, &features) != nil || json.Unmarshal(previous, &before) != nil {
+		return updated
+	}
+	byID := make(map[string][]jsonMember)
+	for _, raw := range before {
+		if members, ok := parseObject(raw); ok {
+			byID[featureID(raw)] = members
+		}
+	}
+	migratedByID := make(map[string]map[string]any)
+	for _, f := range migrated {
+		if feature, ok := f.(map[string]any); ok {
+			id, _ := feature["id"].(string)
+			migratedByID[id] = feature
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, raw := range features {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		id := featureID(raw)
+		members, ok := parseObject(raw)
+		if prev, found := byID[id]; ok && found {
+			raw = writeObject(mergeMembers(members, prev, reflect.TypeOf(Feature{}), migratedByID[id]))
+		}
+		buf.Write(raw)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// memberValue returns the value of an object's member
+func memberValue(members []jsonMember, key string) (json.RawMessage, bool) {
+	for _, m := range members {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return nil, false
+}
+
+// featureID returns the id of an encoded feature, or "" if it has none
+func featureID(raw json.RawMessage) string {
+	var f struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(raw, &f)
+	return f.ID
+}
+
+// normalize converts a decoded YAML or TOML value into a document shaped
+// like one decoded from JSON
+func normalize(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("the file must describe an object")
+	}
+	return doc, nil
+}
+
+// readExisting returns the current contents of path, or nil if it doesn't exist
+func readExisting(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
@@ -0,0 +1,368 @@
+package prd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formatPRD uses every kind of field the formats have to carry
+func formatPRD() *PRD {
+	started := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	return &PRD{
+		SchemaVersion: CurrentSchemaVersion,
+		Name:          "Shop",
+		Description:   "An online shop",
+		TestCommand:   "go test ./...",
+		Coverage:      &CoverageSpec{Threshold: 1.5},
+		Strategy:      StrategyUnblock,
+		Features: []Feature{
+			{
+				ID:          "feat-001",
+				Category:    CategoryFunctional,
+				Priority:    PriorityHigh,
+				Description: "Customers can add items to a cart",
+				Steps:       []string{"Add an item", "See it in the cart"},
+				Passes:      true,
+				Value:       5,
+				Checks:      []Check{{Step: 1, Command: `go test -run "TestCart"`, Output: `ok\s`}},
+			},
+			{
+				ID:            "feat-002",
+				Category:      CategoryUI,
+				Priority:      PriorityLow,
+				Description:   "Customers can pay",
+				Steps:         []string{"Pay with a card"},
+				DependsOn:     []string{"feat-001"},
+				Effort:        3,
+				Context:       &ContextSpec{Files: []string{"internal/pay/**/*.go"}},
+				Status:        StatusBlocked,
+				BlockedReason: "Needs card processor keys",
+				StartedAt:     &started,
+			},
+		},
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatJSON, FormatOf("prd.json"))
+	assert.Equal(t, FormatYAML, FormatOf("prd.yaml"))
+	assert.Equal(t, FormatYAML, FormatOf("prd.yml"))
+	assert.Equal(t, FormatTOML, FormatOf("prd.toml"))
+	assert.Equal(t, FormatMarkdown, FormatOf("docs/PRD.MD"))
+	assert.Equal(t, FormatJSON, FormatOf("prd"))
+}
+
+func TestFormatsRoundTrip(t *testing.T) {
+	for _, name := range Filenames {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, Save(formatPRD(), path))
+
+			loaded, err := Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, formatPRD(), loaded)
+
+			// Saving what was loaded changes nothing
+			before, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.NoError(t, Save(loaded, path))
+			after, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, string(before), string(after))
+		})
+	}
+}
+
+func TestJSONNoOpEditRoundTrips(t *testing.T) {
+	original := `{
+  "schemaVersion": 2,
+  "name": "Shop",
+  "owner": "Payments team",
+  "description": "Checkout flow: cart -> payment -> <receipt> & email",
+  "testCommand": "go test ./...",
+  "features": [
+    {
+      "id": "feat-001",
+      "category": "functional",
+      "priority": "high",
+      "description": "Customers can add items to a cart",
+      "ticket": {
+        "key": "SHOP-12"
+      },
+      "steps": [
+        "Add an item -> see it in the cart"
+      ],
+      "passes": false
+    }
+  ]
+}
+`
+	path := filepath.Join(t.TempDir(), "prd.json")
+	require.NoError(t, os.WriteFile(path, []byte(original), 0644))
+	_, err := Update(path, func(p *PRD) error { return nil })
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+
+	// Edits keep the fields superralph doesn't know
+	_, err = Update(path, func(p *PRD) error {
+		p.Features[0].Passes = true
+		return nil
+	})
+	require.NoError(t, err)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  \"owner\": \"Payments team\",\n")
+	assert.Contains(t, string(data), "\n      \"ticket\": {\n        \"key\": \"SHOP-12\"\n      },\n")
+	assert.Contains(t, string(data), "cart -> payment -> <receipt> & email")
+
+	// The project's own prd.json, once migrated
+	baseline, err := os.ReadFile("../../prd.json")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, baseline, 0644))
+	_, err = MigrateFile(path)
+	require.NoError(t, err)
+	migrated, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = Update(path, func(p *PRD) error { return nil })
+	require.NoError(t, err)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(migrated), string(data))
+	assert.Contains(t, string(data), "PLAN -> VALIDATE -> EXECUTE")
+}
+
+func TestFormatsRoundTripBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.json")
+	baseline, err := os.ReadFile("../../prd.json")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, baseline, 0644))
+	want, err := Load(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, want.Features)
+
+	for _, name := range Filenames[1:] {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, Save(want, path))
+		got, err := Load(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	// Steps keep their spacing
+	step := "Render a navigation bar at the top (e.g., [1] Dashboard  [2] Features  [3] Logs)"
+	require.Contains(t, want.GetFeature("feat-013").Steps, step)
+}
+
+func TestPathInDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, filepath.Join(dir, "prd.json"), PathInDir(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prd.md"), []byte("## feat-001: Feature\n"), 0644))
+	assert.Equal(t, filepath.Join(dir, "prd.md"), PathInDir(dir))
+	assert.True(t, ExistsInDir(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prd.json"), []byte("{}"), 0644))
+	assert.Equal(t, filepath.Join(dir, "prd.json"), PathInDir(dir), "prd.json wins when there are several")
+}
+
+func TestYAMLKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`# Requirements for the shop
+schemaVersion: 1
+name: Shop # working title
+owner: Payments team # who to ask
+description: An online shop
+testCommand: go test ./...
+features:
+  # Checkout comes first
+  - id: feat-001
+    category: functional
+    priority: high
+    description: Customers can check out
+    ticket:
+      key: SHOP-12
+    steps:
+      - Pay with a card
+    passes: false
+`), 0644))
+
+	_, err := Update(path, func(p *PRD) error {
+		p.Features[0].Passes = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Requirements for the shop\n")
+	assert.Contains(t, string(data), "name: Shop # working title\nowner: Payments team # who to ask\n")
+	assert.Contains(t, string(data), "    description: Customers can check out\n    ticket:\n      key: SHOP-12\n")
+	assert.Contains(t, string(data), "  # Checkout comes first\n  - id: feat-001\n")
+	assert.Contains(t, string(data), "    passes: true\n")
+}
+
+func TestTOMLKeepsHeaderAndFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`# Requirements for the shop
+schemaVersion = 2
+name = "Shop"
+owner = "Payments team"
+description = "An online shop"
+testCommand = "go test ./..."
+
+[[features]]
+id = "feat-001"
+category = "functional"
+priority = "high"
+description = "Customers can check out"
+steps = ["Pay with a card"]
+passes = false
+
+[features.ticket]
+key = "SHOP-12"
+`), 0644))
+
+	_, err := Update(path, func(p *PRD) error {
+		p.Features[0].Passes = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Requirements for the shop\n\nschemaVersion = 2\n")
+	assert.Contains(t, string(data), "passes = true\n")
+	assert.Contains(t, string(data), "owner = \"Payments team\"\n")
+	assert.Contains(t, string(data), "\n[features.ticket]\nkey = \"SHOP-12\"\n")
+}
+
+// markdownPRD is a prd.md as someone might write it by hand
+const markdownPRD = `---
+schemaVersion: 1
+name: Shop # working title
+description: An online shop
+testCommand: go test ./...
+---
+
+# Shop requirements
+
+Written with the product team.
+
+## feat-001: Customers can add items to a cart
+
+- Category: functional
+- Priority: high
+
+Keep the cart in the session for now.
+
+* [X] Add an item
+* [x] See it in
+  the cart
+
+## feat-002: Customers can pay
+
+- category: ui
+- priority: low
+- depends_on: feat-001
+
+- [x] Pay with a card
+- [ ] Get a receipt
+
+` + "```yaml" + `
+verify: go test ./internal/pay # fast
+` + "```" + `
+
+` + "```" + `
+## not a feature
+` + "```" + `
+`
+
+func TestMarkdownLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.md")
+	require.NoError(t, os.WriteFile(path, []byte(markdownPRD), 0644))
+
+	p, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Shop", p.Name)
+	assert.Equal(t, "go test ./...", p.TestCommand)
+	require.Len(t, p.Features, 2)
+
+	first := p.Features[0]
+	assert.Equal(t, "feat-001", first.ID)
+	assert.Equal(t, "Customers can add items to a cart", first.Description)
+	assert.Equal(t, PriorityHigh, first.Priority)
+	assert.Equal(t, []string{"Add an item", "See it in the cart"}, first.Steps)
+	assert.True(t, first.Passes, "every box is checked")
+
+	second := p.Features[1]
+	assert.Equal(t, CategoryUI, second.Category)
+	assert.Equal(t, []string{"feat-001"}, second.DependsOn)
+	assert.False(t, second.Passes, "a box is unchecked")
+	require.NotNil(t, second.Verify)
+	assert.Equal(t, "go test ./internal/pay", second.Verify.Command)
+	assert.True(t, Validate(p).Valid)
+}
+
+func TestMarkdownSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.md")
+	require.NoError(t, os.WriteFile(path, []byte(markdownPRD), 0644))
+
+	_, err := Update(path, func(p *PRD) error {
+		p.Features[1].Passes = true
+		p.Features[1].Steps = append(p.Features[1].Steps, "Get an email")
+		return nil
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	md := string(data)
+	assert.Contains(t, md, "name: Shop # working title\n")
+	assert.Contains(t, md, "\n# Shop requirements\n\nWritten with the product team.\n")
+	assert.Contains(t, md, "- priority: high\n\nKeep the cart in the session for now.\n\n- [x] Add an item\n- [x] See it in the cart\n")
+	assert.Contains(t, md, "- depends on: feat-001\n")
+	assert.Contains(t, md, "- [x] Pay with a card\n- [x] Get a receipt\n- [x] Get an email\n")
+	assert.Contains(t, md, "verify: go test ./internal/pay # fast\n")
+	assert.Contains(t, md, "## not a feature\n")
+
+	// Reopening a feature unchecks its boxes
+	p, err := Update(path, func(p *PRD) error {
+		p.Features[0].Passes = false
+		return nil
+	})
+	require.NoError(t, err)
+	assert.False(t, p.Features[0].Passes)
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.False(t, loaded.Features[0].Passes)
+	assert.True(t, loaded.Features[1].Passes)
+}
+
+func TestMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"heading without an ID", "# Shop\n\n## Checkout\n", "line 3: feature headings must look like"},
+		{"unclosed front matter", "---\nname: Shop\n", "front matter has no closing ---"},
+		{"unclosed code block", "## feat-001: Feature\n\n```yaml\nverify: true\n", "never closed"},
+		{"bad number", "## feat-001: Feature\n\n- value: lots\n", "line 3: value of feat-001 must be a whole number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prd.md")
+			require.NoError(t, os.WriteFile(path, []byte(tt.md), 0644))
+			_, err := Load(path)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
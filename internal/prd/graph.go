@@ -0,0 +1,229 @@
+package prd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Cycles returns the dependency cycles in the PRD. Each cycle is a path that
+// follows depends_on and ends where it started, beginning with the feature
+// that comes first in the PRD, e.g. [feat-001 feat-003 feat-001].
+// Self-references aren't included; Validate reports those on their own.
+func (p *PRD) Cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+	var cycles [][]string
+	seen := make(map[string]bool)
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range p.GetFeature(id).DependsOn {
+			if dep == id || p.GetFeature(dep) == nil {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := slices.Index(stack, dep)
+				cycle := p.rotateCycle(slices.Clone(stack[start:]))
+				if key := strings.Join(cycle, " "); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, append(cycle, cycle[0]))
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+
+	for _, f := range p.Features {
+		if state[f.ID] == unvisited {
+			visit(f.ID)
+		}
+	}
+	return cycles
+}
+
+// rotateCycle starts a cycle at its feature that comes first in the PRD
+func (p *PRD) rotateCycle(cycle []string) []string {
+	first := 0
+	for i, id := range cycle {
+		if p.featureIndex(id) < p.featureIndex(cycle[first]) {
+			first = i
+		}
+	}
+	return append(cycle[first:], cycle[:first]...)
+}
+
+// TopologicalOrder returns the feature IDs with every feature after its
+// dependencies, otherwise keeping the PRD's order. Fails if the
+// dependencies have a cycle.
+func (p *PRD) TopologicalOrder() ([]string, error) {
+	if cycles := p.Cycles(); len(cycles) > 0 {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycles[0], " -> "))
+	}
+
+	// Place the first feature whose dependencies are placed until none is left
+	placed := make(map[string]bool)
+	order := make([]string, 0, len(p.Features))
+	for next := 0; next >= 0; {
+		next = slices.IndexFunc(p.Features, func(f Feature) bool {
+			return !placed[f.ID] && p.dependenciesPlaced(f, placed)
+		})
+		if next >= 0 {
+			placed[p.Features[next].ID] = true
+			order = append(order, p.Features[next].ID)
+		}
+	}
+	return order, nil
+}
+
+// dependenciesPlaced reports whether every known dependency of f other than
+// itself is in placed
+func (p *PRD) dependenciesPlaced(f Feature, placed map[string]bool) bool {
+	for _, dep := range f.DependsOn {
+		if dep != f.ID && p.GetFeature(dep) != nil && !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// unplaced returns the IDs of features not in placed
+func (p *PRD) unplaced(placed map[string]bool) []string {
+	var ids []string
+	for _, f := range p.Features {
+		if !placed[f.ID] {
+			ids = append(ids, f.ID)
+		}
+	}
+	return ids
+}
+
+// Unreachable returns the IDs of features that aren't done and never can
+// be built, because they depend on a missing feature, themselves, a cycle or
+// another unreachable feature. NextFeature never selects them.
+func (p *PRD) Unreachable() []string {
+	buildable := p.getDoneFeatureIDs()
+	for changed := true; changed; {
+		changed = false
+		for _, f := range p.Features {
+			if buildable[f.ID] {
+				continue
+			}
+			if !slices.ContainsFunc(f.DependsOn, func(dep string) bool { return !buildable[dep] }) {
+				buildable[f.ID] = true
+				changed = true
+			}
+		}
+	}
+	return p.unplaced(buildable)
+}
+
+// CriticalPath returns the longest chain of features still to build, each
+// depending on the one before it. Its length is the least number of
+// iterations the remaining work needs. Unreachable features are left out,
+// and ties go to the chain that ends earliest in the PRD.
+func (p *PRD) CriticalPath() []string {
+	chains := p.RemainingChains()
+	var end string
+	for _, f := range p.Features {
+		if len(chains[f.ID]) > len(chains[end]) {
+			end = f.ID
+		}
+	}
+	return chains[end]
+}
+
+// RemainingChains returns, for every buildable feature that isn't done,
+// the longest chain of unfinished features that ends with it: the feature's
+// dependencies that still have to be built first, then the feature.
+func (p *PRD) RemainingChains() map[string][]string {
+	unreachable := p.Unreachable()
+	chains := make(map[string][]string)
+	var chain func(id string) []string
+	chain = func(id string) []string {
+		if c, ok := chains[id]; ok {
+			return c
+		}
+		var longest []string
+		for _, dep := range p.GetFeature(id).DependsOn {
+			if f := p.GetFeature(dep); f != nil && !f.IsDone() {
+				if c := chain(dep); len(c) > len(longest) {
+					longest = c
+				}
+			}
+		}
+		chains[id] = append(slices.Clone(longest), id)
+		return chains[id]
+	}
+	for _, f := range p.Features {
+		if !f.IsDone() && !slices.Contains(unreachable, f.ID) {
+			chain(f.ID)
+		}
+	}
+	return chains
+}
+
+// downstreamChains returns, for every buildable feature that isn't done,
+// the longest chain of unfinished features that starts with it: the feature,
+// then features that depend on it, one after another.
+func (p *PRD) downstreamChains() map[string][]string {
+	unreachable := p.Unreachable()
+	chains := make(map[string][]string)
+	var chain func(id string) []string
+	chain = func(id string) []string {
+		if c, ok := chains[id]; ok {
+			return c
+		}
+		var longest []string
+		for _, dep := range p.Dependents(id) {
+			if f := p.GetFeature(dep); !f.IsDone() && !slices.Contains(unreachable, dep) {
+				if c := chain(dep); len(c) > len(longest) {
+					longest = c
+				}
+			}
+		}
+		chains[id] = append([]string{id}, longest...)
+		return chains[id]
+	}
+	for _, f := range p.Features {
+		if !f.IsDone() && !slices.Contains(unreachable, f.ID) {
+			chain(f.ID)
+		}
+	}
+	return chains
+}
+
+// waitingOn returns the buildable, unfinished features that depend on id,
+// directly or through other features, in PRD order
+func (p *PRD) waitingOn(id string) []string {
+	unreachable := p.Unreachable()
+	waiting := map[string]bool{}
+	queue := []string{id}
+	for len(queue) > 0 {
+		for _, dep := range p.Dependents(queue[0]) {
+			if f := p.GetFeature(dep); !waiting[dep] && dep != id && !f.IsDone() && !slices.Contains(unreachable, dep) {
+				waiting[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+		queue = queue[1:]
+	}
+	var ids []string
+	for _, f := range p.Features {
+		if waiting[f.ID] {
+			ids = append(ids, f.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,54 @@
+package prd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycles(t *testing.T) {
+	p := testPRD("a", "b:a,d", "c:b", "d:c", "e:e", "f:g", "g:f")
+	assert.Equal(t, [][]string{{"b", "d", "c", "b"}, {"f", "g", "f"}}, p.Cycles(), "self-references are left out")
+
+	assert.Empty(t, testPRD("a", "b:a", "c:a,b").Cycles())
+}
+
+func TestValidateCycles(t *testing.T) {
+	result := Validate(testPRD("a:b", "b:a"))
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "features[0].depends_on: dependency cycle: a -> b -> a", result.Errors[0].Error())
+
+	next, reason := testPRD("a:b", "b:a", "c+").NextFeatureWithReason()
+	assert.Nil(t, next)
+	assert.Equal(t, "remaining features are blocked by a dependency cycle: a -> b -> a", reason)
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	order, err := testPRD("c:b", "a", "b:a", "d").TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, order)
+
+	_, err = testPRD("a:c", "b:a", "c:b").TopologicalOrder()
+	assert.EqualError(t, err, "dependency cycle: a -> c -> b -> a")
+}
+
+func TestUnreachable(t *testing.T) {
+	p := testPRD("a", "b:missing", "c:b", "d:e", "e:d", "f:f", "g+:b", "h:a,g")
+	assert.Equal(t, []string{"b", "c", "d", "e", "f"}, p.Unreachable(), "passing features are never unreachable")
+	assert.Empty(t, testPRD("a+", "b:a").Unreachable())
+}
+
+func TestCriticalPath(t *testing.T) {
+	p := testPRD("a+", "b:a", "c:b", "d:c", "e", "f:e", "x:missing", "y:x")
+	assert.Equal(t, []string{"b", "c", "d"}, p.CriticalPath(), "passing and unreachable features are left out")
+
+	chains := p.RemainingChains()
+	assert.Equal(t, []string{"e", "f"}, chains["f"])
+	assert.NotContains(t, chains, "a")
+	assert.NotContains(t, chains, "y")
+
+	assert.Equal(t, []string{"b"}, testPRD("a+", "b:a", "c").CriticalPath(), "ties go to the earliest")
+	assert.Empty(t, testPRD("a+").CriticalPath())
+}
@@ -0,0 +1,117 @@
+package prd
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// AllowedCategories returns the categories features may use: the PRD's own
+// list, or the built-in categories if it doesn't declare one
+func (p *PRD) AllowedCategories() []Category {
+	if p != nil && len(p.Categories) > 0 {
+		return slices.Clone(p.Categories)
+	}
+	return ValidCategories()
+}
+
+// AllowedPriorities returns the named priority levels, highest first: the
+// PRD's own list, or high, medium and low if it doesn't declare one
+func (p *PRD) AllowedPriorities() []Priority {
+	if p != nil && len(p.Priorities) > 0 {
+		return slices.Clone(p.Priorities)
+	}
+	return ValidPriorities()
+}
+
+// IsAllowedCategory checks if a feature of this PRD may use the category
+func (p *PRD) IsAllowedCategory(c Category) bool {
+	return lo.Contains(p.AllowedCategories(), c)
+}
+
+// IsAllowedPriority checks if a feature of this PRD may use the priority:
+// one of its levels, or an integer weight
+func (p *PRD) IsAllowedPriority(pri Priority) bool {
+	_, weighted := pri.Weight()
+	return weighted || lo.Contains(p.AllowedPriorities(), pri)
+}
+
+// DefaultCategory returns the category new features get: functional, or the
+// first of the PRD's own categories
+func (p *PRD) DefaultCategory() Category {
+	return p.AllowedCategories()[0]
+}
+
+// DefaultPriority returns the priority new features get: the middle level
+// (medium by default), or the middle weight the features use
+func (p *PRD) DefaultPriority() Priority {
+	if weights := p.weights(); len(weights) > 0 {
+		return Priority(strconv.Itoa(weights[len(weights)/2]))
+	}
+	levels := p.AllowedPriorities()
+	return levels[len(levels)/2]
+}
+
+// UsesWeights returns true if features rank by integer weight instead of by
+// named level
+func (p *PRD) UsesWeights() bool {
+	return p != nil && lo.SomeBy(p.Features, func(f Feature) bool {
+		_, ok := f.Priority.Weight()
+		return ok
+	})
+}
+
+// PriorityRank orders priorities from most to least important: levels by
+// their position in AllowedPriorities, weights from the highest down. Lower
+// ranks are built first; unknown priorities come last.
+func (p *PRD) PriorityRank(pri Priority) int {
+	if w, ok := pri.Weight(); ok {
+		return -w
+	}
+	if i := lo.IndexOf(p.AllowedPriorities(), pri); i >= 0 {
+		return i
+	}
+	return math.MaxInt
+}
+
+// PriorityTier maps a priority onto high, medium or low, for display. Levels
+// split into thirds by position; weights by where they fall among the
+// weights the features use.
+func (p *PRD) PriorityTier(pri Priority) Priority {
+	tiers := ValidPriorities()
+	levels := p.AllowedPriorities()
+	i, n := lo.IndexOf(levels, pri), len(levels)
+	if w, ok := pri.Weight(); ok {
+		weights := p.weights()
+		i, n = lo.IndexOf(weights, w), len(weights)
+	}
+	if i < 0 {
+		return PriorityLow
+	}
+	return tiers[i*len(tiers)/n]
+}
+
+// weights returns the distinct weights features use, highest first
+func (p *PRD) weights() []int {
+	var weights []int
+	for _, f := range p.Features {
+		if w, ok := f.Priority.Weight(); ok && !slices.Contains(weights, w) {
+			weights = append(weights, w)
+		}
+	}
+	slices.Sort(weights)
+	slices.Reverse(weights)
+	return weights
+}
+
+// priorityOrder describes the order priorities are built in, for the rules
+// given to the agent
+func (p *PRD) priorityOrder() string {
+	if p.UsesWeights() {
+		return "higher weights first"
+	}
+	return strings.Join(lo.Map(p.AllowedPriorities(), func(pri Priority, _ int) string { return string(pri) }), " > ")
+}
@@ -0,0 +1,122 @@
+package prd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withLevels declares the categories api, migration and docs, and gives the
+// features category api and the priorities, in order
+func withLevels(p *PRD, priorities ...Priority) *PRD {
+	p.Categories = []Category{"api", "migration", "docs"}
+	for i, priority := range priorities {
+		p.Features[i].Category = "api"
+		p.Features[i].Priority = priority
+	}
+	return p
+}
+
+func TestPriorityJSON(t *testing.T) {
+	var f Feature
+	require.NoError(t, json.Unmarshal([]byte(`{"priority": 5}`), &f))
+	assert.Equal(t, Priority("5"), f.Priority)
+	w, ok := f.Priority.Weight()
+	assert.True(t, ok)
+	assert.Equal(t, 5, w)
+
+	data, err := json.Marshal(f)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"priority":5`, "weights are written as numbers")
+
+	require.NoError(t, json.Unmarshal([]byte(`{"priority": "p0"}`), &f))
+	assert.Equal(t, Priority("p0"), f.Priority)
+	_, ok = f.Priority.Weight()
+	assert.False(t, ok)
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"priority": 1.5}`), &f), "priority must be a name or a whole number")
+}
+
+func TestCustomPriorityLevels(t *testing.T) {
+	p := withLevels(testPRD("a", "b", "c", "d"), "p2", "p0", "p1", "p0")
+	p.Priorities = []Priority{"p0", "p1", "p2"}
+
+	assert.True(t, p.IsAllowedCategory("migration"))
+	assert.False(t, p.IsAllowedCategory(CategoryFunctional), "declared categories replace the built-in ones")
+	assert.False(t, p.IsAllowedPriority(PriorityHigh))
+	assert.True(t, p.IsAllowedPriority("p1"))
+	assert.Equal(t, Category("api"), p.DefaultCategory())
+	assert.Equal(t, Priority("p1"), p.DefaultPriority())
+
+	next, reason := p.NextFeatureWithReason()
+	require.NotNil(t, next)
+	assert.Equal(t, "b", next.ID, "the first feature of the highest level")
+	assert.Contains(t, reason, "p0 priority")
+	assert.Contains(t, p.Scheduler().Rules(p), "Pick the highest priority first (p0 > p1 > p2)")
+
+	assert.Equal(t, Priority("high"), p.PriorityTier("p0"))
+	assert.Equal(t, Priority("medium"), p.PriorityTier("p1"))
+	assert.Equal(t, Priority("low"), p.PriorityTier("p2"))
+	assert.Equal(t, Priority("low"), p.PriorityTier("unknown"))
+	assert.Equal(t, 3, p.ValueOrDefault(&p.Features[1]))
+
+	stats := p.Stats()
+	assert.Equal(t, []Category{"api", "migration", "docs"}, stats.Categories)
+	assert.Equal(t, []Priority{"p0", "p1", "p2"}, stats.Priorities)
+	assert.Equal(t, 2, stats.ByPriority["p0"].Total)
+}
+
+func TestWeightedPriorities(t *testing.T) {
+	p := withLevels(testPRD("a", "b", "c", "d"), "10", "50", "20", "50")
+
+	assert.True(t, p.UsesWeights())
+	next, _ := p.NextFeatureWithReason()
+	require.NotNil(t, next)
+	assert.Equal(t, "b", next.ID, "the highest weight, then PRD order")
+	assert.Contains(t, p.Scheduler().Rules(p), "Pick the highest priority first (higher weights first)")
+
+	assert.Equal(t, Priority("high"), p.PriorityTier("50"))
+	assert.Equal(t, Priority("medium"), p.PriorityTier("20"))
+	assert.Equal(t, Priority("low"), p.PriorityTier("10"))
+	assert.Equal(t, Priority("20"), p.DefaultPriority())
+	assert.Equal(t, 50, p.ValueOrDefault(&p.Features[1]))
+
+	assert.Equal(t, []Priority{"50", "20", "10"}, p.Stats().Priorities, "only weights in use, highest first")
+}
+
+func TestValidateLevels(t *testing.T) {
+	p := withLevels(testPRD("a", "b"), "p0", "p1")
+	p.Priorities = []Priority{"p0", "p1", "p0", " ", "3"}
+	p.Features[1].Category = CategoryFunctional
+
+	result := Validate(p)
+	assert.False(t, result.Valid)
+	assert.ElementsMatch(t, []string{
+		"priorities[2]: duplicate 'p0'",
+		"priorities[3]: cannot be empty",
+		"priorities[4]: '3' is a weight, not a level name (features can use weights without declaring them)",
+		"features[1].category: invalid category 'functional' (must be one of: api, migration, docs)",
+	}, errorStrings(result))
+
+	p = withLevels(testPRD("a", "b"), PriorityHigh, "5")
+	result = Validate(p)
+	assert.Equal(t, []string{
+		"features: mixes named priorities (a) with integer weights; use one or the other",
+	}, errorStrings(result))
+
+	p = withLevels(testPRD("a"), "urgent")
+	result = Validate(p)
+	assert.Equal(t, []string{
+		"features[0].priority: invalid priority 'urgent' (must be one of: high, medium, low, or an integer weight)",
+	}, errorStrings(result))
+}
+
+func errorStrings(result ValidationResult) []string {
+	var errs []string
+	for _, e := range result.Errors {
+		errs = append(errs, e.Error())
+	}
+	return errs
+}
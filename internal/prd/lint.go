@@ -0,0 +1,193 @@
+package prd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// LintRule identifies a lint check. Rule IDs are stable, so features can
+// suppress them and scripts can match on them.
+type LintRule string
+
+const (
+	LintVagueDescription  LintRule = "vague-description"        // Description too short or hand-wavy to build from
+	LintUnverifiableSteps LintRule = "unverifiable-steps"       // Steps that never say what to observe
+	LintTooManySteps      LintRule = "too-many-steps"           // Feature that should probably be split
+	LintDuplicate         LintRule = "duplicate-description"    // Same or nearly the same description as another feature
+	LintPlaceholderTest   LintRule = "placeholder-test-command" // testCommand that doesn't run any tests
+	LintPriorityInversion LintRule = "priority-inversion"       // Dependency with lower priority than its dependent
+)
+
+const (
+	maxSteps               = 8    // More steps than this is a split candidate
+	nearDuplicateThreshold = 0.75 // Share of distinct words two descriptions must have in common
+)
+
+// lintRules describes each rule, in the order they are checked
+var lintRules = []struct {
+	rule        LintRule
+	description string
+}{
+	{LintPlaceholderTest, "testCommand looks like a placeholder"},
+	{LintVagueDescription, "description is too short or vague to build from"},
+	{LintUnverifiableSteps, "no step has an observable outcome to verify"},
+	{LintTooManySteps, fmt.Sprintf("feature has more than %d steps and could be split", maxSteps)},
+	{LintDuplicate, "description duplicates another feature's"},
+	{LintPriorityInversion, "feature depends on a feature with lower priority"},
+}
+
+// LintRules returns the lint rules, in the order they are checked
+func LintRules() []LintRule {
+	rules := make([]LintRule, 0, len(lintRules))
+	for _, r := range lintRules {
+		rules = append(rules, r.rule)
+	}
+	return rules
+}
+
+// IsValid checks if a lint rule exists
+func (r LintRule) IsValid() bool {
+	return lo.Contains(LintRules(), r)
+}
+
+// Description says what the rule flags
+func (r LintRule) Description() string {
+	for _, rule := range lintRules {
+		if rule.rule == r {
+			return rule.description
+		}
+	}
+	return ""
+}
+
+// validLintRuleList returns a comma-separated list of lint rules
+func validLintRuleList() string {
+	return strings.Join(lo.Map(LintRules(), func(r LintRule, _ int) string { return string(r) }), ", ")
+}
+
+// LintWarning is a likely problem with a PRD that is still valid
+type LintWarning struct {
+	Rule    LintRule `json:"rule"`
+	Feature string   `json:"feature,omitempty"` // Empty for warnings about the PRD itself
+	Field   string   `json:"field"`
+	Message string   `json:"message"`
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%s: %s [%s]", w.Field, w.Message, w.Rule)
+}
+
+var (
+	// vagueWords are words that leave what to build up to the reader
+	vagueWords = []string{"etc", "stuff", "things", "misc", "miscellaneous", "various", "tbd", "todo", "something", "somehow", "whatever"}
+
+	// verificationWords are verbs and outcomes a step can be checked against
+	verificationWords = []string{
+		"verify", "verifies", "check", "checks", "confirm", "confirms", "assert", "asserts", "test", "tests",
+		"expect", "expects", "ensure", "ensures", "should", "must",
+		"see", "sees", "show", "shows", "shown", "display", "displays", "displayed",
+		"appear", "appears", "render", "renders", "rendered", "visible",
+		"return", "returns", "returned", "respond", "responds", "receive", "receives", "received",
+		"contain", "contains", "include", "includes", "match", "matches", "equal", "equals",
+		"exist", "exists", "redirect", "redirects", "redirected",
+		"output", "outputs", "print", "prints", "log", "logs", "logged",
+		"pass", "passes", "fail", "fails", "succeed", "succeeds", "reject", "rejects", "rejected",
+		"created", "updated", "deleted", "removed", "saved", "stored", "sent", "downloaded",
+	}
+
+	// placeholderTestCommand matches test commands that don't run tests:
+	// no-ops that are the whole command, npm's default script, and unfilled
+	// templates. Placeholder words only count on their own or inside <...>,
+	// so paths such as ./internal/todo are fine, as are shell redirections.
+	placeholderTestCommand = regexp.MustCompile(`(?i)^(true|:|exit 0|echo( [^&;|]*)?|\.\.\.|(todo|tbd|fixme|placeholder)(:.*)?)$|no test specified|<\w[^<>]*\w>`)
+
+	wordPattern = regexp.MustCompile(`[\pL\pN]+`)
+)
+
+// Lint checks a PRD for likely quality problems that Validate allows: vague
+// descriptions, steps that never say what to verify, features that should be split,
+// duplicate features, placeholder test commands and dependencies with lower
+// priority than their dependents. Features suppress rules with lintIgnore.
+func Lint(p *PRD) []LintWarning {
+	var warnings []LintWarning
+	add := func(f *Feature, rule LintRule, field, message string) {
+		w := LintWarning{Rule: rule, Field: field, Message: message}
+		if f != nil {
+			if lo.Contains(f.LintIgnore, rule) {
+				return
+			}
+			w.Feature = f.ID
+		}
+		warnings = append(warnings, w)
+	}
+
+	if cmd := strings.TrimSpace(p.TestCommand); cmd != "" && placeholderTestCommand.MatchString(cmd) {
+		add(nil, LintPlaceholderTest, "testCommand", fmt.Sprintf("'%s' looks like a placeholder; builds can't tell whether features work", cmd))
+	}
+
+	for i := range p.Features {
+		f := &p.Features[i]
+		prefix := fmt.Sprintf("features[%d]", i)
+
+		if words := lintWords(f.Description); len(words) > 0 && len(words) < 3 {
+			add(f, LintVagueDescription, prefix+".description", fmt.Sprintf("'%s' is too short to say what to build", f.Description))
+		} else if vague, ok := lo.Find(words, func(w string) bool { return lo.Contains(vagueWords, w) }); ok {
+			add(f, LintVagueDescription, prefix+".description", fmt.Sprintf("'%s' is vague; say what it covers instead", vague))
+		}
+
+		if len(f.Steps) > maxSteps {
+			add(f, LintTooManySteps, prefix+".steps", fmt.Sprintf("has %d steps (more than %d); consider splitting the feature", len(f.Steps), maxSteps))
+		}
+
+		if len(f.Steps) > 0 && !f.HasChecks() && !lo.SomeBy(f.Steps, isVerifiable) {
+			add(f, LintUnverifiableSteps, prefix+".steps", "no step says what to observe; add one such as 'Verify the order appears in the list'")
+		}
+
+		for _, other := range p.Features[:i] {
+			if message := duplicateMessage(f.Description, other); message != "" {
+				add(f, LintDuplicate, prefix+".description", message)
+				break
+			}
+		}
+
+		for _, id := range f.DependsOn {
+			dep := p.GetFeature(id)
+			if dep == nil || p.PriorityRank(dep.Priority) <= p.PriorityRank(f.Priority) {
+				continue
+			}
+			add(f, LintPriorityInversion, prefix+".depends_on", fmt.Sprintf("depends on %s, which has lower priority (%s < %s)", dep.ID, dep.Priority, f.Priority))
+		}
+	}
+
+	return warnings
+}
+
+// isVerifiable returns true if a step names something to observe
+func isVerifiable(step string) bool {
+	return lo.SomeBy(lintWords(step), func(w string) bool { return lo.Contains(verificationWords, w) })
+}
+
+// duplicateMessage describes how a description repeats another feature's,
+// or returns "" if it doesn't
+func duplicateMessage(description string, other Feature) string {
+	a, b := lo.Uniq(lintWords(description)), lo.Uniq(lintWords(other.Description))
+	if len(a) == 0 || len(b) == 0 {
+		return ""
+	}
+	if strings.Join(lintWords(description), " ") == strings.Join(lintWords(other.Description), " ") {
+		return fmt.Sprintf("duplicates %s's description", other.ID)
+	}
+	shared := len(lo.Intersect(a, b))
+	if float64(shared)/float64(len(lo.Union(a, b))) >= nearDuplicateThreshold {
+		return fmt.Sprintf("is nearly the same as %s's description ('%s')", other.ID, other.Description)
+	}
+	return ""
+}
+
+// lintWords splits text into lowercase words
+func lintWords(s string) []string {
+	return wordPattern.FindAllString(strings.ToLower(s), -1)
+}
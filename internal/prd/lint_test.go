@@ -0,0 +1,91 @@
+package prd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func warningRules(warnings []LintWarning) []string {
+	var rules []string
+	for _, w := range warnings {
+		rules = append(rules, w.Feature+" "+string(w.Rule))
+	}
+	return rules
+}
+
+func TestLintClean(t *testing.T) {
+	assert.Empty(t, Lint(testPRD("feat-001", "feat-002:feat-001")))
+}
+
+func TestLint(t *testing.T) {
+	p := testPRD("feat-001", "feat-002:feat-001")
+	p.Features[0].Description = "Customers can add items to a cart"
+	p.Features[1].Description = "Customers can pay by card"
+	p.TestCommand = `echo "Error: no test specified" && exit 1`
+	p.Features = append(p.Features,
+		Feature{ID: "feat-003", Priority: PriorityLow, Description: "Misc", Steps: []string{"Click the button"}},
+		Feature{ID: "feat-004", Priority: PriorityHigh, Description: "Customers can add items to their cart", Steps: []string{"See the cart"}, DependsOn: []string{"feat-003"}},
+		Feature{ID: "feat-005", Priority: PriorityLow, Description: "Customers can pay by card", Steps: []string{"1", "2", "3", "4", "5", "6", "7", "8", "Verify 9"}},
+	)
+
+	warnings := Lint(p)
+	assert.Equal(t, []string{
+		" placeholder-test-command",
+		"feat-003 vague-description",
+		"feat-003 unverifiable-steps",
+		"feat-004 duplicate-description",
+		"feat-004 priority-inversion",
+		"feat-005 too-many-steps",
+		"feat-005 duplicate-description",
+	}, warningRules(warnings))
+	assert.Equal(t, "features[3].depends_on: depends on feat-003, which has lower priority (low < high) [priority-inversion]", warnings[4].String())
+	assert.Equal(t, "is nearly the same as feat-001's description ('Customers can add items to a cart')", warnings[3].Message)
+	assert.Equal(t, "duplicates feat-002's description", warnings[6].Message)
+}
+
+func TestLintIgnore(t *testing.T) {
+	p := testPRD("feat-001", "feat-002:feat-001")
+	p.Features[0].Steps = []string{"Add an item"}
+	assert.Equal(t, []string{"feat-001 unverifiable-steps"}, warningRules(Lint(p)))
+
+	p.Features[0].Checks = []Check{{Step: 1, Command: "go test ./cart"}}
+	assert.Empty(t, Lint(p), "checks verify the feature")
+
+	p.Features[0].Checks = nil
+	p.Features[0].LintIgnore = []LintRule{LintUnverifiableSteps}
+	assert.Empty(t, Lint(p))
+	assert.True(t, Validate(p).Valid)
+
+	p.Features[0].LintIgnore = []LintRule{"long-steps"}
+	assert.Equal(t, []string{
+		"features[0].lintIgnore[0]: unknown lint rule 'long-steps' (must be one of: placeholder-test-command, vague-description, unverifiable-steps, too-many-steps, duplicate-description, priority-inversion)",
+	}, errorStrings(Validate(p)))
+}
+
+func TestLintIgnoreRoundTrip(t *testing.T) {
+	p := testPRD("feat-001", "feat-002:feat-001")
+	p.Features[0].LintIgnore = []LintRule{LintTooManySteps}
+	for _, name := range Filenames {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, Save(p, path))
+		loaded, err := Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, []LintRule{LintTooManySteps}, loaded.Features[0].LintIgnore, name)
+	}
+}
+
+func TestPlaceholderTestCommand(t *testing.T) {
+	for _, cmd := range []string{"true", "exit 0", "echo test", "echo", "TODO", "todo: add tests", "<your test command>", "go test <package>", "..."} {
+		assert.True(t, placeholderTestCommand.MatchString(cmd), cmd)
+	}
+	for _, cmd := range []string{
+		"go test ./...", "npm test", "pytest -q", "make test && echo done",
+		"go test ./internal/todo/...", "pytest tests/placeholder_test.py", "echo running && go test ./...",
+		"echo start; npm test", "go test ./... < fixtures.txt > test.log",
+	} {
+		assert.False(t, placeholderTestCommand.MatchString(cmd), cmd)
+	}
+}
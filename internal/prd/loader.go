@@ -1,35 +1,30 @@
 package prd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 )
 
+// DefaultFilename is the PRD file created when a directory has none. See
+// Filenames for the other formats.
 const DefaultFilename = "prd.json"
 
-// Load loads a PRD from the specified path
+// Load loads a PRD from the specified path, in the format its extension
+// names. Files from older schema versions are migrated in memory; the file
+// itself is left alone.
 func Load(path string) (*PRD, error) {
-	data, err := os.ReadFile(path)
+	data, err := readFile(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("prd.json not found at %s", path)
-		}
-		return nil, fmt.Errorf("failed to read prd.json: %w", err)
-	}
-
-	var prd PRD
-	if err := json.Unmarshal(data, &prd); err != nil {
-		return nil, fmt.Errorf("failed to parse prd.json: %w", err)
+		return nil, err
 	}
-
-	return &prd, nil
+	p, _, err := decode(path, data)
+	return p, err
 }
 
-// LoadFromDir loads a PRD from the default filename in the specified directory
+// LoadFromDir loads the PRD file in the specified directory
 func LoadFromDir(dir string) (*PRD, error) {
-	return Load(filepath.Join(dir, DefaultFilename))
+	return Load(PathInDir(dir))
 }
 
 // LoadFromCurrentDir loads a PRD from the current working directory
@@ -41,23 +36,34 @@ func LoadFromCurrentDir() (*PRD, error) {
 	return LoadFromDir(cwd)
 }
 
-// Save saves the PRD to the specified path
+// Save saves the PRD to the specified path, in the format its extension
+// names. An existing file keeps what its format allows, such as comments.
+// Like Update, overwriting a file from an older schema version keeps a
+// backup of the original.
 func Save(p *PRD, path string) error {
-	data, err := json.MarshalIndent(p, "", "  ")
+	original, err := readExisting(path)
 	if err != nil {
-		return fmt.Errorf("failed to marshal prd: %w", err)
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
 	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write prd.json: %w", err)
+	if original != nil {
+		// A file that no longer parses has nothing to migrate
+		if _, migration, err := decode(path, original); err == nil && migration.NeedsMigration() {
+			if _, err := backup(path, original, migration.From); err != nil {
+				return err
+			}
+		}
+	}
+	data, err := encode(p, path, original)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return writeFile(path, data)
 }
 
-// SaveToDir saves the PRD to the default filename in the specified directory
+// SaveToDir saves the PRD to the PRD file in the specified directory
 func SaveToDir(p *PRD, dir string) error {
-	return Save(p, filepath.Join(dir, DefaultFilename))
+	return Save(p, PathInDir(dir))
 }
 
 // SaveToCurrentDir saves the PRD to the current working directory
@@ -75,9 +81,9 @@ func Exists(path string) bool {
 	return err == nil
 }
 
-// ExistsInDir checks if a PRD file exists in the specified directory
+// ExistsInDir checks if a PRD file in any format exists in the specified directory
 func ExistsInDir(dir string) bool {
-	return Exists(filepath.Join(dir, DefaultFilename))
+	return Exists(PathInDir(dir))
 }
 
 // ExistsInCurrentDir checks if a PRD file exists in the current directory
@@ -95,5 +101,5 @@ func GetPath() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to get current directory: %w", err)
 	}
-	return filepath.Join(cwd, DefaultFilename), nil
+	return PathInDir(cwd), nil
 }
@@ -0,0 +1,393 @@
+package prd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// markdownCodec reads and writes prd.md: project fields in YAML front
+// matter, a "## <id>: <description>" heading per feature with attribute
+// bullets, and a checkbox per step. A feature passes when all of its boxes
+// are checked. Fields without a Markdown form go in a yaml code block in the
+// feature. Saving keeps the text before the first feature, notes written
+// under each feature and comments in the YAML.
+//
+//	---
+//	name: Shop
+//	testCommand: go test ./...
+//	---
+//
+//	## feat-001: Customers can check out
+//
+//	- category: functional
+//	- priority: high
+//	- depends on: feat-000
+//	- status: in_progress
+//
+//	- [x] Add an item to the cart
+//	- [ ] Pay with a card
+type markdownCodec struct{}
+
+// markdownDoc is a parsed prd.md
+type markdownDoc struct {
+	frontMatter string
+	preamble    string // Text between the front matter and the first feature
+	features    []*markdownFeature
+}
+
+// markdownFeature is a feature section of prd.md
+type markdownFeature struct {
+	line        int
+	id          string
+	description string
+	attrs       map[string]string // Attribute bullets by normalized key
+	attrLines   map[string]int
+	steps       []string
+	checked     []bool
+	notes       []string // Lines that aren't attributes, steps or the yaml block
+	extra       string   // Contents of the yaml code block
+}
+
+// markdownAttributes are the feature fields written as "- key: value" bullets
+var markdownAttributes = []string{"category", "priority", "depends on", "value", "effort", "status", "blocked reason"}
+
+// markdownFields are the feature fields with a Markdown form; the rest go in
+// the yaml code block
+var markdownFields = []string{"id", "description", "category", "priority", "depends_on", "value", "effort", "status", "blockedReason", "steps", "passes"}
+
+var (
+	checkboxPattern  = regexp.MustCompile(`^[-*+] \[([ xX])\] ?(.*)$`)
+	attributePattern = regexp.MustCompile(`^[-*+] ([A-Za-z][A-Za-z _-]*):\s*(.*)$`)
+)
+
+// parseMarkdown splits prd.md into its parts
+func parseMarkdown(data []byte) (*markdownDoc, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	doc := &markdownDoc{}
+
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		end := slices.IndexFunc(lines[1:], func(line string) bool {
+			return strings.TrimSpace(line) == "---" || strings.TrimSpace(line) == "..."
+		})
+		if end < 0 {
+			return nil, fmt.Errorf("the front matter has no closing ---")
+		}
+		doc.frontMatter = strings.Join(lines[1:end+1], "\n")
+		start = end + 2
+	}
+
+	var preamble []string
+	var f *markdownFeature
+	var fence string // Marker of the open code block, if any
+	inExtra := false
+	lastStep := false
+	for i := start; i < len(lines); i++ {
+		line, n := lines[i], i+1
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+				if inExtra {
+					inExtra = false
+					continue
+				}
+			} else if inExtra {
+				f.extra += line + "\n"
+				continue
+			}
+		} else if marker := fenceMarker(trimmed); marker != "" {
+			fence = marker
+			info := strings.TrimSpace(strings.TrimLeft(trimmed, marker[:1]))
+			if f != nil && f.extra == "" && (info == "yaml" || info == "yml") {
+				inExtra = true
+				continue
+			}
+		} else if heading, ok := strings.CutPrefix(line, "## "); ok {
+			id, description, found := strings.Cut(heading, ":")
+			if !found || strings.TrimSpace(id) == "" {
+				return nil, fmt.Errorf("line %d: feature headings must look like \"## feat-001: Description\"", n)
+			}
+			f = &markdownFeature{
+				line:        n,
+				id:          strings.TrimSpace(id),
+				description: strings.TrimSpace(strings.TrimRight(strings.TrimSpace(description), "#")),
+				attrs:       make(map[string]string),
+				attrLines:   make(map[string]int),
+			}
+			doc.features = append(doc.features, f)
+			lastStep = false
+			continue
+		}
+
+		if f == nil {
+			preamble = append(preamble, line)
+			continue
+		}
+		if fence == "" {
+			if m := checkboxPattern.FindStringSubmatch(line); m != nil {
+				f.steps = append(f.steps, strings.TrimSpace(m[2]))
+				f.checked = append(f.checked, m[1] != " ")
+				lastStep = true
+				continue
+			}
+			if lastStep && strings.HasPrefix(line, "  ") && trimmed != "" {
+				f.steps[len(f.steps)-1] += " " + trimmed
+				continue
+			}
+			if m := attributePattern.FindStringSubmatch(line); m != nil {
+				key := strings.Join(strings.FieldsFunc(strings.ToLower(m[1]), func(r rune) bool {
+					return r == ' ' || r == '_' || r == '-'
+				}), " ")
+				if slices.Contains(markdownAttributes, key) {
+					f.attrs[key] = strings.TrimSpace(m[2])
+					f.attrLines[key] = n
+					lastStep = false
+					continue
+				}
+			}
+		}
+		lastStep = false
+		f.notes = append(f.notes, line)
+	}
+	if fence != "" {
+		return nil, fmt.Errorf("a code block opened with %s is never closed", fence)
+	}
+
+	doc.preamble = trimBlankLines(preamble)
+	return doc, nil
+}
+
+// fenceMarker returns the ``` or ~~~ run that opens a code block, if the line opens one
+func fenceMarker(line string) string {
+	for _, c := range []string{"`", "~"} {
+		if strings.HasPrefix(line, c+c+c) {
+			return line[:len(line)-len(strings.TrimLeft(line, c))]
+		}
+	}
+	return ""
+}
+
+// trimBlankLines joins lines, dropping blank lines at either end
+func trimBlankLines(lines []string) string {
+	return strings.Trim(strings.Join(lines, "\n"), "\n \t")
+}
+
+func (markdownCodec) document(data []byte) (map[string]any, error) {
+	md, err := parseMarkdown(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]any)
+	if strings.TrimSpace(md.frontMatter) != "" {
+		var v any
+		if err := yaml.Unmarshal([]byte(md.frontMatter), &v); err != nil {
+			return nil, fmt.Errorf("front matter: %w", err)
+		}
+		if doc, err = normalize(v); err != nil {
+			return nil, fmt.Errorf("front matter: %w", err)
+		}
+	}
+
+	features := make([]any, 0, len(md.features))
+	for _, f := range md.features {
+		feature := make(map[string]any)
+		if f.extra != "" {
+			var v any
+			if err := yaml.Unmarshal([]byte(f.extra), &v); err != nil {
+				return nil, fmt.Errorf("line %d: yaml block of %s: %w", f.line, f.id, err)
+			}
+			if feature, err = normalize(v); err != nil {
+				return nil, fmt.Errorf("line %d: yaml block of %s: %w", f.line, f.id, err)
+			}
+			if feature == nil {
+				feature = make(map[string]any)
+			}
+		}
+
+		feature["id"] = f.id
+		feature["description"] = f.description
+		steps := make([]any, len(f.steps))
+		for i, step := range f.steps {
+			steps[i] = step
+		}
+		feature["steps"] = steps
+		feature["passes"] = len(f.checked) > 0 && !slices.Contains(f.checked, false)
+
+		for key, value := range f.attrs {
+			switch key {
+			case "category", "priority", "status":
+				feature[key] = value
+			case "blocked reason":
+				feature["blockedReason"] = value
+			case "depends on":
+				deps := []any{}
+				for _, dep := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+					deps = append(deps, dep)
+				}
+				feature["depends_on"] = deps
+			case "value", "effort":
+				if value == "" {
+					continue
+				}
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s of %s must be a whole number, got %q", f.attrLines[key], key, f.id, value)
+				}
+				feature[key] = n
+			}
+		}
+		features = append(features, feature)
+	}
+	doc["features"] = features
+	return normalize(doc)
+}
+
+func (markdownCodec) encode(p *PRD, original []byte) ([]byte, error) {
+	old := &markdownDoc{preamble: "# " + p.Name}
+	if original != nil {
+		if parsed, err := parseMarkdown(original); err == nil {
+			old = parsed
+		}
+	}
+
+	// Front matter: every project field but the features
+	project := *p
+	project.Features = nil
+	node, err := yamlNode(project)
+	if err != nil {
+		return nil, err
+	}
+	removeKeys(node.Content[0], "features")
+	if old.frontMatter != "" {
+		var oldNode yaml.Node
+		if yaml.Unmarshal([]byte(old.frontMatter), &oldNode) == nil {
+			copyComments(&oldNode, node)
+		}
+	}
+	frontMatter, err := encodeYAML(node)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("---\n" + string(frontMatter) + "---\n")
+	if old.preamble != "" {
+		sb.WriteString("\n" + old.preamble + "\n")
+	}
+
+	for i := range p.Features {
+		f := &p.Features[i]
+		var oldFeature *markdownFeature
+		if j := slices.IndexFunc(old.features, func(o *markdownFeature) bool { return o.id == f.ID }); j >= 0 {
+			oldFeature = old.features[j]
+		}
+		if err := writeMarkdownFeature(&sb, f, oldFeature); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(sb.String()), nil
+}
+
+// writeMarkdownFeature writes a feature section, keeping the notes and the
+// checked steps of its previous version
+func writeMarkdownFeature(sb *strings.Builder, f *Feature, old *markdownFeature) error {
+	sb.WriteString(fmt.Sprintf("\n## %s: %s\n\n", f.ID, oneLine(f.Description)))
+	sb.WriteString(fmt.Sprintf("- category: %s\n", f.Category))
+	sb.WriteString(fmt.Sprintf("- priority: %s\n", f.Priority))
+	if len(f.DependsOn) > 0 {
+		sb.WriteString(fmt.Sprintf("- depends on: %s\n", strings.Join(f.DependsOn, ", ")))
+	}
+	if f.Value != 0 {
+		sb.WriteString(fmt.Sprintf("- value: %d\n", f.Value))
+	}
+	if f.Effort != 0 {
+		sb.WriteString(fmt.Sprintf("- effort: %d\n", f.Effort))
+	}
+	if f.Status != "" {
+		sb.WriteString(fmt.Sprintf("- status: %s\n", f.Status))
+	}
+	if f.BlockedReason != "" {
+		sb.WriteString(fmt.Sprintf("- blocked reason: %s\n", oneLine(f.BlockedReason)))
+	}
+
+	if old != nil {
+		if notes := trimBlankLines(old.notes); notes != "" {
+			sb.WriteString("\n" + notes + "\n")
+		}
+	}
+
+	// An unfinished feature keeps the boxes that were checked, as long as
+	// that doesn't make it read as passing
+	checked := make([]bool, len(f.Steps))
+	for i, step := range f.Steps {
+		checked[i] = f.Passes
+		if !f.Passes && old != nil {
+			if j := slices.Index(old.steps, oneLine(step)); j >= 0 {
+				checked[i] = old.checked[j]
+			}
+		}
+	}
+	if !f.Passes && !slices.Contains(checked, false) {
+		clear(checked)
+	}
+	if len(f.Steps) > 0 {
+		sb.WriteString("\n")
+	}
+	for i, step := range f.Steps {
+		box := "[ ]"
+		if checked[i] {
+			box = "[x]"
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s\n", box, oneLine(step)))
+	}
+
+	// Everything else goes in a yaml code block
+	node, err := yamlNode(f)
+	if err != nil {
+		return err
+	}
+	removeKeys(node.Content[0], markdownFields...)
+	if len(node.Content[0].Content) == 0 {
+		return nil
+	}
+	if old != nil && old.extra != "" {
+		var oldNode yaml.Node
+		if yaml.Unmarshal([]byte(old.extra), &oldNode) == nil {
+			copyComments(&oldNode, node)
+		}
+	}
+	extra, err := encodeYAML(node)
+	if err != nil {
+		return err
+	}
+	sb.WriteString("\n```yaml\n" + string(extra) + "```\n")
+	return nil
+}
+
+// removeKeys deletes entries from a mapping node
+func removeKeys(node *yaml.Node, keys ...string) {
+	var content []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !slices.Contains(keys, node.Content[i].Value) {
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+	}
+	node.Content = content
+}
+
+// oneLine puts a string on a single line. Strings already on one line are
+// kept as written, apart from surrounding space, which Markdown drops.
+func oneLine(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return strings.TrimSpace(s)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,246 @@
+package prd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CurrentSchemaVersion is the prd.json schema version this build reads and
+// writes. Files without a schemaVersion are version 0.
+const CurrentSchemaVersion = 2
+
+// Migration upgrades a decoded prd.json document by one schema version
+type Migration struct {
+	To          int    // Schema version the migration upgrades to
+	Description string // What changes, shown when the migration runs
+	Apply       func(doc map[string]any) error
+}
+
+// migrations upgrade version i to i+1. Add a migration, and bump
+// CurrentSchemaVersion, whenever a change to the PRD types would misread or
+// reject files that are already out there.
+var migrations = []Migration{
+	{
+		To:          1,
+		Description: "record the schema version; accept dependsOn and test_command spellings and capitalized categories and priorities",
+		Apply: func(doc map[string]any) error {
+			renameKey(doc, "test_command", "testCommand")
+			for _, feature := range documentFeatures(doc) {
+				renameKey(feature, "dependsOn", "depends_on")
+				renameKey(feature, "depends-on", "depends_on")
+				for _, key := range []string{"category", "priority"} {
+					if s, ok := feature[key].(string); ok {
+						feature[key] = strings.ToLower(strings.TrimSpace(s))
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		To:          2,
+		Description: "spell blocked_reason, started_at, completed_at, lint_ignore and exit_code in camelCase",
+		Apply: func(doc map[string]any) error {
+			for _, feature := range documentFeatures(doc) {
+				renameKey(feature, "blocked_reason", "blockedReason")
+				renameKey(feature, "started_at", "startedAt")
+				renameKey(feature, "completed_at", "completedAt")
+				renameKey(feature, "lint_ignore", "lintIgnore")
+				checks, _ := feature["checks"].([]any)
+				for _, c := range append(checks, feature["verify"]) {
+					if check, ok := c.(map[string]any); ok {
+						renameKey(check, "exit_code", "exitCode")
+					}
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// documentFeatures returns the feature objects of a decoded document
+func documentFeatures(doc map[string]any) []map[string]any {
+	var features []map[string]any
+	list, _ := doc["features"].([]any)
+	for _, f := range list {
+		if feature, ok := f.(map[string]any); ok {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// renameKey moves a value to a new key, unless the new key is already set
+func renameKey(m map[string]any, from, to string) {
+	if v, ok := m[from]; ok {
+		if _, exists := m[to]; !exists {
+			m[to] = v
+		}
+		delete(m, from)
+	}
+}
+
+// MigrationResult describes the migrations a prd.json file needs or got
+type MigrationResult struct {
+	From    int      // Schema version of the file
+	To      int      // Schema version after migrating
+	Applied []string // Descriptions of the migrations, oldest first
+	Backup  string   // Copy of the original file, once migrated
+}
+
+// NeedsMigration returns true if the file is older than CurrentSchemaVersion
+func (r *MigrationResult) NeedsMigration() bool {
+	return len(r.Applied) > 0
+}
+
+// Migrate upgrades a decoded prd.json document to CurrentSchemaVersion in
+// place and returns what changed. Documents from a newer version are rejected
+// rather than guessed at.
+func Migrate(doc map[string]any) (*MigrationResult, error) {
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("prd.json has schema version %d, but this version of superralph only supports up to %d; upgrade superralph",
+			version, CurrentSchemaVersion)
+	}
+
+	result := &MigrationResult{From: version, To: version}
+	for _, m := range migrations[version:] {
+		if err := m.Apply(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate prd.json to schema version %d: %w", m.To, err)
+		}
+		doc["schemaVersion"] = m.To
+		result.To = m.To
+		result.Applied = append(result.Applied, fmt.Sprintf("v%d: %s", m.To, m.Description))
+	}
+	return result, nil
+}
+
+// documentVersion returns a document's schemaVersion, or 0 if it has none
+func documentVersion(doc map[string]any) (int, error) {
+	v, ok := doc["schemaVersion"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != math.Trunc(n) {
+		return 0, fmt.Errorf("schemaVersion must be a whole number, got %v", v)
+	}
+	return int(n), nil
+}
+
+// decode parses the contents of the PRD file at path, in the format its
+// extension names, migrating older schema versions in memory
+func decode(path string, data []byte) (*PRD, *MigrationResult, error) {
+	name := filepath.Base(path)
+	doc, err := codecFor(FormatOf(path)).document(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	result, err := Migrate(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode through JSON so every format follows the json tags
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	var p PRD
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	p.syncStatus(time.Now().UTC())
+	return &p, result, nil
+}
+
+// encode writes a PRD in the format of path, keeping what it can of the
+// original contents (nil for a new file). What it writes is the current
+// schema version, so it says so.
+func encode(p *PRD, path string, original []byte) ([]byte, error) {
+	if p.SchemaVersion != CurrentSchemaVersion {
+		current := *p
+		current.SchemaVersion = CurrentSchemaVersion
+		p = &current
+	}
+	out, err := codecFor(FormatOf(path)).encode(p, original)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal prd: %w", err)
+	}
+	return out, nil
+}
+
+// CheckMigrations returns the migrations the PRD file at path needs, without
+// changing it
+func CheckMigrations(path string) (*MigrationResult, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_, result, err := decode(path, data)
+	return result, err
+}
+
+// MigrateFile upgrades the PRD file at path to CurrentSchemaVersion in place.
+// The original is copied to .superralph/backups next to it first. Files that
+// are up to date are left alone.
+func MigrateFile(path string) (*MigrationResult, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, result, err := decode(path, data)
+	if err != nil || !result.NeedsMigration() {
+		return result, err
+	}
+
+	if result.Backup, err = backup(path, data, result.From); err != nil {
+		return nil, err
+	}
+	out, err := encode(p, path, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(path, out); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// backup copies the original contents of a PRD file of the given schema
+// version to .superralph/backups, returning the backup's path
+func backup(path string, data []byte, version int) (string, error) {
+	dir := filepath.Join(filepath.Dir(path), ".superralph", "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to back up prd.json: %w", err)
+	}
+	name := fmt.Sprintf("%s.v%d.backup", filepath.Base(path), version)
+	backupPath := filepath.Join(dir, name)
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up prd.json: %w", err)
+	}
+	return backupPath, nil
+}
+
+// readFile reads a PRD file
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found at %s", filepath.Base(path), path)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return data, nil
+}
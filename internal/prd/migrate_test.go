@@ -0,0 +1,175 @@
+package prd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyPRD is a version 0 prd.json with spellings the first migration fixes
+const legacyPRD = `{
+	"name": "Legacy",
+	"description": "Written before schemaVersion",
+	"test_command": "go test ./...",
+	"features": [
+		{"id": "feat-001", "category": "Functional", "priority": "HIGH", "description": "First", "steps": ["Step"], "passes": true},
+		{"id": "feat-002", "category": "ui", "priority": "low", "description": "Second", "steps": ["Step"], "passes": false, "dependsOn": ["feat-001"]}
+	]
+}
+`
+
+func TestMigrations(t *testing.T) {
+	require.Len(t, migrations, CurrentSchemaVersion)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.To, "migrations[%d] must upgrade to version %d", i, i+1)
+	}
+}
+
+func TestLoadMigratesInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyPRD), 0644))
+
+	p, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, CurrentSchemaVersion, p.SchemaVersion)
+	assert.Equal(t, "go test ./...", p.TestCommand)
+	assert.Equal(t, CategoryFunctional, p.Features[0].Category)
+	assert.Equal(t, PriorityHigh, p.Features[0].Priority)
+	assert.Equal(t, []string{"feat-001"}, p.Features[1].DependsOn)
+	assert.True(t, Validate(p).Valid)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, legacyPRD, string(data), "Load leaves the file alone")
+}
+
+func TestMigrateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prd.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyPRD), 0644))
+
+	check, err := CheckMigrations(path)
+	require.NoError(t, err)
+	assert.True(t, check.NeedsMigration())
+	assert.Empty(t, check.Backup)
+
+	result, err := MigrateFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.From)
+	assert.Equal(t, CurrentSchemaVersion, result.To)
+	assert.Len(t, result.Applied, CurrentSchemaVersion)
+	assert.Equal(t, filepath.Join(dir, ".superralph", "backups", "prd.json.v0.backup"), result.Backup)
+
+	backup, err := os.ReadFile(result.Backup)
+	require.NoError(t, err)
+	assert.Equal(t, legacyPRD, string(backup))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), fmt.Sprintf("\n\t\"schemaVersion\": %d,\n\t\"name\": \"Legacy\"", CurrentSchemaVersion), "keeps the indentation")
+	assert.Contains(t, string(data), `"depends_on": [`)
+	assert.NotContains(t, string(data), "dependsOn")
+
+	// Up-to-date files are left alone
+	result, err = MigrateFile(path)
+	require.NoError(t, err)
+	assert.False(t, result.NeedsMigration())
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(after))
+}
+
+func TestUpdateBacksUpLegacyFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prd.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyPRD), 0644))
+
+	_, err := Update(path, func(p *PRD) error {
+		_, err := p.Reopen("feat-001")
+		return err
+	})
+	require.NoError(t, err)
+
+	backup, err := os.ReadFile(filepath.Join(dir, ".superralph", "backups", "prd.json.v0.backup"))
+	require.NoError(t, err)
+	assert.Equal(t, legacyPRD, string(backup))
+}
+
+func TestSaveBacksUpLegacyFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prd.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyPRD), 0644))
+
+	p, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, Save(p, path))
+
+	backupPath := filepath.Join(dir, ".superralph", "backups", "prd.json.v0.backup")
+	backup, err := os.ReadFile(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, legacyPRD, string(backup))
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), fmt.Sprintf(`"schemaVersion": %d`, CurrentSchemaVersion))
+
+	// Saving the migrated file again leaves the backup alone, even from a
+	// PRD that doesn't set its version
+	require.NoError(t, os.Remove(backupPath))
+	p.SchemaVersion = 0
+	require.NoError(t, Save(p, path))
+	require.NoError(t, Save(p, path))
+	assert.NoFileExists(t, backupPath)
+}
+
+func TestMigrateCamelCaseFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+	"schemaVersion": 1,
+	"name": "Shop",
+	"description": "An online shop",
+	"testCommand": "go test ./...",
+	"features": [
+		{"id": "feat-001", "category": "functional", "priority": "high", "description": "First", "steps": ["Step"], "passes": false,
+		 "status": "blocked", "blocked_reason": "Needs keys", "started_at": "2026-01-02T03:04:05Z", "lint_ignore": ["too-many-steps"]},
+		{"id": "feat-002", "category": "functional", "priority": "high", "description": "Second", "steps": ["Step"], "passes": true,
+		 "status": "passing", "completed_at": "2026-01-03T03:04:05Z",
+		 "verify": {"command": "false", "exit_code": 1}, "checks": ["true", {"step": 1, "command": "exit 2", "exit_code": 2}]}
+	]
+}
+`), 0644))
+
+	result, err := MigrateFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.From)
+
+	p, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Needs keys", p.Features[0].BlockedReason)
+	require.NotNil(t, p.Features[0].StartedAt)
+	assert.Equal(t, []LintRule{"too-many-steps"}, p.Features[0].LintIgnore)
+	assert.Equal(t, 2026, p.Features[0].StartedAt.Year())
+	require.NotNil(t, p.Features[1].CompletedAt)
+	assert.Equal(t, 1, p.Features[1].Verify.ExitCode)
+	assert.Equal(t, 2, p.Features[1].Checks[1].ExitCode)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"blockedReason": "Needs keys"`)
+	assert.NotContains(t, string(data), "blocked_reason")
+	assert.NotContains(t, string(data), "started_at")
+	assert.NotContains(t, string(data), "completed_at")
+	assert.NotContains(t, string(data), "exit_code")
+	assert.NotContains(t, string(data), "lint_ignore")
+}
+
+func TestMigrateRejectsNewerVersions(t *testing.T) {
+	_, err := Migrate(map[string]any{"schemaVersion": float64(CurrentSchemaVersion + 1)})
+	assert.ErrorContains(t, err, "upgrade superralph")
+
+	_, err = Migrate(map[string]any{"schemaVersion": "one"})
+	assert.EqualError(t, err, "schemaVersion must be a whole number, got one")
+}
@@ -0,0 +1,288 @@
+package prd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Scheduler decides which feature is built next
+type Scheduler interface {
+	// Strategy is the name the scheduler is configured by
+	Strategy() Strategy
+
+	// Next returns the next feature to work on and why it was picked, or nil
+	// if no feature is ready
+	Next(p *PRD) (*Feature, string)
+
+	// Rules tell the agent how to pick the next feature among those that
+	// aren't passing and have their dependencies met. The PRD may be nil, for
+	// the default priority levels.
+	Rules(p *PRD) []string
+}
+
+// Strategy names a scheduling strategy
+type Strategy string
+
+const (
+	StrategyPriority     Strategy = "priority"      // Highest priority first, then PRD order
+	StrategyCriticalPath Strategy = "critical-path" // Start of the longest chain of remaining work first
+	StrategyUnblock      Strategy = "unblock"       // The feature most others wait on first
+	StrategyRoundRobin   Strategy = "round-robin"   // Take turns between categories
+	StrategyValueEffort  Strategy = "value-effort"  // Best value for the effort first
+)
+
+// ValidStrategies returns all valid strategies
+func ValidStrategies() []Strategy {
+	return []Strategy{StrategyPriority, StrategyCriticalPath, StrategyUnblock, StrategyRoundRobin, StrategyValueEffort}
+}
+
+// IsValid checks if a strategy is valid
+func (s Strategy) IsValid() bool {
+	return lo.Contains(ValidStrategies(), s)
+}
+
+// ParseStrategy validates a strategy name
+func ParseStrategy(s string) (Strategy, error) {
+	if strategy := Strategy(s); strategy.IsValid() {
+		return strategy, nil
+	}
+	return "", fmt.Errorf("invalid strategy %q (must be one of: %s)", s, validStrategyList())
+}
+
+// validStrategyList returns a comma-separated list of valid strategies
+func validStrategyList() string {
+	return strings.Join(lo.Map(ValidStrategies(), func(s Strategy, _ int) string { return string(s) }), ", ")
+}
+
+// Scheduler returns the scheduler for the strategy. Unknown strategies
+// schedule by priority.
+func (s Strategy) Scheduler() Scheduler {
+	switch s {
+	case StrategyCriticalPath:
+		return criticalPathScheduler{}
+	case StrategyUnblock:
+		return unblockScheduler{}
+	case StrategyRoundRobin:
+		return roundRobinScheduler{}
+	case StrategyValueEffort:
+		return valueEffortScheduler{}
+	default:
+		return priorityScheduler{}
+	}
+}
+
+// Scheduler returns the scheduler for the PRD's strategy (default: priority)
+func (p *PRD) Scheduler() Scheduler {
+	return p.Strategy.Scheduler()
+}
+
+// priorityScheduler picks the highest priority first, then the first in the PRD
+type priorityScheduler struct{}
+
+func (priorityScheduler) Strategy() Strategy { return StrategyPriority }
+
+func (priorityScheduler) Rules(p *PRD) []string {
+	return []string{
+		fmt.Sprintf("Pick the highest priority first (%s)", p.priorityOrder()),
+		"Within same priority, pick first by ID order",
+	}
+}
+
+func (priorityScheduler) Next(p *PRD) (*Feature, string) {
+	next := p.highestPriorityReady()
+	if next == nil {
+		return nil, ""
+	}
+
+	reasons := []string{"not yet passing"}
+	if len(next.DependsOn) > 0 {
+		reasons = append(reasons, "all dependencies met")
+	}
+	reasons = append(reasons, fmt.Sprintf("%s priority", next.Priority))
+
+	// Check if there are any higher-priority features that are blocked
+	blockedHigherPriority := p.getBlockedHigherPriorityFeatures(next.Priority)
+	if len(blockedHigherPriority) > 0 {
+		reasons = append(reasons, fmt.Sprintf("features %v blocked by unmet dependencies", blockedHigherPriority))
+	}
+	return next, fmt.Sprintf("Selected %s: %s", next.ID, strings.Join(reasons, ", "))
+}
+
+// highestPriorityReady returns the first feature of the highest priority
+// that isn't passing and has its dependencies met. Features with a priority
+// the PRD doesn't know are never picked.
+func (p *PRD) highestPriorityReady() *Feature {
+	var best *Feature
+	for i := range p.Features {
+		f := &p.Features[i]
+		if !f.IsOpen() || !p.IsAllowedPriority(f.Priority) || !p.DependenciesMet(f) {
+			continue
+		}
+		if best == nil || p.PriorityRank(f.Priority) < p.PriorityRank(best.Priority) {
+			best = f
+		}
+	}
+	return best
+}
+
+// criticalPathScheduler picks the feature that starts the longest chain of
+// unfinished features, so the work that takes the most iterations starts first
+type criticalPathScheduler struct{}
+
+func (criticalPathScheduler) Strategy() Strategy { return StrategyCriticalPath }
+
+func (criticalPathScheduler) Rules(p *PRD) []string {
+	return []string{
+		"Pick the feature that starts the longest chain of unfinished features, each depending on the one before",
+		fmt.Sprintf("Break ties by priority (%s), then by ID order", p.priorityOrder()),
+	}
+}
+
+func (criticalPathScheduler) Next(p *PRD) (*Feature, string) {
+	chains := p.downstreamChains()
+	next := bestReady(p, func(f *Feature) float64 { return float64(len(chains[f.ID])) })
+	if next == nil {
+		return nil, ""
+	}
+	chain := chains[next.ID]
+	if len(chain) == 1 {
+		return next, fmt.Sprintf("Selected %s: no unfinished feature depends on it, %s priority", next.ID, next.Priority)
+	}
+	return next, fmt.Sprintf("Selected %s: starts the longest remaining chain (%s, %d features), %s priority",
+		next.ID, strings.Join(chain, " -> "), len(chain), next.Priority)
+}
+
+// unblockScheduler picks the feature the most unfinished features wait on,
+// directly or through other features
+type unblockScheduler struct{}
+
+func (unblockScheduler) Strategy() Strategy { return StrategyUnblock }
+
+func (unblockScheduler) Rules(p *PRD) []string {
+	return []string{
+		"Pick the feature that the most unfinished features depend on, directly or through other features",
+		fmt.Sprintf("Break ties by priority (%s), then by ID order", p.priorityOrder()),
+	}
+}
+
+func (unblockScheduler) Next(p *PRD) (*Feature, string) {
+	waiting := make(map[string][]string)
+	next := bestReady(p, func(f *Feature) float64 {
+		waiting[f.ID] = p.waitingOn(f.ID)
+		return float64(len(waiting[f.ID]))
+	})
+	if next == nil {
+		return nil, ""
+	}
+	if len(waiting[next.ID]) == 0 {
+		return next, fmt.Sprintf("Selected %s: no unfinished feature depends on it, %s priority", next.ID, next.Priority)
+	}
+	return next, fmt.Sprintf("Selected %s: unblocks the most features (%d: %s), %s priority",
+		next.ID, len(waiting[next.ID]), strings.Join(waiting[next.ID], ", "), next.Priority)
+}
+
+// roundRobinScheduler takes turns between categories by picking from the
+// category with the fewest passing features
+type roundRobinScheduler struct{}
+
+func (roundRobinScheduler) Strategy() Strategy { return StrategyRoundRobin }
+
+func (roundRobinScheduler) Rules(p *PRD) []string {
+	return []string{
+		"Take turns between categories: pick from the category with the fewest passing features",
+		fmt.Sprintf("Break ties by priority (%s), then by ID order", p.priorityOrder()),
+	}
+}
+
+func (roundRobinScheduler) Next(p *PRD) (*Feature, string) {
+	passing := lo.CountValuesBy(lo.Filter(p.Features, func(f Feature, _ int) bool { return f.Passes }),
+		func(f Feature) Category { return f.Category })
+	next := bestReady(p, func(f *Feature) float64 { return -float64(passing[f.Category]) })
+	if next == nil {
+		return nil, ""
+	}
+	total := lo.CountBy(p.Features, func(f Feature) bool { return f.Category == next.Category })
+	return next, fmt.Sprintf("Selected %s: %s has the fewest passing features of the categories with work ready (%d/%d), %s priority",
+		next.ID, next.Category, passing[next.Category], total, next.Priority)
+}
+
+// valueEffortScheduler picks the feature with the best value for its effort
+type valueEffortScheduler struct{}
+
+func (valueEffortScheduler) Strategy() Strategy { return StrategyValueEffort }
+
+func (valueEffortScheduler) Rules(p *PRD) []string {
+	return []string{
+		fmt.Sprintf("Pick the feature with the highest value divided by effort (%s; effort defaults to 1)", p.valueDefaults()),
+		fmt.Sprintf("Break ties by priority (%s), then by ID order", p.priorityOrder()),
+	}
+}
+
+func (valueEffortScheduler) Next(p *PRD) (*Feature, string) {
+	next := bestReady(p, func(f *Feature) float64 { return float64(p.ValueOrDefault(f)) / float64(f.EffortOrDefault()) })
+	if next == nil {
+		return nil, ""
+	}
+	value, effort := p.ValueOrDefault(next), next.EffortOrDefault()
+	return next, fmt.Sprintf("Selected %s: best value for the effort (value %d / effort %d = %.2f), %s priority",
+		next.ID, value, effort, float64(value)/float64(effort), next.Priority)
+}
+
+// ValueOrDefault returns the feature's value. It defaults to the priority's
+// place in the PRD's levels, counting up from 1 for the lowest (3, 2 or 1 for
+// high, medium and low), or to the weight for weighted priorities.
+func (p *PRD) ValueOrDefault(f *Feature) int {
+	if f.Value > 0 {
+		return f.Value
+	}
+	if w, ok := f.Priority.Weight(); ok {
+		return max(w, 0)
+	}
+	if !p.IsAllowedPriority(f.Priority) {
+		return 0
+	}
+	return len(p.AllowedPriorities()) - p.PriorityRank(f.Priority)
+}
+
+// valueDefaults describes the value each priority defaults to, for the
+// value-effort rules
+func (p *PRD) valueDefaults() string {
+	if p.UsesWeights() {
+		return "value defaults to the priority weight"
+	}
+	levels := p.AllowedPriorities()
+	parts := lo.Map(levels, func(pri Priority, i int) string { return fmt.Sprintf("%d for %s", len(levels)-i, pri) })
+	if len(parts) == 1 {
+		return "value defaults to " + parts[0] + " priority"
+	}
+	return "value defaults to " + strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1] + " priority"
+}
+
+// EffortOrDefault returns the feature's effort, which defaults to 1
+func (f *Feature) EffortOrDefault() int {
+	if f.Effort > 0 {
+		return f.Effort
+	}
+	return 1
+}
+
+// bestReady returns the feature with the highest score among the open
+// features that have their dependencies met. Ties go to the higher
+// priority, then to the feature earlier in the PRD.
+func bestReady(p *PRD, score func(f *Feature) float64) *Feature {
+	var best *Feature
+	var bestScore float64
+	for i := range p.Features {
+		f := &p.Features[i]
+		if !f.IsOpen() || !p.DependenciesMet(f) {
+			continue
+		}
+		s := score(f)
+		if best == nil || s > bestScore || s == bestScore && p.PriorityRank(f.Priority) < p.PriorityRank(best.Priority) {
+			best, bestScore = f, s
+		}
+	}
+	return best
+}
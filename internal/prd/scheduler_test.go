@@ -0,0 +1,102 @@
+package prd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStrategy(t *testing.T) {
+	for _, s := range ValidStrategies() {
+		strategy, err := ParseStrategy(string(s))
+		require.NoError(t, err)
+		assert.Equal(t, s, strategy.Scheduler().Strategy())
+		assert.NotEmpty(t, strategy.Scheduler().Rules(nil))
+	}
+
+	_, err := ParseStrategy("fastest")
+	assert.EqualError(t, err, `invalid strategy "fastest" (must be one of: priority, critical-path, unblock, round-robin, value-effort)`)
+
+	assert.Equal(t, StrategyPriority, (&PRD{}).Scheduler().Strategy(), "priority is the default")
+}
+
+func TestValidateStrategy(t *testing.T) {
+	p := testPRD("a", "b")
+	p.Strategy = "fastest"
+	p.Features[0].Value = -1
+	p.Features[1].Effort = -2
+
+	result := Validate(p)
+	assert.False(t, result.Valid)
+	var messages []string
+	for _, e := range result.Errors {
+		messages = append(messages, e.Error())
+	}
+	assert.Equal(t, []string{
+		"strategy: invalid strategy 'fastest' (must be one of: priority, critical-path, unblock, round-robin, value-effort)",
+		"features[0].value: must not be negative, got -1",
+		"features[1].effort: must not be negative, got -2",
+	}, messages)
+}
+
+func TestCriticalPathScheduler(t *testing.T) {
+	p := testPRD("a", "b", "c:b", "d:c", "e:a")
+	p.Strategy = StrategyCriticalPath
+
+	next, reason := p.NextFeatureWithReason()
+	require.NotNil(t, next)
+	assert.Equal(t, "b", next.ID)
+	assert.Equal(t, "Selected b: starts the longest remaining chain (b -> c -> d, 3 features), high priority", reason)
+
+	p = testPRD("a+", "b", "c:a")
+	p.Features[1].Priority = PriorityLow
+	next, reason = p.NextFeatureWith(StrategyCriticalPath.Scheduler())
+	assert.Equal(t, "c", next.ID, "ties go to the higher priority")
+	assert.Equal(t, "Selected c: no unfinished feature depends on it, high priority", reason)
+}
+
+func TestUnblockScheduler(t *testing.T) {
+	p := testPRD("a", "b", "c:b", "d:b", "e:d", "f:a,x", "x:f")
+	next, reason := p.NextFeatureWith(StrategyUnblock.Scheduler())
+	require.NotNil(t, next)
+	assert.Equal(t, "b", next.ID)
+	assert.Equal(t, "Selected b: unblocks the most features (3: c, d, e), high priority", reason,
+		"features stuck in a cycle don't count")
+}
+
+func TestRoundRobinScheduler(t *testing.T) {
+	p := testPRD("a+", "b+", "c", "d", "e")
+	p.Features[3].Category = CategoryUI
+	p.Features[4].Category = CategoryUI
+	p.Features[4].Priority = PriorityLow
+	p.Features[3].Priority = PriorityMedium
+
+	next, reason := p.NextFeatureWith(StrategyRoundRobin.Scheduler())
+	require.NotNil(t, next)
+	assert.Equal(t, "d", next.ID)
+	assert.Equal(t, "Selected d: ui has the fewest passing features of the categories with work ready (0/2), medium priority", reason)
+}
+
+func TestValueEffortScheduler(t *testing.T) {
+	p := testPRD("a", "b", "c", "d:a")
+	p.Features[0].Effort = 3                           // 3/3
+	p.Features[1].Value, p.Features[1].Effort = 5, 2   // 5/2
+	p.Features[2].Priority = PriorityMedium            // 2/1
+	p.Features[3].Value, p.Features[3].Effort = 100, 1 // blocked
+
+	next, reason := p.NextFeatureWith(StrategyValueEffort.Scheduler())
+	require.NotNil(t, next)
+	assert.Equal(t, "b", next.ID)
+	assert.Equal(t, "Selected b: best value for the effort (value 5 / effort 2 = 2.50), high priority", reason)
+
+	assert.Equal(t, 1, p.ValueOrDefault(&Feature{Priority: PriorityLow}))
+}
+
+func TestSchedulerNoReadyFeature(t *testing.T) {
+	for _, s := range ValidStrategies() {
+		next, reason := testPRD("a+", "b:x", "x:b").NextFeatureWith(s.Scheduler())
+		assert.Nil(t, next, s)
+		assert.Equal(t, "remaining features are blocked by a dependency cycle: b -> x -> b", reason, s)
+	}
+}
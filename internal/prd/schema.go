@@ -0,0 +1,155 @@
+package prd
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// JSONSchema returns a JSON Schema (draft 2020-12) for prd.json, generated
+// from the PRD types so it follows them as fields are added. Enums come from
+// the valid strategies and statuses; categories and priorities aren't enums, since a PRD
+// may declare its own. Constraints that Validate enforces beyond the types
+// are listed in schemaFields.
+func JSONSchema() map[string]any {
+	b := &schemaBuilder{defs: make(map[string]any)}
+	schema := b.object(reflect.TypeOf(PRD{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "SuperRalph PRD"
+	schema["description"] = "Product requirements for superralph build, kept in prd.json"
+	schema["$defs"] = b.defs
+	return schema
+}
+
+// schemaEnums are the allowed values of string types
+var schemaEnums = map[reflect.Type]func() []string{
+	reflect.TypeOf(Strategy("")): func() []string { return lo.Map(ValidStrategies(), func(s Strategy, _ int) string { return string(s) }) },
+	reflect.TypeOf(Status("")):   func() []string { return lo.Map(ValidStatuses(), func(s Status, _ int) string { return string(s) }) },
+	reflect.TypeOf(LintRule("")): func() []string { return lo.Map(LintRules(), func(r LintRule, _ int) string { return string(r) }) },
+}
+
+// nonBlank matches strings Validate accepts as not empty
+const nonBlank = `\S`
+
+// idPattern matches feature IDs: no whitespace, and none of the commas and
+// colons that separate IDs from each other and from descriptions in prd.md
+const idPattern = `^[^\s,:]+$`
+
+// schemaFields add descriptions and constraints to fields, keyed by type
+// and JSON name
+var schemaFields = map[string]map[string]any{
+	"PRD.$schema":       {"description": "JSON Schema for editors, e.g. \"./prd.schema.json\""},
+	"PRD.schemaVersion": {"description": "Version of the prd.json format; older files are migrated", "minimum": 0, "maximum": CurrentSchemaVersion},
+	"PRD.name":          {"description": "Project name", "pattern": nonBlank},
+	"PRD.description":   {"description": "What the project does", "pattern": nonBlank},
+	"PRD.testCommand":   {"description": "Command that runs the tests, e.g. \"go test ./...\"", "pattern": nonBlank},
+	"PRD.features":      {"minItems": 1},
+	"PRD.strategy":      {"description": "How the next feature is picked (default: priority)"},
+	"PRD.categories":    {"description": "Categories features may use, replacing the built-in ones", "uniqueItems": true, "items": map[string]any{"type": "string", "pattern": nonBlank}},
+	"PRD.priorities":    {"description": "Priority levels features may use, highest first, replacing high, medium and low", "uniqueItems": true, "items": map[string]any{"type": "string", "pattern": nonBlank}},
+
+	"Feature.id":            {"description": "Unique identifier without spaces, commas or colons, e.g. \"feat-001\"", "pattern": idPattern},
+	"Feature.description":   {"description": "What the feature does", "pattern": nonBlank},
+	"Feature.category":      {"description": "One of the PRD's categories (default: " + strings.Join(lo.Map(ValidCategories(), func(c Category, _ int) string { return string(c) }), ", ") + ")"},
+	"Feature.priority":      {"description": "One of the PRD's priority levels (default: " + strings.Join(lo.Map(ValidPriorities(), func(p Priority, _ int) string { return string(p) }), ", ") + "), or an integer weight where higher is built first"},
+	"Feature.steps":         {"description": "Steps that verify the feature works", "minItems": 1, "items": map[string]any{"type": "string", "pattern": nonBlank}},
+	"Feature.passes":        {"description": "false initially, true once the feature is complete"},
+	"Feature.depends_on":    {"description": "IDs of features that must pass first", "uniqueItems": true, "items": map[string]any{"type": "string", "pattern": idPattern}},
+	"Feature.status":        {"description": "Lifecycle state; passes is true exactly when it is passing (default: todo or passing, by passes)"},
+	"Feature.blockedReason": {"description": "What a blocked feature is waiting on", "pattern": nonBlank},
+	"Feature.startedAt":     {"description": "When a build first started on the feature"},
+	"Feature.completedAt":   {"description": "When the feature started passing"},
+	"Feature.value":         {"description": "Benefit of the feature, for the value-effort strategy", "minimum": 0},
+	"Feature.effort":        {"description": "Relative cost of the feature, for the value-effort strategy", "minimum": 0},
+	"Feature.lintIgnore":    {"description": "Lint rules that don't apply to this feature", "uniqueItems": true},
+
+	"Check.step":     {"description": "1-based step this checks", "minimum": 1},
+	"Check.command":  {"description": "Shell command, run from the project directory", "pattern": nonBlank},
+	"Check.exitCode": {"description": "Expected exit code (default: 0)", "minimum": 0, "maximum": 255},
+	"Check.output":   {"description": "Regular expression the combined output must match", "format": "regex"},
+
+	"CoverageSpec.threshold": {"description": "Allowed drop in percentage points (default: 0.5)", "minimum": 0, "exclusiveMaximum": 100},
+}
+
+// schemaBuilder collects the schemas of struct types in $defs
+type schemaBuilder struct {
+	defs map[string]any
+}
+
+// schema returns the schema for a Go type
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(Check{}) {
+		// Checks are either a command string or an object (see Check.UnmarshalJSON)
+		return map[string]any{"oneOf": []any{map[string]any{"type": "string", "pattern": nonBlank}, b.ref(t)}}
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if t == reflect.TypeOf(Priority("")) {
+		// Priorities are either a level name or a weight (see Priority.UnmarshalJSON)
+		return map[string]any{"oneOf": []any{map[string]any{"type": "string", "pattern": nonBlank}, map[string]any{"type": "integer"}}}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem())
+	case reflect.String:
+		s := map[string]any{"type": "string"}
+		if enum, ok := schemaEnums[t]; ok {
+			s["enum"] = enum()
+		}
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Struct:
+		return b.ref(t)
+	}
+	return map[string]any{}
+}
+
+// ref adds a struct type to $defs and returns a reference to it
+func (b *schemaBuilder) ref(t reflect.Type) map[string]any {
+	if _, ok := b.defs[t.Name()]; !ok {
+		b.defs[t.Name()] = nil // Claim the name before descending, for recursive types
+		b.defs[t.Name()] = b.object(t)
+	}
+	return map[string]any{"$ref": "#/$defs/" + t.Name()}
+}
+
+// object returns the schema for a struct's JSON fields. Fields without
+// omitempty are required; unknown fields are rejected, since they are more
+// likely misspellings than notes. superralph keeps them when it saves.
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		s := b.schema(field.Type)
+		maps.Copy(s, schemaFields[t.Name()+"."+name])
+		properties[name] = s
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package prd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := json.Marshal(JSONSchema())
+	require.NoError(t, err)
+
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Required   []string                  `json:"required"`
+		Defs       map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	assert.Equal(t, []string{"name", "description", "testCommand", "features"}, schema.Required)
+	assert.Equal(t, float64(CurrentSchemaVersion), schema.Properties["schemaVersion"]["maximum"])
+	assert.Equal(t, map[string]any{"$ref": "#/$defs/Feature"}, schema.Properties["features"]["items"])
+
+	feature := schema.Defs["Feature"]
+	assert.Equal(t, []string{"id", "category", "priority", "description", "steps", "passes"}, feature.Required)
+	assert.NotContains(t, feature.Properties["category"], "enum", "PRDs may declare their own categories")
+	assert.Len(t, feature.Properties["priority"]["oneOf"], 2, "priorities may be level names or weights")
+	assert.Equal(t, true, schema.Properties["priorities"]["uniqueItems"])
+	assert.Equal(t, map[string]any{"type": "string", "pattern": `^[^\s,:]+$`}, feature.Properties["depends_on"]["items"])
+	assert.Equal(t, true, feature.Properties["depends_on"]["uniqueItems"])
+	assert.Contains(t, feature.Properties["verify"], "oneOf", "checks may be plain commands")
+	assert.Contains(t, schema.Defs, "BenchmarkSpec")
+
+	// Field names are camelCase, apart from depends_on
+	names := lo.Keys(schema.Properties)
+	for _, def := range schema.Defs {
+		names = append(names, lo.Keys(def.Properties)...)
+	}
+	for _, name := range names {
+		if name != "depends_on" {
+			assert.NotContains(t, name, "_", name)
+		}
+	}
+}
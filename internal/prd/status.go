@@ -0,0 +1,134 @@
+package prd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Status is where a feature is in its lifecycle. PRDs written before
+// statuses existed leave it out; see Feature.CurrentStatus.
+type Status string
+
+const (
+	StatusTodo        Status = "todo"         // Not started
+	StatusInProgress  Status = "in_progress"  // A build has started on it
+	StatusBlocked     Status = "blocked"      // Waiting on something outside the build (see blockedReason)
+	StatusStuck       Status = "stuck"        // Used up its attempts at the build's gates
+	StatusNeedsReview Status = "needs_review" // Built, but waiting for a person to look at it
+	StatusPassing     Status = "passing"      // Complete
+	StatusSkipped     Status = "skipped"      // Won't be built
+)
+
+// ValidStatuses returns all valid statuses, in lifecycle order
+func ValidStatuses() []Status {
+	return []Status{StatusTodo, StatusInProgress, StatusBlocked, StatusStuck, StatusNeedsReview, StatusPassing, StatusSkipped}
+}
+
+// IsValid checks if a status is valid
+func (s Status) IsValid() bool {
+	return lo.Contains(ValidStatuses(), s)
+}
+
+// ParseStatus validates a status name
+func ParseStatus(s string) (Status, error) {
+	if status := Status(s); status.IsValid() {
+		return status, nil
+	}
+	return "", fmt.Errorf("invalid status %q (must be one of: %s)", s, validStatusList())
+}
+
+// validStatusList returns a comma-separated list of valid statuses
+func validStatusList() string {
+	return strings.Join(lo.Map(ValidStatuses(), func(s Status, _ int) string { return string(s) }), ", ")
+}
+
+// CurrentStatus returns the feature's status. Features without one are
+// passing or todo, by passes.
+func (f *Feature) CurrentStatus() Status {
+	switch {
+	case f.Status != "":
+		return f.Status
+	case f.Passes:
+		return StatusPassing
+	default:
+		return StatusTodo
+	}
+}
+
+// SetStatus moves the feature to a status, keeping passes in step with it
+// for tools that only read passes. Starting work records when it started;
+// passing records when it completed.
+func (f *Feature) SetStatus(status Status, now time.Time) {
+	f.Status = status
+	f.Passes = status == StatusPassing
+	if status != StatusBlocked {
+		f.BlockedReason = ""
+	}
+	if status == StatusInProgress && f.StartedAt == nil {
+		f.StartedAt = &now
+	}
+	switch status {
+	case StatusPassing:
+		if f.CompletedAt == nil {
+			f.CompletedAt = &now
+		}
+	default:
+		f.CompletedAt = nil
+	}
+}
+
+// IsOpen returns true if the feature is waiting to be built: it isn't
+// passing, and isn't blocked, stuck, waiting for review or skipped
+func (f *Feature) IsOpen() bool {
+	status := f.CurrentStatus()
+	return status == StatusTodo || status == StatusInProgress
+}
+
+// IsDone returns true if the feature is passing or skipped
+func (f *Feature) IsDone() bool {
+	status := f.CurrentStatus()
+	return status == StatusPassing || status == StatusSkipped
+}
+
+// syncStatus makes statuses agree with passes, which agents and older tools
+// set on their own: a feature marked passing becomes passing, and a passing
+// feature marked not passing goes back to todo
+func (p *PRD) syncStatus(now time.Time) {
+	for i := range p.Features {
+		f := &p.Features[i]
+		if f.Status == "" || f.Passes == (f.Status == StatusPassing) {
+			continue
+		}
+		if f.Passes {
+			f.SetStatus(StatusPassing, now)
+		} else {
+			f.SetStatus(StatusTodo, now)
+		}
+	}
+}
+
+// Held returns the features that aren't done but won't be built until
+// someone acts: blocked, stuck or waiting for review
+func (p *PRD) Held() []*Feature {
+	var held []*Feature
+	for i := range p.Features {
+		f := &p.Features[i]
+		if !f.IsOpen() && !f.IsDone() {
+			held = append(held, f)
+		}
+	}
+	return held
+}
+
+// StatusSummary describes a feature's status for display, with the reason a
+// blocked feature gave
+func (f *Feature) StatusSummary() string {
+	status := f.CurrentStatus()
+	if status == StatusBlocked && f.BlockedReason != "" {
+		return fmt.Sprintf("%s: %s", status, f.BlockedReason)
+	}
+	return string(status)
+}
@@ -0,0 +1,121 @@
+package prd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetStatus(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	f := &Feature{ID: "feat-001"}
+	assert.Equal(t, StatusTodo, f.CurrentStatus())
+
+	f.SetStatus(StatusInProgress, start)
+	f.SetStatus(StatusInProgress, end)
+	assert.Equal(t, start, *f.StartedAt, "keeps when it first started")
+	assert.Nil(t, f.CompletedAt)
+
+	f.SetStatus(StatusBlocked, end)
+	f.BlockedReason = "Needs API keys"
+	assert.Equal(t, "blocked: Needs API keys", f.StatusSummary())
+	assert.False(t, f.IsOpen())
+	assert.False(t, f.IsDone())
+
+	f.SetStatus(StatusPassing, end)
+	assert.True(t, f.Passes)
+	assert.Empty(t, f.BlockedReason)
+	assert.Equal(t, end, *f.CompletedAt)
+
+	f.SetStatus(StatusTodo, end)
+	assert.False(t, f.Passes)
+	assert.Nil(t, f.CompletedAt)
+	assert.NotNil(t, f.StartedAt)
+
+	assert.True(t, (&Feature{Passes: true}).IsDone(), "features without a status go by passes")
+	_, err := ParseStatus("done")
+	assert.ErrorContains(t, err, `invalid status "done" (must be one of: todo, in_progress, blocked, stuck, needs_review, passing, skipped)`)
+}
+
+func TestStatusFollowsPasses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prd.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "name": "Test",
+  "description": "Test",
+  "testCommand": "go test ./...",
+  "features": [
+    {"id": "feat-001", "category": "functional", "priority": "high", "description": "First", "steps": ["Step"], "passes": true, "status": "in_progress"},
+    {"id": "feat-002", "category": "functional", "priority": "high", "description": "Second", "steps": ["Step"], "passes": false, "status": "passing"},
+    {"id": "feat-003", "category": "functional", "priority": "high", "description": "Third", "steps": ["Step"], "passes": false, "status": "blocked", "blockedReason": "Needs keys"}
+  ]
+}`), 0644))
+
+	p, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPassing, p.Features[0].Status, "an agent marked it passing")
+	assert.NotNil(t, p.Features[0].CompletedAt)
+	assert.Equal(t, StatusTodo, p.Features[1].Status, "someone marked it not passing")
+	assert.Equal(t, StatusBlocked, p.Features[2].Status)
+	assert.Equal(t, "Needs keys", p.Features[2].BlockedReason)
+}
+
+func TestHeldFeatures(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	require.NoError(t, p.SetFeatureStatus("feat-002", StatusSkipped, ""))
+	require.NoError(t, p.SetFeatureStatus("feat-010", StatusBlocked, "Needs a design"))
+
+	next, reason := p.NextFeatureWithReason()
+	assert.Nil(t, next)
+	assert.Contains(t, reason, "feat-010 (blocked: Needs a design)")
+	assert.Len(t, p.Held(), 1)
+	assert.False(t, p.IsComplete())
+
+	require.NoError(t, p.SetFeatureStatus("feat-010", StatusSkipped, ""))
+	assert.True(t, p.IsComplete(), "skipped features don't need building")
+	assert.Equal(t, 2, p.Stats().ByStatus[StatusSkipped])
+
+	assert.EqualError(t, p.SetFeatureStatus("feat-010", StatusTodo, "Why"), "only blocked features have a reason")
+	reopened, err := p.Reopen("feat-010")
+	require.NoError(t, err)
+	assert.True(t, reopened)
+	assert.Equal(t, "feat-010", p.NextFeature().ID)
+}
+
+func TestHeldDependencies(t *testing.T) {
+	p := testPRD("feat-001", "feat-002:feat-001", "feat-003:feat-002")
+
+	// A skipped dependency leaves nothing to wait for
+	require.NoError(t, p.SetFeatureStatus("feat-001", StatusSkipped, ""))
+	assert.True(t, p.DependenciesMet(p.GetFeature("feat-002")))
+	assert.Equal(t, "feat-002", p.NextFeature().ID)
+	assert.Empty(t, p.Unreachable())
+	assert.Equal(t, []string{"feat-002", "feat-003"}, p.CriticalPath())
+
+	// A held dependency holds its dependents, and the reason names it
+	require.NoError(t, p.SetFeatureStatus("feat-002", StatusBlocked, "Needs keys"))
+	assert.Equal(t, "feat-002", p.HeldDependency(p.GetFeature("feat-003")).ID)
+	next, reason := p.NextFeatureWithReason()
+	assert.Nil(t, next)
+	assert.Equal(t, "remaining features are waiting on someone: feat-002 (blocked: Needs keys); feat-003 needs feat-002", reason)
+	assert.Empty(t, p.Unreachable())
+	assert.False(t, p.IsComplete())
+
+	require.NoError(t, p.SetFeatureStatus("feat-002", StatusSkipped, ""))
+	assert.Equal(t, "feat-003", p.NextFeature().ID)
+}
+
+func TestValidateStatus(t *testing.T) {
+	p := testPRD("feat-001+", "feat-002:feat-001", "feat-010")
+	p.Features[1].Status = "done"
+	p.Features[2].BlockedReason = "Needs keys"
+
+	assert.ElementsMatch(t, []string{
+		"features[1].status: invalid status 'done' (must be one of: todo, in_progress, blocked, stuck, needs_review, passing, skipped)",
+		"features[2].blockedReason: only applies to blocked features",
+	}, errorStrings(Validate(p)))
+}
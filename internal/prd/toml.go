@@ -0,0 +1,186 @@
+package prd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// tomlCodec reads and writes prd.toml. Saving keeps the comment block at the
+// top of the file and fields the PRD types don't model; other comments are
+// lost.
+type tomlCodec struct{}
+
+func (tomlCodec) document(data []byte) (map[string]any, error) {
+	var v map[string]any
+	if _, err := toml.Decode(string(data), &v); err != nil {
+		return nil, err
+	}
+	return normalize(v)
+}
+
+func (tomlCodec) encode(p *PRD, original []byte) ([]byte, error) {
+	var previous []byte
+	if original != nil {
+		if doc, err := (tomlCodec{}).document(original); err == nil {
+			previous, _ = json.Marshal(doc)
+		}
+	}
+	node, err := prdNode(p, previous)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	if header := tomlHeader(original); header != "" {
+		sb.WriteString(header + "\n\n")
+	}
+	writeTOMLTable(&sb, nil, node.Content[0], false)
+	return []byte(strings.TrimLeft(sb.String(), "\n")), nil
+}
+
+// tomlHeader returns the comment lines that open a TOML file
+func tomlHeader(data []byte) string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			break
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeTOMLTable writes a mapping node as a table: its plain values first,
+// then its sub-tables and arrays of tables, as TOML requires. The root table
+// has no path.
+func writeTOMLTable(sb *strings.Builder, path []string, node *yaml.Node, arrayItem bool) {
+	if len(path) > 0 {
+		header := strings.Join(tomlKeys(path), ".")
+		if arrayItem {
+			sb.WriteString(fmt.Sprintf("\n[[%s]]\n", header))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n[%s]\n", header))
+		}
+	}
+
+	var tables []int
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		switch {
+		case isNull(value):
+			continue
+		case value.Kind == yaml.MappingNode || isTableArray(value):
+			tables = append(tables, i)
+		default:
+			sb.WriteString(fmt.Sprintf("%s = %s\n", tomlKey(node.Content[i].Value), tomlValue(value)))
+		}
+	}
+	for _, i := range tables {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		tablePath := append(append([]string{}, path...), key)
+		if value.Kind == yaml.MappingNode {
+			writeTOMLTable(sb, tablePath, value, false)
+			continue
+		}
+		for _, item := range value.Content {
+			writeTOMLTable(sb, tablePath, item, true)
+		}
+	}
+}
+
+// isTableArray reports whether a node is a non-empty sequence of mappings,
+// written as an array of tables
+func isTableArray(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// isNull reports whether a node is null, which TOML can't express
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// tomlValue writes a node as an inline TOML value
+func tomlValue(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			items = append(items, tomlValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case yaml.MappingNode:
+		var fields []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if !isNull(node.Content[i+1]) {
+				fields = append(fields, tomlKey(node.Content[i].Value)+" = "+tomlValue(node.Content[i+1]))
+			}
+		}
+		return "{ " + strings.Join(fields, ", ") + " }"
+	}
+	switch node.Tag {
+	case "!!int", "!!float", "!!bool":
+		return node.Value
+	default:
+		return tomlString(node.Value)
+	}
+}
+
+// bareKey matches keys that don't need quoting
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey quotes a key if needed
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlKeys quotes each part of a dotted key
+func tomlKeys(keys []string) []string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = tomlKey(key)
+	}
+	return quoted
+}
+
+// tomlString writes a basic string with TOML's escapes
+func tomlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
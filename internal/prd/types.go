@@ -1,18 +1,50 @@
 package prd
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/samber/lo"
 )
 
-// PRD represents a Product Requirements Document
+// PRD represents a Product Requirements Document. Its JSON field names are
+// camelCase; depends_on predates that and keeps its spelling.
 type PRD struct {
+	// Schema points editors at a JSON Schema, e.g. "./prd.schema.json"
+	Schema string `json:"$schema,omitempty"`
+
+	// SchemaVersion is the version of the prd.json format (see CurrentSchemaVersion)
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	TestCommand string    `json:"testCommand"`
 	Features    []Feature `json:"features"`
+
+	// Coverage enables the coverage gate for the test command
+	Coverage *CoverageSpec `json:"coverage,omitempty"`
+
+	// Strategy decides which feature is built next (default: priority)
+	Strategy Strategy `json:"strategy,omitempty"`
+
+	// Categories replaces the built-in categories with the project's own
+	Categories []Category `json:"categories,omitempty"`
+
+	// Priorities replaces the built-in priority levels with the project's
+	// own, highest first. Features may use integer weights instead.
+	Priorities []Priority `json:"priorities,omitempty"`
+}
+
+// CoverageSpec configures the coverage gate. A `go test` test command writes
+// its profile automatically; other runners must name the file they write.
+type CoverageSpec struct {
+	Profile   string  `json:"profile,omitempty"`   // Coverage file the test command writes, relative to the project directory
+	Threshold float64 `json:"threshold,omitempty"` // Allowed drop in percentage points (default: 0.5)
 }
 
 // Feature represents a single feature in the PRD
@@ -24,6 +56,172 @@ type Feature struct {
 	Steps       []string `json:"steps"`
 	Passes      bool     `json:"passes"`
 	DependsOn   []string `json:"depends_on,omitempty"` // Optional list of feature IDs that must pass first
+
+	// Status is the feature's lifecycle state; passes stays true exactly when
+	// it is passing. Features without one are todo or passing, by passes.
+	Status        Status     `json:"status,omitempty"`
+	BlockedReason string     `json:"blockedReason,omitempty"` // What a blocked feature is waiting on
+	StartedAt     *time.Time `json:"startedAt,omitempty"`     // When a build first started on it
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`   // When it started passing
+
+	// Value and Effort weigh the feature for the value-effort strategy
+	Value  int `json:"value,omitempty"`  // Benefit of the feature (default: 3, 2 or 1 by priority)
+	Effort int `json:"effort,omitempty"` // Relative cost of building it (default: 1)
+
+	// Context declares files and documents to include in the prompt whenever this feature is selected
+	Context *ContextSpec `json:"context,omitempty"`
+
+	// Verify is a feature-level check that must pass before the feature is accepted
+	Verify *Check `json:"verify,omitempty"`
+
+	// Checks are executable checks for individual steps
+	Checks []Check `json:"checks,omitempty"`
+
+	// Benchmark is a performance target the feature must meet (performance features only)
+	Benchmark *BenchmarkSpec `json:"benchmark,omitempty"`
+
+	// LintIgnore lists lint rules that don't apply to this feature
+	LintIgnore []LintRule `json:"lintIgnore,omitempty"`
+}
+
+// AllChecks returns the step checks followed by the feature-level check
+func (f *Feature) AllChecks() []Check {
+	checks := append([]Check{}, f.Checks...)
+	if f.Verify != nil {
+		checks = append(checks, *f.Verify)
+	}
+	return checks
+}
+
+// HasChecks returns true if the feature has any executable checks
+func (f *Feature) HasChecks() bool {
+	return f.Verify != nil || len(f.Checks) > 0
+}
+
+// Check is an executable acceptance criterion. In prd.json it is either an
+// object or, when only the command matters, a plain command string.
+type Check struct {
+	Step     int    `json:"step,omitempty"`     // 1-based step this checks; omitted for feature-level checks
+	Command  string `json:"command"`            // Shell command, run from the project directory
+	ExitCode int    `json:"exitCode,omitempty"` // Expected exit code (default: 0)
+	Output   string `json:"output,omitempty"`   // Regular expression the combined output must match
+}
+
+// checkFields avoids recursion when decoding the object form of a Check
+type checkFields Check
+
+// UnmarshalJSON accepts either a command string or a check object
+func (c *Check) UnmarshalJSON(data []byte) error {
+	var command string
+	if err := json.Unmarshal(data, &command); err == nil {
+		*c = Check{Command: command}
+		return nil
+	}
+	var fields checkFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*c = Check(fields)
+	return nil
+}
+
+// MarshalJSON writes checks that only have a command as a plain string
+func (c Check) MarshalJSON() ([]byte, error) {
+	if c.Step == 0 && c.ExitCode == 0 && c.Output == "" {
+		return json.Marshal(c.Command)
+	}
+	return json.Marshal(checkFields(c))
+}
+
+// String describes the check, e.g. "step 2: curl -sf localhost:8080/health (exit 0)"
+func (c Check) String() string {
+	var sb strings.Builder
+	if c.Step > 0 {
+		sb.WriteString(fmt.Sprintf("step %d: ", c.Step))
+	}
+	sb.WriteString(c.Command)
+	sb.WriteString(fmt.Sprintf(" (exit %d", c.ExitCode))
+	if c.Output != "" {
+		sb.WriteString(fmt.Sprintf(", output matches /%s/", c.Output))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// BenchmarkSpec is a performance target measured with Go benchmark output,
+// as printed by `go test -bench`. The metric is compared as a median over
+// repeated runs. Metrics ending in "/s" are higher-is-better; all others are
+// lower-is-better.
+type BenchmarkSpec struct {
+	Command     string  `json:"command"`               // Shell command that prints benchmark results
+	Benchmark   string  `json:"benchmark,omitempty"`   // Regular expression selecting benchmarks by name (default: all)
+	Metric      string  `json:"metric,omitempty"`      // Unit to compare (default: "ns/op")
+	Target      float64 `json:"target,omitempty"`      // Absolute target for the metric
+	Improvement float64 `json:"improvement,omitempty"` // Required improvement over the baseline, in percent
+	Runs        int     `json:"runs,omitempty"`        // Times to run the command (default: 3)
+	Tolerance   float64 `json:"tolerance,omitempty"`   // Allowance for noise, in percent (default: 5)
+}
+
+// MetricName returns the metric to compare
+func (b *BenchmarkSpec) MetricName() string {
+	if b.Metric == "" {
+		return "ns/op"
+	}
+	return b.Metric
+}
+
+// HigherIsBetter returns true for throughput metrics such as "MB/s"
+func (b *BenchmarkSpec) HigherIsBetter() bool {
+	return strings.HasSuffix(b.MetricName(), "/s")
+}
+
+// String describes the target, e.g. "go test -bench Parse ./parser: ns/op <= 1500"
+func (b *BenchmarkSpec) String() string {
+	var targets []string
+	if b.Target > 0 {
+		op := "<="
+		if b.HigherIsBetter() {
+			op = ">="
+		}
+		targets = append(targets, fmt.Sprintf("%s %s %g", b.MetricName(), op, b.Target))
+	}
+	if b.Improvement > 0 {
+		targets = append(targets, fmt.Sprintf("%s %g%% better than baseline", b.MetricName(), b.Improvement))
+	}
+	return fmt.Sprintf("%s: %s", b.Command, strings.Join(targets, ", "))
+}
+
+// ContextSpec declares the codebase context a feature needs.
+// Entries use the same syntax as @ tags, with or without the leading @.
+type ContextSpec struct {
+	Files   []string `json:"files,omitempty"`   // Paths or glob patterns, e.g. "internal/api/**/*.go"
+	Exclude []string `json:"exclude,omitempty"` // Exclusions, e.g. "@!internal/api/generated"
+	Docs    []string `json:"docs,omitempty"`    // Reference documents such as design docs or API specs
+}
+
+// Tags converts the declaration into tag strings for tagging.Tagger.ResolveTags
+func (c *ContextSpec) Tags() []string {
+	if c == nil {
+		return nil
+	}
+
+	var tags []string
+	for _, pattern := range c.Files {
+		tags = append(tags, "@"+strings.TrimPrefix(pattern, "@"))
+	}
+	for _, doc := range c.Docs {
+		tags = append(tags, "@"+strings.TrimPrefix(doc, "@"))
+	}
+	for _, excl := range c.Exclude {
+		excl = strings.TrimPrefix(strings.TrimPrefix(excl, "@"), "!")
+		tags = append(tags, "@!"+excl)
+	}
+	return tags
+}
+
+// IsEmpty returns true if the declaration has no entries
+func (c *ContextSpec) IsEmpty() bool {
+	return c == nil || len(c.Files)+len(c.Exclude)+len(c.Docs) == 0
 }
 
 // Category represents the type of feature
@@ -76,6 +274,36 @@ func (p Priority) IsValid() bool {
 	return lo.Contains(ValidPriorities(), p)
 }
 
+// Weight returns the priority as an integer weight, for PRDs that rank
+// features by number instead of by named level
+func (p Priority) Weight() (int, bool) {
+	w, err := strconv.Atoi(string(p))
+	return w, err == nil
+}
+
+// UnmarshalJSON accepts a level name or an integer weight
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = Priority(name)
+		return nil
+	}
+	var weight int
+	if err := json.Unmarshal(data, &weight); err != nil {
+		return fmt.Errorf("priority must be a name or a whole number, got %s", data)
+	}
+	*p = Priority(strconv.Itoa(weight))
+	return nil
+}
+
+// MarshalJSON writes weights as numbers and level names as strings
+func (p Priority) MarshalJSON() ([]byte, error) {
+	if w, ok := p.Weight(); ok {
+		return json.Marshal(w)
+	}
+	return json.Marshal(string(p))
+}
+
 // Stats returns statistics about the PRD
 func (p *PRD) Stats() PRDStats {
 	stats := PRDStats{
@@ -83,14 +311,19 @@ func (p *PRD) Stats() PRDStats {
 		PassingFeatures: lo.CountBy(p.Features, func(f Feature) bool { return f.Passes }),
 		ByCategory:      make(map[Category]CategoryStats),
 		ByPriority:      make(map[Priority]PriorityStats),
+		ByStatus:        lo.CountValuesBy(p.Features, func(f Feature) Status { return f.CurrentStatus() }),
 	}
 
-	// Initialize all categories and priorities
-	for _, cat := range ValidCategories() {
+	// Initialize the PRD's categories and priority levels
+	stats.Categories = p.AllowedCategories()
+	for _, cat := range stats.Categories {
 		stats.ByCategory[cat] = CategoryStats{}
 	}
-	for _, pri := range ValidPriorities() {
-		stats.ByPriority[pri] = PriorityStats{}
+	if !p.UsesWeights() {
+		stats.Priorities = p.AllowedPriorities()
+		for _, pri := range stats.Priorities {
+			stats.ByPriority[pri] = PriorityStats{}
+		}
 	}
 
 	// Count by category and priority
@@ -110,6 +343,19 @@ func (p *PRD) Stats() PRDStats {
 		stats.ByPriority[f.Priority] = ps
 	}
 
+	// List the values features use that the PRD doesn't declare too
+	for _, f := range p.Features {
+		if !lo.Contains(stats.Categories, f.Category) {
+			stats.Categories = append(stats.Categories, f.Category)
+		}
+		if !lo.Contains(stats.Priorities, f.Priority) {
+			stats.Priorities = append(stats.Priorities, f.Priority)
+		}
+	}
+	slices.SortStableFunc(stats.Priorities, func(a, b Priority) int {
+		return cmp.Compare(p.PriorityRank(a), p.PriorityRank(b))
+	})
+
 	return stats
 }
 
@@ -119,6 +365,13 @@ type PRDStats struct {
 	PassingFeatures int
 	ByCategory      map[Category]CategoryStats
 	ByPriority      map[Priority]PriorityStats
+	ByStatus        map[Status]int // Features in each status (see Feature.CurrentStatus)
+
+	// Categories and Priorities list the keys of ByCategory and ByPriority in
+	// display order: the PRD's own, then any others features use. Priorities
+	// run from highest to lowest.
+	Categories []Category
+	Priorities []Priority
 }
 
 // CategoryStats holds statistics for a category
@@ -141,64 +394,69 @@ func (s PRDStats) PercentComplete() float64 {
 	return float64(s.PassingFeatures) / float64(s.TotalFeatures) * 100
 }
 
-// NextFeature returns the next feature to work on based on:
-// 1. Skip features with passes: true
-// 2. Skip features blocked by unmet dependencies
-// 3. Highest priority first (high > medium > low)
-// 4. ID order within same priority
+// NextFeature returns the next feature to work on, as picked by the PRD's
+// strategy. Only open features (see Feature.IsOpen) whose dependencies are
+// done are picked; the default strategy picks the highest priority first
+// (see PriorityRank), then the first in ID order.
 func (p *PRD) NextFeature() *Feature {
-	// Priority order: high > medium > low
-	priorities := []Priority{PriorityHigh, PriorityMedium, PriorityLow}
-
-	for _, priority := range priorities {
-		for i := range p.Features {
-			f := &p.Features[i]
-			if !f.Passes && f.Priority == priority && p.DependenciesMet(f) {
-				return f
-			}
-		}
-	}
-	return nil
+	next, _ := p.Scheduler().Next(p)
+	return next
 }
 
 // NextFeatureWithReason returns the next feature and a human-readable reason for why it was selected
 func (p *PRD) NextFeatureWithReason() (*Feature, string) {
-	next := p.NextFeature()
-	if next == nil {
+	return p.NextFeatureWith(p.Scheduler())
+}
+
+// NextFeatureWith returns the feature a scheduler picks and why, or nil and
+// why no feature can be picked
+func (p *PRD) NextFeatureWith(s Scheduler) (*Feature, string) {
+	if next, reason := s.Next(p); next != nil {
+		return next, reason
+	}
+	if p.IsComplete() || len(p.Features) == 0 {
 		return nil, "all features are complete"
 	}
-
-	var reasons []string
-	reasons = append(reasons, "not yet passing")
-
-	if len(next.DependsOn) > 0 {
-		reasons = append(reasons, "all dependencies met")
+	if held := p.Held(); len(held) > 0 {
+		// Open features whose dependencies are held wait with them
+		var waiting []string
+		ready := lo.SomeBy(p.Features, func(f Feature) bool {
+			if !f.IsOpen() {
+				return false
+			}
+			dep := p.HeldDependency(&f)
+			if dep != nil {
+				waiting = append(waiting, fmt.Sprintf("%s needs %s", f.ID, dep.ID))
+			}
+			return dep == nil
+		})
+		if !ready {
+			reason := "remaining features are waiting on someone: " + strings.Join(lo.Map(held, func(f *Feature, _ int) string {
+				return fmt.Sprintf("%s (%s)", f.ID, f.StatusSummary())
+			}), ", ")
+			if len(waiting) > 0 {
+				reason += "; " + strings.Join(waiting, ", ")
+			}
+			return nil, reason
+		}
 	}
-
-	reasons = append(reasons, fmt.Sprintf("%s priority", next.Priority))
-
-	// Check if there are any higher-priority features that are blocked
-	blockedHigherPriority := p.getBlockedHigherPriorityFeatures(next.Priority)
-	if len(blockedHigherPriority) > 0 {
-		reasons = append(reasons, fmt.Sprintf("features %v blocked by unmet dependencies", blockedHigherPriority))
+	if cycles := p.Cycles(); len(cycles) > 0 {
+		return nil, fmt.Sprintf("remaining features are blocked by a dependency cycle: %s", strings.Join(cycles[0], " -> "))
 	}
-
-	return next, fmt.Sprintf("Selected %s: %s", next.ID, strings.Join(reasons, ", "))
+	if unreachable := p.Unreachable(); len(unreachable) > 0 {
+		return nil, fmt.Sprintf("remaining features can never be built: %s", strings.Join(unreachable, ", "))
+	}
+	return nil, "no remaining feature is ready to work on"
 }
 
 // getBlockedHigherPriorityFeatures returns IDs of features with higher priority than the given one that are blocked
 func (p *PRD) getBlockedHigherPriorityFeatures(selectedPriority Priority) []string {
-	priorityOrder := map[Priority]int{
-		PriorityHigh:   0,
-		PriorityMedium: 1,
-		PriorityLow:    2,
-	}
-	selectedOrder := priorityOrder[selectedPriority]
+	selectedOrder := p.PriorityRank(selectedPriority)
 
 	blocked := lo.FilterMap(p.Features, func(f Feature, _ int) (string, bool) {
-		fOrder := priorityOrder[f.Priority]
+		fOrder := p.PriorityRank(f.Priority)
 		// Only consider features with higher priority (lower order number)
-		if !f.Passes && fOrder < selectedOrder && !p.DependenciesMet(&f) {
+		if f.IsOpen() && fOrder < selectedOrder && !p.DependenciesMet(&f) {
 			return f.ID, true
 		}
 		return "", false
@@ -206,24 +464,25 @@ func (p *PRD) getBlockedHigherPriorityFeatures(selectedPriority Priority) []stri
 	return blocked
 }
 
-// DependenciesMet returns true if all dependencies of the feature have passes: true
+// DependenciesMet returns true if all dependencies of the feature are done:
+// passing, or skipped, which leaves nothing for dependents to wait on
 func (p *PRD) DependenciesMet(f *Feature) bool {
 	if len(f.DependsOn) == 0 {
 		return true
 	}
 
-	passingIDs := p.getPassingFeatureIDs()
+	doneIDs := p.getDoneFeatureIDs()
 	return lo.EveryBy(f.DependsOn, func(depID string) bool {
-		return passingIDs[depID]
+		return doneIDs[depID]
 	})
 }
 
-// getPassingFeatureIDs returns a set of feature IDs that have passes: true
-func (p *PRD) getPassingFeatureIDs() map[string]bool {
-	passingFeatures := lo.Filter(p.Features, func(f Feature, _ int) bool {
-		return f.Passes
+// getDoneFeatureIDs returns a set of feature IDs that are passing or skipped
+func (p *PRD) getDoneFeatureIDs() map[string]bool {
+	doneFeatures := lo.Filter(p.Features, func(f Feature, _ int) bool {
+		return f.IsDone()
 	})
-	return lo.SliceToMap(passingFeatures, func(f Feature) (string, bool) {
+	return lo.SliceToMap(doneFeatures, func(f Feature) (string, bool) {
 		return f.ID, true
 	})
 }
@@ -231,25 +490,62 @@ func (p *PRD) getPassingFeatureIDs() map[string]bool {
 // GetBlockedFeatures returns features that are blocked by unmet dependencies
 func (p *PRD) GetBlockedFeatures() []Feature {
 	return lo.Filter(p.Features, func(f Feature, _ int) bool {
-		return !f.Passes && !p.DependenciesMet(&f)
+		return !f.IsDone() && !p.DependenciesMet(&f)
 	})
 }
 
-// GetUnmetDependencies returns the IDs of dependencies that are not yet passing for a feature
+// GetUnmetDependencies returns the IDs of dependencies that are not yet done for a feature
 func (p *PRD) GetUnmetDependencies(f *Feature) []string {
 	if len(f.DependsOn) == 0 {
 		return nil
 	}
 
-	passingIDs := p.getPassingFeatureIDs()
+	doneIDs := p.getDoneFeatureIDs()
 	return lo.Filter(f.DependsOn, func(depID string, _ int) bool {
-		return !passingIDs[depID]
+		return !doneIDs[depID]
 	})
 }
 
-// IsComplete returns true if all features pass
+// HeldDependency returns the held feature (see PRD.Held) that an open
+// feature waits on, directly or through other open features, or nil if it
+// isn't waiting on one
+func (p *PRD) HeldDependency(f *Feature) *Feature {
+	seen := map[string]bool{f.ID: true}
+	var find func(f *Feature) *Feature
+	find = func(f *Feature) *Feature {
+		for _, id := range f.DependsOn {
+			dep := p.GetFeature(id)
+			if dep == nil || seen[id] {
+				continue
+			}
+			seen[id] = true
+			if !dep.IsOpen() && !dep.IsDone() {
+				return dep
+			}
+			if dep.IsOpen() {
+				if held := find(dep); held != nil {
+					return held
+				}
+			}
+		}
+		return nil
+	}
+	return find(f)
+}
+
+// IsComplete returns true if all features pass or were skipped
 func (p *PRD) IsComplete() bool {
 	return len(p.Features) > 0 && lo.EveryBy(p.Features, func(f Feature) bool {
-		return f.Passes
+		return f.IsDone()
 	})
 }
+
+// GetFeature returns the feature with the given ID, or nil if there is none
+func (p *PRD) GetFeature(id string) *Feature {
+	for i := range p.Features {
+		if p.Features[i].ID == id {
+			return &p.Features[i]
+		}
+	}
+	return nil
+}
@@ -1,12 +1,38 @@
 package prd
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// testPRD returns a valid PRD with a feature for each spec, written
+// "id[+][:dep,dep]" where + marks the feature passing. Features are
+// functional, high priority and have one verifiable step; tests change
+// whatever they are about.
+func testPRD(specs ...string) *PRD {
+	p := &PRD{Name: "Test Project", Description: "Test description", TestCommand: "go test ./..."}
+	for _, spec := range specs {
+		id, deps, _ := strings.Cut(spec, ":")
+		f := Feature{
+			ID:       strings.TrimSuffix(id, "+"),
+			Category: CategoryFunctional,
+			Priority: PriorityHigh,
+			Steps:    []string{"Verify it works"},
+			Passes:   strings.HasSuffix(id, "+"),
+		}
+		f.Description = "Build the " + f.ID + " feature"
+		if deps != "" {
+			f.DependsOn = strings.Split(deps, ",")
+		}
+		p.Features = append(p.Features, f)
+	}
+	return p
+}
+
 func TestCategoryIsValid(t *testing.T) {
 	tests := []struct {
 		category Category
@@ -367,3 +393,105 @@ func TestFeatureDependsOnField(t *testing.T) {
 	require.Len(t, f.DependsOn, 1)
 	assert.Equal(t, "feat-000", f.DependsOn[0])
 }
+
+func TestContextSpecTags(t *testing.T) {
+	spec := &ContextSpec{
+		Files:   []string{"internal/api/**/*.go", "@cmd/root.go"},
+		Exclude: []string{"internal/api/generated", "@!vendor", "!tmp"},
+		Docs:    []string{"docs/design.md"},
+	}
+
+	assert.Equal(t, []string{
+		"@internal/api/**/*.go",
+		"@cmd/root.go",
+		"@docs/design.md",
+		"@!internal/api/generated",
+		"@!vendor",
+		"@!tmp",
+	}, spec.Tags())
+	assert.False(t, spec.IsEmpty())
+
+	var nilSpec *ContextSpec
+	assert.Nil(t, nilSpec.Tags())
+	assert.True(t, nilSpec.IsEmpty())
+	assert.True(t, (&ContextSpec{}).IsEmpty())
+}
+
+func TestFeatureContextJSON(t *testing.T) {
+	data := `{"id": "feat-001", "context": {"files": ["src/*.go"], "docs": ["docs/api.md"]}}`
+
+	var f Feature
+	require.NoError(t, json.Unmarshal([]byte(data), &f))
+	require.NotNil(t, f.Context)
+	assert.Equal(t, []string{"src/*.go"}, f.Context.Files)
+	assert.Equal(t, []string{"docs/api.md"}, f.Context.Docs)
+
+	// Omitted when not set
+	out, err := json.Marshal(Feature{ID: "feat-002"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "context")
+}
+
+func TestFeatureChecksJSON(t *testing.T) {
+	data := `{
+		"id": "feat-001",
+		"verify": "make e2e",
+		"checks": [
+			{"step": 1, "command": "curl -sf localhost:8080/health"},
+			{"step": 2, "command": "./bin/cli --bad-flag", "exitCode": 2, "output": "unknown flag"}
+		]
+	}`
+
+	var f Feature
+	require.NoError(t, json.Unmarshal([]byte(data), &f))
+	require.NotNil(t, f.Verify)
+	assert.Equal(t, Check{Command: "make e2e"}, *f.Verify)
+	require.Len(t, f.Checks, 2)
+	assert.Equal(t, Check{Step: 2, Command: "./bin/cli --bad-flag", ExitCode: 2, Output: "unknown flag"}, f.Checks[1])
+
+	assert.True(t, f.HasChecks())
+	all := f.AllChecks()
+	require.Len(t, all, 3)
+	assert.Equal(t, "make e2e", all[2].Command)
+
+	// Command-only checks are written back as strings
+	out, err := json.Marshal(f)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"verify":"make e2e"`)
+	assert.Contains(t, string(out), `{"step":1,"command":"curl -sf localhost:8080/health"}`)
+
+	// Omitted when not set
+	out, err = json.Marshal(Feature{ID: "feat-002"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "verify")
+	assert.NotContains(t, string(out), "checks")
+	assert.False(t, (&Feature{}).HasChecks())
+}
+
+func TestCheckString(t *testing.T) {
+	assert.Equal(t, "make e2e (exit 0)", Check{Command: "make e2e"}.String())
+	assert.Equal(t, "step 2: ./cli -x (exit 2, output matches /unknown flag/)",
+		Check{Step: 2, Command: "./cli -x", ExitCode: 2, Output: "unknown flag"}.String())
+}
+
+func TestGetFeature(t *testing.T) {
+	p := &PRD{Features: []Feature{{ID: "feat-001"}, {ID: "feat-002"}}}
+
+	f := p.GetFeature("feat-002")
+	require.NotNil(t, f)
+	f.Passes = true
+	assert.True(t, p.Features[1].Passes, "returns a pointer into the PRD")
+
+	assert.Nil(t, p.GetFeature("feat-999"))
+}
+
+func TestBenchmarkSpecString(t *testing.T) {
+	b := &BenchmarkSpec{Command: "go test -bench Parse ./parser", Target: 1500, Improvement: 20}
+	assert.Equal(t, "ns/op", b.MetricName())
+	assert.False(t, b.HigherIsBetter())
+	assert.Equal(t, "go test -bench Parse ./parser: ns/op <= 1500, ns/op 20% better than baseline", b.String())
+
+	throughput := &BenchmarkSpec{Command: "make bench", Metric: "MB/s", Target: 200}
+	assert.True(t, throughput.HigherIsBetter())
+	assert.Equal(t, "make bench: MB/s >= 200", throughput.String())
+}
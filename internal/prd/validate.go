@@ -2,11 +2,18 @@ package prd
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/samber/lo"
+
+	"github.com/mpjhorner/superralph/internal/coverage"
+	"github.com/mpjhorner/superralph/internal/tagging"
 )
 
+// validID matches feature IDs, see idPattern
+var validID = regexp.MustCompile(idPattern)
+
 // ValidationError represents a validation error with context
 type ValidationError struct {
 	Field   string
@@ -47,6 +54,34 @@ func Validate(p *PRD) ValidationResult {
 		result.addError("features", "must have at least one feature")
 	}
 
+	// Validate the coverage gate
+	if p.Coverage != nil {
+		if p.Coverage.Threshold < 0 || p.Coverage.Threshold >= 100 {
+			result.addError("coverage.threshold", fmt.Sprintf("must be between 0 and 100, got %g", p.Coverage.Threshold))
+		}
+		if strings.TrimSpace(p.Coverage.Profile) == "" {
+			if _, _, ok := coverage.GoCommand(p.TestCommand, coverage.DefaultProfile); !ok {
+				result.addError("coverage.profile", "is required unless testCommand is a plain go test command")
+			}
+		}
+	}
+
+	if p.Strategy != "" && !p.Strategy.IsValid() {
+		result.addError("strategy", fmt.Sprintf("invalid strategy '%s' (must be one of: %s)", p.Strategy, validStrategyList()))
+	}
+
+	// Validate the project's own categories and priority levels
+	result.validateLevels("categories", lo.Map(p.Categories, func(c Category, _ int) string { return string(c) }))
+	result.validateLevels("priorities", lo.Map(p.Priorities, func(pri Priority, _ int) string { return string(pri) }))
+	for i, pri := range p.Priorities {
+		if _, ok := pri.Weight(); ok {
+			result.addError(fmt.Sprintf("priorities[%d]", i), fmt.Sprintf("'%s' is a weight, not a level name (features can use weights without declaring them)", pri))
+		}
+	}
+	if named := p.namedPriorityFeature(); named != "" && p.UsesWeights() {
+		result.addError("features", fmt.Sprintf("mixes named priorities (%s) with integer weights; use one or the other", named))
+	}
+
 	// Validate each feature
 	seenIDs := make(map[string]bool)
 	for i, f := range p.Features {
@@ -55,6 +90,8 @@ func Validate(p *PRD) ValidationResult {
 		// Validate ID
 		if strings.TrimSpace(f.ID) == "" {
 			result.addError(prefix+".id", "is required")
+		} else if !validID.MatchString(f.ID) {
+			result.addError(prefix+".id", fmt.Sprintf("invalid id '%s' (no spaces, commas or colons)", f.ID))
 		} else if seenIDs[f.ID] {
 			result.addError(prefix+".id", fmt.Sprintf("duplicate id '%s'", f.ID))
 		} else {
@@ -62,15 +99,36 @@ func Validate(p *PRD) ValidationResult {
 		}
 
 		// Validate category
-		if !f.Category.IsValid() {
+		if !p.IsAllowedCategory(f.Category) {
 			result.addError(prefix+".category", fmt.Sprintf("invalid category '%s' (must be one of: %s)",
-				f.Category, validCategoryList()))
+				f.Category, validCategoryList(p)))
 		}
 
 		// Validate priority
-		if !f.Priority.IsValid() {
-			result.addError(prefix+".priority", fmt.Sprintf("invalid priority '%s' (must be one of: %s)",
-				f.Priority, validPriorityList()))
+		if !p.IsAllowedPriority(f.Priority) {
+			result.addError(prefix+".priority", fmt.Sprintf("invalid priority '%s' (must be one of: %s, or an integer weight)",
+				f.Priority, validPriorityList(p)))
+		}
+
+		// Validate status
+		if f.Status != "" && !f.Status.IsValid() {
+			result.addError(prefix+".status", fmt.Sprintf("invalid status '%s' (must be one of: %s)", f.Status, validStatusList()))
+		}
+		if f.BlockedReason != "" && f.CurrentStatus() != StatusBlocked {
+			result.addError(prefix+".blockedReason", "only applies to blocked features")
+		}
+		for j, rule := range f.LintIgnore {
+			if !rule.IsValid() {
+				result.addError(fmt.Sprintf("%s.lintIgnore[%d]", prefix, j), fmt.Sprintf("unknown lint rule '%s' (must be one of: %s)", rule, validLintRuleList()))
+			}
+		}
+
+		// Validate value and effort
+		if f.Value < 0 {
+			result.addError(prefix+".value", fmt.Sprintf("must not be negative, got %d", f.Value))
+		}
+		if f.Effort < 0 {
+			result.addError(prefix+".effort", fmt.Sprintf("must not be negative, got %d", f.Effort))
 		}
 
 		// Validate description
@@ -90,12 +148,60 @@ func Validate(p *PRD) ValidationResult {
 		}
 	}
 
+	// Validate context declarations (entries must not be blank)
+	for i, f := range p.Features {
+		if f.Context == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("features[%d].context", i)
+		fields := []struct {
+			name    string
+			entries []string
+		}{
+			{"files", f.Context.Files},
+			{"exclude", f.Context.Exclude},
+			{"docs", f.Context.Docs},
+		}
+		for _, field := range fields {
+			for j, entry := range field.entries {
+				if strings.TrimSpace(strings.TrimLeft(entry, "@!")) == "" {
+					result.addError(fmt.Sprintf("%s.%s[%d]", prefix, field.name, j), "cannot be empty")
+				}
+			}
+		}
+	}
+
+	// Validate executable checks
+	for i, f := range p.Features {
+		prefix := fmt.Sprintf("features[%d]", i)
+		if f.Verify != nil {
+			result.validateCheck(prefix+".verify", *f.Verify, len(f.Steps))
+		}
+		for j, check := range f.Checks {
+			field := fmt.Sprintf("%s.checks[%d]", prefix, j)
+			result.validateCheck(field, check, len(f.Steps))
+			if check.Step == 0 {
+				result.addError(field+".step", "is required (use verify for feature-level checks)")
+			}
+		}
+	}
+
+	// Validate benchmark targets
+	for i, f := range p.Features {
+		if f.Benchmark != nil {
+			result.validateBenchmark(fmt.Sprintf("features[%d].benchmark", i), f)
+		}
+	}
+
 	// Validate depends_on references (second pass, after all IDs are collected)
 	for i, f := range p.Features {
 		prefix := fmt.Sprintf("features[%d]", i)
 		for j, depID := range f.DependsOn {
 			if strings.TrimSpace(depID) == "" {
 				result.addError(fmt.Sprintf("%s.depends_on[%d]", prefix, j), "cannot be empty")
+			} else if !validID.MatchString(depID) {
+				result.addError(fmt.Sprintf("%s.depends_on[%d]", prefix, j),
+					fmt.Sprintf("invalid id '%s' (no spaces, commas or colons)", depID))
 			} else if !seenIDs[depID] {
 				result.addError(fmt.Sprintf("%s.depends_on[%d]", prefix, j),
 					fmt.Sprintf("references unknown feature '%s'", depID))
@@ -106,23 +212,149 @@ func Validate(p *PRD) ValidationResult {
 		}
 	}
 
+	// Validate that the dependencies don't form a cycle, which would leave
+	// every feature on it blocked forever
+	for _, cycle := range p.Cycles() {
+		result.addError(fmt.Sprintf("features[%d].depends_on", p.featureIndex(cycle[0])),
+			fmt.Sprintf("dependency cycle: %s", strings.Join(cycle, " -> ")))
+	}
+
 	return result
 }
 
+// validateCheck checks that a check has a command, a valid exit code and
+// output pattern, and refers to an existing step
+func (r *ValidationResult) validateCheck(field string, c Check, steps int) {
+	if strings.TrimSpace(c.Command) == "" {
+		r.addError(field+".command", "is required")
+	}
+	if c.ExitCode < 0 || c.ExitCode > 255 {
+		r.addError(field+".exitCode", fmt.Sprintf("must be between 0 and 255, got %d", c.ExitCode))
+	}
+	if c.Output != "" {
+		if _, err := regexp.Compile(c.Output); err != nil {
+			r.addError(field+".output", fmt.Sprintf("invalid pattern: %v", err))
+		}
+	}
+	if c.Step < 0 || c.Step > steps {
+		r.addError(field+".step", fmt.Sprintf("references step %d but the feature has %d steps", c.Step, steps))
+	}
+}
+
+// validateBenchmark checks that a benchmark is declared on a performance
+// feature and has a command and at least one target
+func (r *ValidationResult) validateBenchmark(field string, f Feature) {
+	b := f.Benchmark
+	if f.Category != CategoryPerformance {
+		r.addError(field, fmt.Sprintf("is only allowed on %s features", CategoryPerformance))
+	}
+	if strings.TrimSpace(b.Command) == "" {
+		r.addError(field+".command", "is required")
+	}
+	if b.Benchmark != "" {
+		if _, err := regexp.Compile(b.Benchmark); err != nil {
+			r.addError(field+".benchmark", fmt.Sprintf("invalid pattern: %v", err))
+		}
+	}
+	if b.Target < 0 {
+		r.addError(field+".target", "cannot be negative")
+	}
+	if b.Improvement < 0 {
+		r.addError(field+".improvement", "cannot be negative")
+	}
+	if b.Target == 0 && b.Improvement == 0 {
+		r.addError(field, "must set a target or an improvement")
+	}
+	if b.Runs < 0 {
+		r.addError(field+".runs", "cannot be negative")
+	}
+	if b.Tolerance < 0 {
+		r.addError(field+".tolerance", "cannot be negative")
+	}
+}
+
+// ValidateContext checks that every file pattern and document declared in a
+// feature's context matches at least one file under dir. Exclusions are not checked.
+// This is separate from Validate because it needs access to the filesystem.
+func ValidateContext(p *PRD, dir string) ValidationResult {
+	result := ValidationResult{Valid: true}
+	tagger := tagging.New(dir)
+
+	for i, f := range p.Features {
+		if f.Context == nil {
+			continue
+		}
+		prefix := fmt.Sprintf("features[%d].context", i)
+		check := func(field string, entries []string) {
+			for j, entry := range entries {
+				if strings.TrimSpace(strings.TrimPrefix(entry, "@")) == "" {
+					continue // Reported by Validate
+				}
+				tag, err := tagger.ParseTag(entry)
+				if err != nil {
+					result.addError(fmt.Sprintf("%s.%s[%d]", prefix, field, j),
+						fmt.Sprintf("invalid pattern '%s': %v", entry, err))
+				} else if len(tag.ResolvedPaths) == 0 {
+					result.addError(fmt.Sprintf("%s.%s[%d]", prefix, field, j),
+						fmt.Sprintf("'%s' does not match any files", entry))
+				}
+			}
+		}
+		check("files", f.Context.Files)
+		check("docs", f.Context.Docs)
+	}
+
+	return result
+}
+
+// Merge appends the errors from other into r
+func (r *ValidationResult) Merge(other ValidationResult) {
+	if !other.Valid {
+		r.Valid = false
+	}
+	r.Errors = append(r.Errors, other.Errors...)
+}
+
 func (r *ValidationResult) addError(field, message string) {
 	r.Valid = false
 	r.Errors = append(r.Errors, ValidationError{Field: field, Message: message})
 }
 
-func validCategoryList() string {
-	strs := lo.Map(ValidCategories(), func(c Category, _ int) string {
+// validateLevels checks a PRD's own category or priority list: entries must
+// be non-blank and unique
+func (r *ValidationResult) validateLevels(field string, levels []string) {
+	seen := make(map[string]bool)
+	for i, level := range levels {
+		switch {
+		case strings.TrimSpace(level) == "":
+			r.addError(fmt.Sprintf("%s[%d]", field, i), "cannot be empty")
+		case seen[level]:
+			r.addError(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("duplicate '%s'", level))
+		}
+		seen[level] = true
+	}
+}
+
+// namedPriorityFeature returns the ID of the first feature with a named
+// priority, or "" if there isn't one
+func (p *PRD) namedPriorityFeature() string {
+	for _, f := range p.Features {
+		if _, ok := f.Priority.Weight(); !ok && f.Priority != "" {
+			return f.ID
+		}
+	}
+	return ""
+}
+
+func validCategoryList(p *PRD) string {
+	strs := lo.Map(p.AllowedCategories(), func(c Category, _ int) string {
 		return string(c)
 	})
 	return strings.Join(strs, ", ")
 }
 
-func validPriorityList() string {
-	strs := lo.Map(ValidPriorities(), func(p Priority, _ int) string {
+func validPriorityList(p *PRD) string {
+	strs := lo.Map(p.AllowedPriorities(), func(p Priority, _ int) string {
 		return string(p)
 	})
 	return strings.Join(strs, ", ")
@@ -1,9 +1,12 @@
 package prd
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidate(t *testing.T) {
@@ -190,6 +193,26 @@ func TestValidate(t *testing.T) {
 			wantValid:  false,
 			wantErrors: 1,
 		},
+		{
+			name: "id with a space",
+			prd: &PRD{
+				Name:        "Test Project",
+				Description: "Test description",
+				TestCommand: "go test ./...",
+				Features: []Feature{
+					{
+						ID:          "feat 001",
+						Category:    CategoryFunctional,
+						Priority:    PriorityHigh,
+						Description: "Test feature",
+						Steps:       []string{"Step 1"},
+						Passes:      false,
+					},
+				},
+			},
+			wantValid:  false,
+			wantErrors: 1,
+		},
 		{
 			name: "multiple errors",
 			prd: &PRD{
@@ -334,6 +357,35 @@ func TestValidateDependsOn(t *testing.T) {
 			wantValid:  false,
 			wantErrors: 1,
 		},
+		{
+			name: "depends_on id with a comma",
+			prd: &PRD{
+				Name:        "Test Project",
+				Description: "Test description",
+				TestCommand: "go test ./...",
+				Features: []Feature{
+					{
+						ID:          "feat-001,feat-003",
+						Category:    CategoryFunctional,
+						Priority:    PriorityHigh,
+						Description: "First feature",
+						Steps:       []string{"Step 1"},
+						Passes:      false,
+					},
+					{
+						ID:          "feat-002",
+						Category:    CategoryFunctional,
+						Priority:    PriorityHigh,
+						Description: "Feature with a comma in its dep",
+						Steps:       []string{"Step 1"},
+						Passes:      false,
+						DependsOn:   []string{"feat-001,feat-003"},
+					},
+				},
+			},
+			wantValid:  false,
+			wantErrors: 2, // the id and the dependency on it
+		},
 		{
 			name: "multiple depends_on errors",
 			prd: &PRD{
@@ -403,3 +455,180 @@ func TestValidateDependsOn(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateContextEntriesNotEmpty(t *testing.T) {
+	p := &PRD{
+		Name:        "Test",
+		Description: "Test",
+		TestCommand: "go test ./...",
+		Features: []Feature{
+			{
+				ID:          "feat-001",
+				Category:    CategoryFunctional,
+				Priority:    PriorityHigh,
+				Description: "Feature",
+				Steps:       []string{"Step 1"},
+				Context:     &ContextSpec{Files: []string{"  "}, Exclude: []string{"@!"}},
+			},
+		},
+	}
+
+	result := Validate(p)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, "features[0].context.files[0]", result.Errors[0].Field)
+	assert.Equal(t, "features[0].context.exclude[0]", result.Errors[1].Field)
+}
+
+func TestValidateChecks(t *testing.T) {
+	p := &PRD{
+		Name:        "Test",
+		Description: "Test",
+		TestCommand: "go test ./...",
+		Features: []Feature{
+			{
+				ID:          "feat-001",
+				Category:    CategoryFunctional,
+				Priority:    PriorityHigh,
+				Description: "Feature",
+				Steps:       []string{"Step 1", "Step 2"},
+				Verify:      &Check{Command: " "},
+				Checks: []Check{
+					{Step: 1, Command: "ok"},
+					{Step: 3, Command: "ok"},
+					{Command: "ok", ExitCode: 300},
+					{Step: 2, Command: "ok", Output: "("},
+				},
+			},
+		},
+	}
+
+	result := Validate(p)
+	assert.False(t, result.Valid)
+
+	fields := make([]string, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"features[0].verify.command",
+		"features[0].checks[1].step",
+		"features[0].checks[2].exitCode",
+		"features[0].checks[2].step",
+		"features[0].checks[3].output",
+	}, fields)
+}
+
+func TestValidateBenchmark(t *testing.T) {
+	tests := []struct {
+		name       string
+		category   Category
+		benchmark  *BenchmarkSpec
+		wantFields []string
+	}{
+		{"valid target", CategoryPerformance, &BenchmarkSpec{Command: "go test -bench .", Target: 1500}, nil},
+		{"valid improvement", CategoryPerformance, &BenchmarkSpec{Command: "go test -bench .", Improvement: 20, Runs: 5}, nil},
+		{"wrong category", CategoryFunctional, &BenchmarkSpec{Command: "go test -bench .", Target: 1}, []string{"features[0].benchmark"}},
+		{"missing command and target", CategoryPerformance, &BenchmarkSpec{}, []string{"features[0].benchmark.command", "features[0].benchmark"}},
+		{"invalid values", CategoryPerformance, &BenchmarkSpec{Command: "x", Benchmark: "(", Target: 1, Runs: -1, Tolerance: -1}, []string{
+			"features[0].benchmark.benchmark",
+			"features[0].benchmark.runs",
+			"features[0].benchmark.tolerance",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PRD{
+				Name:        "Test",
+				Description: "Test",
+				TestCommand: "go test ./...",
+				Features: []Feature{
+					{ID: "feat-001", Category: tt.category, Priority: PriorityHigh, Description: "Feature", Steps: []string{"Step 1"}, Benchmark: tt.benchmark},
+				},
+			}
+
+			result := Validate(p)
+			var fields []string
+			for _, e := range result.Errors {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestValidateCoverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		testCommand string
+		coverage    *CoverageSpec
+		wantFields  []string
+	}{
+		{"go test needs no profile", "go test ./...", &CoverageSpec{Threshold: 1}, nil},
+		{"other runners need a profile", "npm test", &CoverageSpec{}, []string{"coverage.profile"}},
+		{"profile given", "npm test", &CoverageSpec{Profile: "coverage/lcov.info"}, nil},
+		{"negative threshold", "go test ./...", &CoverageSpec{Threshold: -1}, []string{"coverage.threshold"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PRD{
+				Name:        "Test",
+				Description: "Test",
+				TestCommand: tt.testCommand,
+				Coverage:    tt.coverage,
+				Features: []Feature{
+					{ID: "feat-001", Category: CategoryFunctional, Priority: PriorityHigh, Description: "Feature", Steps: []string{"Step 1"}},
+				},
+			}
+
+			result := Validate(p)
+			var fields []string
+			for _, e := range result.Errors {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+			assert.Equal(t, len(tt.wantFields) == 0, result.Valid)
+		})
+	}
+}
+
+func TestValidateContextPatternsMatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "design.md"), []byte("# Design"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+
+	p := &PRD{
+		Features: []Feature{
+			{ID: "feat-001", Context: &ContextSpec{
+				Files:   []string{"*.go", "@main.go"},
+				Exclude: []string{"does-not-exist"},
+				Docs:    []string{"docs/design.md"},
+			}},
+			{ID: "feat-002", Context: &ContextSpec{
+				Files: []string{"src/**/*.ts"},
+				Docs:  []string{"docs/missing.md"},
+			}},
+			{ID: "feat-003"},
+		},
+	}
+
+	result := ValidateContext(p, dir)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, "features[1].context.files[0]", result.Errors[0].Field)
+	assert.Contains(t, result.Errors[0].Message, "does not match any files")
+	assert.Equal(t, "features[1].context.docs[0]", result.Errors[1].Field)
+}
+
+func TestValidationResultMerge(t *testing.T) {
+	result := ValidationResult{Valid: true}
+	result.Merge(ValidationResult{Valid: true})
+	assert.True(t, result.Valid)
+
+	result.Merge(ValidationResult{Valid: false, Errors: []ValidationError{{Field: "x", Message: "bad"}}})
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 1)
+}
@@ -0,0 +1,206 @@
+package prd
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlCodec reads and writes prd.yaml. Comments and fields the PRD types
+// don't model survive saving: they stay with their keys, and with their
+// features by ID.
+type yamlCodec struct{}
+
+func (yamlCodec) document(data []byte) (map[string]any, error) {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return normalize(v)
+}
+
+func (yamlCodec) encode(p *PRD, original []byte) ([]byte, error) {
+	var old yaml.Node
+	var previous []byte
+	if original != nil && yaml.Unmarshal(original, &old) == nil {
+		previous, _ = nodeJSON(&old)
+	}
+	node, err := prdNode(p, previous)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		copyComments(&old, node)
+	}
+	return encodeYAML(node)
+}
+
+// prdNode converts a PRD to a YAML document node like yamlNode, keeping the
+// fields of previous, the file being replaced as JSON, that the PRD types
+// don't model (see keepUnmodeled)
+func prdNode(p *PRD, previous []byte) (*yaml.Node, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		data = keepUnmodeled(data, previous)
+	}
+	return jsonNode(data)
+}
+
+// yamlNode converts a value to a YAML document node, keeping the field order
+// of its JSON encoding
+func yamlNode(v any) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsonNode(data)
+}
+
+// jsonNode parses JSON into a YAML document node written as block YAML
+func jsonNode(data []byte) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearStyle(&node)
+	return &node, nil
+}
+
+// nodeJSON writes a YAML node as JSON, keeping the order of mapping keys
+func nodeJSON(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	var write func(node *yaml.Node) error
+	write = func(node *yaml.Node) error {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			if len(node.Content) == 0 {
+				buf.WriteString("null")
+				return nil
+			}
+			return write(node.Content[0])
+		case yaml.AliasNode:
+			return write(node.Alias)
+		case yaml.MappingNode:
+			buf.WriteByte('{')
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				key, _ := json.Marshal(node.Content[i].Value)
+				buf.Write(key)
+				buf.WriteByte(':')
+				if err := write(node.Content[i+1]); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte('}')
+		case yaml.SequenceNode:
+			buf.WriteByte('[')
+			for i, item := range node.Content {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				if err := write(item); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(']')
+		default:
+			var v any
+			if err := node.Decode(&v); err != nil {
+				return err
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			buf.Write(data)
+		}
+		return nil
+	}
+	if err := write(node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clearStyle drops the flow and quoting styles of nodes parsed from JSON, so
+// they are written as block YAML
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// encodeYAML writes a node with two-space indentation
+func encodeYAML(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// copyComments copies the comments of old onto the matching nodes of node:
+// mapping values by key, sequence items by "id" when they have one and by
+// position otherwise
+func copyComments(old, node *yaml.Node) {
+	if old == nil || node == nil {
+		return
+	}
+	node.HeadComment = old.HeadComment
+	node.LineComment = old.LineComment
+	node.FootComment = old.FootComment
+	if old.Kind != node.Kind {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(old.Content) > 0 && len(node.Content) > 0 {
+			copyComments(old.Content[0], node.Content[0])
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if oldKey, oldValue := mappingEntry(old, node.Content[i].Value); oldKey != nil {
+				copyComments(oldKey, node.Content[i])
+				copyComments(oldValue, node.Content[i+1])
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if _, id := mappingEntry(item, "id"); id != nil {
+				for _, oldItem := range old.Content {
+					if _, oldID := mappingEntry(oldItem, "id"); oldID != nil && oldID.Value == id.Value {
+						copyComments(oldItem, item)
+					}
+				}
+			} else if i < len(old.Content) {
+				copyComments(old.Content[i], item)
+			}
+		}
+	}
+}
+
+// mappingEntry returns the key and value nodes for key in a mapping node
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
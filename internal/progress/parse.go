@@ -0,0 +1,104 @@
+package progress
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedEntry is an entry read back from progress.txt. Entries the agent
+// writes in its own format keep their text, but may lack the other fields.
+type ParsedEntry struct {
+	Timestamp   time.Time // Zero if the header had none
+	Iteration   int
+	WorkingOn   string // Feature ID from the starting state, if given
+	TestsPassed *bool  // Test result, if given
+	Note        string // Title of a note written outside a build session
+	Text        string // Entry body, without the header
+}
+
+// ParseEntries splits progress file content into entries. Each entry starts
+// with a header of "Session:" and "Iteration:" (or "Note:") lines between
+// separator lines, as written by Writer.
+func ParseEntries(content string) []ParsedEntry {
+	lines := strings.Split(content, "\n")
+	var entries []ParsedEntry
+	var current *ParsedEntry
+	var body []string
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.TrimSpace(strings.Join(body, "\n"))
+			entries = append(entries, *current)
+		}
+		current, body = nil, nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if isSeparator(lines[i]) {
+			// A header is a separator, Session/Iteration lines and a separator
+			end := i + 1
+			for end < len(lines) && end-i <= 3 && !isSeparator(lines[end]) {
+				end++
+			}
+			if end < len(lines) && isSeparator(lines[end]) && end > i+1 {
+				flush()
+				current = &ParsedEntry{}
+				for _, line := range lines[i+1 : end] {
+					key, value, _ := strings.Cut(line, ":")
+					value = strings.TrimSpace(value)
+					switch strings.TrimSpace(key) {
+					case "Session":
+						current.Timestamp, _ = time.Parse(time.RFC3339, value)
+					case "Iteration":
+						current.Iteration, _ = strconv.Atoi(value)
+					case "Note":
+						current.Note = value
+					}
+				}
+				i = end
+				continue
+			}
+		}
+		if current == nil {
+			continue
+		}
+		body = append(body, lines[i])
+
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(line, "- Working on:"):
+			if fields := strings.Fields(strings.TrimPrefix(line, "- Working on:")); len(fields) > 0 {
+				current.WorkingOn = fields[0]
+			}
+		case line == "- Result: PASSED", line == "- Result: FAILED":
+			passed := line == "- Result: PASSED"
+			current.TestsPassed = &passed
+		}
+	}
+	flush()
+	return entries
+}
+
+// Section returns the lines under an entry's "## heading", without the
+// list markers of bulleted lines and without blank lines
+func (e ParsedEntry) Section(heading string) []string {
+	var lines []string
+	in := false
+	for _, line := range strings.Split(e.Text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "## ") {
+			in = strings.EqualFold(strings.TrimSpace(trimmed[3:]), heading)
+			continue
+		}
+		if in && trimmed != "" {
+			lines = append(lines, strings.TrimPrefix(trimmed, "- "))
+		}
+	}
+	return lines
+}
+
+// isSeparator reports whether line is an entry header separator
+func isSeparator(line string) bool {
+	return len(line) >= 10 && strings.Trim(line, "=") == ""
+}
@@ -0,0 +1,69 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntries(t *testing.T) {
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	first := formatEntry(Entry{
+		Timestamp:     started,
+		Iteration:     1,
+		StartingState: State{FeaturesTotal: 2, WorkingOn: &FeatureRef{ID: "feat-001", Description: "Export"}},
+		WorkDone:      []string{"Wrote the exporter"},
+		Testing:       TestResult{Command: "go test ./...", Passed: false},
+	})
+	second := formatEntry(Entry{
+		Timestamp: started.Add(time.Hour),
+		Iteration: 2,
+		WorkDone:  []string{"Fixed feat-001"},
+		Testing:   TestResult{Command: "go test ./...", Passed: true},
+	})
+	agent := `================================================================================
+Session: not a time
+Iteration: 3
+================================================================================
+
+Implemented feat-002 by hand.
+`
+
+	entries := ParseEntries("# Progress\n\n" + first + second + agent)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, started, entries[0].Timestamp)
+	assert.Equal(t, 1, entries[0].Iteration)
+	assert.Equal(t, "feat-001", entries[0].WorkingOn)
+	require.NotNil(t, entries[0].TestsPassed)
+	assert.False(t, *entries[0].TestsPassed)
+	assert.Contains(t, entries[0].Text, "- Wrote the exporter")
+	assert.NotContains(t, entries[0].Text, "Fixed feat-001")
+
+	assert.Equal(t, 2, entries[1].Iteration)
+	assert.Empty(t, entries[1].WorkingOn)
+	assert.True(t, *entries[1].TestsPassed)
+
+	assert.True(t, entries[2].Timestamp.IsZero())
+	assert.Equal(t, 3, entries[2].Iteration)
+	assert.Nil(t, entries[2].TestsPassed)
+	assert.Equal(t, "Implemented feat-002 by hand.", entries[2].Text)
+
+	assert.Empty(t, ParseEntries("free text\nwithout headers\n"))
+}
+
+func TestParsedEntrySection(t *testing.T) {
+	entries := ParseEntries(formatEntry(Entry{
+		Timestamp:           time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Iteration:           1,
+		WorkDone:            []string{"Wrote the exporter"},
+		NotesForNextSession: []string{"CSV quoting is untested", "Start on feat-002"},
+	}))
+	require.Len(t, entries, 1)
+
+	assert.Equal(t, []string{"CSV quoting is untested", "Start on feat-002"}, entries[0].Section("Notes for Next Session"))
+	assert.Equal(t, []string{"Wrote the exporter"}, entries[0].Section("work done"))
+	assert.Empty(t, entries[0].Section("Missing"))
+}
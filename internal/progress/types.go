@@ -1,6 +1,12 @@
 package progress
 
-import "time"
+import (
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/bench"
+	"github.com/mpjhorner/superralph/internal/coverage"
+	"github.com/mpjhorner/superralph/internal/testresult"
+)
 
 // Entry represents a single progress entry/session
 type Entry struct {
@@ -9,11 +15,22 @@ type Entry struct {
 	StartingState       State
 	WorkDone            []string
 	Testing             TestResult
+	Benchmarks          []*bench.Evaluation // Benchmark targets checked this session
+	Integrity           *IntegrityResult    // Weakened tests found in the session's changes
 	Commits             []Commit
 	EndingState         State
 	NotesForNextSession []string
 }
 
+// Note is an entry written outside a build session, such as when a feature
+// is reset by hand
+type Note struct {
+	Timestamp           time.Time
+	Title               string
+	Lines               []string
+	NotesForNextSession []string
+}
+
 // State represents the state of the project at a point in time
 type State struct {
 	FeaturesTotal   int
@@ -30,9 +47,16 @@ type FeatureRef struct {
 
 // TestResult represents the result of running tests
 type TestResult struct {
-	Command string
-	Passed  bool
-	Details string
+	Command  string
+	Passed   bool
+	Details  *testresult.Report   // Parsed results; nil if the output wasn't recognized
+	Coverage *coverage.Comparison // Coverage gate result; nil if the gate is off
+}
+
+// IntegrityResult records changes that weakened the test suite
+type IntegrityResult struct {
+	Findings []string
+	Rejected bool // False if the session was only flagged for review
 }
 
 // Commit represents a git commit
@@ -48,6 +48,21 @@ func (w *Writer) Append(entry Entry) error {
 	return nil
 }
 
+// AppendNote appends a note to the progress file
+func (w *Writer) AppendNote(note Note) error {
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open progress file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(formatNote(note)); err != nil {
+		return fmt.Errorf("failed to write progress note: %w", err)
+	}
+
+	return nil
+}
+
 // Path returns the path to the progress file
 func (w *Writer) Path() string {
 	return w.path
@@ -91,11 +106,61 @@ func formatEntry(e Entry) string {
 	} else {
 		sb.WriteString("- Result: FAILED\n")
 	}
-	if e.Testing.Details != "" {
-		sb.WriteString(fmt.Sprintf("- Details: %s\n", e.Testing.Details))
+	if e.Testing.Details != nil {
+		sb.WriteString(fmt.Sprintf("- Details: %s\n", e.Testing.Details.Summary()))
+		for _, f := range e.Testing.Details.Failures {
+			sb.WriteString(fmt.Sprintf("- Failed: %s\n", f.String()))
+			for _, line := range strings.Split(f.Excerpt, "\n") {
+				if strings.TrimSpace(line) != "" {
+					sb.WriteString(fmt.Sprintf("    %s\n", line))
+				}
+			}
+		}
+	}
+	if c := e.Testing.Coverage; c != nil {
+		sb.WriteString(fmt.Sprintf("- Coverage: %s\n", c.Summary()))
+		for _, r := range c.Regressions {
+			sb.WriteString(fmt.Sprintf("- Coverage dropped: %s %.1f%% -> %.1f%%\n", r.Package, r.Before, r.After))
+		}
+		if c.Rejected() {
+			sb.WriteString(fmt.Sprintf("- Coverage gate: REJECTED (threshold %.1f points)\n", c.Threshold))
+		}
 	}
 	sb.WriteString("\n")
 
+	// Benchmarks (only for sessions that checked a benchmark target)
+	if len(e.Benchmarks) > 0 {
+		sb.WriteString("## Benchmarks\n")
+		for _, b := range e.Benchmarks {
+			result := "MET"
+			if !b.Met() {
+				result = "NOT MET"
+			}
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", b.FeatureID, result))
+			for _, c := range b.Comparisons {
+				sb.WriteString(fmt.Sprintf("    %s\n", c.Describe(b.Metric)))
+				if c.Reason != "" {
+					sb.WriteString(fmt.Sprintf("    %s\n", c.Reason))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// Test integrity (only when weakened tests were found)
+	if e.Integrity != nil && len(e.Integrity.Findings) > 0 {
+		sb.WriteString("## Test Integrity\n")
+		if e.Integrity.Rejected {
+			sb.WriteString("- Result: REJECTED\n")
+		} else {
+			sb.WriteString("- Result: FLAGGED FOR REVIEW\n")
+		}
+		for _, f := range e.Integrity.Findings {
+			sb.WriteString(fmt.Sprintf("- %s\n", f))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Commits
 	sb.WriteString("## Commits\n")
 	for _, commit := range e.Commits {
@@ -126,6 +191,37 @@ func formatEntry(e Entry) string {
 	return sb.String()
 }
 
+// String returns the note as AppendNote writes it
+func (n Note) String() string {
+	return formatNote(n)
+}
+
+func formatNote(n Note) string {
+	var sb strings.Builder
+
+	// The header names the note instead of an iteration
+	sb.WriteString("================================================================================\n")
+	sb.WriteString(fmt.Sprintf("Session: %s\n", n.Timestamp.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("Note: %s\n", n.Title))
+	sb.WriteString("================================================================================\n\n")
+
+	sb.WriteString("## " + n.Title + "\n")
+	for _, line := range n.Lines {
+		sb.WriteString(fmt.Sprintf("- %s\n", line))
+	}
+	sb.WriteString("\n")
+
+	if len(n.NotesForNextSession) > 0 {
+		sb.WriteString("## Notes for Next Session\n")
+		for _, note := range n.NotesForNextSession {
+			sb.WriteString(fmt.Sprintf("- %s\n", note))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
 // GetPath returns the path to the progress file in the given directory
 func GetPath(dir string) string {
 	return filepath.Join(dir, DefaultFilename)
@@ -166,3 +262,30 @@ func ReadFromCurrentDir() (string, error) {
 	}
 	return Read(path)
 }
+
+// maxLastEntryLines bounds LastEntry when the file has no entry headers
+const maxLastEntryLines = 60
+
+// LastEntry returns the most recent entry of a progress file's content: the
+// text from the last entry header on. Files without headers (entries the
+// agent wrote in its own format) get their last lines instead.
+func LastEntry(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	var separators []int
+	for i, line := range lines {
+		if len(line) >= 10 && strings.Trim(line, "=") == "" {
+			separators = append(separators, i)
+		}
+	}
+
+	start := 0
+	switch {
+	case len(separators) >= 2:
+		start = separators[len(separators)-2] // Header lines are wrapped in separators
+	case len(separators) == 1:
+		start = separators[0]
+	case len(lines) > maxLastEntryLines:
+		start = len(lines) - maxLastEntryLines
+	}
+	return strings.Join(lines[start:], "\n")
+}
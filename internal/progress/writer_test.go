@@ -1,11 +1,17 @@
 package progress
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mpjhorner/superralph/internal/bench"
+	"github.com/mpjhorner/superralph/internal/coverage"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/testresult"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -37,7 +43,7 @@ func TestWriterAppend(t *testing.T) {
 		Testing: TestResult{
 			Command: "go test ./...",
 			Passed:  true,
-			Details: "All tests passed",
+			Details: &testresult.Report{Format: testresult.FormatGo, Passed: 47},
 		},
 		Commits: []Commit{
 			{Hash: "abc1234", Message: "feat: add feature"},
@@ -77,6 +83,7 @@ func TestWriterAppend(t *testing.T) {
 		"Added tests",
 		"go test ./...",
 		"PASSED",
+		"Details: 47 passed",
 		"abc1234: feat: add feature",
 		"Features passing: 4/10",
 		"All tests passing: YES",
@@ -88,6 +95,112 @@ func TestWriterAppend(t *testing.T) {
 	}
 }
 
+func TestFormatEntryFailedTests(t *testing.T) {
+	entry := Entry{
+		Timestamp: time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC),
+		Iteration: 3,
+		Testing: TestResult{
+			Command: "go test ./...",
+			Passed:  false,
+			Details: &testresult.Report{
+				Format: testresult.FormatGoJSON,
+				Passed: 10,
+				Failed: 1,
+				Failures: []testresult.Failure{
+					{Name: "TestParse/empty", Package: "example.com/app/parser", Excerpt: "parser_test.go:42: expected error\n\ngot nil"},
+				},
+			},
+		},
+	}
+
+	content := formatEntry(entry)
+
+	assert.Contains(t, content, "- Result: FAILED\n")
+	assert.Contains(t, content, "- Details: 10 passed, 1 failed\n")
+	assert.Contains(t, content, "- Failed: example.com/app/parser: TestParse/empty\n")
+	assert.Contains(t, content, "    parser_test.go:42: expected error\n    got nil\n")
+}
+
+func TestFormatEntryCoverage(t *testing.T) {
+	baseline := &coverage.Baseline{Profile: &coverage.Profile{Packages: map[string]coverage.Counts{
+		"example.com/app/api": {Covered: 80, Total: 100},
+	}}}
+	current := &coverage.Profile{Packages: map[string]coverage.Counts{
+		"example.com/app/api": {Covered: 60, Total: 100},
+	}}
+	entry := Entry{
+		Timestamp: time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC),
+		Iteration: 4,
+		Testing: TestResult{
+			Command:  "go test ./...",
+			Passed:   true,
+			Coverage: coverage.Compare(baseline, current, 0.5),
+		},
+	}
+
+	content := formatEntry(entry)
+
+	assert.Contains(t, content, "- Coverage: 60.0% (-20.0 from 80.0%)\n")
+	assert.Contains(t, content, "- Coverage dropped: example.com/app/api 80.0% -> 60.0%\n")
+	assert.Contains(t, content, "- Coverage gate: REJECTED (threshold 0.5 points)\n")
+}
+
+func TestFormatEntryBenchmarks(t *testing.T) {
+	current := &bench.Measurement{Metric: "ns/op", Samples: map[string][]float64{"BenchmarkParse": {800}}}
+	entry := Entry{
+		Timestamp:  time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC),
+		Iteration:  5,
+		Benchmarks: []*bench.Evaluation{bench.Evaluate("feat-007", &prd.BenchmarkSpec{Target: 700}, nil, current)},
+	}
+
+	content := formatEntry(entry)
+
+	assert.Contains(t, content, "## Benchmarks\n- feat-007: NOT MET\n")
+	assert.Contains(t, content, "    BenchmarkParse: 800 ns/op (±0.0%)\n")
+	assert.Contains(t, content, "    800 ns/op is above the target of 700\n")
+	assert.NotContains(t, formatEntry(Entry{}), "## Benchmarks")
+}
+
+func TestFormatEntryIntegrity(t *testing.T) {
+	entry := Entry{
+		Timestamp: time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC),
+		Iteration: 6,
+		Integrity: &IntegrityResult{Findings: []string{"skip added: parser_test.go: t.Skip()"}},
+	}
+
+	content := formatEntry(entry)
+	assert.Contains(t, content, "## Test Integrity\n- Result: FLAGGED FOR REVIEW\n- skip added: parser_test.go: t.Skip()\n")
+
+	entry.Integrity.Rejected = true
+	assert.Contains(t, formatEntry(entry), "- Result: REJECTED\n")
+	assert.NotContains(t, formatEntry(Entry{}), "## Test Integrity")
+}
+
+func TestWriterAppendNote(t *testing.T) {
+	w := NewWriter(t.TempDir())
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, w.Append(Entry{Timestamp: at, Iteration: 1, WorkDone: []string{"Built the export"}}))
+	require.NoError(t, w.AppendNote(Note{
+		Timestamp:           at.Add(time.Hour),
+		Title:               "Reset",
+		Lines:               []string{"Reopened feat-001"},
+		NotesForNextSession: []string{"CSV quoting was wrong"},
+	}))
+
+	content, err := Read(w.Path())
+	require.NoError(t, err)
+	entries := ParseEntries(content)
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].Note)
+
+	note := entries[1]
+	assert.Equal(t, "Reset", note.Note)
+	assert.Equal(t, at.Add(time.Hour), note.Timestamp)
+	assert.Zero(t, note.Iteration)
+	assert.Equal(t, []string{"Reopened feat-001"}, note.Section("Reset"))
+	assert.Equal(t, []string{"CSV quoting was wrong"}, note.Section("Notes for Next Session"))
+}
+
 func TestWriterAppendMultiple(t *testing.T) {
 	// Create a temp directory
 	tmpDir, err := os.MkdirTemp("", "ralph-test-*")
@@ -166,3 +279,24 @@ func TestReadEmpty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, content)
 }
+
+func TestLastEntry(t *testing.T) {
+	first := formatEntry(Entry{Iteration: 1, WorkDone: []string{"First"}})
+	second := formatEntry(Entry{Iteration: 2, WorkDone: []string{"Second"}})
+
+	last := LastEntry(first + second)
+	assert.True(t, strings.HasPrefix(last, "====="), last)
+	assert.Contains(t, last, "Iteration: 2")
+	assert.Contains(t, last, "- Second")
+	assert.NotContains(t, last, "- First")
+
+	var free strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&free, "line %d\n", i)
+	}
+	last = LastEntry(free.String())
+	assert.True(t, strings.HasPrefix(last, "line 40\n"), last)
+	assert.True(t, strings.HasSuffix(last, "line 99"))
+
+	assert.Equal(t, "", LastEntry(""))
+}
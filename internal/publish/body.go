@@ -0,0 +1,72 @@
+package publish
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+// Gate is the outcome of one of the harness's gates, shown in the PR body
+type Gate struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// BodyInput is what a pull request body is written from
+type BodyInput struct {
+	Features []*prd.Feature
+	Progress string // The iteration's progress entry
+	DiffStat string // Output of git diff --stat
+	Gates    []Gate
+}
+
+// Body writes a pull request body in Markdown
+func Body(in BodyInput) string {
+	var sb strings.Builder
+
+	sb.WriteString("## Features\n\n")
+	for _, f := range in.Features {
+		sb.WriteString(fmt.Sprintf("### %s: %s\n\n", f.ID, f.Description))
+		for _, step := range f.Steps {
+			sb.WriteString(fmt.Sprintf("- [x] %s\n", step))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(in.Gates) > 0 {
+		sb.WriteString("## Gates\n\n")
+		sb.WriteString("| Gate | Result | Details |\n")
+		sb.WriteString("|------|--------|---------|\n")
+		for _, g := range in.Gates {
+			result := "passed"
+			if !g.Passed {
+				result = "**failed**"
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", g.Name, result, tableCell(g.Detail)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if stat := strings.TrimRight(in.DiffStat, "\n"); stat != "" {
+		sb.WriteString("## Changes\n\n```\n")
+		sb.WriteString(stat)
+		sb.WriteString("\n```\n\n")
+	}
+
+	if progress := strings.TrimSpace(in.Progress); progress != "" {
+		sb.WriteString("<details>\n<summary>Progress notes</summary>\n\n```\n")
+		sb.WriteString(progress)
+		sb.WriteString("\n```\n\n</details>\n\n")
+	}
+
+	sb.WriteString("---\nOpened by SuperRalph.\n")
+	return sb.String()
+}
+
+// tableCell keeps text on one table row
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,168 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubURL is the GitHub REST API; GitHub Enterprise uses
+// https://<host>/api/v3
+const DefaultGitHubURL = "https://api.github.com"
+
+// GitHub opens pull requests through the GitHub REST API
+type GitHub struct {
+	BaseURL string // API root (default: DefaultGitHubURL)
+	Owner   string
+	Repo    string
+	Token   string
+	Client  *http.Client
+}
+
+// NewGitHub creates a provider for the repository "owner/name"
+func NewGitHub(baseURL, repo, token string) (*GitHub, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid repository %q (expected owner/name)", repo)
+	}
+	if baseURL == "" {
+		baseURL = DefaultGitHubURL
+	}
+	return &GitHub{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Owner:   owner,
+		Repo:    name,
+		Token:   token,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Name returns "github"
+func (g *GitHub) Name() string {
+	return "github"
+}
+
+type githubPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// CreatePullRequest opens a pull request. If Head already has an open pull
+// request, GitHub rejects the new one and the existing one is returned, so
+// retries and later iterations of the same branch are harmless.
+func (g *GitHub) CreatePullRequest(ctx context.Context, pr PullRequest) (*Result, error) {
+	body, err := json.Marshal(map[string]any{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"draft": pr.Draft,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pull request: %w", err)
+	}
+
+	var created githubPull
+	err = g.do(ctx, http.MethodPost, g.repoPath("pulls"), body, &created)
+	if err == nil {
+		return &Result{Number: created.Number, URL: created.HTMLURL}, nil
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnprocessableEntity {
+		if existing, findErr := g.findOpen(ctx, pr.Head, pr.Base); findErr == nil && existing != nil {
+			return &Result{Number: existing.Number, URL: existing.HTMLURL}, nil
+		}
+	}
+	return nil, err
+}
+
+// findOpen returns the open pull request from head into base, if any
+func (g *GitHub) findOpen(ctx context.Context, head, base string) (*githubPull, error) {
+	query := url.Values{}
+	query.Set("state", "open")
+	query.Set("head", g.Owner+":"+head)
+	query.Set("base", base)
+
+	var pulls []githubPull
+	if err := g.do(ctx, http.MethodGet, g.repoPath("pulls")+"?"+query.Encode(), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return &pulls[0], nil
+}
+
+func (g *GitHub) repoPath(rest string) string {
+	return fmt.Sprintf("/repos/%s/%s/%s", url.PathEscape(g.Owner), url.PathEscape(g.Repo), rest)
+}
+
+// do sends a request and decodes a successful JSON response into out
+func (g *GitHub) do(ctx context.Context, method, path string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.BaseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read GitHub response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return &StatusError{StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse GitHub response: %w", err)
+	}
+	return nil
+}
+
+// ParseGitHubRepo returns "owner/name" from a GitHub remote URL, such as
+// git@github.com:owner/name.git or https://github.com/owner/name
+func ParseGitHubRepo(remoteURL string) (string, bool) {
+	s := strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(remoteURL), "/"), ".git")
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		s = u.Path
+	} else if _, path, ok := strings.Cut(s, ":"); ok {
+		s = path // scp-like syntax: git@host:owner/name
+	}
+	parts := strings.Split(strings.Trim(s, "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", false
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1], true
+}
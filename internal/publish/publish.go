@@ -0,0 +1,138 @@
+// Package publish pushes finished features and opens pull requests for them
+// through a provider adapter.
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RecordFile lists publication attempts, relative to the project directory
+const RecordFile = ".superralph/publish.json"
+
+// PullRequest is a pull request to open
+type PullRequest struct {
+	Title string
+	Body  string
+	Head  string // Branch with the changes
+	Base  string // Branch to merge into
+	Draft bool
+}
+
+// Result identifies an opened pull request
+type Result struct {
+	Number int
+	URL    string
+}
+
+// Provider opens pull requests on a code host
+type Provider interface {
+	// Name identifies the provider in output, e.g. "github"
+	Name() string
+
+	// CreatePullRequest opens a pull request, or returns the open one if
+	// Head already has one
+	CreatePullRequest(ctx context.Context, pr PullRequest) (*Result, error)
+}
+
+// StatusError is an unexpected HTTP response from a provider
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary returns true for responses worth retrying: rate limits and
+// server errors
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Retry calls fn up to attempts times, doubling delay after each failure.
+// Errors with a Temporary method returning false aren't retried. Returns the
+// number of calls made and the last error.
+func Retry(ctx context.Context, attempts int, delay time.Duration, fn func() error) (int, error) {
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 1; ; i++ {
+		if err = fn(); err == nil {
+			return i, nil
+		}
+		var temp interface{ Temporary() bool }
+		if i == attempts || (errors.As(err, &temp) && !temp.Temporary()) {
+			return i, err
+		}
+		select {
+		case <-ctx.Done():
+			return i, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Record is one feature's publication
+type Record struct {
+	FeatureIDs []string  `json:"feature_ids"`
+	Branch     string    `json:"branch"`
+	Remote     string    `json:"remote"`
+	Pushed     bool      `json:"pushed"`
+	Number     int       `json:"number,omitempty"` // Pull request number
+	URL        string    `json:"url,omitempty"`
+	Attempts   int       `json:"attempts"` // Calls made, including retries
+	Error      string    `json:"error,omitempty"`
+	Recorded   time.Time `json:"recorded"`
+}
+
+// LoadRecords reads the publication log for the project in dir, oldest first
+func LoadRecords(dir string) ([]Record, error) {
+	data, err := os.ReadFile(filepath.Join(dir, RecordFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read publication log: %w", err)
+	}
+
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse publication log: %w", err)
+	}
+	return records, nil
+}
+
+// AppendRecord adds a publication to the project's log
+func AppendRecord(dir string, r Record) error {
+	records, err := LoadRecords(dir)
+	if err != nil {
+		return err
+	}
+	records = append(records, r)
+
+	path := filepath.Join(dir, RecordFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .superralph directory: %w", err)
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal publication log: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write publication log: %w", err)
+	}
+	return nil
+}
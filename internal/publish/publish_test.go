@@ -0,0 +1,175 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+func TestGitHubCreatePullRequest(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v3/repos/acme/app/pulls", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 42, "html_url": "https://github.example.com/acme/app/pull/42"}`))
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub(server.URL+"/api/v3/", "acme/app", "secret")
+	require.NoError(t, err)
+	result, err := gh.CreatePullRequest(context.Background(), PullRequest{
+		Title: "feat: delete messages",
+		Body:  "body",
+		Head:  "ralph/feat-004",
+		Base:  "main",
+		Draft: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Number: 42, URL: "https://github.example.com/acme/app/pull/42"}, result)
+	assert.Equal(t, map[string]any{
+		"title": "feat: delete messages",
+		"body":  "body",
+		"head":  "ralph/feat-004",
+		"base":  "main",
+		"draft": true,
+	}, got)
+}
+
+func TestGitHubExistingPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "Validation Failed"}`))
+			return
+		}
+		assert.Equal(t, "acme:ralph/feat-004", r.URL.Query().Get("head"))
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		_, _ = w.Write([]byte(`[{"number": 7, "html_url": "https://github.com/acme/app/pull/7"}]`))
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub(server.URL, "acme/app", "")
+	require.NoError(t, err)
+	result, err := gh.CreatePullRequest(context.Background(), PullRequest{Head: "ralph/feat-004", Base: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, 7, result.Number)
+}
+
+func TestGitHubErrors(t *testing.T) {
+	status := http.StatusUnauthorized
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+	}))
+	defer server.Close()
+
+	gh, err := NewGitHub(server.URL, "acme/app", "wrong")
+	require.NoError(t, err)
+	_, err = gh.CreatePullRequest(context.Background(), PullRequest{Head: "a", Base: "main"})
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, "status 401: Bad credentials", statusErr.Error())
+	assert.False(t, statusErr.Temporary())
+
+	status = http.StatusBadGateway
+	_, err = gh.CreatePullRequest(context.Background(), PullRequest{Head: "a", Base: "main"})
+	require.ErrorAs(t, err, &statusErr)
+	assert.True(t, statusErr.Temporary())
+
+	_, err = NewGitHub("", "acme", "")
+	assert.Error(t, err)
+}
+
+func TestParseGitHubRepo(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:acme/app.git":              "acme/app",
+		"https://github.com/acme/app":              "acme/app",
+		"https://github.com/acme/app.git":          "acme/app",
+		"ssh://git@github.example.com/acme/app":    "acme/app",
+		"https://token@github.com/acme/app.git/":   "acme/app",
+		"git@github.example.com:team/acme/app.git": "acme/app",
+	}
+	for url, want := range tests {
+		repo, ok := ParseGitHubRepo(url)
+		assert.True(t, ok, url)
+		assert.Equal(t, want, repo, url)
+	}
+
+	_, ok := ParseGitHubRepo("/srv/git/app")
+	assert.True(t, ok, "local paths still have two segments")
+	_, ok = ParseGitHubRepo("app")
+	assert.False(t, ok)
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	n, err := Retry(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return &StatusError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// Permanent errors aren't retried
+	n, err = Retry(context.Background(), 3, time.Millisecond, func() error {
+		return &StatusError{StatusCode: http.StatusForbidden}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+
+	// Other errors are retried until attempts run out
+	n, err = Retry(context.Background(), 2, time.Millisecond, func() error {
+		return errors.New("connection refused")
+	})
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 2, n)
+}
+
+func TestBody(t *testing.T) {
+	body := Body(BodyInput{
+		Features: []*prd.Feature{{ID: "feat-004", Description: "User can delete messages", Steps: []string{"Open a message", "Click delete"}}},
+		Progress: "Implemented delete\n",
+		DiffStat: " msg.go | 3 +++\n 1 file changed\n",
+		Gates: []Gate{
+			{Name: "Test gate", Passed: true, Detail: "12 passed"},
+			{Name: "Coverage", Passed: true, Detail: "80.0% | up"},
+		},
+	})
+
+	assert.Contains(t, body, "### feat-004: User can delete messages\n\n- [x] Open a message\n- [x] Click delete\n")
+	assert.Contains(t, body, "| Test gate | passed | 12 passed |\n")
+	assert.Contains(t, body, `| Coverage | passed | 80.0% \| up |`)
+	assert.Contains(t, body, "```\n msg.go | 3 +++\n 1 file changed\n```")
+	assert.Contains(t, body, "Implemented delete")
+}
+
+func TestRecords(t *testing.T) {
+	tmpDir := t.TempDir()
+	records, err := LoadRecords(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	require.NoError(t, AppendRecord(tmpDir, Record{FeatureIDs: []string{"feat-001"}, Branch: "ralph/feat-001", Pushed: true, Number: 3}))
+	require.NoError(t, AppendRecord(tmpDir, Record{FeatureIDs: []string{"feat-002"}, Error: "status 401"}))
+	records, err = LoadRecords(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, 3, records[0].Number)
+	assert.Equal(t, "status 401", records[1].Error)
+}
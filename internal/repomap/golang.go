@@ -0,0 +1,150 @@
+package repomap
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// GoOutliner outlines Go source files using go/parser.
+// Test files are skipped since they add little to a map of the codebase.
+type GoOutliner struct{}
+
+// Name returns the language name
+func (GoOutliner) Name() string {
+	return "go"
+}
+
+// Match reports whether path is a non-test Go file
+func (GoOutliner) Match(path string) bool {
+	return strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go")
+}
+
+// Outline lists the exported constants, variables, types, functions and
+// methods of a Go file. Interface methods are listed under their interface.
+func (GoOutliner) Outline(path string, src []byte) (*FileOutline, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	outline := &FileOutline{Package: file.Name.Name}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			outline.Symbols = append(outline.Symbols, goGenDecl(fset, d)...)
+		case *ast.FuncDecl:
+			if sig, ok := goFuncDecl(fset, d); ok {
+				outline.Symbols = append(outline.Symbols, sig)
+			}
+		}
+	}
+
+	return outline, nil
+}
+
+// goGenDecl outlines exported constants, variables and types
+func goGenDecl(fset *token.FileSet, d *ast.GenDecl) []string {
+	var symbols []string
+
+	switch d.Tok {
+	case token.CONST, token.VAR:
+		var names []string
+		for _, spec := range d.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				if name.IsExported() {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		if len(names) > 0 {
+			symbols = append(symbols, d.Tok.String()+" "+strings.Join(names, ", "))
+		}
+
+	case token.TYPE:
+		for _, spec := range d.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if !ts.Name.IsExported() {
+				continue
+			}
+			symbols = append(symbols, goTypeSpec(fset, ts))
+		}
+	}
+
+	return symbols
+}
+
+// goTypeSpec renders a type declaration. Struct and interface bodies are
+// elided, except for an interface's exported methods.
+func goTypeSpec(fset *token.FileSet, ts *ast.TypeSpec) string {
+	header := *ts
+	header.Doc = nil
+	header.Comment = nil
+
+	var methods []string
+	switch t := ts.Type.(type) {
+	case *ast.StructType:
+		header.Type = ast.NewIdent("struct")
+	case *ast.InterfaceType:
+		header.Type = ast.NewIdent("interface")
+		for _, field := range t.Methods.List {
+			fn, ok := field.Type.(*ast.FuncType)
+			if !ok || len(field.Names) == 0 || !field.Names[0].IsExported() {
+				continue
+			}
+			methods = append(methods, "  "+field.Names[0].Name+strings.TrimPrefix(goNode(fset, fn), "func"))
+		}
+	}
+
+	sig := "type " + goNode(fset, &header)
+	if len(methods) > 0 {
+		sig += "\n" + strings.Join(methods, "\n")
+	}
+	return sig
+}
+
+// goFuncDecl renders an exported function or a method on an exported type
+func goFuncDecl(fset *token.FileSet, d *ast.FuncDecl) (string, bool) {
+	if !d.Name.IsExported() {
+		return "", false
+	}
+	if d.Recv != nil && len(d.Recv.List) > 0 && !goReceiverExported(d.Recv.List[0].Type) {
+		return "", false
+	}
+
+	sig := *d
+	sig.Doc = nil
+	sig.Body = nil
+	return goNode(fset, &sig), true
+}
+
+// goReceiverExported reports whether a method receiver's base type is exported
+func goReceiverExported(expr ast.Expr) bool {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// goNode prints an AST node on a single line
+func goNode(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
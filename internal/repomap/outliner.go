@@ -0,0 +1,59 @@
+package repomap
+
+import (
+	"sync"
+)
+
+// Outliner extracts the public symbols of source files in one language.
+// Implementations are registered with Register and selected by file path.
+type Outliner interface {
+	// Name identifies the language (e.g., "go")
+	Name() string
+
+	// Match reports whether the outliner handles the file at path
+	Match(path string) bool
+
+	// Outline returns the outline of a file's public symbols.
+	// The Path field is filled in by the caller.
+	Outline(path string, src []byte) (*FileOutline, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Outliner
+)
+
+func init() {
+	Register(GoOutliner{})
+}
+
+// Register adds an outliner. Outliners registered later take precedence, so a
+// project can replace a built-in outliner for the same files.
+func Register(o Outliner) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, o)
+}
+
+// For returns the outliner that handles path, or nil if none does
+func For(path string) Outliner {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for i := len(registry) - 1; i >= 0; i-- {
+		if registry[i].Match(path) {
+			return registry[i]
+		}
+	}
+	return nil
+}
+
+// Outliners returns the names of all registered outliners
+func Outliners() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for _, o := range registry {
+		names = append(names, o.Name())
+	}
+	return names
+}
@@ -0,0 +1,166 @@
+// Package repomap builds a compact outline of a repository's public symbols.
+//
+// A repository map lists, for each source file, its package and the
+// signatures of its exported types, functions and methods. It gives a
+// fresh-context agent a table of contents for the codebase without pasting
+// whole files into the prompt.
+//
+// Languages are supported through Outliner implementations registered with
+// Register. A Go outliner based on go/parser is registered by default.
+package repomap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxFileSize is the largest source file that will be outlined
+const DefaultMaxFileSize = 512 * 1024
+
+// Lister returns the relative paths of candidate files. Directory entries
+// (with a trailing slash) are ignored.
+type Lister func() ([]string, error)
+
+// FileOutline is the symbol outline of a single source file
+type FileOutline struct {
+	Path    string   // Path relative to the working directory
+	Package string   // Package, module or namespace name (optional)
+	Symbols []string // One signature per line, in source order
+}
+
+// Render formats the outline as a header line followed by indented symbols
+func (f *FileOutline) Render() string {
+	var sb strings.Builder
+	sb.WriteString(filepath.ToSlash(f.Path))
+	if f.Package != "" {
+		sb.WriteString(fmt.Sprintf(" (package %s)", f.Package))
+	}
+	sb.WriteString("\n")
+	for _, symbol := range f.Symbols {
+		for _, line := range strings.Split(symbol, "\n") {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// cachedOutline is an outline along with the file state it was built from
+type cachedOutline struct {
+	modTime time.Time
+	size    int64
+	outline *FileOutline // nil when the file has no public symbols or failed to parse
+}
+
+// Mapper builds repository maps, caching outlines between calls.
+// Files are only re-parsed when their modification time or size changes.
+type Mapper struct {
+	workDir     string
+	lister      Lister
+	maxFileSize int64
+
+	mu    sync.Mutex
+	cache map[string]cachedOutline
+}
+
+// New creates a Mapper for workDir using lister to enumerate candidate files
+func New(workDir string, lister Lister) *Mapper {
+	return &Mapper{
+		workDir:     workDir,
+		lister:      lister,
+		maxFileSize: DefaultMaxFileSize,
+		cache:       make(map[string]cachedOutline),
+	}
+}
+
+// Outlines returns the outline of every supported file that has public
+// symbols, in the order the lister returned them
+func (m *Mapper) Outlines() ([]*FileOutline, error) {
+	paths, err := m.lister()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var outlines []*FileOutline
+	seen := make(map[string]bool, len(paths))
+	for _, relPath := range paths {
+		if strings.HasSuffix(relPath, "/") {
+			continue
+		}
+		outliner := For(relPath)
+		if outliner == nil {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(m.workDir, relPath))
+		if err != nil || info.IsDir() || info.Size() > m.maxFileSize {
+			continue
+		}
+		seen[relPath] = true
+
+		cached, ok := m.cache[relPath]
+		if !ok || !cached.modTime.Equal(info.ModTime()) || cached.size != info.Size() {
+			cached = cachedOutline{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+				outline: m.outlineFile(outliner, relPath),
+			}
+			m.cache[relPath] = cached
+		}
+
+		if cached.outline != nil {
+			outlines = append(outlines, cached.outline)
+		}
+	}
+
+	for relPath := range m.cache {
+		if !seen[relPath] {
+			delete(m.cache, relPath)
+		}
+	}
+
+	return outlines, nil
+}
+
+// outlineFile reads and outlines a single file. Returns nil if the file
+// can't be read or parsed, or has no public symbols.
+func (m *Mapper) outlineFile(outliner Outliner, relPath string) *FileOutline {
+	src, err := os.ReadFile(filepath.Join(m.workDir, relPath))
+	if err != nil {
+		return nil
+	}
+	outline, err := outliner.Outline(relPath, src)
+	if err != nil || outline == nil || len(outline.Symbols) == 0 {
+		return nil
+	}
+	outline.Path = relPath
+	return outline
+}
+
+// Build renders the repository map, stopping before it exceeds budget bytes.
+// A budget of zero or less means no limit. Returns an empty string when no
+// supported files are found.
+func (m *Mapper) Build(budget int) (string, error) {
+	outlines, err := m.Outlines()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, outline := range outlines {
+		rendered := outline.Render()
+		if budget > 0 && sb.Len()+len(rendered) > budget {
+			sb.WriteString(fmt.Sprintf("[... %d more files omitted - use Glob/Grep to explore further ...]\n", len(outlines)-i))
+			break
+		}
+		sb.WriteString(rendered)
+	}
+
+	return sb.String(), nil
+}
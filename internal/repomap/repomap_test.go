@@ -0,0 +1,205 @@
+package repomap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleGo = `package store
+
+import "context"
+
+// MaxItems is exported
+const MaxItems, minItems = 10, 1
+
+var ErrNotFound = errors.New("not found")
+
+// Store persists items
+type Store struct {
+	items map[string]Item
+}
+
+type Item struct{ ID string }
+
+type Kind string
+
+type Pair[K comparable, V any] struct{ Key K; Value V }
+
+type Getter interface {
+	Get(ctx context.Context,
+		id string) (Item, error)
+	internal()
+}
+
+type hidden struct{}
+
+// New creates a store
+func New() *Store { return &Store{} }
+
+func (s *Store) Get(ctx context.Context, id string) (Item, error) {
+	return s.items[id], nil
+}
+
+func (s *Store) reset() {}
+
+func (h hidden) Exported() {}
+
+func helper() {}
+`
+
+// writeFiles creates files in dir and returns a lister over them
+func writeFiles(t *testing.T, dir string, files map[string]string) Lister {
+	t.Helper()
+	var paths []string
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+		paths = append(paths, path)
+	}
+	return func() ([]string, error) { return paths, nil }
+}
+
+func TestGoOutliner(t *testing.T) {
+	outline, err := GoOutliner{}.Outline("store.go", []byte(sampleGo))
+	require.NoError(t, err)
+
+	assert.Equal(t, "store", outline.Package)
+	assert.Equal(t, []string{
+		"const MaxItems",
+		"var ErrNotFound",
+		"type Store struct",
+		"type Item struct",
+		"type Kind string",
+		"type Pair[K comparable, V any] struct",
+		"type Getter interface\n  Get(ctx context.Context, id string) (Item, error)",
+		"func New() *Store",
+		"func (s *Store) Get(ctx context.Context, id string) (Item, error)",
+	}, outline.Symbols)
+}
+
+func TestGoOutlinerMatch(t *testing.T) {
+	g := GoOutliner{}
+	assert.True(t, g.Match("internal/store/store.go"))
+	assert.False(t, g.Match("internal/store/store_test.go"))
+	assert.False(t, g.Match("README.md"))
+}
+
+func TestGoOutlinerSyntaxError(t *testing.T) {
+	_, err := GoOutliner{}.Outline("bad.go", []byte("package bad\nfunc {"))
+	assert.Error(t, err)
+}
+
+func TestFileOutlineRender(t *testing.T) {
+	outline := &FileOutline{
+		Path:    filepath.Join("internal", "store", "store.go"),
+		Package: "store",
+		Symbols: []string{"type Getter interface\n  Get(id string) Item", "func New() *Store"},
+	}
+
+	assert.Equal(t, "internal/store/store.go (package store)\n"+
+		"  type Getter interface\n"+
+		"    Get(id string) Item\n"+
+		"  func New() *Store\n", outline.Render())
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{
+		"store/store.go":      sampleGo,
+		"store/store_test.go": "package store\n\nfunc TestX() {}",
+		"internal/priv.go":    "package internal\n\nfunc helper() {}",
+		"README.md":           "# Readme",
+		"broken.go":           "package broken\nfunc {",
+	})
+
+	repoMap, err := New(dir, lister).Build(0)
+	require.NoError(t, err)
+
+	assert.Contains(t, repoMap, "store/store.go (package store)")
+	assert.Contains(t, repoMap, "  func New() *Store")
+	assert.NotContains(t, repoMap, "store_test.go", "test files are skipped")
+	assert.NotContains(t, repoMap, "priv.go", "files without exported symbols are skipped")
+	assert.NotContains(t, repoMap, "README.md")
+	assert.NotContains(t, repoMap, "broken.go")
+}
+
+func TestBuildBudget(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		files[name+"/"+name+".go"] = "package " + name + "\n\nfunc Exported" + strings.ToUpper(name) + "() {}\n"
+	}
+	paths := []string{"a/a.go", "b/b.go", "c/c.go", "d/d.go"}
+	writeFiles(t, dir, files)
+	lister := func() ([]string, error) { return paths, nil }
+
+	repoMap, err := New(dir, lister).Build(80)
+	require.NoError(t, err)
+
+	assert.Contains(t, repoMap, "a/a.go")
+	assert.NotContains(t, repoMap, "d/d.go")
+	assert.Contains(t, repoMap, "more files omitted")
+}
+
+func TestOutlinesCacheInvalidatedByModTime(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{"a.go": "package a\n\nfunc First() {}\n"})
+
+	m := New(dir, lister)
+	repoMap, err := m.Build(0)
+	require.NoError(t, err)
+	assert.Contains(t, repoMap, "func First()")
+
+	path := filepath.Join(dir, "a.go")
+	require.NoError(t, os.WriteFile(path, []byte("package a\n\nfunc Second() {}\n"), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	repoMap, err = m.Build(0)
+	require.NoError(t, err)
+	assert.Contains(t, repoMap, "func Second()")
+	assert.NotContains(t, repoMap, "func First()")
+}
+
+// textOutliner outlines .txt files by listing their lines starting with "def "
+type textOutliner struct{}
+
+func (textOutliner) Name() string           { return "text" }
+func (textOutliner) Match(path string) bool { return strings.HasSuffix(path, ".txt") }
+func (textOutliner) Outline(path string, src []byte) (*FileOutline, error) {
+	outline := &FileOutline{}
+	for _, line := range strings.Split(string(src), "\n") {
+		if strings.HasPrefix(line, "def ") {
+			outline.Symbols = append(outline.Symbols, line)
+		}
+	}
+	return outline, nil
+}
+
+func TestRegisterOutliner(t *testing.T) {
+	assert.Nil(t, For("notes.txt"))
+	assert.Contains(t, Outliners(), "go")
+
+	Register(textOutliner{})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = registry[:len(registry)-1]
+		registryMu.Unlock()
+	})
+
+	require.NotNil(t, For("notes.txt"))
+	assert.Equal(t, "text", For("notes.txt").Name())
+
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{"notes.txt": "def build()\nplain line"})
+	repoMap, err := New(dir, lister).Build(0)
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt\n  def build()\n", repoMap)
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/publish"
+)
+
+// HTML renders the report as a single self-contained page. Diffs use the
+// DiffViewer's colors.
+func HTML(r *Report) (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.String(), nil
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+	"cost":     formatCost,
+	"result":   iterationResult,
+	"mark":     gateMark,
+	"local": func(t time.Time, layout string) string {
+		return t.Local().Format(layout)
+	},
+	"gateNames": func(gates []publish.Gate) string {
+		var names []string
+		for _, g := range gates {
+			names = append(names, g.Name)
+		}
+		return strings.Join(names, ", ")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Build report{{if .Project}}: {{.Project}}{{end}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1000px; padding: 0 1em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+.meta, .muted { color: #8a8a8a; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { text-align: left; padding: 0.35em 0.6em; border-bottom: 1px solid #ddd; vertical-align: top; }
+.summary { display: flex; flex-wrap: wrap; gap: 1em; }
+.summary div { background: #f5f5f5; border-radius: 6px; padding: 0.6em 1em; }
+.summary b { display: block; font-size: 1.4em; }
+.pass { color: #00af5f; }
+.fail { color: #d70000; }
+details { margin: 0.5em 0; }
+summary { cursor: pointer; }
+.path { color: #875fff; font-weight: bold; }
+.added-count { color: #00af5f; }
+.removed-count { color: #d70000; }
+pre.diff { background: #1c1c1c; color: #8a8a8a; padding: 0.6em 0; overflow-x: auto; border: 1px solid #585858; border-radius: 6px; font-size: 0.85em; }
+pre.diff span { display: block; padding: 0 0.8em; white-space: pre; }
+pre.diff .added { color: #00d787; background: #005f00; }
+pre.diff .removed { color: #ff0000; background: #5f0000; }
+pre.diff .header { color: #00afff; font-weight: bold; }
+pre.diff .path { color: #875fff; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Build report{{if .Project}}: {{.Project}}{{end}}</h1>
+<p class="meta">Run <code>{{.Run.ID}}</code>, started {{local .Run.Started "2006-01-02 15:04"}}, {{.Run.Outcome}}{{if .Run.Error}}: {{.Run.Error}}{{end}}</p>
+
+<div class="summary">
+<div><b>{{len .Completed}}</b>completed</div>
+<div><b>{{len .Stuck}}</b>stuck</div>
+<div><b>{{len .Iterations}}</b>iterations</div>
+<div><b>{{duration .Run.Duration}}</b>time</div>
+<div><b>{{cost .Run.CostUSD}}</b>cost</div>
+<div><b>{{len .Files}}</b>files (<span class="added-count">+{{.Added}}</span> <span class="removed-count">-{{.Removed}}</span>)</div>
+</div>
+{{if .Completed}}
+<h2>Completed</h2>
+<table>
+<tr><th>Feature</th><th>Description</th><th>Iterations</th><th>Time</th><th>Cost</th></tr>
+{{range .Completed}}<tr><td>{{.ID}}</td><td>{{.Description}}</td><td>{{.Iterations}}</td><td>{{duration .Duration}}</td><td>{{cost .CostUSD}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Stuck}}
+<h2>Stuck</h2>
+<table>
+<tr><th>Feature</th><th>Description</th><th>Iterations</th><th>Time</th><th>Cost</th><th>Failing gates</th></tr>
+{{range .Stuck}}<tr><td>{{.ID}}</td><td>{{.Description}}</td><td>{{.Iterations}}</td><td>{{duration .Duration}}</td><td>{{cost .CostUSD}}</td><td class="fail">{{gateNames .FailedGates}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Iterations}}
+<h2>Iterations</h2>
+<table>
+<tr><th>#</th><th>Feature</th><th>Started</th><th>Time</th><th>Cost</th><th>Gates</th><th>Result</th></tr>
+{{range .Iterations}}<tr><td>{{.Number}}</td><td>{{.FeatureID}}</td><td>{{local .Started "15:04"}}</td><td>{{duration .Duration}}</td><td>{{cost .CostUSD}}</td><td>{{range .Gates}}<div class="{{if .Passed}}pass{{else}}fail{{end}}">{{mark .}} {{.Name}}{{if and (not .Passed) .Detail}}<div class="muted">{{.Detail}}</div>{{end}}</div>{{else}}<span class="muted">none</span>{{end}}</td><td{{if .Rejected}} class="fail"{{end}}>{{result .}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Files}}
+<h2>Files Changed</h2>
+{{range .Files}}<details>
+<summary><span class="path">{{.Path}}</span> {{if .Binary}}<span class="muted">binary</span>{{else}}<span class="added-count">+{{.Added}}</span> <span class="removed-count">-{{.Removed}}</span>{{end}}</summary>
+{{if .Lines}}<pre class="diff">{{range .Lines}}<span class="{{.Kind}}">{{.Text}}</span>{{end}}{{if .Truncated}}<span>... (truncated)</span>{{end}}</pre>{{end}}
+</details>
+{{end}}{{end}}{{if .Notes}}
+<h2>Notes for Next Session</h2>
+<ul>
+{{range .Notes}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}
+<p class="muted">Generated by SuperRalph on {{local .Generated "2006-01-02 15:04"}}.</p>
+</body>
+</html>
+`))
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders the report as Markdown, with diffs in collapsible blocks
+func Markdown(r *Report) string {
+	var sb strings.Builder
+
+	title := "Build report"
+	if r.Project != "" {
+		title += ": " + r.Project
+	}
+	sb.WriteString("# " + title + "\n\n")
+	sb.WriteString(fmt.Sprintf("Run `%s`, started %s, %s.\n\n", r.Run.ID, r.Run.Started.Local().Format("2006-01-02 15:04"), r.Run.Outcome))
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString(fmt.Sprintf("- Features completed: %d\n", len(r.Completed)))
+	sb.WriteString(fmt.Sprintf("- Features stuck: %d\n", len(r.Stuck)))
+	sb.WriteString(fmt.Sprintf("- Iterations: %d\n", len(r.Iterations)))
+	sb.WriteString(fmt.Sprintf("- Time: %s\n", formatDuration(r.Run.Duration())))
+	sb.WriteString(fmt.Sprintf("- Cost: %s\n", formatCost(r.Run.CostUSD())))
+	sb.WriteString(fmt.Sprintf("- Files changed: %d (+%d -%d)\n", len(r.Files), r.Added(), r.Removed()))
+	if r.Run.Error != "" {
+		sb.WriteString(fmt.Sprintf("- Stopped by: %s\n", r.Run.Error))
+	}
+	sb.WriteString("\n")
+
+	if len(r.Completed) > 0 {
+		sb.WriteString("## Completed\n\n")
+		sb.WriteString("| Feature | Description | Iterations | Time | Cost |\n")
+		sb.WriteString("|---|---|---|---|---|\n")
+		for _, f := range r.Completed {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d | %s | %s |\n",
+				f.ID, cell(f.Description), f.Iterations, formatDuration(f.Duration), formatCost(f.CostUSD)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Stuck) > 0 {
+		sb.WriteString("## Stuck\n\n")
+		sb.WriteString("| Feature | Description | Iterations | Time | Cost | Failing gates |\n")
+		sb.WriteString("|---|---|---|---|---|---|\n")
+		for _, f := range r.Stuck {
+			var failed []string
+			for _, g := range f.FailedGates {
+				failed = append(failed, g.Name)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d | %s | %s | %s |\n",
+				f.ID, cell(f.Description), f.Iterations, formatDuration(f.Duration), formatCost(f.CostUSD), cell(strings.Join(failed, ", "))))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Iterations) > 0 {
+		sb.WriteString("## Iterations\n\n")
+		sb.WriteString("| # | Feature | Started | Time | Cost | Result |\n")
+		sb.WriteString("|---|---|---|---|---|---|\n")
+		for _, it := range r.Iterations {
+			sb.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s | %s |\n",
+				it.Number, it.FeatureID, it.Started.Local().Format("15:04"), formatDuration(it.Duration), formatCost(it.CostUSD), iterationResult(it)))
+		}
+		sb.WriteString("\n")
+
+		sb.WriteString("## Gate Results\n\n")
+		for _, it := range r.Iterations {
+			sb.WriteString(fmt.Sprintf("**Iteration %d** (%s)\n\n", it.Number, it.FeatureID))
+			if len(it.Gates) == 0 {
+				sb.WriteString("- No gates ran\n")
+			}
+			for _, g := range it.Gates {
+				line := fmt.Sprintf("- %s %s", gateMark(g), g.Name)
+				if g.Detail != "" {
+					line += ": " + strings.ReplaceAll(g.Detail, "\n", " ")
+				}
+				sb.WriteString(line + "\n")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(r.Files) > 0 {
+		sb.WriteString("## Files Changed\n\n")
+		sb.WriteString("| File | Added | Removed |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, f := range r.Files {
+			if f.Binary {
+				sb.WriteString(fmt.Sprintf("| %s | binary | |\n", cell(f.Path)))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("| %s | +%d | -%d |\n", cell(f.Path), f.Added, f.Removed))
+		}
+		sb.WriteString("\n")
+
+		sb.WriteString("## Diffs\n\n")
+		for _, f := range r.Files {
+			if len(f.Lines) == 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("<details><summary>%s (+%d -%d)</summary>\n\n", f.Path, f.Added, f.Removed))
+			sb.WriteString("```diff\n")
+			for _, l := range f.Lines {
+				sb.WriteString(l.Text + "\n")
+			}
+			if f.Truncated {
+				sb.WriteString("... (truncated)\n")
+			}
+			sb.WriteString("```\n\n</details>\n\n")
+		}
+	}
+
+	if len(r.Notes) > 0 {
+		sb.WriteString("## Notes for Next Session\n\n")
+		for _, note := range r.Notes {
+			sb.WriteString("- " + note + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("_Generated by SuperRalph on %s._\n", r.Generated.Local().Format("2006-01-02 15:04")))
+	return sb.String()
+}
+
+// cell escapes text for a Markdown table cell
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
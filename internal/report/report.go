@@ -0,0 +1,255 @@
+// Package report summarizes a build run for people who weren't watching it:
+// what got done, what's stuck, what it cost and what changed.
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/publish"
+	"github.com/mpjhorner/superralph/internal/runs"
+)
+
+// MaxDiffLines caps the lines embedded per file, to keep reports readable
+const MaxDiffLines = 400
+
+// Report is everything a run report shows
+type Report struct {
+	Project    string
+	Run        *runs.Run
+	Generated  time.Time
+	Completed  []*Feature
+	Stuck      []*Feature
+	Iterations []*runs.Iteration
+	Files      []*FileDiff
+	Notes      []string // The agent's notes for the next session
+}
+
+// Feature is a feature worked on during the run
+type Feature struct {
+	ID          string
+	Description string
+	Iterations  int
+	Duration    time.Duration
+	CostUSD     float64
+	FailedGates []publish.Gate // Gates its last iteration failed
+}
+
+// FileDiff is a changed file with its diff lines
+type FileDiff struct {
+	Path      string
+	Added     int
+	Removed   int
+	Binary    bool
+	Truncated bool // Lines beyond MaxDiffLines were left out
+	Lines     []DiffLine
+}
+
+// DiffLine is a line of a unified diff
+type DiffLine struct {
+	Kind LineKind
+	Text string
+}
+
+// LineKind classifies diff lines, as DiffViewer does
+type LineKind string
+
+const (
+	LineContext  LineKind = "context"
+	LineAdded    LineKind = "added"
+	LineRemoved  LineKind = "removed"
+	LineHeader   LineKind = "header" // @@ ... @@
+	LineFilePath LineKind = "path"   // --- or +++
+)
+
+// Build gathers a run's report from its record, the PRD, git and
+// progress.txt. The PRD is read from prdPath, or from dir if prdPath is empty.
+func Build(dir, prdPath string, run *runs.Run) (*Report, error) {
+	r := &Report{Run: run, Generated: time.Now().UTC(), Iterations: run.Iterations}
+
+	if prdPath == "" {
+		prdPath = prd.PathInDir(dir)
+	}
+	p, err := prd.Load(prdPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filepath.Base(prdPath), err)
+	}
+	r.Project = p.Name
+
+	for _, s := range run.Features() {
+		f := &Feature{
+			ID:         s.FeatureID,
+			Iterations: s.Iterations,
+			Duration:   s.Duration,
+			CostUSD:    s.CostUSD,
+		}
+		if pf := p.GetFeature(s.FeatureID); pf != nil {
+			f.Description = pf.Description
+		}
+		if s.Passed {
+			r.Completed = append(r.Completed, f)
+			continue
+		}
+		for _, g := range s.LastGates {
+			if !g.Passed {
+				f.FailedGates = append(f.FailedGates, g)
+			}
+		}
+		r.Stuck = append(r.Stuck, f)
+	}
+
+	if git.IsInsideWorkTree(dir) {
+		head := run.HeadCommit
+		if head == "" {
+			head = "HEAD"
+		}
+		if head != run.BaseCommit {
+			patch, err := git.DiffRange(dir, run.BaseCommit, head)
+			if err != nil {
+				return nil, fmt.Errorf("failed to diff the run: %w", err)
+			}
+			r.Files = ParseDiff(patch)
+		}
+	}
+
+	if content, err := progress.Read(progress.GetPath(dir)); err == nil {
+		r.Notes = runNotes(progress.ParseEntries(content), run)
+	}
+	return r, nil
+}
+
+// runNotes returns the notes for next session from the latest progress entry
+// written during the run that has any
+func runNotes(entries []progress.ParsedEntry, run *runs.Run) []string {
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !e.Timestamp.IsZero() && e.Timestamp.Before(run.Started.Truncate(time.Second)) {
+			break
+		}
+		if notes := e.Section("Notes for Next Session"); len(notes) > 0 {
+			return notes
+		}
+	}
+	return nil
+}
+
+// Added totals the lines added across files
+func (r *Report) Added() int {
+	n := 0
+	for _, f := range r.Files {
+		n += f.Added
+	}
+	return n
+}
+
+// Removed totals the lines removed across files
+func (r *Report) Removed() int {
+	n := 0
+	for _, f := range r.Files {
+		n += f.Removed
+	}
+	return n
+}
+
+// ParseDiff splits a unified diff into files
+func ParseDiff(patch string) []*FileDiff {
+	var files []*FileDiff
+	var current *FileDiff
+	inHunk := false
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			current = &FileDiff{Path: diffPath(line)}
+			files = append(files, current)
+			inHunk = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		var kind LineKind
+		switch {
+		case !inHunk && (strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ")):
+			kind = LineFilePath
+		case strings.HasPrefix(line, "@@"):
+			kind = LineHeader
+			inHunk = true
+		case strings.HasPrefix(line, "+"):
+			kind = LineAdded
+			current.Added++
+		case strings.HasPrefix(line, "-"):
+			kind = LineRemoved
+			current.Removed++
+		case strings.HasPrefix(line, " "), strings.HasPrefix(line, `\`):
+			kind = LineContext
+		case strings.HasPrefix(line, "Binary files "):
+			current.Binary = true
+			continue
+		default:
+			continue // index, mode and rename lines
+		}
+
+		if len(current.Lines) >= MaxDiffLines {
+			current.Truncated = true
+			continue
+		}
+		current.Lines = append(current.Lines, DiffLine{Kind: kind, Text: line})
+	}
+	return files
+}
+
+// diffPath takes the new path from a "diff --git a/x b/x" line
+func diffPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// formatDuration rounds durations for display
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Round(time.Minute).Minutes()))
+	default:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// formatCost formats a cost in US dollars
+func formatCost(usd float64) string {
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+// iterationResult describes how an iteration ended
+func iterationResult(it *runs.Iteration) string {
+	switch {
+	case it.Rejected:
+		return "rejected"
+	case len(it.Accepted) > 0:
+		return "accepted " + strings.Join(it.Accepted, ", ")
+	case it.Duration == 0:
+		return "in progress"
+	default:
+		return "no feature finished"
+	}
+}
+
+// gateMark is a gate's result as a symbol
+func gateMark(g publish.Gate) string {
+	if g.Passed {
+		return "✓"
+	}
+	return "✗"
+}
@@ -0,0 +1,136 @@
+package report
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+	"github.com/mpjhorner/superralph/internal/publish"
+	"github.com/mpjhorner/superralph/internal/runs"
+)
+
+func TestParseDiff(t *testing.T) {
+	patch := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-var x = 1
++var x = 2
++++ not a path, an added line
+\ No newline at end of file
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+`
+	files := ParseDiff(patch)
+	require.Len(t, files, 2)
+
+	f := files[0]
+	assert.Equal(t, "main.go", f.Path)
+	assert.Equal(t, 2, f.Added)
+	assert.Equal(t, 1, f.Removed)
+	var kinds []LineKind
+	for _, l := range f.Lines {
+		kinds = append(kinds, l.Kind)
+	}
+	assert.Equal(t, []LineKind{LineFilePath, LineFilePath, LineHeader, LineContext, LineRemoved, LineAdded, LineAdded, LineContext}, kinds)
+
+	assert.Equal(t, "logo.png", files[1].Path)
+	assert.True(t, files[1].Binary)
+	assert.Empty(t, files[1].Lines)
+
+	long := "diff --git a/big.txt b/big.txt\n@@ -0,0 +1,500 @@\n" + strings.Repeat("+line\n", 500)
+	big := ParseDiff(long)[0]
+	assert.Equal(t, 500, big.Added, "counts every line")
+	assert.Len(t, big.Lines, MaxDiffLines)
+	assert.True(t, big.Truncated)
+}
+
+func TestBuildAndRender(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, git.Init(dir))
+	for _, args := range [][]string{
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+	}
+
+	p := &prd.PRD{Name: "Shop", Features: []prd.Feature{
+		{ID: "feat-001", Category: prd.CategoryFunctional, Description: "Export orders", Steps: []string{"Step"}, Passes: true},
+		{ID: "feat-002", Category: prd.CategoryFunctional, Description: "Import <orders>", Steps: []string{"Step"}},
+	}}
+	require.NoError(t, prd.SaveToDir(p, dir))
+	base, err := git.CommitAll(dir, "initial", git.CommitOptions{})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "export.go"), []byte("package shop\n\nfunc Export() {}\n"), 0644))
+	head, err := git.CommitAll(dir, "feat: export orders", git.CommitOptions{})
+	require.NoError(t, err)
+
+	started := time.Now().UTC().Add(-time.Hour)
+	run := &runs.Run{
+		ID: "run-1", Started: started, Finished: started.Add(30 * time.Minute),
+		Outcome: runs.OutcomeMaxIterations, BaseCommit: base, HeadCommit: head,
+		Iterations: []*runs.Iteration{
+			{Number: 1, FeatureID: "feat-001", Started: started, Duration: 10 * time.Minute, CostUSD: 0.42,
+				Gates: []publish.Gate{{Name: "Tests", Passed: true}}, Accepted: []string{"feat-001"}},
+			{Number: 2, FeatureID: "feat-002", Started: started.Add(10 * time.Minute), Duration: 20 * time.Minute, CostUSD: 1.1,
+				Gates: []publish.Gate{{Name: "Tests", Passed: false, Detail: "2 failed"}}, Rejected: true},
+		},
+	}
+
+	old := progress.Entry{Timestamp: started.Add(-24 * time.Hour), Iteration: 1, NotesForNextSession: []string{"Stale note"}}
+	latest := progress.Entry{Timestamp: started.Add(20 * time.Minute), Iteration: 2, NotesForNextSession: []string{"Import parser needs a fixture"}}
+	w := progress.NewWriter(dir)
+	require.NoError(t, w.Append(old))
+	require.NoError(t, w.Append(latest))
+
+	r, err := Build(dir, "", run)
+	require.NoError(t, err)
+	assert.Equal(t, "Shop", r.Project)
+	require.Len(t, r.Completed, 1)
+	assert.Equal(t, "Export orders", r.Completed[0].Description)
+	require.Len(t, r.Stuck, 1)
+	assert.Equal(t, "feat-002", r.Stuck[0].ID)
+	require.Len(t, r.Stuck[0].FailedGates, 1)
+	require.Len(t, r.Files, 1)
+	assert.Equal(t, "export.go", r.Files[0].Path)
+	assert.Equal(t, 3, r.Added())
+	assert.Equal(t, []string{"Import parser needs a fixture"}, r.Notes)
+
+	md := Markdown(r)
+	assert.Contains(t, md, "# Build report: Shop")
+	assert.Contains(t, md, "- Cost: $1.52")
+	assert.Contains(t, md, "| feat-001 | Export orders | 1 | 10m | $0.42 |")
+	assert.Contains(t, md, "| feat-002 | Import <orders> | 1 | 20m | $1.10 | Tests |")
+	assert.Contains(t, md, "| 2 | feat-002 |")
+	assert.Contains(t, md, "- ✗ Tests: 2 failed")
+	assert.Contains(t, md, "| export.go | +3 | -0 |")
+	assert.Contains(t, md, "```diff\n--- /dev/null\n+++ b/export.go\n@@")
+	assert.Contains(t, md, "- Import parser needs a fixture")
+	assert.NotContains(t, md, "Stale note")
+
+	page, err := HTML(r)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+	assert.Contains(t, page, "<style>", "self-contained")
+	assert.Contains(t, page, "Import &lt;orders&gt;", "escaped")
+	assert.Contains(t, page, `<span class="added">&#43;func Export() {}</span>`)
+	assert.Contains(t, page, `<span class="header">@@`)
+	assert.Contains(t, page, "<li>Import parser needs a fixture</li>")
+}
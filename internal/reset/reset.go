@@ -0,0 +1,266 @@
+// Package reset puts a finished feature back in the build queue: it reopens
+// the feature, and optionally everything that depends on it, notes why in
+// progress.txt, and can revert the commits that implemented it.
+package reset
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/commitmsg"
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+)
+
+// Options controls what a reset changes
+type Options struct {
+	Dependents bool   // Also reopen the features that depend on it, transitively
+	Revert     bool   // Revert the commits attributed to the feature
+	Reason     string // Why the feature is being reset, for the progress note
+	PRD        string // PRD file; empty for the one in the project directory
+}
+
+// Plan is everything a reset will change
+type Plan struct {
+	Feature prd.Feature
+	Reason  string
+	PRD     string // PRD file the reset updates
+
+	// Features to reopen, starting with the feature itself, then its
+	// dependents in breadth-first order. Passes is their current state.
+	Reopen []prd.Feature
+
+	// Commits to revert, newest first
+	Revert []git.Commit
+
+	// Commits attributed to the feature that also implement other features,
+	// which are left alone
+	Shared []git.Commit
+}
+
+// bookkeeping returns the files in dir that keep their current content when
+// commits are reverted: the reset rewrites the PRD itself, and progress.txt
+// only ever grows
+func bookkeeping(dir, prdPath string) []string {
+	files := []string{progress.DefaultFilename}
+	if rel, err := filepath.Rel(dir, prdPath); err == nil && !strings.HasPrefix(rel, "..") {
+		files = append([]string{filepath.ToSlash(rel)}, files...)
+	}
+	return files
+}
+
+// revertedPattern finds the commits a revert commit undid
+var revertedPattern = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+
+// New works out what resetting a feature would change, without changing anything
+func New(dir, featureID string, opts Options) (*Plan, error) {
+	path := opts.PRD
+	if path == "" {
+		path = prd.PathInDir(dir)
+	}
+	p, err := prd.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	f := p.GetFeature(featureID)
+	if f == nil {
+		return nil, fmt.Errorf("feature %s not found in %s", featureID, filepath.Base(path))
+	}
+
+	plan := &Plan{Feature: *f, Reason: opts.Reason, PRD: path, Reopen: []prd.Feature{*f}}
+	if opts.Dependents {
+		queue := []string{featureID}
+		seen := map[string]bool{featureID: true}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, dep := range p.Dependents(id) {
+				if !seen[dep] {
+					seen[dep] = true
+					queue = append(queue, dep)
+					plan.Reopen = append(plan.Reopen, *p.GetFeature(dep))
+				}
+			}
+		}
+	}
+
+	if opts.Revert {
+		if !git.IsInsideWorkTree(dir) {
+			return nil, fmt.Errorf("reverting commits needs a git repository")
+		}
+		if err := plan.findCommits(dir); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// findCommits fills in the commits attributed to the feature: by their
+// Feature-Id trailers, or for commits without any, by the progress entries
+// of sessions that worked on it. Commits already reverted are skipped.
+func (plan *Plan) findCommits(dir string) error {
+	commits, err := git.Log(dir, git.LogOptions{})
+	if err != nil {
+		return err
+	}
+
+	var recorded []string
+	if content, err := progress.Read(progress.GetPath(dir)); err == nil {
+		for _, e := range progress.ParseEntries(content) {
+			if e.WorkingOn != plan.Feature.ID {
+				continue
+			}
+			for _, line := range e.Section("Commits") {
+				if hash, _, _ := strings.Cut(line, ":"); len(hash) >= 7 {
+					recorded = append(recorded, strings.TrimSpace(hash))
+				}
+			}
+		}
+	}
+
+	var reverted []string
+	for _, c := range commits {
+		for _, m := range revertedPattern.FindAllStringSubmatch(c.Body, -1) {
+			reverted = append(reverted, m[1])
+		}
+	}
+	hasPrefix := func(hashes []string, hash string) bool {
+		return slices.ContainsFunc(hashes, func(h string) bool { return strings.HasPrefix(hash, h) })
+	}
+
+	for _, c := range commits {
+		if c.Trailer(commitmsg.TrailerStatus) == commitmsg.StatusReset || hasPrefix(reverted, c.Hash) {
+			continue
+		}
+		ids, tagged := c.Trailers[commitmsg.TrailerFeature]
+		switch {
+		case tagged && !slices.Contains(ids, plan.Feature.ID):
+			continue
+		case !tagged && !hasPrefix(recorded, c.Hash):
+			continue
+		case len(ids) > 1:
+			plan.Shared = append(plan.Shared, c)
+		default:
+			plan.Revert = append(plan.Revert, c)
+		}
+	}
+	return nil
+}
+
+// Note returns the progress note the reset appends
+func (plan *Plan) Note() progress.Note {
+	id := plan.Feature.ID
+	note := progress.Note{Title: "Reset", Timestamp: time.Now()}
+	for i, f := range plan.Reopen {
+		if i == 0 {
+			note.Lines = append(note.Lines, fmt.Sprintf("Reopened %s \"%s\"", f.ID, f.Description))
+		} else {
+			note.Lines = append(note.Lines, fmt.Sprintf("Reopened %s \"%s\", which depends on %s", f.ID, f.Description, id))
+		}
+	}
+	for _, c := range plan.Revert {
+		note.Lines = append(note.Lines, fmt.Sprintf("Reverted %s: %s", c.ShortHash(), c.Subject))
+	}
+	for _, c := range plan.Shared {
+		others := slices.DeleteFunc(slices.Clone(c.Trailers[commitmsg.TrailerFeature]), func(s string) bool { return s == id })
+		note.Lines = append(note.Lines, fmt.Sprintf("Not reverted %s: %s (also implements %s)", c.ShortHash(), c.Subject, strings.Join(others, ", ")))
+	}
+
+	reason := plan.Reason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	note.Lines = append(note.Lines, "Reason: "+reason)
+	note.NotesForNextSession = []string{fmt.Sprintf("%s was reset and needs to be implemented again: %s", id, reason)}
+	return note
+}
+
+// Apply carries out the plan: reverts the commits, reopens the features and
+// appends the progress note. Reverts are committed together with the
+// updated prd.json and progress.txt; without reverts nothing is committed.
+// Returns the revert commit's hash, or "".
+func Apply(dir string, plan *Plan, signing git.CommitOptions) (string, error) {
+	if len(plan.Revert) > 0 {
+		if err := revertCommits(dir, plan.PRD, plan.Revert); err != nil {
+			return "", err
+		}
+	}
+
+	ids := make([]string, 0, len(plan.Reopen))
+	for _, f := range plan.Reopen {
+		ids = append(ids, f.ID)
+	}
+	_, err := prd.Update(plan.PRD, func(p *prd.PRD) error {
+		for _, id := range ids {
+			if _, err := p.Reopen(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if len(plan.Revert) > 0 {
+			_ = git.AbortRevert(dir)
+		}
+		return "", err
+	}
+
+	if err := progress.NewWriter(dir).AppendNote(plan.Note()); err != nil {
+		if len(plan.Revert) > 0 {
+			_ = git.AbortRevert(dir)
+		}
+		return "", err
+	}
+
+	if len(plan.Revert) == 0 {
+		return "", nil
+	}
+	hashes := make([]string, 0, len(plan.Revert))
+	for _, c := range plan.Revert {
+		hashes = append(hashes, c.Hash)
+	}
+	msg := commitmsg.Revert(&plan.Feature, plan.Reason, hashes)
+	hash, err := git.CommitFiles(dir, msg.String(), bookkeeping(dir, plan.PRD), signing)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit the reset: %w", err)
+	}
+	return hash, nil
+}
+
+// revertCommits stages the inverse of each commit, newest first, keeping the
+// bookkeeping files as they are. Conflicts in other files undo every revert.
+func revertCommits(dir, prdPath string, commits []git.Commit) error {
+	if changed, err := git.HasTrackedChanges(dir); err != nil {
+		return err
+	} else if changed {
+		return fmt.Errorf("commit or stash your changes before reverting commits")
+	}
+
+	kept := bookkeeping(dir, prdPath)
+	for _, c := range commits {
+		if err := git.Revert(dir, c.Hash); err != nil {
+			conflicts, cerr := git.ConflictedFiles(dir)
+			if cerr != nil || len(conflicts) == 0 {
+				_ = git.AbortRevert(dir)
+				return fmt.Errorf("failed to revert %s: %w", c.ShortHash(), err)
+			}
+			if others := slices.DeleteFunc(conflicts, func(f string) bool { return slices.Contains(kept, f) }); len(others) > 0 {
+				_ = git.AbortRevert(dir)
+				return fmt.Errorf("reverting %s conflicts in %s; revert it by hand with git revert", c.ShortHash(), strings.Join(others, ", "))
+			}
+			_ = git.QuitRevert(dir)
+		}
+		for _, path := range kept {
+			if err := git.RestoreFile(dir, "HEAD", path); err != nil {
+				_ = git.AbortRevert(dir)
+				return fmt.Errorf("failed to revert %s: %w", c.ShortHash(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package reset
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/commitmsg"
+	"github.com/mpjhorner/superralph/internal/git"
+	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/progress"
+)
+
+// setupRepo creates a repository where feat-001 was built over three
+// commits: one with a trailer, one recorded only in progress.txt, and one
+// shared with feat-002. feat-002 and feat-003 depend on it.
+func setupRepo(t *testing.T) (string, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, git.Init(dir))
+	for _, args := range [][]string{
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		require.NoError(t, cmd.Run())
+	}
+
+	feature := func(id, description string, deps ...string) prd.Feature {
+		return prd.Feature{ID: id, Category: "functional", Priority: "high", Description: description, Steps: []string{"Step"}, DependsOn: deps}
+	}
+	p := &prd.PRD{Name: "Test", Description: "Test project", TestCommand: "go test ./...", Features: []prd.Feature{
+		feature("feat-001", "Export data"),
+		feature("feat-002", "Import data", "feat-001"),
+		feature("feat-003", "Sync data", "feat-002"),
+		feature("feat-004", "Delete data"),
+	}}
+	p.Features[3].Passes = true
+	pass := func(ids ...string) {
+		for _, id := range ids {
+			p.GetFeature(id).Passes = true
+		}
+		require.NoError(t, prd.Save(p, filepath.Join(dir, prd.DefaultFilename)))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	writer := progress.NewWriter(dir)
+	commit := func(message string) string {
+		hash, err := git.CommitAll(dir, message, git.CommitOptions{})
+		require.NoError(t, err)
+		return hash
+	}
+
+	hashes := make(map[string]string)
+	pass()
+	write(progress.DefaultFilename, "# Progress\n")
+	commit("chore: setup")
+
+	write("export.go", "package export\n")
+	hashes["untagged"] = commit("wip exporter")
+	require.NoError(t, writer.Append(progress.Entry{
+		Timestamp:     time.Now(),
+		Iteration:     1,
+		StartingState: progress.State{WorkingOn: &progress.FeatureRef{ID: "feat-001", Description: "Export data"}},
+		Commits:       []progress.Commit{{Hash: hashes["untagged"][:7], Message: "wip exporter"}},
+	}))
+
+	write("export_test.go", "package export\n")
+	pass("feat-001")
+	hashes["tagged"] = commit("feat: export data\n\nFeature-Id: feat-001\nSuperRalph-Status: passing\n")
+
+	write("shared.go", "package shared\n")
+	hashes["shared"] = commit("feat: shared code\n\nFeature-Id: feat-001\nFeature-Id: feat-002\n")
+
+	write("import.go", "package importer\n")
+	pass("feat-002")
+	require.NoError(t, writer.Append(progress.Entry{Timestamp: time.Now(), Iteration: 2}))
+	hashes["other"] = commit("feat: import data\n\nFeature-Id: feat-002\nSuperRalph-Status: passing\n")
+	return dir, hashes
+}
+
+func TestNew(t *testing.T) {
+	dir, hashes := setupRepo(t)
+
+	plan, err := New(dir, "feat-001", Options{Reason: "exports are truncated"})
+	require.NoError(t, err)
+	require.Len(t, plan.Reopen, 1)
+	assert.Empty(t, plan.Revert, "commits are only found when reverting")
+
+	plan, err = New(dir, "feat-001", Options{Dependents: true, Revert: true})
+	require.NoError(t, err)
+	var reopen []string
+	for _, f := range plan.Reopen {
+		reopen = append(reopen, f.ID)
+	}
+	assert.Equal(t, []string{"feat-001", "feat-002", "feat-003"}, reopen, "transitive dependents")
+	require.Len(t, plan.Revert, 2)
+	assert.Equal(t, hashes["tagged"], plan.Revert[0].Hash, "newest first")
+	assert.Equal(t, hashes["untagged"], plan.Revert[1].Hash, "found through progress.txt")
+	require.Len(t, plan.Shared, 1)
+	assert.Equal(t, hashes["shared"], plan.Shared[0].Hash)
+
+	note := plan.Note()
+	assert.Contains(t, note.Lines, `Reopened feat-002 "Import data", which depends on feat-001`)
+	assert.Contains(t, note.Lines, "Not reverted "+hashes["shared"][:7]+": feat: shared code (also implements feat-002)")
+	assert.Contains(t, note.Lines, "Reason: no reason given")
+
+	_, err = New(dir, "feat-404", Options{})
+	assert.ErrorContains(t, err, "feat-404 not found")
+}
+
+func TestApply(t *testing.T) {
+	dir, hashes := setupRepo(t)
+
+	plan, err := New(dir, "feat-001", Options{Dependents: true, Revert: true, Reason: "exports are truncated"})
+	require.NoError(t, err)
+	hash, err := Apply(dir, plan, git.CommitOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, hash)
+
+	assert.NoFileExists(t, filepath.Join(dir, "export.go"))
+	assert.NoFileExists(t, filepath.Join(dir, "export_test.go"))
+	assert.FileExists(t, filepath.Join(dir, "shared.go"), "shared commits are kept")
+	assert.FileExists(t, filepath.Join(dir, "import.go"))
+
+	p, err := prd.LoadFromDir(dir)
+	require.NoError(t, err)
+	for _, id := range []string{"feat-001", "feat-002", "feat-003"} {
+		assert.False(t, p.GetFeature(id).Passes, id)
+	}
+	assert.True(t, p.GetFeature("feat-004").Passes, "unrelated features are untouched")
+
+	content, err := progress.Read(progress.GetPath(dir))
+	require.NoError(t, err)
+	entries := progress.ParseEntries(content)
+	require.Len(t, entries, 3, "progress.txt keeps its entries")
+	assert.Equal(t, "Reset", entries[2].Note)
+	assert.Contains(t, entries[2].Text, "Reverted "+hashes["tagged"][:7])
+	assert.Contains(t, entries[2].Text, "feat-001 was reset and needs to be implemented again: exports are truncated")
+
+	commits, err := git.Log(dir, git.LogOptions{Max: 1})
+	require.NoError(t, err)
+	assert.Equal(t, hash, commits[0].Hash)
+	assert.Equal(t, commitmsg.StatusReset, commits[0].Trailer(commitmsg.TrailerStatus))
+	assert.Contains(t, commits[0].Body, "This reverts commit "+hashes["untagged"]+".")
+	changed, err := git.HasTrackedChanges(dir)
+	require.NoError(t, err)
+	assert.False(t, changed, "everything is committed")
+
+	again, err := New(dir, "feat-001", Options{Revert: true})
+	require.NoError(t, err)
+	assert.Empty(t, again.Revert, "reverted commits aren't reverted twice")
+}
+
+func TestApplyWithoutRevert(t *testing.T) {
+	dir, _ := setupRepo(t)
+
+	plan, err := New(dir, "feat-001", Options{Reason: "flaky"})
+	require.NoError(t, err)
+	hash, err := Apply(dir, plan, git.CommitOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, hash, "nothing is committed")
+
+	p, err := prd.LoadFromDir(dir)
+	require.NoError(t, err)
+	assert.False(t, p.GetFeature("feat-001").Passes)
+	assert.True(t, p.GetFeature("feat-002").Passes, "dependents only when asked")
+	assert.FileExists(t, filepath.Join(dir, "export.go"))
+}
+
+func TestApplyConflict(t *testing.T) {
+	dir, _ := setupRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "export_test.go"), []byte("package export\n\nfunc TestExport() {}\n"), 0644))
+	_, err := git.CommitAll(dir, "test: cover export", git.CommitOptions{})
+	require.NoError(t, err)
+
+	plan, err := New(dir, "feat-001", Options{Revert: true})
+	require.NoError(t, err)
+	_, err = Apply(dir, plan, git.CommitOptions{})
+	assert.ErrorContains(t, err, "conflicts in export_test.go")
+
+	p, err := prd.LoadFromDir(dir)
+	require.NoError(t, err)
+	assert.True(t, p.GetFeature("feat-001").Passes, "nothing changes")
+	assert.FileExists(t, filepath.Join(dir, "export.go"))
+	changed, err := git.HasTrackedChanges(dir)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "export.go"), []byte("package changed\n"), 0644))
+	_, err = Apply(dir, plan, git.CommitOptions{})
+	assert.ErrorContains(t, err, "commit or stash")
+}
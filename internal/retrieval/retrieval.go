@@ -0,0 +1,320 @@
+// Package retrieval ranks repository files by relevance to a free-text query.
+//
+// The index is a BM25 model over the identifiers, comments and path segments of
+// every text file in the working directory. Documents are cached between queries
+// and only re-tokenized when their modification time or size changes, so
+// repeated queries across build iterations stay cheap.
+package retrieval
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// pathBoost is how many times path tokens are counted relative to content tokens.
+	// File and directory names are strong relevance signals.
+	pathBoost = 3
+)
+
+// DefaultMaxFileSize is the largest file that will be indexed (larger files are skipped)
+const DefaultMaxFileSize = 256 * 1024
+
+// Lister returns the relative paths of candidate files. Directory entries
+// (with a trailing slash) are ignored.
+type Lister func() ([]string, error)
+
+// Result is a single ranked file
+type Result struct {
+	Path  string  // Path relative to the working directory
+	Score float64 // BM25 score (higher is more relevant)
+	Size  int64   // File size in bytes
+}
+
+// document holds the cached term statistics for a single file
+type document struct {
+	modTime time.Time
+	size    int64
+	terms   map[string]int
+	length  int
+}
+
+// Index is a cached BM25 index over repository files
+type Index struct {
+	workDir     string
+	lister      Lister
+	maxFileSize int64
+
+	mu   sync.Mutex
+	docs map[string]*document
+}
+
+// New creates an index for workDir using lister to enumerate candidate files
+func New(workDir string, lister Lister) *Index {
+	return &Index{
+		workDir:     workDir,
+		lister:      lister,
+		maxFileSize: DefaultMaxFileSize,
+		docs:        make(map[string]*document),
+	}
+}
+
+// SetMaxFileSize sets the largest file size that will be indexed
+func (idx *Index) SetMaxFileSize(size int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.maxFileSize = size
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return len(idx.docs)
+}
+
+// Refresh brings the index up to date with the working directory.
+// Files whose modification time and size are unchanged keep their cached terms;
+// new or modified files are re-tokenized and deleted files are dropped.
+func (idx *Index) Refresh() error {
+	paths, err := idx.lister()
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	seen := make(map[string]bool, len(paths))
+	for _, relPath := range paths {
+		if strings.HasSuffix(relPath, "/") {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(idx.workDir, relPath))
+		if err != nil || info.IsDir() || info.Size() > idx.maxFileSize {
+			continue
+		}
+		seen[relPath] = true
+
+		if doc, ok := idx.docs[relPath]; ok && doc.modTime.Equal(info.ModTime()) && doc.size == info.Size() {
+			continue // Cached entry is still fresh
+		}
+
+		doc, ok := idx.indexFile(relPath, info)
+		if !ok {
+			delete(idx.docs, relPath)
+			delete(seen, relPath)
+			continue
+		}
+		idx.docs[relPath] = doc
+	}
+
+	for relPath := range idx.docs {
+		if !seen[relPath] {
+			delete(idx.docs, relPath)
+		}
+	}
+
+	return nil
+}
+
+// indexFile tokenizes a single file. Returns false for unreadable or binary files.
+func (idx *Index) indexFile(relPath string, info os.FileInfo) (*document, bool) {
+	content, err := os.ReadFile(filepath.Join(idx.workDir, relPath))
+	if err != nil || isBinary(content) {
+		return nil, false
+	}
+
+	terms := make(map[string]int)
+	length := 0
+	for _, tok := range Tokenize(string(content)) {
+		terms[tok]++
+		length++
+	}
+	for _, tok := range Tokenize(relPath) {
+		terms[tok] += pathBoost
+		length += pathBoost
+	}
+
+	return &document{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		terms:   terms,
+		length:  length,
+	}, true
+}
+
+// Query refreshes the index and returns up to k files ranked by relevance to text.
+// Files with no matching terms are never returned.
+func (idx *Index) Query(text string, k int) ([]Result, error) {
+	if err := idx.Refresh(); err != nil {
+		return nil, err
+	}
+
+	queryTerms := uniqueTerms(Tokenize(text))
+	if len(queryTerms) == 0 || k <= 0 {
+		return nil, nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	n := len(idx.docs)
+	if n == 0 {
+		return nil, nil
+	}
+
+	totalLen := 0
+	docFreq := make(map[string]int, len(queryTerms))
+	for _, doc := range idx.docs {
+		totalLen += doc.length
+		for _, term := range queryTerms {
+			if doc.terms[term] > 0 {
+				docFreq[term]++
+			}
+		}
+	}
+	avgLen := float64(totalLen) / float64(n)
+
+	var results []Result
+	for path, doc := range idx.docs {
+		score := 0.0
+		for _, term := range queryTerms {
+			tf := float64(doc.terms[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(docFreq[term])
+			idf := math.Log(1 + (float64(n)-df+0.5)/(df+0.5))
+			norm := tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLen)
+			score += idf * tf * (bm25K1 + 1) / norm
+		}
+		if score > 0 {
+			results = append(results, Result{Path: path, Score: score, Size: doc.size})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Tokenize splits text into lowercase search terms.
+// Identifiers are split on camelCase and snake_case boundaries, and both the
+// parts and the joined identifier are kept, so "parseTagString" yields
+// "parsetagstring", "parse", "tag" and "string". Stop words and single
+// characters are dropped.
+func Tokenize(text string) []string {
+	var tokens []string
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	for _, word := range words {
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			if joined := strings.ToLower(strings.ReplaceAll(word, "_", "")); keepToken(joined) {
+				tokens = append(tokens, joined)
+			}
+		}
+		for _, part := range parts {
+			part = strings.ToLower(part)
+			if keepToken(part) {
+				tokens = append(tokens, part)
+			}
+		}
+	}
+
+	return tokens
+}
+
+// splitIdentifier splits an identifier on underscores and case transitions
+func splitIdentifier(word string) []string {
+	var parts []string
+	for _, segment := range strings.Split(word, "_") {
+		if segment == "" {
+			continue
+		}
+		runes := []rune(segment)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+				unicode.IsLetter(prev) && unicode.IsDigit(cur) ||
+				unicode.IsDigit(prev) && unicode.IsLetter(cur) ||
+				// "HTTPServer" -> "HTTP", "Server"
+				i+1 < len(runes) && unicode.IsUpper(prev) && unicode.IsUpper(cur) && unicode.IsLower(runes[i+1])
+			if boundary {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		parts = append(parts, string(runes[start:]))
+	}
+	return parts
+}
+
+// keepToken reports whether a lowercase token is worth indexing
+func keepToken(tok string) bool {
+	if len(tok) < 2 || stopWords[tok] {
+		return false
+	}
+	// Pure numbers carry little meaning
+	return strings.IndexFunc(tok, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0
+}
+
+// stopWords are common English words and language keywords that appear
+// almost everywhere and would only add noise to the ranking
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "has": true, "if": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "that": true, "the": true, "this": true,
+	"to": true, "was": true, "with": true, "should": true, "can": true, "will": true,
+	"func": true, "return": true, "var": true, "const": true, "package": true, "import": true,
+	"type": true, "struct": true, "else": true, "nil": true, "err": true, "true": true,
+	"false": true, "def": true, "self": true, "let": true, "function": true, "export": true,
+	"new": true, "null": true, "none": true,
+}
+
+// uniqueTerms de-duplicates query terms while keeping their order
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	var result []string
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// isBinary uses the same heuristic as git: a NUL byte in the first 8KB
+func isBinary(content []byte) bool {
+	head := content
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0
+}
@@ -0,0 +1,174 @@
+package retrieval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates files in dir and returns a lister over them
+func writeFiles(t *testing.T, dir string, files map[string]string) Lister {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	return func() ([]string, error) {
+		var paths []string
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(dir, path)
+			if info.IsDir() {
+				if rel != "." {
+					paths = append(paths, rel+"/")
+				}
+				return nil
+			}
+			paths = append(paths, rel)
+			return nil
+		})
+		return paths, err
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("parseTagString(input) // HTTPServer handles user_login")
+
+	assert.Contains(t, tokens, "parsetagstring")
+	assert.Contains(t, tokens, "parse")
+	assert.Contains(t, tokens, "tag")
+	assert.Contains(t, tokens, "string")
+	assert.Contains(t, tokens, "input")
+	assert.Contains(t, tokens, "http")
+	assert.Contains(t, tokens, "server")
+	assert.Contains(t, tokens, "userlogin")
+	assert.Contains(t, tokens, "user")
+	assert.Contains(t, tokens, "login")
+}
+
+func TestTokenizeDropsStopWordsAndNumbers(t *testing.T) {
+	tokens := Tokenize("the func returns 42 and a value")
+
+	assert.NotContains(t, tokens, "the")
+	assert.NotContains(t, tokens, "func")
+	assert.NotContains(t, tokens, "42")
+	assert.NotContains(t, tokens, "a")
+	assert.Contains(t, tokens, "returns")
+	assert.Contains(t, tokens, "value")
+}
+
+func TestQueryRanksRelevantFilesFirst(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{
+		"auth/login.go":    "package auth\n\n// Login authenticates a user with a password\nfunc Login(user, password string) error { return nil }",
+		"billing/plans.go": "package billing\n\n// Plan describes a subscription plan\ntype Plan struct{ Price int }",
+		"README.md":        "# Project\n\nA small service.",
+	})
+
+	idx := New(dir, lister)
+	results, err := idx.Query("User can log in with email and password", 3)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+
+	assert.Equal(t, filepath.Join("auth", "login.go"), results[0].Path)
+	for _, r := range results {
+		assert.NotEqual(t, filepath.Join("billing", "plans.go"), r.Path, "unrelated files should not match")
+	}
+}
+
+func TestQueryUsesPathTokens(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{
+		"internal/notify/notify.go": "package x\n\nfunc Send() {}",
+		"internal/other/other.go":   "package y\n\nfunc Send() {}",
+	})
+
+	idx := New(dir, lister)
+	results, err := idx.Query("Send a notify message when the build completes", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, filepath.Join("internal", "notify", "notify.go"), results[0].Path)
+}
+
+func TestQueryLimit(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{
+		"a.go": "widget",
+		"b.go": "widget",
+		"c.go": "widget",
+	})
+
+	idx := New(dir, lister)
+	results, err := idx.Query("widget", 2)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = idx.Query("widget", 0)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestQueryNoMatches(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{"a.go": "widget"})
+
+	idx := New(dir, lister)
+	results, err := idx.Query("gadget", 5)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestRefreshInvalidatesByModTime(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{"a.go": "widget"})
+
+	idx := New(dir, lister)
+	results, err := idx.Query("gadget", 5)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Rewrite with new content and a later mtime
+	path := filepath.Join(dir, "a.go")
+	require.NoError(t, os.WriteFile(path, []byte("gadget"), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	results, err = idx.Query("gadget", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "a.go", results[0].Path)
+}
+
+func TestRefreshDropsDeletedFiles(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{"a.go": "widget", "b.go": "widget"})
+
+	idx := New(dir, lister)
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, 2, idx.Len())
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.go")))
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, 1, idx.Len())
+}
+
+func TestRefreshSkipsBinaryAndLargeFiles(t *testing.T) {
+	dir := t.TempDir()
+	lister := writeFiles(t, dir, map[string]string{
+		"text.go":  "widget",
+		"blob.bin": "widget\x00\x01\x02",
+		"big.txt":  "widget widget widget widget",
+	})
+
+	idx := New(dir, lister)
+	idx.SetMaxFileSize(20)
+	require.NoError(t, idx.Refresh())
+	assert.Equal(t, 1, idx.Len())
+}
@@ -0,0 +1,207 @@
+// Package runs records what happened in each build run: the iterations, the
+// features they worked on, their time and cost, and their gate results.
+package runs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/publish"
+)
+
+// Dir holds one file per run, relative to the project directory
+const Dir = ".superralph/runs"
+
+// Outcomes of a run
+const (
+	OutcomeRunning       = "running"
+	OutcomeComplete      = "complete"       // Every feature passes
+	OutcomeMaxIterations = "max-iterations" // Stopped at the iteration limit
+	OutcomeInterrupted   = "interrupted"
+	OutcomeFailed        = "failed"
+)
+
+// Run is a build run, identified by its session ID
+type Run struct {
+	ID         string       `json:"id"`
+	Started    time.Time    `json:"started"`
+	Finished   time.Time    `json:"finished,omitempty"`
+	Outcome    string       `json:"outcome"`
+	Error      string       `json:"error,omitempty"`
+	BaseCommit string       `json:"base_commit,omitempty"` // HEAD when the run started
+	HeadCommit string       `json:"head_commit,omitempty"` // HEAD when the run finished
+	Branch     string       `json:"branch,omitempty"`      // Branch the run started on
+	Iterations []*Iteration `json:"iterations"`
+}
+
+// Iteration is one agent session within a run
+type Iteration struct {
+	Number     int            `json:"number"`
+	FeatureID  string         `json:"feature_id"`
+	Started    time.Time      `json:"started"`
+	Duration   time.Duration  `json:"duration"`
+	CostUSD    float64        `json:"cost_usd"`
+	Gates      []publish.Gate `json:"gates,omitempty"`
+	Accepted   []string       `json:"accepted,omitempty"` // Features that passed this iteration
+	Rejected   bool           `json:"rejected"`           // Failed a gate, so the work wasn't kept
+	BaseCommit string         `json:"base_commit,omitempty"`
+	HeadCommit string         `json:"head_commit,omitempty"`
+}
+
+// New starts a run
+func New(id string) *Run {
+	return &Run{ID: id, Started: time.Now().UTC(), Outcome: OutcomeRunning}
+}
+
+// FeatureSummary totals a run's iterations on one feature
+type FeatureSummary struct {
+	FeatureID  string
+	Iterations int
+	Duration   time.Duration
+	CostUSD    float64
+	Passed     bool // Accepted during the run
+	LastGates  []publish.Gate
+}
+
+// Features totals the iterations per feature, in the order work started
+func (r *Run) Features() []*FeatureSummary {
+	var summaries []*FeatureSummary
+	byID := make(map[string]*FeatureSummary)
+	add := func(id string) *FeatureSummary {
+		s, ok := byID[id]
+		if !ok {
+			s = &FeatureSummary{FeatureID: id}
+			byID[id] = s
+			summaries = append(summaries, s)
+		}
+		return s
+	}
+	for _, it := range r.Iterations {
+		s := add(it.FeatureID)
+		s.Iterations++
+		s.Duration += it.Duration
+		s.CostUSD += it.CostUSD
+		if len(it.Gates) > 0 {
+			s.LastGates = it.Gates
+		}
+		for _, id := range it.Accepted {
+			add(id).Passed = true
+		}
+	}
+	return summaries
+}
+
+// Duration returns how long the run took, or has taken so far
+func (r *Run) Duration() time.Duration {
+	if r.Finished.IsZero() {
+		var d time.Duration
+		for _, it := range r.Iterations {
+			d += it.Duration
+		}
+		return d
+	}
+	return r.Finished.Sub(r.Started)
+}
+
+// CostUSD totals the cost of the run's iterations
+func (r *Run) CostUSD() float64 {
+	var cost float64
+	for _, it := range r.Iterations {
+		cost += it.CostUSD
+	}
+	return cost
+}
+
+// Path returns the file a run is saved to
+func Path(dir, id string) string {
+	return filepath.Join(dir, Dir, id+".json")
+}
+
+// Save writes the run to the project's run directory
+func (r *Run) Save(dir string) error {
+	path := Path(dir, r.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create runs directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	return nil
+}
+
+// Load reads a run by ID. A unique prefix of the ID is enough.
+func Load(dir, id string) (*Run, error) {
+	if data, err := os.ReadFile(Path(dir, id)); err == nil {
+		return parse(data)
+	}
+
+	all, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*Run
+	for _, r := range all {
+		if strings.HasPrefix(r.ID, id) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("run %s not found", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("run ID %s is ambiguous (%d runs match)", id, len(matches))
+	}
+}
+
+// List returns every recorded run, oldest first
+func List(dir string) ([]*Run, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, Dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	var all []*Run
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read run: %w", err)
+		}
+		r, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		all = append(all, r)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Started.Before(all[j].Started) })
+	return all, nil
+}
+
+// Latest returns the most recently started run
+func Latest(dir string) (*Run, error) {
+	all, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("no build runs recorded yet")
+	}
+	return all[len(all)-1], nil
+}
+
+func parse(data []byte) (*Run, error) {
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse run: %w", err)
+	}
+	return &r, nil
+}
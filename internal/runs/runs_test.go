@@ -0,0 +1,76 @@
+package runs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/publish"
+)
+
+func TestRunFeatures(t *testing.T) {
+	r := New("run-1")
+	r.Iterations = []*Iteration{
+		{Number: 1, FeatureID: "feat-001", Duration: time.Minute, CostUSD: 0.5,
+			Gates: []publish.Gate{{Name: "Tests", Passed: false}}, Rejected: true},
+		{Number: 2, FeatureID: "feat-002", Duration: 2 * time.Minute, CostUSD: 0.25},
+		{Number: 3, FeatureID: "feat-001", Duration: 3 * time.Minute, CostUSD: 1,
+			Gates: []publish.Gate{{Name: "Tests", Passed: true}}, Accepted: []string{"feat-001"}},
+	}
+
+	features := r.Features()
+	require.Len(t, features, 2)
+	assert.Equal(t, "feat-001", features[0].FeatureID)
+	assert.Equal(t, 2, features[0].Iterations)
+	assert.Equal(t, 4*time.Minute, features[0].Duration)
+	assert.InDelta(t, 1.5, features[0].CostUSD, 0.001)
+	assert.True(t, features[0].Passed)
+	assert.True(t, features[0].LastGates[0].Passed, "gates of the latest iteration")
+	assert.False(t, features[1].Passed)
+
+	assert.InDelta(t, 1.75, r.CostUSD(), 0.001)
+	assert.Equal(t, 6*time.Minute, r.Duration(), "unfinished runs total their iterations")
+	r.Finished = r.Started.Add(time.Hour)
+	assert.Equal(t, time.Hour, r.Duration())
+}
+
+func TestSaveLoadList(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Latest(dir)
+	assert.Error(t, err, "no runs yet")
+
+	older := New("20260301-aaaa")
+	older.Started = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	newer := New("20260302-bbbb")
+	newer.Started = older.Started.Add(24 * time.Hour)
+	newer.Iterations = []*Iteration{{Number: 1, FeatureID: "feat-001", CostUSD: 0.1}}
+	require.NoError(t, newer.Save(dir))
+	require.NoError(t, older.Save(dir))
+
+	all, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "20260301-aaaa", all[0].ID, "oldest first")
+
+	latest, err := Latest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "20260302-bbbb", latest.ID)
+	require.Len(t, latest.Iterations, 1)
+	assert.Equal(t, "feat-001", latest.Iterations[0].FeatureID)
+
+	loaded, err := Load(dir, "20260301-aaaa")
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeRunning, loaded.Outcome)
+
+	loaded, err = Load(dir, "20260302")
+	require.NoError(t, err, "unique prefix")
+	assert.Equal(t, "20260302-bbbb", loaded.ID)
+
+	_, err = Load(dir, "2026")
+	assert.ErrorContains(t, err, "ambiguous")
+	_, err = Load(dir, "missing")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/mpjhorner/superralph/internal/fileset"
 	"github.com/samber/lo"
 )
 
@@ -26,32 +27,26 @@ type FileTag struct {
 
 // Tagger handles file tagging operations
 type Tagger struct {
-	workDir     string
-	excludeDirs []string // Directories to always exclude (e.g., .git, node_modules)
+	workDir string
+	files   *fileset.Enumerator // Shared, ignore-aware file listing
 }
 
 // New creates a new Tagger for the given working directory
 func New(workDir string) *Tagger {
 	return &Tagger{
 		workDir: workDir,
-		excludeDirs: []string{
-			".git",
-			"node_modules",
-			"vendor",
-			"__pycache__",
-			".venv",
-			"venv",
-			"target",
-			"build",
-			"dist",
-			".superralph",
-		},
+		files:   fileset.New(workDir),
 	}
 }
 
 // SetExcludeDirs sets the directories to always exclude
 func (t *Tagger) SetExcludeDirs(dirs []string) {
-	t.excludeDirs = dirs
+	t.files.SetExcludeDirs(dirs)
+}
+
+// Enumerator returns the file enumerator used to resolve tags
+func (t *Tagger) Enumerator() *fileset.Enumerator {
+	return t.files
 }
 
 // ParseTag parses a tag string and returns a FileTag
@@ -112,7 +107,7 @@ func (t *Tagger) resolvePattern(pattern string) ([]string, error) {
 
 		if info.IsDir() {
 			// If it's a directory, return all files in it (non-recursive)
-			return t.filesInDir(fullPath, false)
+			return t.filesInDir(fullPath)
 		}
 
 		return []string{fullPath}, nil
@@ -122,98 +117,88 @@ func (t *Tagger) resolvePattern(pattern string) ([]string, error) {
 	return t.resolveGlob(pattern)
 }
 
-// resolveGlob resolves a glob pattern to matching files
+// resolveGlob resolves a glob pattern to matching files.
+// Patterns inside the working directory are matched against the project's
+// file list, so ignored files and excluded directories never match.
 func (t *Tagger) resolveGlob(pattern string) ([]string, error) {
-	// Make pattern absolute if it isn't
-	fullPattern := pattern
-	if !filepath.IsAbs(pattern) {
-		fullPattern = filepath.Join(t.workDir, pattern)
+	relPattern := pattern
+	if filepath.IsAbs(pattern) {
+		rel, err := filepath.Rel(t.workDir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		relPattern = rel
 	}
+	relPattern = filepath.Clean(relPattern)
 
-	// Use doublestar for ** support
-	matches, err := doublestar.FilepathGlob(fullPattern)
+	if relPattern == ".." || strings.HasPrefix(relPattern, ".."+string(filepath.Separator)) {
+		// Outside the working directory; glob the filesystem directly
+		return t.globOutside(filepath.Join(t.workDir, relPattern))
+	}
+
+	if !doublestar.ValidatePattern(filepath.ToSlash(relPattern)) {
+		return nil, doublestar.ErrBadPattern
+	}
+
+	files, err := t.files.Files()
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter out directories and excluded paths
 	var result []string
-	for _, match := range matches {
-		info, err := os.Stat(match)
-		if err != nil {
-			continue
+	for _, file := range files {
+		if matched, _ := doublestar.Match(filepath.ToSlash(relPattern), filepath.ToSlash(file)); matched {
+			result = append(result, filepath.Join(t.workDir, file))
 		}
-
-		if info.IsDir() {
-			continue
-		}
-
-		// Check if path contains excluded directory
-		if t.isExcluded(match) {
-			continue
-		}
-
-		result = append(result, match)
 	}
 
 	return result, nil
 }
 
-// filesInDir returns all files in a directory
-func (t *Tagger) filesInDir(dir string, recursive bool) ([]string, error) {
-	var files []string
+// globOutside resolves a glob pattern that points outside the working directory
+func (t *Tagger) globOutside(fullPattern string) ([]string, error) {
+	matches, err := doublestar.FilepathGlob(fullPattern)
+	if err != nil {
+		return nil, err
+	}
 
-	if recursive {
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
+	return lo.Filter(matches, func(match string, _ int) bool {
+		info, err := os.Stat(match)
+		return err == nil && !info.IsDir()
+	}), nil
+}
 
-			// Skip excluded directories
-			if info.IsDir() {
-				if t.isExcludedDir(info.Name()) {
-					return filepath.SkipDir
-				}
-				return nil
+// filesInDir returns the files directly inside a directory.
+// Directories inside the working directory are listed from the project's file
+// list, so ignored files are left out.
+func (t *Tagger) filesInDir(dir string) ([]string, error) {
+	relDir, err := filepath.Rel(t.workDir, dir)
+	if err != nil || relDir == ".." || strings.HasPrefix(relDir, ".."+string(filepath.Separator)) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		var files []string
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(dir, entry.Name()))
 			}
-
-			files = append(files, path)
-			return nil
-		})
-		return files, err
+		}
+		return files, nil
 	}
 
-	entries, err := os.ReadDir(dir)
+	files, err := t.files.Files()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	var result []string
+	for _, file := range files {
+		if filepath.Dir(file) == relDir {
+			result = append(result, filepath.Join(t.workDir, file))
 		}
-		files = append(files, filepath.Join(dir, entry.Name()))
 	}
-
-	return files, nil
-}
-
-// isExcluded checks if a path should be excluded
-func (t *Tagger) isExcluded(path string) bool {
-	relPath, err := filepath.Rel(t.workDir, path)
-	if err != nil {
-		return false
-	}
-
-	parts := strings.Split(relPath, string(filepath.Separator))
-	return lo.SomeBy(parts, func(part string) bool {
-		return t.isExcludedDir(part)
-	})
-}
-
-// isExcludedDir checks if a directory name should be excluded
-func (t *Tagger) isExcludedDir(name string) bool {
-	return lo.Contains(t.excludeDirs, name)
+	return result, nil
 }
 
 // LoadContents loads the contents of all resolved paths into the FileTag
@@ -304,55 +289,10 @@ func (t *Tagger) isExcludedByPatterns(path string, exclusions []string) bool {
 	})
 }
 
-// ListFiles returns a list of files in the working directory for autocomplete
-// Respects .gitignore if present, and always excludes default directories
+// ListFiles returns a list of files in the working directory for autocomplete.
+// Respects .gitignore and .superralphignore, and always excludes default directories.
 func (t *Tagger) ListFiles(maxDepth int) ([]string, error) {
-	var files []string
-
-	err := filepath.Walk(t.workDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip the work directory itself
-		if path == t.workDir {
-			return nil
-		}
-
-		relPath, err := filepath.Rel(t.workDir, path)
-		if err != nil {
-			return err
-		}
-
-		// Check depth
-		depth := strings.Count(relPath, string(filepath.Separator)) + 1
-		if depth > maxDepth {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip excluded directories
-		if info.IsDir() {
-			if t.isExcludedDir(info.Name()) {
-				return filepath.SkipDir
-			}
-			// Add directories with trailing slash
-			files = append(files, relPath+"/")
-			return nil
-		}
-
-		// Skip hidden files (except .gitignore)
-		if strings.HasPrefix(info.Name(), ".") && info.Name() != ".gitignore" {
-			return nil
-		}
-
-		files = append(files, relPath)
-		return nil
-	})
-
-	return files, err
+	return t.files.List(maxDepth)
 }
 
 // ParseTagString parses a string containing multiple tags separated by spaces or newlines
@@ -13,7 +13,7 @@ func TestNew(t *testing.T) {
 	tagger := New("/tmp/test")
 	require.NotNil(t, tagger)
 	assert.Equal(t, "/tmp/test", tagger.workDir)
-	assert.NotEmpty(t, tagger.excludeDirs)
+	assert.NotEmpty(t, tagger.Enumerator().ExcludeDirs())
 }
 
 func TestSetExcludeDirs(t *testing.T) {
@@ -21,7 +21,7 @@ func TestSetExcludeDirs(t *testing.T) {
 	customDirs := []string{"custom1", "custom2"}
 	tagger.SetExcludeDirs(customDirs)
 
-	assert.Len(t, tagger.excludeDirs, 2)
+	assert.Len(t, tagger.Enumerator().ExcludeDirs(), 2)
 }
 
 func TestParseTagExactFile(t *testing.T) {
@@ -342,26 +342,69 @@ func TestParseTagString(t *testing.T) {
 	}
 }
 
-func TestIsExcluded(t *testing.T) {
-	tagger := New("/tmp/workdir")
+func TestResolveGlobSkipsExcludedAndIgnored(t *testing.T) {
+	tmpDir := t.TempDir()
 
-	tests := []struct {
-		path     string
-		excluded bool
-	}{
-		{"/tmp/workdir/main.go", false},
-		{"/tmp/workdir/node_modules/dep/index.js", true},
-		{"/tmp/workdir/vendor/pkg/pkg.go", true},
-		{"/tmp/workdir/src/main.go", false},
-		{"/tmp/workdir/.git/config", true},
-		{"/tmp/workdir/__pycache__/module.pyc", true},
+	files := map[string]string{
+		".gitignore":                  "*.gen.go\n",
+		".superralphignore":           "testdata/\n",
+		"main.go":                     "package main",
+		"src/main.go":                 "package src",
+		"src/api.gen.go":              "package src",
+		"node_modules/dep/index.go":   "package dep",
+		"vendor/pkg/pkg.go":           "package pkg",
+		"__pycache__/module.go":       "package cache",
+		"testdata/fixture/fixture.go": "package fixture",
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.path, func(t *testing.T) {
-			assert.Equal(t, tt.excluded, tagger.isExcluded(tt.path))
-		})
+	for path, content := range files {
+		full := filepath.Join(tmpDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
 	}
+
+	tagger := New(tmpDir)
+	tag, err := tagger.ParseTag("@**/*.go")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		filepath.Join(tmpDir, "main.go"),
+		filepath.Join(tmpDir, "src", "main.go"),
+	}, tag.ResolvedPaths)
+}
+
+func TestParseTagDirectorySkipsIgnored(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	srcDir := filepath.Join(tmpDir, "src")
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("*.log\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package src"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "debug.log"), []byte("log"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "sub", "nested.go"), []byte("package sub"), 0644))
+
+	tagger := New(tmpDir)
+	tag, err := tagger.ParseTag("@src")
+	require.NoError(t, err)
+
+	// Directory tags are non-recursive and leave out ignored files
+	assert.Equal(t, []string{filepath.Join(srcDir, "main.go")}, tag.ResolvedPaths)
+}
+
+func TestListFilesRespectsGitignore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("out/\n*.log\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "debug.log"), []byte("log"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "out"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "out", "bundle.js"), []byte("bundle"), 0644))
+
+	files, err := New(tmpDir).ListFiles(3)
+	require.NoError(t, err)
+
+	assert.Contains(t, files, "main.go")
+	assert.NotContains(t, files, "debug.log")
+	assert.NotContains(t, files, "out/")
 }
 
 func TestFileTagSerialization(t *testing.T) {
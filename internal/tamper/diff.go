@@ -0,0 +1,54 @@
+package tamper
+
+import (
+	"strings"
+)
+
+// FileDiff is the change to one file in a unified diff
+type FileDiff struct {
+	Path    string   // Path after the change (before, for deleted files)
+	Deleted bool     // The file was deleted
+	Added   []string // Added lines, without the leading "+"
+	Removed []string // Removed lines, without the leading "-"
+}
+
+// ParseDiff splits a unified diff, as printed by `git diff`, into files
+func ParseDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var cur *FileDiff
+	inHunk := false // Header lines like "--- a/x" look like content outside hunks
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, FileDiff{})
+			cur = &files[len(files)-1]
+			inHunk = false
+			// "diff --git a/path b/path"; refined by the ---/+++ headers below
+			if _, b, ok := strings.Cut(line, " b/"); ok {
+				cur.Path = b
+			}
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			cur.Added = append(cur.Added, line[1:])
+		case inHunk && strings.HasPrefix(line, "-"):
+			cur.Removed = append(cur.Removed, line[1:])
+		case inHunk:
+			continue
+		case strings.HasPrefix(line, "deleted file mode"):
+			cur.Deleted = true
+		case strings.HasPrefix(line, "--- "):
+			if p := strings.TrimPrefix(line, "--- "); p != "/dev/null" && cur.Deleted {
+				cur.Path = strings.TrimPrefix(p, "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if p := strings.TrimPrefix(line, "+++ "); p != "/dev/null" {
+				cur.Path = strings.TrimPrefix(p, "b/")
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,61 @@
+package tamper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReviewFile lists iterations flagged for review, relative to the project directory
+const ReviewFile = ".superralph/review.json"
+
+// Review is an iteration whose changes weakened the tests
+type Review struct {
+	Iteration  int       `json:"iteration"`
+	FeatureID  string    `json:"feature_id"`
+	BaseCommit string    `json:"base_commit,omitempty"` // Commit the iteration started from
+	Findings   []string  `json:"findings"`
+	Rejected   bool      `json:"rejected"`
+	Recorded   time.Time `json:"recorded"`
+}
+
+// LoadReviews reads the flagged iterations for the project in dir, oldest first
+func LoadReviews(dir string) ([]Review, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ReviewFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read review list: %w", err)
+	}
+
+	var reviews []Review
+	if err := json.Unmarshal(data, &reviews); err != nil {
+		return nil, fmt.Errorf("failed to parse review list: %w", err)
+	}
+	return reviews, nil
+}
+
+// AppendReview adds a flagged iteration to the project's review list
+func AppendReview(dir string, r Review) error {
+	reviews, err := LoadReviews(dir)
+	if err != nil {
+		return err
+	}
+	reviews = append(reviews, r)
+
+	path := filepath.Join(dir, ReviewFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create .superralph directory: %w", err)
+	}
+	data, err := json.MarshalIndent(reviews, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal review list: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write review list: %w", err)
+	}
+	return nil
+}
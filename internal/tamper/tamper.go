@@ -0,0 +1,215 @@
+// Package tamper looks for ways an iteration weakened the test suite:
+// deleted test files and tests, fewer tests running, new skip or focus
+// markers, removed assertions, and edited expectations in test files that
+// have nothing to do with the feature being built.
+package tamper
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/mpjhorner/superralph/internal/testresult"
+)
+
+// Kind identifies a way of weakening tests
+type Kind string
+
+const (
+	KindDeletedFile        Kind = "deleted test file"
+	KindDeletedTest        Kind = "deleted test"
+	KindFewerTests         Kind = "fewer tests"
+	KindSkipAdded          Kind = "skip added"
+	KindFocusAdded         Kind = "focus added"
+	KindAssertionsRemoved  Kind = "assertions removed"
+	KindExpectationChanged Kind = "expectation changed"
+)
+
+// Finding is one suspicious change
+type Finding struct {
+	Kind   Kind
+	File   string // Empty for findings about the whole suite
+	Detail string
+}
+
+// String formats the finding, e.g. "skip added: parser_test.go: t.Skip("flaky")"
+func (f Finding) String() string {
+	if f.File == "" {
+		return fmt.Sprintf("%s: %s", f.Kind, f.Detail)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Kind, f.File, f.Detail)
+}
+
+// Policy decides what happens to an iteration with findings
+type Policy string
+
+const (
+	PolicyOff    Policy = "off"    // Don't check
+	PolicyWarn   Policy = "warn"   // Flag the iteration for review
+	PolicyReject Policy = "reject" // Reopen the feature and count a failed attempt
+)
+
+// ParsePolicy validates a policy name
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyOff, PolicyWarn, PolicyReject:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid tamper policy %q (must be one of: off, warn, reject)", s)
+}
+
+// Input is what Analyze looks at
+type Input struct {
+	Diff         string             // Unified diff of the iteration
+	RelatedFiles []string           // Files related to the feature; their expectations may change
+	TestsBefore  *testresult.Report // Test results before the iteration, if known
+	TestsAfter   *testresult.Report // Test results after the iteration, if known
+}
+
+var testFilePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`_test\.go$`),
+	regexp.MustCompile(`(^|/)test_[^/]*\.py$`),
+	regexp.MustCompile(`_test\.py$`),
+	regexp.MustCompile(`\.(test|spec)\.[cm]?[jt]sx?$`),
+	regexp.MustCompile(`(^|/)__tests__/`),
+	regexp.MustCompile(`_spec\.rb$`),
+	regexp.MustCompile(`(^|/)tests?/.*\.rs$`),
+	regexp.MustCompile(`(^|/)src/test/`),
+}
+
+// IsTestFile reports whether a path looks like a test file
+func IsTestFile(p string) bool {
+	for _, re := range testFilePatterns {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// testDecl matches test declarations; the first non-empty group is the name
+var testDecl = regexp.MustCompile(`^\s*(?:func\s+((?:Test|Fuzz)\w*)\s*\(|(?:async\s+)?def\s+(test_\w+)\s*\(|(?:it|test)(?:\.\w+)?\s*\(\s*['"` + "`" + `]([^'"` + "`" + `]+))`)
+
+// marker is a pattern that disables or narrows tests
+type marker struct {
+	kind Kind
+	re   *regexp.Regexp
+}
+
+var markers = []marker{
+	{KindSkipAdded, regexp.MustCompile(`\bt\.Skip(f|Now)?\(`)},
+	{KindSkipAdded, regexp.MustCompile(`\b(xit|xtest|xdescribe)\s*\(`)},
+	{KindSkipAdded, regexp.MustCompile(`\b(it|test|describe)\.(skip|todo)\s*\(`)},
+	{KindSkipAdded, regexp.MustCompile(`@pytest\.mark\.(skip|skipif|xfail)\b|\bpytest\.skip\(|@unittest\.skip`)},
+	{KindSkipAdded, regexp.MustCompile(`#\[ignore\]|@Disabled\b|@Ignore\b`)},
+	{KindFocusAdded, regexp.MustCompile(`\b(it|test|describe)\.only\s*\(|\b(fit|fdescribe)\s*\(`)},
+}
+
+var assertion = regexp.MustCompile(`\b(assert\w*|require\.\w+|expect\s*\(|self\.assert\w+|t\.(Error|Errorf|Fatal|Fatalf|Fail|FailNow)\b)`)
+
+// Analyze returns the ways the iteration weakened the tests
+func Analyze(in Input) []Finding {
+	var findings []Finding
+	files := ParseDiff(in.Diff)
+
+	// Directories with source changes; tests there belong to the change
+	changedDirs := make(map[string]bool)
+	addedNames := make(map[string]bool)
+	for _, f := range files {
+		if !IsTestFile(f.Path) {
+			changedDirs[path.Dir(f.Path)] = true
+		}
+		for _, line := range f.Added {
+			if name := testName(line); name != "" {
+				addedNames[name] = true
+			}
+		}
+	}
+	related := make(map[string]bool)
+	for _, p := range in.RelatedFiles {
+		related[strings.TrimPrefix(p, "./")] = true
+	}
+
+	for _, f := range files {
+		if !IsTestFile(f.Path) {
+			continue
+		}
+		if f.Deleted {
+			findings = append(findings, Finding{Kind: KindDeletedFile, File: f.Path, Detail: "file deleted"})
+			continue
+		}
+
+		// Tests removed without being re-added elsewhere (renames and moves are fine)
+		for _, line := range f.Removed {
+			if name := testName(line); name != "" && !addedNames[name] {
+				findings = append(findings, Finding{Kind: KindDeletedTest, File: f.Path, Detail: name})
+			}
+		}
+
+		// Markers added more often than removed
+		for _, m := range markers {
+			added := matching(f.Added, m.re)
+			if len(added) > count(f.Removed, m.re) {
+				findings = append(findings, Finding{Kind: m.kind, File: f.Path, Detail: strings.TrimSpace(added[len(added)-1])})
+			}
+		}
+
+		removed, addedAsserts := count(f.Removed, assertion), count(f.Added, assertion)
+		if removed > addedAsserts {
+			findings = append(findings, Finding{
+				Kind:   KindAssertionsRemoved,
+				File:   f.Path,
+				Detail: fmt.Sprintf("%d removed, %d added", removed, addedAsserts),
+			})
+		} else if removed > 0 && !related[f.Path] && !changedDirs[path.Dir(f.Path)] {
+			findings = append(findings, Finding{
+				Kind:   KindExpectationChanged,
+				File:   f.Path,
+				Detail: "assertions edited in a file unrelated to the feature",
+			})
+		}
+	}
+
+	if before, after := in.TestsBefore, in.TestsAfter; before != nil && after != nil && before.Format == after.Format {
+		ranBefore, ranAfter := before.Passed+before.Failed, after.Passed+after.Failed
+		if ranAfter < ranBefore {
+			findings = append(findings, Finding{
+				Kind:   KindFewerTests,
+				Detail: fmt.Sprintf("%d tests ran, down from %d", ranAfter, ranBefore),
+			})
+		}
+	}
+
+	return findings
+}
+
+// testName returns the name of the test declared on line, or ""
+func testName(line string) string {
+	m := testDecl.FindStringSubmatch(line)
+	if m == nil {
+		return ""
+	}
+	for _, group := range m[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// matching returns the lines that match re
+func matching(lines []string, re *regexp.Regexp) []string {
+	var out []string
+	for _, line := range lines {
+		if re.MatchString(line) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// count returns the number of lines that match re
+func count(lines []string, re *regexp.Regexp) int {
+	return len(matching(lines, re))
+}
@@ -0,0 +1,162 @@
+package tamper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/testresult"
+)
+
+const sampleDiff = `diff --git a/parser/parser.go b/parser/parser.go
+index 1111111..2222222 100644
+--- a/parser/parser.go
++++ b/parser/parser.go
+@@ -10 +10 @@ func Parse() {
+-	return nil
++	return errEmpty
+diff --git a/parser/parser_test.go b/parser/parser_test.go
+index 3333333..4444444 100644
+--- a/parser/parser_test.go
++++ b/parser/parser_test.go
+@@ -5,0 +6 @@ func TestParse(t *testing.T) {
++	t.Skip("flaky on CI")
+@@ -20,4 +21 @@ func TestParse(t *testing.T) {
+-func TestParseEmpty(t *testing.T) {
+-	assert.Error(t, Parse(""))
+-	assert.Equal(t, 1, 1)
+-}
++// removed
+diff --git a/legacy/legacy_test.py b/legacy/legacy_test.py
+deleted file mode 100644
+index 5555555..0000000
+--- a/legacy/legacy_test.py
++++ /dev/null
+@@ -1,2 +0,0 @@
+-def test_legacy():
+-    assert legacy() == 1
+diff --git a/store/store_test.go b/store/store_test.go
+index 6666666..7777777 100644
+--- a/store/store_test.go
++++ b/store/store_test.go
+@@ -30 +30 @@ func TestStore(t *testing.T) {
+-	assert.Equal(t, 3, count)
++	assert.Equal(t, 4, count)
+diff --git a/web/app.test.ts b/web/app.test.ts
+index 8888888..9999999 100644
+--- a/web/app.test.ts
++++ b/web/app.test.ts
+@@ -1 +1 @@
+-it('renders', () => {
++it.only('renders', () => {
+diff --git a/db/schema.sql b/db/schema.sql
+index aaaaaaa..bbbbbbb 100644
+--- a/db/schema.sql
++++ b/db/schema.sql
+@@ -1 +0,0 @@
+--- old comment
+`
+
+func TestParseDiff(t *testing.T) {
+	files := ParseDiff(sampleDiff)
+	require.Len(t, files, 6)
+
+	assert.Equal(t, "parser/parser.go", files[0].Path)
+	assert.Equal(t, []string{"\treturn errEmpty"}, files[0].Added)
+
+	assert.Equal(t, "legacy/legacy_test.py", files[2].Path)
+	assert.True(t, files[2].Deleted)
+
+	// A removed line starting with "--" isn't mistaken for a header
+	assert.Equal(t, "db/schema.sql", files[5].Path)
+	assert.Equal(t, []string{"-- old comment"}, files[5].Removed)
+}
+
+func TestIsTestFile(t *testing.T) {
+	for _, p := range []string{"a_test.go", "pkg/test_util.py", "x_test.py", "app.spec.tsx", "src/__tests__/a.js", "user_spec.rb", "tests/it.rs", "src/test/java/A.java"} {
+		assert.True(t, IsTestFile(p), p)
+	}
+	for _, p := range []string{"main.go", "testdata.go", "src/contest.py", "app.ts"} {
+		assert.False(t, IsTestFile(p), p)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	findings := Analyze(Input{Diff: sampleDiff})
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	assert.Equal(t, []string{
+		`deleted test: parser/parser_test.go: TestParseEmpty`,
+		`skip added: parser/parser_test.go: t.Skip("flaky on CI")`,
+		`assertions removed: parser/parser_test.go: 2 removed, 0 added`,
+		`deleted test file: legacy/legacy_test.py: file deleted`,
+		`expectation changed: store/store_test.go: assertions edited in a file unrelated to the feature`,
+		`focus added: web/app.test.ts: it.only('renders', () => {`,
+	}, got)
+}
+
+func TestAnalyzeRelatedAndRenamed(t *testing.T) {
+	diff := `diff --git a/store/store_test.go b/store/store_test.go
+--- a/store/store_test.go
++++ b/store/store_test.go
+@@ -30 +30 @@
+-	assert.Equal(t, 3, count)
++	assert.Equal(t, 4, count)
+@@ -40 +40 @@
+-func TestOldName(t *testing.T) {
++func TestNewName(t *testing.T) {
+diff --git a/store/moved_test.go b/store/moved_test.go
+--- a/store/moved_test.go
++++ b/store/moved_test.go
+@@ -1,0 +1 @@
++func TestOldName(t *testing.T) {
+`
+	// Declared as related to the feature: expectation edits are fine
+	findings := Analyze(Input{Diff: diff, RelatedFiles: []string{"store/store_test.go"}})
+	assert.Empty(t, findings, "moved tests and related edits aren't flagged")
+}
+
+func TestAnalyzeFewerTests(t *testing.T) {
+	before := &testresult.Report{Format: testresult.FormatGoJSON, Passed: 40, Failed: 2}
+	after := &testresult.Report{Format: testresult.FormatGoJSON, Passed: 38, Skipped: 3}
+
+	findings := Analyze(Input{TestsBefore: before, TestsAfter: after})
+	require.Len(t, findings, 1)
+	assert.Equal(t, "fewer tests: 38 tests ran, down from 42", findings[0].String())
+
+	// Different formats aren't comparable
+	after.Format = testresult.FormatJUnit
+	assert.Empty(t, Analyze(Input{TestsBefore: before, TestsAfter: after}))
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("reject")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyReject, p)
+
+	_, err = ParsePolicy("block")
+	assert.Error(t, err)
+}
+
+func TestReviews(t *testing.T) {
+	dir := t.TempDir()
+
+	reviews, err := LoadReviews(dir)
+	require.NoError(t, err)
+	assert.Empty(t, reviews)
+
+	r := Review{Iteration: 3, FeatureID: "feat-002", Findings: []string{"skip added: a_test.go: t.Skip()"}, Recorded: time.Now().UTC()}
+	require.NoError(t, AppendReview(dir, r))
+	require.NoError(t, AppendReview(dir, Review{Iteration: 4, FeatureID: "feat-002", Rejected: true}))
+
+	reviews, err = LoadReviews(dir)
+	require.NoError(t, err)
+	require.Len(t, reviews, 2)
+	assert.Equal(t, r.Findings, reviews[0].Findings)
+	assert.True(t, reviews[1].Rejected)
+}
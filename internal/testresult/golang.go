@@ -0,0 +1,277 @@
+package testresult
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// goEvent is a single line of `go test -json` output (see `go doc test2json`)
+type goEvent struct {
+	Action     string
+	Package    string
+	Test       string
+	Output     string
+	ImportPath string // Set on build-output and build-fail events
+}
+
+// goTestKey identifies a test within a package
+type goTestKey struct {
+	pkg  string
+	test string
+}
+
+func detectGoJSON(output string) bool {
+	return strings.Contains(output, `"Action":`)
+}
+
+// parseGoJSON parses `go test -json` output. Parent tests that only failed
+// because a subtest failed are not counted separately.
+func parseGoJSON(output string) *Report {
+	report := &Report{}
+	outputs := make(map[goTestKey][]string)
+	buildOutputs := make(map[string][]string)
+	failed := make(map[goTestKey]bool)
+	var failOrder []goTestKey
+	pkgFailed := make(map[string]bool)
+	var pkgOrder []string
+	seen := false
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var ev goEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil || ev.Action == "" {
+			continue
+		}
+		seen = true
+
+		key := goTestKey{pkg: ev.Package, test: ev.Test}
+		switch ev.Action {
+		case "output":
+			outputs[key] = append(outputs[key], strings.TrimRight(ev.Output, "\n"))
+		case "build-output":
+			// ImportPath names the test variant, e.g. "pkg [pkg.test]"
+			pkg, _, _ := strings.Cut(ev.ImportPath, " ")
+			buildOutputs[pkg] = append(buildOutputs[pkg], strings.TrimRight(ev.Output, "\n"))
+		case "pass":
+			if ev.Test != "" {
+				report.Passed++
+			}
+		case "skip":
+			if ev.Test != "" {
+				report.Skipped++
+			}
+		case "fail":
+			if ev.Test == "" {
+				if !pkgFailed[ev.Package] {
+					pkgFailed[ev.Package] = true
+					pkgOrder = append(pkgOrder, ev.Package)
+				}
+				continue
+			}
+			if !failed[key] {
+				failed[key] = true
+				failOrder = append(failOrder, key)
+			}
+		}
+	}
+	if !seen {
+		return nil
+	}
+
+	for _, key := range failOrder {
+		if hasFailedSubtest(key, failOrder) {
+			continue
+		}
+		report.Failed++
+		report.Failures = append(report.Failures, Failure{
+			Name:    key.test,
+			Package: key.pkg,
+			Excerpt: excerpt(goFailureLines(outputs[key])),
+		})
+	}
+
+	// Packages that failed without a failing test didn't build or panicked
+	for _, pkg := range pkgOrder {
+		if packageHasFailure(pkg, failOrder) {
+			continue
+		}
+		name, lines := "[build failed]", buildOutputs[pkg]
+		if len(lines) == 0 {
+			name, lines = "[package failed]", goFailureLines(outputs[goTestKey{pkg: pkg}])
+		}
+		report.Failed++
+		report.Failures = append(report.Failures, Failure{
+			Name:    name,
+			Package: pkg,
+			Excerpt: excerpt(lines),
+		})
+	}
+
+	return report
+}
+
+// hasFailedSubtest checks whether any failed test is a subtest of key
+func hasFailedSubtest(key goTestKey, failed []goTestKey) bool {
+	for _, other := range failed {
+		if other.pkg == key.pkg && strings.HasPrefix(other.test, key.test+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// packageHasFailure checks whether any failed test belongs to pkg
+func packageHasFailure(pkg string, failed []goTestKey) bool {
+	for _, key := range failed {
+		if key.pkg == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// goFailureLines drops the run/result markers from a test's output
+func goFailureLines(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") ||
+			trimmed == "FAIL" || trimmed == "PASS" || goPackageLine.MatchString(line) {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+var (
+	// goResultLine matches "--- FAIL: TestName (0.01s)", indented for subtests
+	goResultLine = regexp.MustCompile(`^(\s*)--- (PASS|FAIL|SKIP): (\S+)`)
+
+	// goRunLine matches "=== RUN   TestName"
+	goRunLine = regexp.MustCompile(`^=== (RUN|CONT|PAUSE|NAME)\s+(\S+)`)
+
+	// goPackageLine matches the per-package summary, e.g. "ok  \tpkg\t0.01s" or "FAIL\tpkg [build failed]"
+	goPackageLine = regexp.MustCompile(`^(ok|FAIL|\?)\s*\t(\S+)`)
+)
+
+func detectGo(output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if goResultLine.MatchString(line) || goPackageLine.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGo parses plain `go test` output. Passing tests are only listed with
+// -v, so without it passing packages are counted instead of passing tests.
+func parseGo(output string) *Report {
+	report := &Report{}
+	lines := strings.Split(output, "\n")
+
+	var pending []int // Indexes into report.Failures awaiting a package name
+	var okPackages int
+	var packageFailures []Failure
+	var loose []string // Output outside any test, such as compiler errors
+	runOutput := make(map[string][]string)
+	var current string // Test whose output is being streamed in -v mode
+	sawTests := false
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := goRunLine.FindStringSubmatch(line); m != nil {
+			current = m[2]
+			continue
+		}
+
+		if m := goResultLine.FindStringSubmatch(line); m != nil {
+			sawTests = true
+			indent, status, name := len(m[1]), m[2], m[3]
+			current = ""
+			switch status {
+			case "PASS":
+				report.Passed++
+			case "SKIP":
+				report.Skipped++
+			case "FAIL":
+				// Failure details follow the result line, indented further
+				var details []string
+				for i+1 < len(lines) {
+					next := lines[i+1]
+					nextIndent := len(next) - len(strings.TrimLeft(next, " \t"))
+					if strings.TrimSpace(next) != "" && (nextIndent <= indent || goResultLine.MatchString(next)) {
+						break
+					}
+					details = append(details, next)
+					i++
+				}
+				if strings.TrimSpace(strings.Join(details, "")) == "" {
+					details = runOutput[name] // -v mode streams output before the result
+				}
+				report.Failures = append(report.Failures, Failure{Name: name, Excerpt: excerpt(details)})
+				pending = append(pending, len(report.Failures)-1)
+			}
+			continue
+		}
+
+		if m := goPackageLine.FindStringSubmatch(line); m != nil {
+			pkg := m[2]
+			for _, idx := range pending {
+				report.Failures[idx].Package = pkg
+			}
+			switch m[1] {
+			case "ok":
+				okPackages++
+			case "FAIL":
+				// A failing package without failing tests didn't build or panicked
+				if len(pending) == 0 {
+					name := "[package failed]"
+					if strings.Contains(line, "[build failed]") {
+						name = "[build failed]"
+					}
+					packageFailures = append(packageFailures, Failure{Name: name, Package: pkg, Excerpt: excerpt(loose)})
+				}
+			}
+			pending = nil
+			loose = nil
+			current = ""
+			continue
+		}
+
+		if current != "" {
+			runOutput[current] = append(runOutput[current], line)
+		} else if trimmed := strings.TrimSpace(line); trimmed != "FAIL" && trimmed != "PASS" {
+			loose = append(loose, line)
+		}
+	}
+
+	// Drop parents whose failure is explained by a failing subtest
+	var failures []Failure
+	for _, f := range report.Failures {
+		parent := false
+		for _, other := range report.Failures {
+			if other.Package == f.Package && strings.HasPrefix(other.Name, f.Name+"/") {
+				parent = true
+				break
+			}
+		}
+		if !parent {
+			failures = append(failures, f)
+		}
+	}
+	failures = append(failures, packageFailures...)
+	report.Failures = failures
+	report.Failed = len(failures)
+
+	if !sawTests {
+		report.Passed = okPackages
+	}
+
+	return report
+}
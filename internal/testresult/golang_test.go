@@ -0,0 +1,155 @@
+package testresult
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixtures were captured from a package with passing, skipped, failing and
+// nested failing tests, plus a package that doesn't compile.
+
+const goPlainOutput = `--- FAIL: TestFail (0.00s)
+    a_test.go:8: some context
+    a_test.go:9: expected 1, got 2
+--- FAIL: TestParent (0.00s)
+    --- FAIL: TestParent/bad_case (0.00s)
+        a_test.go:13: boom
+FAIL
+FAIL	example.com/gotest	0.002s
+# example.com/gotest/sub [example.com/gotest/sub.test]
+sub/b_test.go:5:28: undefined: undefined
+FAIL	example.com/gotest/sub [build failed]
+FAIL
+`
+
+const goVerboseOutput = `=== RUN   TestPass
+--- PASS: TestPass (0.00s)
+=== RUN   TestSkip
+    a_test.go:6: not yet
+--- SKIP: TestSkip (0.00s)
+=== RUN   TestFail
+    a_test.go:8: some context
+    a_test.go:9: expected 1, got 2
+--- FAIL: TestFail (0.00s)
+=== RUN   TestParent
+=== RUN   TestParent/ok
+=== RUN   TestParent/bad_case
+    a_test.go:13: boom
+--- FAIL: TestParent (0.00s)
+    --- PASS: TestParent/ok (0.00s)
+    --- FAIL: TestParent/bad_case (0.00s)
+FAIL
+FAIL	example.com/gotest	0.002s
+# example.com/gotest/sub [example.com/gotest/sub.test]
+sub/b_test.go:5:28: undefined: undefined
+FAIL	example.com/gotest/sub [build failed]
+FAIL
+`
+
+const goJSONOutput = `{"Time":"2026-10-18T11:36:18.563864963Z","Action":"start","Package":"example.com/gotest"}
+{"Time":"2026-10-18T11:36:18.565888939Z","Action":"run","Package":"example.com/gotest","Test":"TestPass"}
+{"Time":"2026-10-18T11:36:18.565980503Z","Action":"output","Package":"example.com/gotest","Test":"TestPass","Output":"=== RUN   TestPass\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566013357Z","Action":"output","Package":"example.com/gotest","Test":"TestPass","Output":"--- PASS: TestPass (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.56601779Z","Action":"pass","Package":"example.com/gotest","Test":"TestPass","Elapsed":0}
+{"Time":"2026-10-18T11:36:18.566024768Z","Action":"run","Package":"example.com/gotest","Test":"TestSkip"}
+{"Time":"2026-10-18T11:36:18.566027117Z","Action":"output","Package":"example.com/gotest","Test":"TestSkip","Output":"=== RUN   TestSkip\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.5660385Z","Action":"output","Package":"example.com/gotest","Test":"TestSkip","Output":"    a_test.go:6: not yet\n"}
+{"Time":"2026-10-18T11:36:18.566044414Z","Action":"output","Package":"example.com/gotest","Test":"TestSkip","Output":"--- SKIP: TestSkip (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566047675Z","Action":"skip","Package":"example.com/gotest","Test":"TestSkip","Elapsed":0}
+{"Time":"2026-10-18T11:36:18.566051065Z","Action":"run","Package":"example.com/gotest","Test":"TestFail"}
+{"Time":"2026-10-18T11:36:18.566053784Z","Action":"output","Package":"example.com/gotest","Test":"TestFail","Output":"=== RUN   TestFail\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566056808Z","Action":"output","Package":"example.com/gotest","Test":"TestFail","Output":"    a_test.go:8: some context\n"}
+{"Time":"2026-10-18T11:36:18.566061345Z","Action":"output","Package":"example.com/gotest","Test":"TestFail","Output":"    a_test.go:9: expected 1, got 2\n","OutputType":"error"}
+{"Time":"2026-10-18T11:36:18.566066084Z","Action":"output","Package":"example.com/gotest","Test":"TestFail","Output":"--- FAIL: TestFail (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566069121Z","Action":"fail","Package":"example.com/gotest","Test":"TestFail","Elapsed":0}
+{"Time":"2026-10-18T11:36:18.566071945Z","Action":"run","Package":"example.com/gotest","Test":"TestParent"}
+{"Time":"2026-10-18T11:36:18.566074318Z","Action":"output","Package":"example.com/gotest","Test":"TestParent","Output":"=== RUN   TestParent\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566077193Z","Action":"run","Package":"example.com/gotest","Test":"TestParent/ok"}
+{"Time":"2026-10-18T11:36:18.566079729Z","Action":"output","Package":"example.com/gotest","Test":"TestParent/ok","Output":"=== RUN   TestParent/ok\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.56608423Z","Action":"output","Package":"example.com/gotest","Test":"TestParent/ok","Output":"--- PASS: TestParent/ok (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566087592Z","Action":"pass","Package":"example.com/gotest","Test":"TestParent/ok","Elapsed":0}
+{"Time":"2026-10-18T11:36:18.566092106Z","Action":"run","Package":"example.com/gotest","Test":"TestParent/bad_case"}
+{"Time":"2026-10-18T11:36:18.566094624Z","Action":"output","Package":"example.com/gotest","Test":"TestParent/bad_case","Output":"=== RUN   TestParent/bad_case\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566097664Z","Action":"output","Package":"example.com/gotest","Test":"TestParent/bad_case","Output":"    a_test.go:13: boom\n","OutputType":"error"}
+{"Time":"2026-10-18T11:36:18.566102906Z","Action":"output","Package":"example.com/gotest","Test":"TestParent/bad_case","Output":"--- FAIL: TestParent/bad_case (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566105791Z","Action":"fail","Package":"example.com/gotest","Test":"TestParent/bad_case","Elapsed":0}
+{"Time":"2026-10-18T11:36:18.566109671Z","Action":"output","Package":"example.com/gotest","Test":"TestParent","Output":"--- FAIL: TestParent (0.00s)\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566114465Z","Action":"fail","Package":"example.com/gotest","Test":"TestParent","Elapsed":0}
+{"Time":"2026-10-18T11:36:18.566125284Z","Action":"output","Package":"example.com/gotest","Output":"FAIL\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566154869Z","Action":"output","Package":"example.com/gotest","Output":"FAIL\texample.com/gotest\t0.002s\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.566161931Z","Action":"fail","Package":"example.com/gotest","Elapsed":0.002}
+{"ImportPath":"example.com/gotest/sub [example.com/gotest/sub.test]","Action":"build-output","Output":"# example.com/gotest/sub [example.com/gotest/sub.test]\n"}
+{"ImportPath":"example.com/gotest/sub [example.com/gotest/sub.test]","Action":"build-output","Output":"sub/b_test.go:5:28: undefined: undefined\n"}
+{"ImportPath":"example.com/gotest/sub [example.com/gotest/sub.test]","Action":"build-fail"}
+{"Time":"2026-10-18T11:36:18.572246295Z","Action":"start","Package":"example.com/gotest/sub"}
+{"Time":"2026-10-18T11:36:18.572255987Z","Action":"output","Package":"example.com/gotest/sub","Output":"FAIL\texample.com/gotest/sub [build failed]\n","OutputType":"frame"}
+{"Time":"2026-10-18T11:36:18.572262373Z","Action":"fail","Package":"example.com/gotest/sub","Elapsed":0,"FailedBuild":"example.com/gotest/sub [example.com/gotest/sub.test]"}
+`
+
+func TestParseGoJSON(t *testing.T) {
+	report := Parse(goJSONOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatGoJSON, report.Format)
+	assert.Equal(t, 2, report.Passed, "TestPass and TestParent/ok")
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 3, report.Failed, "TestFail, TestParent/bad_case and the build failure")
+	require.Len(t, report.Failures, 3)
+
+	assert.Equal(t, Failure{
+		Name:    "TestFail",
+		Package: "example.com/gotest",
+		Excerpt: "a_test.go:8: some context\na_test.go:9: expected 1, got 2",
+	}, report.Failures[0])
+	assert.Equal(t, "TestParent/bad_case", report.Failures[1].Name)
+	assert.Equal(t, "a_test.go:13: boom", report.Failures[1].Excerpt)
+	assert.Equal(t, "[build failed]", report.Failures[2].Name)
+	assert.Equal(t, "example.com/gotest/sub", report.Failures[2].Package)
+	assert.Contains(t, report.Failures[2].Excerpt, "undefined: undefined")
+}
+
+func TestParseGoPlain(t *testing.T) {
+	report := Parse(goPlainOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatGo, report.Format)
+	assert.Equal(t, 3, report.Failed)
+	require.Len(t, report.Failures, 3)
+
+	assert.Equal(t, Failure{
+		Name:    "TestFail",
+		Package: "example.com/gotest",
+		Excerpt: "a_test.go:8: some context\na_test.go:9: expected 1, got 2",
+	}, report.Failures[0])
+	assert.Equal(t, "TestParent/bad_case", report.Failures[1].Name)
+	assert.Equal(t, "a_test.go:13: boom", report.Failures[1].Excerpt)
+	assert.Equal(t, "[build failed]", report.Failures[2].Name)
+	assert.Equal(t, "example.com/gotest/sub", report.Failures[2].Package)
+	assert.Contains(t, report.Failures[2].Excerpt, "undefined: undefined")
+}
+
+func TestParseGoVerbose(t *testing.T) {
+	report := Parse(goVerboseOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatGo, report.Format)
+	assert.Equal(t, 2, report.Passed, "TestPass and TestParent/ok")
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 3, report.Failed)
+	require.Len(t, report.Failures, 3)
+
+	assert.Equal(t, "a_test.go:8: some context\na_test.go:9: expected 1, got 2", report.Failures[0].Excerpt)
+	assert.Equal(t, "TestParent/bad_case", report.Failures[1].Name)
+	assert.Equal(t, "a_test.go:13: boom", report.Failures[1].Excerpt, "verbose output streams before the result line")
+}
+
+func TestParseGoPlainAllPassing(t *testing.T) {
+	output := "ok  \texample.com/app\t0.01s\nok  \texample.com/app/store\t(cached)\n?   \texample.com/app/cmd\t[no test files]\n"
+
+	report := Parse(output)
+	require.NotNil(t, report)
+	assert.Equal(t, 2, report.Passed, "without -v, passing packages are counted")
+	assert.True(t, report.OK())
+}
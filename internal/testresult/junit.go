@@ -0,0 +1,114 @@
+package testresult
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// junitSuite is a <testsuite> element. Suites may be nested, and a
+// <testsuites> root has the same shape.
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Suites    []junitSuite    `xml:"testsuite"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a <testcase> element
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *struct{}     `xml:"skipped"`
+	SystemOut string        `xml:"system-out"`
+}
+
+// junitProblem is a <failure> or <error> element
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func detectJUnit(output string) bool {
+	return strings.Contains(output, "<testsuite")
+}
+
+// parseJUnitOutput parses JUnit XML embedded in command output
+func parseJUnitOutput(output string) *Report {
+	start := strings.Index(output, "<testsuite")
+	if xmlDecl := strings.Index(output, "<?xml"); xmlDecl >= 0 && xmlDecl < start {
+		start = xmlDecl
+	}
+	report, err := ParseJUnit(strings.NewReader(output[start:]))
+	if err != nil {
+		return nil
+	}
+	return report
+}
+
+// ParseJUnit parses a JUnit XML report, such as one written by a test runner
+// to a results file
+func ParseJUnit(r io.Reader) (*Report, error) {
+	var root struct {
+		XMLName xml.Name
+		junitSuite
+	}
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to parse JUnit XML: %w", err)
+	}
+	if root.XMLName.Local != "testsuite" && root.XMLName.Local != "testsuites" {
+		return nil, fmt.Errorf("failed to parse JUnit XML: unexpected root element <%s>", root.XMLName.Local)
+	}
+
+	report := &Report{Format: FormatJUnit}
+	addJUnitSuite(report, root.junitSuite)
+	return report, nil
+}
+
+// addJUnitSuite adds a suite's test cases, and those of nested suites, to report
+func addJUnitSuite(report *Report, suite junitSuite) {
+	for _, tc := range suite.TestCases {
+		switch {
+		case tc.Failure != nil || tc.Error != nil:
+			problem := tc.Failure
+			if problem == nil {
+				problem = tc.Error
+			}
+			pkg := tc.ClassName
+			if pkg == "" {
+				pkg = suite.Name
+			}
+			report.Failed++
+			report.Failures = append(report.Failures, Failure{
+				Name:    tc.Name,
+				Package: pkg,
+				Excerpt: junitExcerpt(problem),
+			})
+		case tc.Skipped != nil:
+			report.Skipped++
+		default:
+			report.Passed++
+		}
+	}
+
+	for _, nested := range suite.Suites {
+		addJUnitSuite(report, nested)
+	}
+}
+
+// junitExcerpt combines a problem's message and body
+func junitExcerpt(p *junitProblem) string {
+	var lines []string
+	if p.Message != "" {
+		lines = append(lines, p.Message)
+	}
+	if text := strings.TrimSpace(p.Text); text != "" && text != p.Message {
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return excerpt(lines)
+}
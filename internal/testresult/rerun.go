@@ -0,0 +1,71 @@
+package testresult
+
+import (
+	"regexp"
+	"strings"
+)
+
+// shellMeta matches commands that chain, pipe or redirect, which can't be
+// safely extended with extra arguments
+var shellMeta = regexp.MustCompile("[;&|<>`$()]")
+
+// RerunCommand returns a command that runs only the given failing tests,
+// derived from the command that produced them. Returns "" if the runner or
+// command shape isn't supported, in which case the whole command should be
+// rerun instead.
+func RerunCommand(command, format string, failures []Failure) string {
+	command = strings.TrimSpace(command)
+	if len(failures) == 0 || shellMeta.MatchString(command) {
+		return ""
+	}
+
+	switch format {
+	case FormatGo, FormatGoJSON:
+		return goRerunCommand(command, failures)
+	case FormatPytest:
+		return pytestRerunCommand(command, failures)
+	}
+	return ""
+}
+
+// goRerunCommand restricts `go test` to the failing top-level tests with -run.
+// Subtests run with their parent, since not every subtest name round-trips
+// through -run.
+func goRerunCommand(command string, failures []Failure) string {
+	if !strings.HasPrefix(command, "go test") || strings.Contains(command, "-run") {
+		return ""
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, f := range failures {
+		// Package-level failures like "[build failed]" can't be selected
+		if strings.HasPrefix(f.Name, "[") {
+			return ""
+		}
+		name, _, _ := strings.Cut(f.Name, "/")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, regexp.QuoteMeta(name))
+		}
+	}
+
+	return command + " -run '^(" + strings.Join(names, "|") + ")$'"
+}
+
+// pytestRerunCommand passes the failing tests' node IDs to pytest
+func pytestRerunCommand(command string, failures []Failure) string {
+	if !strings.Contains(command, "pytest") {
+		return ""
+	}
+
+	var ids []string
+	for _, f := range failures {
+		if f.Package == "" || strings.Contains(f.Package+f.Name, "'") {
+			return ""
+		}
+		ids = append(ids, "'"+f.Package+"::"+f.Name+"'")
+	}
+
+	return command + " " + strings.Join(ids, " ")
+}
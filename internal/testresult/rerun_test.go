@@ -0,0 +1,87 @@
+package testresult
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRerunCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		format   string
+		failures []Failure
+		want     string
+	}{
+		{
+			name:     "go selects top-level tests",
+			command:  "go test ./...",
+			format:   FormatGo,
+			failures: []Failure{{Name: "TestParse/empty"}, {Name: "TestParse/nil"}, {Name: "TestLoad"}},
+			want:     "go test ./... -run '^(TestParse|TestLoad)$'",
+		},
+		{
+			name:     "go json",
+			command:  "go test -json ./internal/...",
+			format:   FormatGoJSON,
+			failures: []Failure{{Name: "TestLoad", Package: "app/internal/store"}},
+			want:     "go test -json ./internal/... -run '^(TestLoad)$'",
+		},
+		{
+			name:     "go build failure reruns everything",
+			command:  "go test ./...",
+			format:   FormatGo,
+			failures: []Failure{{Name: "[build failed]", Package: "app/store"}},
+			want:     "",
+		},
+		{
+			name:     "go command already filtered",
+			command:  "go test -run TestLoad ./...",
+			format:   FormatGo,
+			failures: []Failure{{Name: "TestLoad"}},
+			want:     "",
+		},
+		{
+			name:     "pytest node ids",
+			command:  "pytest -q",
+			format:   FormatPytest,
+			failures: []Failure{{Name: "test_login", Package: "tests/test_api.py"}},
+			want:     "pytest -q 'tests/test_api.py::test_login'",
+		},
+		{
+			name:     "pytest without file",
+			command:  "pytest",
+			format:   FormatPytest,
+			failures: []Failure{{Name: "test_login"}},
+			want:     "",
+		},
+		{
+			name:     "chained command",
+			command:  "cd api && go test ./...",
+			format:   FormatGo,
+			failures: []Failure{{Name: "TestLoad"}},
+			want:     "",
+		},
+		{
+			name:     "wrapper command",
+			command:  "make test",
+			format:   FormatGo,
+			failures: []Failure{{Name: "TestLoad"}},
+			want:     "",
+		},
+		{
+			name:     "unsupported runner",
+			command:  "npx jest",
+			format:   FormatJest,
+			failures: []Failure{{Name: "adds"}},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RerunCommand(tt.command, tt.format, tt.failures))
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package testresult
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Runners that don't have a machine-readable format are parsed from the
+// summary and failure sections they print by default.
+
+var (
+	// pytestSummary matches "==== 2 failed, 10 passed, 1 skipped in 1.23s ===="
+	pytestSummary = regexp.MustCompile(`(?m)^=+ (.*\d+ (?:passed|failed|skipped|errors?|xfailed|xpassed|deselected).*) in [\d.]+m?s.* =+$`)
+
+	// pytestShortFailure matches "FAILED tests/test_api.py::test_login - AssertionError: ..."
+	pytestShortFailure = regexp.MustCompile(`^(FAILED|ERROR) (\S+)(?: - (.*))?$`)
+
+	// countPattern matches "12 passed" style counts
+	countPattern = regexp.MustCompile(`(\d+) (passed|failed|skipped|errors?|xfailed|xpassed|ignored|todo|pending)`)
+)
+
+func detectPytest(output string) bool {
+	return pytestSummary.MatchString(output)
+}
+
+// parsePytest parses pytest's final summary line and short test summary
+func parsePytest(output string) *Report {
+	matches := pytestSummary.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	report := &Report{}
+	counts := parseCounts(matches[len(matches)-1][1])
+	report.Passed = counts["passed"] + counts["xpassed"]
+	report.Failed = counts["failed"] + counts["error"] + counts["errors"]
+	report.Skipped = counts["skipped"] + counts["xfailed"]
+
+	for _, line := range strings.Split(output, "\n") {
+		m := pytestShortFailure.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		failure := Failure{Name: m[2], Excerpt: m[3]}
+		if file, test, ok := strings.Cut(m[2], "::"); ok {
+			failure.Package, failure.Name = file, test
+		}
+		report.Failures = append(report.Failures, failure)
+	}
+
+	return report
+}
+
+var (
+	// jestSummary matches "Tests:       1 failed, 1 skipped, 10 passed, 12 total"
+	jestSummary = regexp.MustCompile(`(?m)^Tests:\s+(.*\d+ total.*)$`)
+
+	// jestFileResult matches "FAIL src/calc.test.js" and "PASS src/calc.test.js"
+	jestFileResult = regexp.MustCompile(`^\s*(FAIL|PASS)\s+(\S+)`)
+
+	// jestFailure matches "  ● Calculator › adds numbers"
+	jestFailure = regexp.MustCompile(`^\s*● (.+)$`)
+
+	// jestSection matches the headers jest prints after the failure blocks
+	jestSection = regexp.MustCompile(`^(Summary of all failing tests|Test Suites:|Tests:)`)
+)
+
+func detectJest(output string) bool {
+	return jestSummary.MatchString(output)
+}
+
+// parseJest parses jest's summary and its "●" failure blocks
+func parseJest(output string) *Report {
+	matches := jestSummary.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	report := &Report{}
+	counts := parseCounts(matches[len(matches)-1][1])
+	report.Passed = counts["passed"]
+	report.Failed = counts["failed"]
+	report.Skipped = counts["skipped"] + counts["todo"] + counts["pending"]
+
+	lines := strings.Split(output, "\n")
+	seen := make(map[string]bool)
+	file := ""
+	for i := 0; i < len(lines); i++ {
+		if m := jestFileResult.FindStringSubmatch(lines[i]); m != nil {
+			file = m[2]
+			continue
+		}
+		m := jestFailure.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+
+		// The failure message runs until the next failure, file result or section
+		var details []string
+		for i+1 < len(lines) && !jestFailure.MatchString(lines[i+1]) &&
+			!jestFileResult.MatchString(lines[i+1]) && !jestSection.MatchString(lines[i+1]) {
+			details = append(details, lines[i+1])
+			i++
+		}
+
+		// Jest repeats failures in its "Summary of all failing tests" section
+		name := strings.TrimSpace(m[1])
+		if seen[file+"\x00"+name] {
+			continue
+		}
+		seen[file+"\x00"+name] = true
+		report.Failures = append(report.Failures, Failure{Name: name, Package: file, Excerpt: excerpt(details)})
+	}
+
+	return report
+}
+
+var (
+	// vitestSummary matches "      Tests  1 failed | 10 passed (11)"
+	vitestSummary = regexp.MustCompile(`(?m)^\s*Tests\s+(\d+ (?:passed|failed|skipped|todo).*)$`)
+
+	// vitestFailure matches " FAIL  src/calc.test.ts > Calculator > adds"
+	vitestFailure = regexp.MustCompile(`^\s*FAIL\s+(\S+) > (.+)$`)
+)
+
+func detectVitest(output string) bool {
+	return vitestSummary.MatchString(output)
+}
+
+// parseVitest parses vitest's summary and failure headers
+func parseVitest(output string) *Report {
+	matches := vitestSummary.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	report := &Report{}
+	counts := parseCounts(matches[len(matches)-1][1])
+	report.Passed = counts["passed"]
+	report.Failed = counts["failed"]
+	report.Skipped = counts["skipped"] + counts["todo"]
+
+	lines := strings.Split(output, "\n")
+	seen := make(map[string]bool)
+	for i := 0; i < len(lines); i++ {
+		m := vitestFailure.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		var details []string
+		for i+1 < len(lines) && !vitestFailure.MatchString(lines[i+1]) &&
+			!strings.HasPrefix(strings.TrimSpace(lines[i+1]), "⎯") {
+			details = append(details, lines[i+1])
+			i++
+		}
+		key := m[1] + "\x00" + m[2]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		report.Failures = append(report.Failures, Failure{Name: m[2], Package: m[1], Excerpt: excerpt(details)})
+	}
+
+	return report
+}
+
+var (
+	// cargoSummary matches "test result: FAILED. 8 passed; 2 failed; 1 ignored; 0 measured; 0 filtered out"
+	cargoSummary = regexp.MustCompile(`(?m)^test result: \w+\. (.*)$`)
+
+	// cargoFailureOutput matches "---- tests::it_works stdout ----"
+	cargoFailureOutput = regexp.MustCompile(`^---- (\S+) stdout ----$`)
+)
+
+func detectCargo(output string) bool {
+	return cargoSummary.MatchString(output)
+}
+
+// parseCargo parses cargo test's per-binary summaries and captured failure output
+func parseCargo(output string) *Report {
+	matches := cargoSummary.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// Cargo prints one summary per test binary
+	report := &Report{}
+	for _, m := range matches {
+		counts := parseCounts(m[1])
+		report.Passed += counts["passed"]
+		report.Failed += counts["failed"]
+		report.Skipped += counts["ignored"]
+	}
+
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		m := cargoFailureOutput.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		var details []string
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			details = append(details, lines[i+1])
+			i++
+		}
+		report.Failures = append(report.Failures, Failure{Name: m[1], Excerpt: excerpt(details)})
+	}
+
+	return report
+}
+
+// parseCounts extracts "N label" pairs from a summary line
+func parseCounts(summary string) map[string]int {
+	counts := make(map[string]int)
+	for _, m := range countPattern.FindAllStringSubmatch(summary, -1) {
+		n, _ := strconv.Atoi(m[1])
+		counts[m[2]] += n
+	}
+	return counts
+}
@@ -0,0 +1,216 @@
+package testresult
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const junitOutput = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="jest tests" tests="5" failures="1" errors="1">
+  <testsuite name="calc" tests="4">
+    <testcase classname="calc.add" name="adds numbers" time="0.01"/>
+    <testcase classname="calc.add" name="adds negatives" time="0.01">
+      <failure message="expected 3 to equal 4" type="AssertionError">AssertionError: expected 3 to equal 4
+    at Context.&lt;anonymous&gt; (test/calc.test.js:12:10)</failure>
+    </testcase>
+    <testcase classname="calc.div" name="divides by zero">
+      <error message="TypeError: boom"/>
+    </testcase>
+    <testcase classname="calc.div" name="rounds">
+      <skipped/>
+    </testcase>
+    <testsuite name="nested">
+      <testcase name="nested case"/>
+    </testsuite>
+  </testsuite>
+</testsuites>
+`
+
+func TestParseJUnit(t *testing.T) {
+	report := Parse("Running tests...\n" + junitOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatJUnit, report.Format)
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Failures, 2)
+
+	assert.Equal(t, "adds negatives", report.Failures[0].Name)
+	assert.Equal(t, "calc.add", report.Failures[0].Package)
+	assert.Equal(t, "expected 3 to equal 4\nAssertionError: expected 3 to equal 4\nat Context.<anonymous> (test/calc.test.js:12:10)",
+		report.Failures[0].Excerpt)
+	assert.Equal(t, "TypeError: boom", report.Failures[1].Excerpt)
+}
+
+func TestParseJUnitReader(t *testing.T) {
+	report, err := ParseJUnit(strings.NewReader(`<testsuite name="s"><testcase name="a"/></testsuite>`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Passed)
+
+	_, err = ParseJUnit(strings.NewReader(`<html></html>`))
+	assert.Error(t, err)
+
+	_, err = ParseJUnit(strings.NewReader(`not xml`))
+	assert.Error(t, err)
+}
+
+const pytestOutput = `============================= test session starts ==============================
+platform linux -- Python 3.12.1, pytest-8.0.0, pluggy-1.4.0
+collected 14 items
+
+tests/test_api.py ..F.s                                                  [ 35%]
+tests/test_models.py ........E                                           [100%]
+
+=================================== FAILURES ===================================
+__________________________________ test_login __________________________________
+
+    def test_login():
+>       assert login("bob", "wrong") is True
+E       AssertionError: assert False is True
+
+tests/test_api.py:12: AssertionError
+=========================== short test summary info ============================
+FAILED tests/test_api.py::test_login - AssertionError: assert False is True
+ERROR tests/test_models.py::test_save - sqlite3.OperationalError: no such table
+============== 1 failed, 11 passed, 1 skipped, 1 error in 0.42s ===============
+`
+
+func TestParsePytest(t *testing.T) {
+	report := Parse(pytestOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatPytest, report.Format)
+	assert.Equal(t, 11, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Failures, 2)
+
+	assert.Equal(t, Failure{
+		Name:    "test_login",
+		Package: "tests/test_api.py",
+		Excerpt: "AssertionError: assert False is True",
+	}, report.Failures[0])
+	assert.Equal(t, "test_save", report.Failures[1].Name)
+}
+
+func TestParsePytestAllPassing(t *testing.T) {
+	report := Parse("collected 3 items\n\ntests/test_a.py ...\n\n============================== 3 passed in 0.01s ===============================\n")
+	require.NotNil(t, report)
+	assert.Equal(t, 3, report.Passed)
+	assert.True(t, report.OK())
+}
+
+const jestOutput = `PASS src/utils.test.js
+FAIL src/calc.test.js
+  ● Calculator › adds numbers
+
+    expect(received).toBe(expected) // Object.is equality
+
+    Expected: 3
+    Received: 4
+
+      4 | test('adds numbers', () => {
+    > 5 |   expect(add(1, 2)).toBe(3);
+
+  ● Calculator › divides
+
+    TypeError: Cannot divide
+
+Summary of all failing tests
+FAIL src/calc.test.js
+  ● Calculator › adds numbers
+
+    expect(received).toBe(expected) // Object.is equality
+
+Test Suites: 1 failed, 1 passed, 2 total
+Tests:       2 failed, 1 skipped, 7 passed, 10 total
+Snapshots:   0 total
+Time:        1.234 s
+`
+
+func TestParseJest(t *testing.T) {
+	report := Parse(jestOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatJest, report.Format)
+	assert.Equal(t, 7, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Failures, 2, "repeated failures in the summary are de-duplicated")
+
+	assert.Equal(t, "Calculator › adds numbers", report.Failures[0].Name)
+	assert.Equal(t, "src/calc.test.js", report.Failures[0].Package)
+	assert.True(t, strings.HasPrefix(report.Failures[0].Excerpt, "expect(received).toBe(expected)"))
+	assert.Equal(t, "TypeError: Cannot divide", report.Failures[1].Excerpt)
+}
+
+const vitestOutput = ` ❯ src/calc.test.ts  (3 tests | 1 failed) 5ms
+   × Calculator > adds
+
+⎯⎯⎯⎯⎯⎯⎯ Failed Tests 1 ⎯⎯⎯⎯⎯⎯⎯
+
+ FAIL  src/calc.test.ts > Calculator > adds
+AssertionError: expected 4 to be 3
+ ❯ src/calc.test.ts:5:22
+
+⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯[1/1]⎯
+
+ Test Files  1 failed (1)
+      Tests  1 failed | 2 passed (3)
+   Duration  312ms
+`
+
+func TestParseVitest(t *testing.T) {
+	report := Parse(vitestOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatVitest, report.Format)
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, Failure{
+		Name:    "Calculator > adds",
+		Package: "src/calc.test.ts",
+		Excerpt: "AssertionError: expected 4 to be 3\n ❯ src/calc.test.ts:5:22",
+	}, report.Failures[0])
+}
+
+const cargoOutput = `running 3 tests
+test tests::adds ... ok
+test tests::ignored ... ignored
+test tests::divides ... FAILED
+
+failures:
+
+---- tests::divides stdout ----
+thread 'tests::divides' panicked at src/lib.rs:20:9:
+attempt to divide by zero
+
+failures:
+    tests::divides
+
+test result: FAILED. 1 passed; 1 failed; 1 ignored; 0 measured; 0 filtered out; finished in 0.00s
+
+running 2 tests
+test it_works ... ok
+test it_also_works ... ok
+
+test result: ok. 2 passed; 0 failed; 0 ignored; 0 measured; 0 filtered out; finished in 0.00s
+`
+
+func TestParseCargo(t *testing.T) {
+	report := Parse(cargoOutput)
+	require.NotNil(t, report)
+
+	assert.Equal(t, FormatCargo, report.Format)
+	assert.Equal(t, 3, report.Passed, "summaries from each test binary are added")
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "tests::divides", report.Failures[0].Name)
+	assert.Contains(t, report.Failures[0].Excerpt, "attempt to divide by zero")
+}
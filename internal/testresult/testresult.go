@@ -0,0 +1,204 @@
+// Package testresult parses test runner output into structured results.
+//
+// Supported formats are `go test -json`, plain `go test` output, JUnit XML,
+// and the summaries printed by pytest, jest, vitest and cargo test. Parse
+// detects the format automatically, so callers can hand it whatever a test
+// command printed.
+package testresult
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Output formats recognized by Parse
+const (
+	FormatGoJSON = "go-json"
+	FormatGo     = "go"
+	FormatJUnit  = "junit"
+	FormatPytest = "pytest"
+	FormatJest   = "jest"
+	FormatVitest = "vitest"
+	FormatCargo  = "cargo"
+)
+
+// Excerpt limits keep failure details readable in the TUI and progress file
+const (
+	maxExcerptLines = 10
+	maxExcerptChars = 1000
+)
+
+// Failure is a single failing test
+type Failure struct {
+	Name    string `json:"name"`              // Test name (e.g., "TestParse/empty", "test_login")
+	Package string `json:"package,omitempty"` // Package, class or file the test belongs to
+	Excerpt string `json:"excerpt,omitempty"` // Relevant lines of failure output
+}
+
+// String returns the failure as "package: name", or just the name
+func (f Failure) String() string {
+	if f.Package == "" {
+		return f.Name
+	}
+	return f.Package + ": " + f.Name
+}
+
+// Report holds the structured results of a test run
+type Report struct {
+	Format   string    `json:"format"`
+	Passed   int       `json:"passed"`
+	Failed   int       `json:"failed"`
+	Skipped  int       `json:"skipped"`
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// Total returns the number of tests that ran or were skipped
+func (r *Report) Total() int {
+	return r.Passed + r.Failed + r.Skipped
+}
+
+// OK returns true if no tests failed
+func (r *Report) OK() bool {
+	return r.Failed == 0 && len(r.Failures) == 0
+}
+
+// Summary returns the counts as text, e.g. "12 passed, 2 failed, 1 skipped"
+func (r *Report) Summary() string {
+	parts := []string{fmt.Sprintf("%d passed", r.Passed)}
+	if r.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", r.Failed))
+	}
+	if r.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", r.Skipped))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FailedNames returns the names of the failing tests
+func (r *Report) FailedNames() []string {
+	names := make([]string, 0, len(r.Failures))
+	for _, f := range r.Failures {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// parser is a single output format
+type parser struct {
+	format string
+	detect func(output string) bool
+	parse  func(output string) *Report
+}
+
+// parsers are tried in order; more specific formats come first
+var parsers = []parser{
+	{FormatGoJSON, detectGoJSON, parseGoJSON},
+	{FormatJUnit, detectJUnit, parseJUnitOutput},
+	{FormatGo, detectGo, parseGo},
+	{FormatPytest, detectPytest, parsePytest},
+	{FormatJest, detectJest, parseJest},
+	{FormatVitest, detectVitest, parseVitest},
+	{FormatCargo, detectCargo, parseCargo},
+}
+
+// Parse detects the format of test output and returns structured results.
+// Returns nil if the output doesn't contain recognizable test results.
+func Parse(output string) *Report {
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	output = stripANSI(output)
+
+	for _, p := range parsers {
+		if !p.detect(output) {
+			continue
+		}
+		if report := p.parse(output); report != nil {
+			report.Format = p.format
+			return report
+		}
+	}
+	return nil
+}
+
+// testCommandPatterns match commands that run a test suite
+var testCommandPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bgo\s+test\b`),
+	regexp.MustCompile(`\bgotestsum\b`),
+	regexp.MustCompile(`\b(pytest|py\.test)\b`),
+	regexp.MustCompile(`\bpython[\d.]*\s+-m\s+(pytest|unittest)\b`),
+	regexp.MustCompile(`\b(jest|vitest|mocha|ava|rspec|phpunit)\b`),
+	regexp.MustCompile(`\b(npm|yarn|pnpm|bun)\s+(run\s+)?test\b`),
+	regexp.MustCompile(`\bcargo\s+(test|nextest)\b`),
+	regexp.MustCompile(`\b(make|just|task)\s+(\S+\s+)*test\b`),
+	regexp.MustCompile(`\b(dotnet|gradle|gradlew|mvn|mix|deno)\s+test\b`),
+}
+
+// IsTestCommand reports whether a shell command runs a test suite
+func IsTestCommand(command string) bool {
+	for _, re := range testCommandPatterns {
+		if re.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+// ansiPattern matches terminal color and cursor escape sequences
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+// stripANSI removes terminal escape sequences, which jest and vitest emit
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// excerpt trims failure output to a readable size, removing blank edges and
+// common indentation
+func excerpt(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	truncated := false
+	if len(lines) > maxExcerptLines {
+		lines = lines[:maxExcerptLines]
+		truncated = true
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent {
+			line = line[indent:]
+		}
+		trimmed[i] = strings.TrimRight(line, " \t")
+	}
+
+	result := strings.Join(trimmed, "\n")
+	if len(result) > maxExcerptChars {
+		result = result[:maxExcerptChars]
+		truncated = true
+	}
+	if truncated {
+		result += "\n..."
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package testresult
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnrecognized(t *testing.T) {
+	assert.Nil(t, Parse(""))
+	assert.Nil(t, Parse("Compiling...\nDone in 2.1s\n"))
+}
+
+func TestReportSummary(t *testing.T) {
+	r := &Report{Passed: 12, Failed: 2, Skipped: 1}
+	assert.Equal(t, "12 passed, 2 failed, 1 skipped", r.Summary())
+	assert.Equal(t, 15, r.Total())
+	assert.False(t, r.OK())
+
+	r = &Report{Passed: 3}
+	assert.Equal(t, "3 passed", r.Summary())
+	assert.True(t, r.OK())
+}
+
+func TestFailureString(t *testing.T) {
+	assert.Equal(t, "pkg: TestX", Failure{Name: "TestX", Package: "pkg"}.String())
+	assert.Equal(t, "TestX", Failure{Name: "TestX"}.String())
+}
+
+func TestIsTestCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"go test ./...", true},
+		{"cd api && go test -race ./internal/...", true},
+		{"pytest -x tests/", true},
+		{"python -m pytest", true},
+		{"npx jest --coverage", true},
+		{"npm test", true},
+		{"npm run test -- --watch=false", true},
+		{"pnpm test", true},
+		{"cargo test --all", true},
+		{"make test", true},
+		{"make -C backend test", true},
+		{"bundle exec rspec", true},
+		{"go build ./...", false},
+		{"cat testdata/input.txt", false},
+		{"git commit -m 'add tests'", false},
+		{"make build", false},
+		{"ls internal/testresult", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTestCommand(tt.command))
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "", excerpt(nil))
+	assert.Equal(t, "a\n  b", excerpt([]string{"", "    a", "      b", "  "}))
+
+	var many []string
+	for i := 0; i < 20; i++ {
+		many = append(many, "line")
+	}
+	result := excerpt(many)
+	assert.Equal(t, maxExcerptLines+1, strings.Count(result, "\n")+1)
+	assert.True(t, strings.HasSuffix(result, "\n..."))
+
+	long := excerpt([]string{strings.Repeat("x", 2000)})
+	assert.LessOrEqual(t, len(long), maxExcerptChars+4)
+}
+
+func TestParseStripsANSI(t *testing.T) {
+	output := "\x1b[1mTests:\x1b[22m       \x1b[1m\x1b[31m1 failed\x1b[39m\x1b[22m, \x1b[1m\x1b[32m4 passed\x1b[39m\x1b[22m, 5 total\n"
+
+	report := Parse(output)
+	require.NotNil(t, report)
+	assert.Equal(t, FormatJest, report.Format)
+	assert.Equal(t, 4, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+}
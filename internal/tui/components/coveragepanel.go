@@ -0,0 +1,102 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mpjhorner/superralph/internal/coverage"
+)
+
+// CoveragePanel displays the coverage gate result, or the recorded baseline
+// when no gate has run yet
+type CoveragePanel struct {
+	Result         *coverage.Comparison // Most recent coverage gate result
+	Baseline       *coverage.Baseline   // Recorded baseline, shown when Result is nil
+	MaxRegressions int                  // maximum regressed packages to display
+	Width          int
+	Title          string
+}
+
+// NewCoveragePanel creates a new coverage panel
+func NewCoveragePanel(width int) *CoveragePanel {
+	return &CoveragePanel{
+		MaxRegressions: 5,
+		Width:          width,
+		Title:          "Coverage",
+	}
+}
+
+// SetResult records a coverage gate result
+func (p *CoveragePanel) SetResult(c *coverage.Comparison) {
+	p.Result = c
+}
+
+// SetBaseline records the baseline to show before any gate has run
+func (p *CoveragePanel) SetBaseline(b *coverage.Baseline) {
+	p.Baseline = b
+}
+
+// HasContent returns true if there is a result or baseline to show
+func (p *CoveragePanel) HasContent() bool {
+	return p.Result != nil || p.Baseline != nil
+}
+
+// Render returns the coverage panel as a string
+func (p *CoveragePanel) Render() string {
+	if !p.HasContent() {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("245")).
+		Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	passStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+
+	contentWidth := p.Width - 6 // Account for border and indent
+
+	sb.WriteString(titleStyle.Render(p.Title))
+	sb.WriteString("\n")
+
+	if p.Result == nil {
+		// Baseline only (e.g., in status)
+		line := fmt.Sprintf("%.1f%% recorded %s", p.Baseline.Profile.Percent(), p.Baseline.Recorded.Local().Format("Jan 2 15:04"))
+		if p.Baseline.FeatureID != "" {
+			line += " at " + p.Baseline.FeatureID
+		}
+		sb.WriteString(" " + truncateLine(line, contentWidth) + "\n")
+	} else {
+		status := passStyle.Render("● OK")
+		if p.Result.Rejected() {
+			status = failStyle.Render("✗ DROPPED")
+		}
+		sb.WriteString(fmt.Sprintf(" %s %s\n", status, p.Result.Summary()))
+
+		regressions := p.Result.Regressions
+		hidden := 0
+		if p.MaxRegressions > 0 && len(regressions) > p.MaxRegressions {
+			hidden = len(regressions) - p.MaxRegressions
+			regressions = regressions[:p.MaxRegressions]
+		}
+		for _, r := range regressions {
+			line := fmt.Sprintf("%s %.1f%% -> %.1f%%", r.Package, r.Before, r.After)
+			sb.WriteString(" " + failStyle.Render("✗") + " " + truncateLine(line, contentWidth-2) + "\n")
+		}
+		if hidden > 0 {
+			sb.WriteString(mutedStyle.Render(fmt.Sprintf("   ... and %d more", hidden)) + "\n")
+		}
+	}
+
+	// Render in a box
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("245")).
+		Width(p.Width - 2)
+
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
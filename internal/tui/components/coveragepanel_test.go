@@ -0,0 +1,51 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/coverage"
+)
+
+func coverageProfile(covered int) *coverage.Profile {
+	return &coverage.Profile{Packages: map[string]coverage.Counts{
+		"app/api": {Covered: covered, Total: 100},
+	}}
+}
+
+func TestNewCoveragePanel(t *testing.T) {
+	p := NewCoveragePanel(80)
+
+	require.NotNil(t, p)
+	assert.Equal(t, 80, p.Width)
+	assert.False(t, p.HasContent())
+	assert.Empty(t, p.Render())
+}
+
+func TestCoveragePanelBaseline(t *testing.T) {
+	p := NewCoveragePanel(80)
+	p.SetBaseline(&coverage.Baseline{FeatureID: "feat-004", Recorded: time.Now(), Profile: coverageProfile(75)})
+
+	view := p.Render()
+	assert.Contains(t, view, "Coverage")
+	assert.Contains(t, view, "75.0% recorded")
+	assert.Contains(t, view, "at feat-004")
+}
+
+func TestCoveragePanelResult(t *testing.T) {
+	baseline := &coverage.Baseline{Profile: coverageProfile(80)}
+
+	p := NewCoveragePanel(80)
+	p.SetResult(coverage.Compare(baseline, coverageProfile(80), 0.5))
+	view := p.Render()
+	assert.Contains(t, view, "OK")
+	assert.Contains(t, view, "80.0% (+0.0 from 80.0%)")
+
+	p.SetResult(coverage.Compare(baseline, coverageProfile(60), 0.5))
+	view = p.Render()
+	assert.Contains(t, view, "DROPPED")
+	assert.Contains(t, view, "app/api 80.0% -> 60.0%")
+}
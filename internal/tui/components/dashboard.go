@@ -192,6 +192,18 @@ func (d *Dashboard) Render() string {
 	return b.String()
 }
 
+// StatusCounts lists how many features are in each status, e.g. "3 todo, 1
+// in progress, 1 blocked", leaving out statuses no feature is in
+func StatusCounts(stats prd.PRDStats) string {
+	var parts []string
+	for _, status := range prd.ValidStatuses() {
+		if n := stats.ByStatus[status]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, strings.ReplaceAll(string(status), "_", " ")))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 // renderProgress renders the progress section with bars
 func (d *Dashboard) renderProgress() string {
 	stats := d.PRDStats
@@ -200,14 +212,18 @@ func (d *Dashboard) renderProgress() string {
 	var b strings.Builder
 	b.WriteString(d.labelStyle.Render("Progress: "))
 	b.WriteString(pb.Render())
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if counts := StatusCounts(stats); counts != "" {
+		b.WriteString(d.mutedStyle.Render(counts) + "\n")
+	}
+	b.WriteString("\n")
 
 	// Category breakdown
 	b.WriteString(d.mutedStyle.Render("By Category:") + "                    ")
 	b.WriteString(d.mutedStyle.Render("By Priority:") + "\n")
 
-	categories := prd.ValidCategories()
-	priorities := prd.ValidPriorities()
+	categories := stats.Categories
+	priorities := stats.Priorities
 	maxRows := len(categories)
 	if len(priorities) > maxRows {
 		maxRows = len(priorities)
@@ -0,0 +1,136 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mpjhorner/superralph/internal/graph"
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+// DependencyTree shows each feature under the features it depends on,
+// colored by status, with the critical path and any cycles below
+type DependencyTree struct {
+	Nodes        []graph.Node
+	Statuses     map[string]graph.Status
+	Descriptions map[string]string
+	CriticalPath []string
+	Cycles       [][]string
+	Width        int
+	Height       int
+	ScrollOffset int
+}
+
+// NewDependencyTree creates a new dependency tree component
+func NewDependencyTree(width, height int) *DependencyTree {
+	return &DependencyTree{Width: width, Height: height}
+}
+
+// SetPRD lays out the tree for a PRD. currentFeatureID is highlighted as the
+// feature being built.
+func (t *DependencyTree) SetPRD(p *prd.PRD, currentFeatureID string) {
+	t.Nodes = graph.Tree(p)
+	t.Statuses = graph.Statuses(p, currentFeatureID)
+	t.Descriptions = make(map[string]string, len(p.Features))
+	for _, f := range p.Features {
+		t.Descriptions[f.ID] = f.Description
+	}
+	t.CriticalPath = p.CriticalPath()
+	t.Cycles = p.Cycles()
+	t.ScrollOffset = min(t.ScrollOffset, t.maxOffset())
+}
+
+// Resize updates the component dimensions
+func (t *DependencyTree) Resize(width, height int) {
+	t.Width = width
+	t.Height = height
+	t.ScrollOffset = min(t.ScrollOffset, t.maxOffset())
+}
+
+// ScrollUp scrolls the tree up by n lines
+func (t *DependencyTree) ScrollUp(n int) {
+	t.ScrollOffset = max(t.ScrollOffset-n, 0)
+}
+
+// ScrollDown scrolls the tree down by n lines
+func (t *DependencyTree) ScrollDown(n int) {
+	t.ScrollOffset = min(t.ScrollOffset+n, t.maxOffset())
+}
+
+// visibleLines is the number of tree lines that fit, after the title and
+// the summary below the tree
+func (t *DependencyTree) visibleLines() int {
+	return max(t.Height-4-len(t.Cycles), 3)
+}
+
+// maxOffset is the furthest the tree can scroll
+func (t *DependencyTree) maxOffset() int {
+	return max(len(t.Nodes)-t.visibleLines(), 0)
+}
+
+// dependencyStatusStyles color each status; features that can never be
+// built are red
+var dependencyStatusStyles = map[graph.Status]lipgloss.Style{
+	graph.StatusPassing:     lipgloss.NewStyle().Foreground(lipgloss.Color("245")),
+	graph.StatusCurrent:     lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true),
+	graph.StatusReady:       lipgloss.NewStyle().Foreground(lipgloss.Color("252")),
+	graph.StatusBlocked:     lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	graph.StatusHeld:        lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	graph.StatusUnreachable: lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+}
+
+// dependencyStatusIcons follow the feature list's icons
+var dependencyStatusIcons = map[graph.Status]string{
+	graph.StatusPassing:     "✓",
+	graph.StatusCurrent:     "→",
+	graph.StatusReady:       "○",
+	graph.StatusBlocked:     "✗",
+	graph.StatusHeld:        "‖",
+	graph.StatusUnreachable: "⊘",
+}
+
+// Render returns the dependency tree as a string
+func (t *DependencyTree) Render() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Dependencies"))
+	sb.WriteString("\n\n")
+
+	if len(t.Nodes) == 0 {
+		sb.WriteString(mutedStyle.Render("No features in prd.json"))
+		return sb.String()
+	}
+
+	end := min(t.ScrollOffset+t.visibleLines(), len(t.Nodes))
+	for _, n := range t.Nodes[t.ScrollOffset:end] {
+		status := t.Statuses[n.ID]
+		style := dependencyStatusStyles[status]
+		line := mutedStyle.Render(n.Prefix) + style.Render(dependencyStatusIcons[status]+" "+n.ID)
+		if n.Repeat {
+			line += mutedStyle.Render(" (see above)")
+		} else {
+			room := t.Width - lipgloss.Width(n.Prefix+n.ID) - 4
+			line += " " + mutedStyle.Render(truncateLine(t.Descriptions[n.ID], max(room, 4)))
+		}
+		sb.WriteString(line + "\n")
+	}
+	if end < len(t.Nodes) {
+		sb.WriteString(mutedStyle.Render(fmt.Sprintf("  ↓ %d more", len(t.Nodes)-end)) + "\n")
+	}
+
+	sb.WriteString("\n")
+	if len(t.CriticalPath) > 0 {
+		sb.WriteString(mutedStyle.Render(fmt.Sprintf("Critical path (%d): ", len(t.CriticalPath))) + strings.Join(t.CriticalPath, " → "))
+	} else {
+		sb.WriteString(mutedStyle.Render("Critical path: nothing left to build"))
+	}
+	for _, cycle := range t.Cycles {
+		sb.WriteString("\n" + errorStyle.Render("Cycle: "+strings.Join(cycle, " → ")))
+	}
+	return sb.String()
+}
@@ -0,0 +1,40 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+func TestDependencyTree(t *testing.T) {
+	p := &prd.PRD{Features: []prd.Feature{
+		{ID: "feat-001", Description: "Catalog", Passes: true},
+		{ID: "feat-002", Description: "Cart", DependsOn: []string{"feat-001"}},
+		{ID: "feat-003", Description: "Checkout", DependsOn: []string{"feat-002"}},
+		{ID: "feat-004", Description: "Refunds", DependsOn: []string{"feat-005"}},
+		{ID: "feat-005", Description: "Disputes", DependsOn: []string{"feat-004"}},
+	}}
+
+	tree := NewDependencyTree(80, 30)
+	tree.SetPRD(p, "feat-002")
+	view := tree.Render()
+	assert.Contains(t, view, "Dependencies")
+	assert.Contains(t, view, "✓ feat-001")
+	assert.Contains(t, view, "└─ → feat-002")
+	assert.Contains(t, view, "✗ feat-003")
+	assert.Contains(t, view, "⊘ feat-004")
+	assert.Contains(t, view, "(see above)")
+	assert.Contains(t, view, "feat-002 → feat-003")
+	assert.Contains(t, view, "Cycle: feat-004 → feat-005 → feat-004")
+
+	tree.Resize(80, 8)
+	assert.Contains(t, tree.Render(), "↓ 3 more")
+	tree.ScrollDown(10)
+	assert.Equal(t, 3, tree.ScrollOffset)
+	tree.ScrollUp(1)
+	assert.Equal(t, 2, tree.ScrollOffset)
+
+	assert.Contains(t, NewDependencyTree(80, 30).Render(), "No features in prd.json")
+}
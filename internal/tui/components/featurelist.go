@@ -17,13 +17,46 @@ const (
 	FeatureStatusCurrent  FeatureStatus = "current"
 	FeatureStatusPending  FeatureStatus = "pending"
 	FeatureStatusBlocked  FeatureStatus = "blocked"
+	FeatureStatusStarted  FeatureStatus = "started" // In progress in an earlier build
+	FeatureStatusStuck    FeatureStatus = "stuck"
+	FeatureStatusReview   FeatureStatus = "review"
+	FeatureStatusSkipped  FeatureStatus = "skipped"
 )
 
+// featureListStatus returns a feature's status in the list: its status in
+// the PRD, with open features shown as current while they're being built and
+// as blocked while their dependencies don't pass
+func featureListStatus(p *prd.PRD, f *prd.Feature, currentFeatureID string) FeatureStatus {
+	switch f.CurrentStatus() {
+	case prd.StatusPassing:
+		return FeatureStatusComplete
+	case prd.StatusBlocked:
+		return FeatureStatusBlocked
+	case prd.StatusStuck:
+		return FeatureStatusStuck
+	case prd.StatusNeedsReview:
+		return FeatureStatusReview
+	case prd.StatusSkipped:
+		return FeatureStatusSkipped
+	}
+	switch {
+	case f.ID == currentFeatureID:
+		return FeatureStatusCurrent
+	case !p.DependenciesMet(f):
+		return FeatureStatusBlocked
+	case f.CurrentStatus() == prd.StatusInProgress:
+		return FeatureStatusStarted
+	default:
+		return FeatureStatusPending
+	}
+}
+
 // FeatureListItem represents a single item in the feature list
 type FeatureListItem struct {
 	ID          string
 	Description string
 	Priority    prd.Priority
+	Tier        prd.Priority // High, medium or low, for the priority color
 	Status      FeatureStatus
 }
 
@@ -81,21 +114,14 @@ func (fl *FeatureList) UpdateFromPRD(p *prd.PRD, currentFeatureID string) {
 	fl.CurrentFeatureID = currentFeatureID
 	fl.Items = make([]FeatureListItem, 0, len(p.Features))
 
-	for _, f := range p.Features {
-		status := FeatureStatusPending
-		if f.Passes {
-			status = FeatureStatusComplete
-		} else if f.ID == currentFeatureID {
-			status = FeatureStatusCurrent
-		} else if !p.DependenciesMet(&f) {
-			status = FeatureStatusBlocked
-		}
-
+	for i := range p.Features {
+		f := &p.Features[i]
 		fl.Items = append(fl.Items, FeatureListItem{
 			ID:          f.ID,
 			Description: f.Description,
 			Priority:    f.Priority,
-			Status:      status,
+			Tier:        p.PriorityTier(f.Priority),
+			Status:      featureListStatus(p, f, currentFeatureID),
 		})
 	}
 
@@ -204,13 +230,25 @@ func (fl *FeatureList) renderItem(item FeatureListItem) string {
 	case FeatureStatusBlocked:
 		icon = "✗"
 		style = fl.blockedStyle
+	case FeatureStatusStuck:
+		icon = "!"
+		style = fl.blockedStyle
+	case FeatureStatusReview:
+		icon = "◆"
+		style = fl.itemStyle
+	case FeatureStatusStarted:
+		icon = "◐"
+		style = fl.itemStyle
+	case FeatureStatusSkipped:
+		icon = "–"
+		style = fl.completeStyle
 	default:
 		icon = "○"
 		style = fl.itemStyle
 	}
 
 	// Priority indicator (colored dot)
-	priorityStyle, ok := fl.priorityStyles[item.Priority]
+	priorityStyle, ok := fl.priorityStyles[item.Tier]
 	if !ok {
 		priorityStyle = fl.itemStyle
 	}
@@ -0,0 +1,166 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mpjhorner/superralph/internal/history"
+)
+
+// HistoryView shows feature timelines: a list of features, and the events
+// of the selected one
+type HistoryView struct {
+	Timelines []*history.Timeline
+	Selected  int
+	Width     int
+	Height    int
+}
+
+// NewHistoryView creates a new history view
+func NewHistoryView(timelines []*history.Timeline, width, height int) *HistoryView {
+	return &HistoryView{
+		Timelines: timelines,
+		Width:     width,
+		Height:    height,
+	}
+}
+
+// SetSize updates the view dimensions
+func (v *HistoryView) SetSize(width, height int) {
+	v.Width = width
+	v.Height = height
+}
+
+// MoveUp selects the previous feature
+func (v *HistoryView) MoveUp() {
+	if v.Selected > 0 {
+		v.Selected--
+	}
+}
+
+// MoveDown selects the next feature
+func (v *HistoryView) MoveDown() {
+	if v.Selected < len(v.Timelines)-1 {
+		v.Selected++
+	}
+}
+
+// Current returns the selected timeline, or nil if there are none
+func (v *HistoryView) Current() *history.Timeline {
+	if v.Selected < 0 || v.Selected >= len(v.Timelines) {
+		return nil
+	}
+	return v.Timelines[v.Selected]
+}
+
+// Render returns the history view as a string
+func (v *HistoryView) Render() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("99")).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Feature History"))
+	sb.WriteString("\n\n")
+
+	if len(v.Timelines) == 0 {
+		sb.WriteString(mutedStyle.Render("No features in prd.json"))
+		return sb.String()
+	}
+
+	// Feature list, scrolled to keep the selection visible in half the height
+	listHeight := max(v.Height/2-3, 3)
+	start := 0
+	if v.Selected >= listHeight {
+		start = v.Selected - listHeight + 1
+	}
+	end := min(start+listHeight, len(v.Timelines))
+	for i := start; i < end; i++ {
+		t := v.Timelines[i]
+		line := fmt.Sprintf("%s %-10s %-12s %2d iter  %s", historyStatusIcon(t.Status), t.FeatureID, t.Status, t.Attempts, t.Description)
+		line = truncateLine(line, v.Width-4)
+		if i == v.Selected {
+			sb.WriteString(selectedStyle.Render("▸ " + line))
+		} else {
+			sb.WriteString("  " + line)
+		}
+		sb.WriteString("\n")
+	}
+
+	if t := v.Current(); t != nil {
+		sb.WriteString("\n")
+		sb.WriteString(v.renderDetail(t, max(v.Height-listHeight-6, 3)))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(mutedStyle.Render("↑/↓ select • q quit"))
+	return sb.String()
+}
+
+// renderDetail shows a timeline's summary and its latest events
+func (v *HistoryView) renderDetail(t *history.Timeline, height int) string {
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	boldStyle := lipgloss.NewStyle().Bold(true)
+
+	var sb strings.Builder
+	sb.WriteString(boldStyle.Render(t.FeatureID+": "+t.Description) + "\n")
+
+	var facts []string
+	if t.Started != nil {
+		facts = append(facts, "started "+t.Started.Local().Format("Jan 2 15:04"))
+	}
+	if t.Finished != nil {
+		facts = append(facts, "finished "+t.Finished.Local().Format("Jan 2 15:04"))
+		facts = append(facts, "took "+t.Duration().Round(time.Minute).String())
+	}
+	facts = append(facts, fmt.Sprintf("%d commits", len(t.Commits)))
+	if len(t.Tests) > 0 {
+		facts = append(facts, fmt.Sprintf("tests %d/%d passed", t.TestsPassed(), len(t.Tests)))
+	}
+	sb.WriteString(mutedStyle.Render(strings.Join(facts, " • ")) + "\n")
+
+	events := t.Events
+	if len(events) > height {
+		sb.WriteString(mutedStyle.Render(fmt.Sprintf("  ... %d earlier events", len(events)-height)) + "\n")
+		events = events[len(events)-height:]
+	}
+	if len(events) == 0 {
+		sb.WriteString(mutedStyle.Render("  No recorded activity") + "\n")
+	}
+	for _, e := range events {
+		when := "            "
+		if !e.Time.IsZero() {
+			when = e.Time.Local().Format("Jan 02 15:04")
+		}
+		line := fmt.Sprintf("%s  %-11s %s", when, e.Kind, e.Detail)
+		sb.WriteString("  " + truncateLine(line, v.Width-6) + "\n")
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("245")).
+		Width(max(v.Width-2, 20))
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// historyStatusIcon returns the icon for a feature status
+func historyStatusIcon(status string) string {
+	switch status {
+	case history.StatusPassing:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Render("✓")
+	case history.StatusInProgress:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("●")
+	case history.StatusBlocked:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("⊘")
+	case history.StatusStuck:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("✗")
+	case history.StatusNeedsReview:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render("◆")
+	case history.StatusSkipped:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render("–")
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render("○")
+	}
+}
@@ -0,0 +1,46 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mpjhorner/superralph/internal/history"
+)
+
+func TestHistoryView(t *testing.T) {
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	finished := started.Add(90 * time.Minute)
+	timelines := []*history.Timeline{
+		{
+			FeatureID: "feat-001", Description: "Export data", Status: history.StatusPassing,
+			Started: &started, Finished: &finished, DurationSeconds: 5400, Attempts: 2,
+			Commits: []history.CommitRef{{Hash: "abc1234", Subject: "feat: export data"}},
+			Tests:   []history.TestOutcome{{Passed: false}, {Passed: true}},
+			Events:  []history.Event{{Time: started, Kind: history.EventCommit, Detail: "abc1234 feat: export data"}},
+		},
+		{FeatureID: "feat-002", Description: "Import data", Status: history.StatusNotStarted},
+	}
+
+	v := NewHistoryView(timelines, 100, 30)
+	view := v.Render()
+	assert.Contains(t, view, "Feature History")
+	assert.Contains(t, view, "▸ ✓ feat-001")
+	assert.Contains(t, view, "Export data")
+	assert.Contains(t, view, "took 1h30m0s")
+	assert.Contains(t, view, "tests 1/2 passed")
+	assert.Contains(t, view, "abc1234 feat: export data")
+
+	v.MoveDown()
+	v.MoveDown()
+	assert.Equal(t, "feat-002", v.Current().FeatureID)
+	assert.Contains(t, v.Render(), "No recorded activity")
+	v.MoveUp()
+	v.MoveUp()
+	assert.Equal(t, 0, v.Selected)
+
+	empty := NewHistoryView(nil, 80, 20)
+	assert.Nil(t, empty.Current())
+	assert.Contains(t, empty.Render(), "No features")
+}
@@ -22,12 +22,44 @@ const (
 	FeatureItemStatusInProgress
 	FeatureItemStatusDone
 	FeatureItemStatusBlocked
+	FeatureItemStatusStuck
+	FeatureItemStatusReview
+	FeatureItemStatusSkipped
 )
 
+// featureItemStatus returns a feature's status in the list: its status in
+// the PRD, with open features shown as in progress while they're being
+// built and as blocked while their dependencies don't pass
+func featureItemStatus(p *prd.PRD, f *prd.Feature, currentFeatureID string) FeatureItemStatus {
+	switch f.CurrentStatus() {
+	case prd.StatusPassing:
+		return FeatureItemStatusDone
+	case prd.StatusBlocked:
+		return FeatureItemStatusBlocked
+	case prd.StatusStuck:
+		return FeatureItemStatusStuck
+	case prd.StatusNeedsReview:
+		return FeatureItemStatusReview
+	case prd.StatusSkipped:
+		return FeatureItemStatusSkipped
+	}
+	switch {
+	case f.ID == currentFeatureID:
+		return FeatureItemStatusInProgress
+	case !p.DependenciesMet(f):
+		return FeatureItemStatusBlocked
+	case f.CurrentStatus() == prd.StatusInProgress:
+		return FeatureItemStatusInProgress
+	default:
+		return FeatureItemStatusPending
+	}
+}
+
 // FeatureItem implements list.Item for the bubbles/list component
 type FeatureItem struct {
 	feature *prd.Feature
 	status  FeatureItemStatus
+	tier    prd.Priority // High, medium or low, for the priority color
 }
 
 // FilterValue returns the value used for filtering
@@ -64,6 +96,12 @@ func (f FeatureItem) StatusIcon() string {
 		return "◐"
 	case FeatureItemStatusBlocked:
 		return "✗"
+	case FeatureItemStatusStuck:
+		return "!"
+	case FeatureItemStatusReview:
+		return "◆"
+	case FeatureItemStatusSkipped:
+		return "–"
 	default:
 		return "○"
 	}
@@ -71,7 +109,7 @@ func (f FeatureItem) StatusIcon() string {
 
 // PriorityIcon returns the colored priority indicator
 func (f FeatureItem) PriorityIcon() string {
-	switch f.feature.Priority {
+	switch f.tier {
 	case prd.PriorityHigh:
 		return "●" // Will be styled red
 	case prd.PriorityMedium:
@@ -103,6 +141,7 @@ type FeatureDelegateStyles struct {
 	DoneIcon       lipgloss.Style
 	PendingIcon    lipgloss.Style
 	BlockedIcon    lipgloss.Style
+	ReviewIcon     lipgloss.Style
 	HighPriority   lipgloss.Style
 	MediumPriority lipgloss.Style
 	LowPriority    lipgloss.Style
@@ -136,6 +175,8 @@ func NewFeatureDelegate() FeatureDelegate {
 				Foreground(lipgloss.Color("252")), // Light gray
 			BlockedIcon: lipgloss.NewStyle().
 				Foreground(lipgloss.Color("196")), // Red
+			ReviewIcon: lipgloss.NewStyle().
+				Foreground(lipgloss.Color("39")), // Blue
 			HighPriority: lipgloss.NewStyle().
 				Foreground(lipgloss.Color("196")), // Red
 			MediumPriority: lipgloss.NewStyle().
@@ -175,12 +216,14 @@ func (d FeatureDelegate) Render(w io.Writer, m list.Model, index int, item list.
 	// Build the status icon
 	var iconStyle lipgloss.Style
 	switch fi.status {
-	case FeatureItemStatusDone:
+	case FeatureItemStatusDone, FeatureItemStatusSkipped:
 		iconStyle = d.Styles.DoneIcon
 	case FeatureItemStatusInProgress:
 		iconStyle = d.Styles.InProgressIcon
-	case FeatureItemStatusBlocked:
+	case FeatureItemStatusBlocked, FeatureItemStatusStuck:
 		iconStyle = d.Styles.BlockedIcon
+	case FeatureItemStatusReview:
+		iconStyle = d.Styles.ReviewIcon
 	default:
 		iconStyle = d.Styles.PendingIcon
 	}
@@ -190,7 +233,7 @@ func (d FeatureDelegate) Render(w io.Writer, m list.Model, index int, item list.
 	var priorityIcon string
 	if d.ShowPriority {
 		var priorityStyle lipgloss.Style
-		switch fi.feature.Priority {
+		switch fi.tier {
 		case prd.PriorityHigh:
 			priorityStyle = d.Styles.HighPriority
 		case prd.PriorityMedium:
@@ -206,7 +249,7 @@ func (d FeatureDelegate) Render(w io.Writer, m list.Model, index int, item list.
 	if isSelected {
 		titleStyle = d.Styles.SelectedTitle
 		descStyle = d.Styles.SelectedDesc
-	} else if fi.status == FeatureItemStatusDone {
+	} else if fi.status == FeatureItemStatusDone || fi.status == FeatureItemStatusSkipped {
 		titleStyle = d.Styles.DoneTitle
 		descStyle = d.Styles.DoneDesc
 	} else {
@@ -224,8 +267,11 @@ func (d FeatureDelegate) Render(w io.Writer, m list.Model, index int, item list.
 	// Build title line
 	titleLine := fmt.Sprintf("%s %s%s", icon, priorityIcon, titleStyle.Render(fi.Title()))
 
-	// Build description line with truncation
+	// Build description line with truncation; blocked features say why
 	desc := fi.Description()
+	if fi.feature.BlockedReason != "" {
+		desc = "Blocked: " + fi.feature.BlockedReason
+	}
 	if len(desc) > contentWidth-4 {
 		desc = desc[:contentWidth-7] + "..."
 	}
@@ -304,19 +350,10 @@ func (ifl *InteractiveFeatureList) SetPRD(p *prd.PRD, currentFeatureID string) {
 	var items []list.Item
 	for i := range p.Features {
 		f := &p.Features[i]
-		status := FeatureItemStatusPending
-
-		if f.Passes {
-			status = FeatureItemStatusDone
-		} else if f.ID == currentFeatureID {
-			status = FeatureItemStatusInProgress
-		} else if !p.DependenciesMet(f) {
-			status = FeatureItemStatusBlocked
-		}
-
 		items = append(items, FeatureItem{
 			feature: f,
-			status:  status,
+			status:  featureItemStatus(p, f, currentFeatureID),
+			tier:    p.PriorityTier(f.Priority),
 		})
 	}
 
@@ -325,12 +362,15 @@ func (ifl *InteractiveFeatureList) SetPRD(p *prd.PRD, currentFeatureID string) {
 		fi := items[i].(FeatureItem)
 		fj := items[j].(FeatureItem)
 
-		// Sort order: InProgress < Blocked < Pending < Done
+		// Sort order: InProgress < Blocked, Stuck, Review < Pending < Done, Skipped
 		statusOrder := map[FeatureItemStatus]int{
 			FeatureItemStatusInProgress: 0,
 			FeatureItemStatusBlocked:    1,
+			FeatureItemStatusStuck:      1,
+			FeatureItemStatusReview:     1,
 			FeatureItemStatusPending:    2,
 			FeatureItemStatusDone:       3,
+			FeatureItemStatusSkipped:    3,
 		}
 
 		if statusOrder[fi.status] != statusOrder[fj.status] {
@@ -338,13 +378,7 @@ func (ifl *InteractiveFeatureList) SetPRD(p *prd.PRD, currentFeatureID string) {
 		}
 
 		// Within same status, sort by priority
-		priorityOrder := map[prd.Priority]int{
-			prd.PriorityHigh:   0,
-			prd.PriorityMedium: 1,
-			prd.PriorityLow:    2,
-		}
-
-		return priorityOrder[fi.feature.Priority] < priorityOrder[fj.feature.Priority]
+		return p.PriorityRank(fi.feature.Priority) < p.PriorityRank(fj.feature.Priority)
 	})
 
 	ifl.List.SetItems(items)
@@ -464,18 +498,10 @@ func (ifl *InteractiveFeatureList) applyFilter(query string) {
 			continue
 		}
 
-		status := FeatureItemStatusPending
-		if f.Passes {
-			status = FeatureItemStatusDone
-		} else if f.ID == ifl.CurrentFeatureID {
-			status = FeatureItemStatusInProgress
-		} else if !ifl.PRD.DependenciesMet(f) {
-			status = FeatureItemStatusBlocked
-		}
-
 		items = append(items, FeatureItem{
 			feature: f,
-			status:  status,
+			status:  featureItemStatus(ifl.PRD, f, ifl.CurrentFeatureID),
+			tier:    ifl.PRD.PriorityTier(f.Priority),
 		})
 	}
 
@@ -556,12 +582,31 @@ func (ifl *InteractiveFeatureList) renderDetailView() string {
 
 	// Status
 	statusStyle := lipgloss.NewStyle().Bold(true)
-	if f.Passes {
-		statusStyle = statusStyle.Foreground(lipgloss.Color("42"))
-		b.WriteString(statusStyle.Render("✓ COMPLETE"))
-	} else {
-		statusStyle = statusStyle.Foreground(lipgloss.Color("214"))
-		b.WriteString(statusStyle.Render("○ PENDING"))
+	switch f.CurrentStatus() {
+	case prd.StatusPassing:
+		b.WriteString(statusStyle.Foreground(lipgloss.Color("42")).Render("✓ COMPLETE"))
+	case prd.StatusInProgress:
+		b.WriteString(statusStyle.Foreground(lipgloss.Color("42")).Render("◐ IN PROGRESS"))
+	case prd.StatusBlocked:
+		b.WriteString(statusStyle.Foreground(lipgloss.Color("196")).Render("✗ BLOCKED"))
+	case prd.StatusStuck:
+		b.WriteString(statusStyle.Foreground(lipgloss.Color("196")).Render("! STUCK"))
+	case prd.StatusNeedsReview:
+		b.WriteString(statusStyle.Foreground(lipgloss.Color("39")).Render("◆ NEEDS REVIEW"))
+	case prd.StatusSkipped:
+		b.WriteString(statusStyle.Foreground(lipgloss.Color("245")).Render("– SKIPPED"))
+	default:
+		b.WriteString(statusStyle.Foreground(lipgloss.Color("214")).Render("○ PENDING"))
+	}
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	if f.BlockedReason != "" {
+		b.WriteString("\n" + mutedStyle.Render(f.BlockedReason))
+	}
+	if f.StartedAt != nil {
+		b.WriteString("\n" + mutedStyle.Render("Started "+f.StartedAt.Local().Format("2006-01-02 15:04")))
+	}
+	if f.CompletedAt != nil {
+		b.WriteString("\n" + mutedStyle.Render("Completed "+f.CompletedAt.Local().Format("2006-01-02 15:04")))
 	}
 	b.WriteString("\n\n")
 
@@ -625,7 +670,8 @@ func (ifl *InteractiveFeatureList) renderHelp() string {
 	return helpStyle.Render(strings.Join(keys, "  "))
 }
 
-// GetStats returns statistics about the features
+// GetStats returns statistics about the features. Stuck features and those
+// waiting for review count as blocked, and skipped features as done.
 func (ifl *InteractiveFeatureList) GetStats() (inProgress, pending, blocked, done int) {
 	items := ifl.List.Items()
 	for _, item := range items {
@@ -638,9 +684,9 @@ func (ifl *InteractiveFeatureList) GetStats() (inProgress, pending, blocked, don
 			inProgress++
 		case FeatureItemStatusPending:
 			pending++
-		case FeatureItemStatusBlocked:
+		case FeatureItemStatusBlocked, FeatureItemStatusStuck, FeatureItemStatusReview:
 			blocked++
-		case FeatureItemStatusDone:
+		case FeatureItemStatusDone, FeatureItemStatusSkipped:
 			done++
 		}
 	}
@@ -13,6 +13,7 @@ const (
 	TabDashboard Tab = iota
 	TabFeatures
 	TabLogs
+	TabGraph
 )
 
 // String returns the display name for a tab
@@ -24,6 +25,8 @@ func (t Tab) String() string {
 		return "Features"
 	case TabLogs:
 		return "Logs"
+	case TabGraph:
+		return "Graph"
 	default:
 		return "Unknown"
 	}
@@ -38,6 +41,8 @@ func (t Tab) ShortKey() string {
 		return "2"
 	case TabLogs:
 		return "3"
+	case TabGraph:
+		return "4"
 	default:
 		return "?"
 	}
@@ -45,7 +50,7 @@ func (t Tab) ShortKey() string {
 
 // AllTabs returns all available tabs in order
 func AllTabs() []Tab {
-	return []Tab{TabDashboard, TabFeatures, TabLogs}
+	return []Tab{TabDashboard, TabFeatures, TabLogs, TabGraph}
 }
 
 // TabBar represents a navigation bar with tabs
@@ -175,6 +180,8 @@ func TabFromKey(key string) Tab {
 		return TabFeatures
 	case "3":
 		return TabLogs
+	case "4":
+		return TabGraph
 	default:
 		return Tab(-1)
 	}
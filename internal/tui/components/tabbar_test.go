@@ -39,7 +39,10 @@ func TestTabBarNextTab(t *testing.T) {
 	assert.Equal(t, TabLogs, tb.ActiveTab, "NextTab from Features should be Logs")
 
 	tb.NextTab()
-	assert.Equal(t, TabDashboard, tb.ActiveTab, "NextTab from Logs should wrap to Dashboard")
+	assert.Equal(t, TabGraph, tb.ActiveTab, "NextTab from Logs should be Graph")
+
+	tb.NextTab()
+	assert.Equal(t, TabDashboard, tb.ActiveTab, "NextTab from Graph should wrap to Dashboard")
 }
 
 func TestTabBarPrevTab(t *testing.T) {
@@ -47,7 +50,10 @@ func TestTabBarPrevTab(t *testing.T) {
 	assert.Equal(t, TabDashboard, tb.ActiveTab)
 
 	tb.PrevTab()
-	assert.Equal(t, TabLogs, tb.ActiveTab, "PrevTab from Dashboard should wrap to Logs")
+	assert.Equal(t, TabGraph, tb.ActiveTab, "PrevTab from Dashboard should wrap to Graph")
+
+	tb.PrevTab()
+	assert.Equal(t, TabLogs, tb.ActiveTab, "PrevTab from Graph should be Logs")
 
 	tb.PrevTab()
 	assert.Equal(t, TabFeatures, tb.ActiveTab, "PrevTab from Logs should be Features")
@@ -101,7 +107,8 @@ func TestTabFromKey(t *testing.T) {
 		{"1", TabDashboard},
 		{"2", TabFeatures},
 		{"3", TabLogs},
-		{"4", Tab(-1)},
+		{"4", TabGraph},
+		{"5", Tab(-1)},
 		{"a", Tab(-1)},
 		{"", Tab(-1)},
 	}
@@ -120,6 +127,7 @@ func TestTabString(t *testing.T) {
 		{TabDashboard, "Dashboard"},
 		{TabFeatures, "Features"},
 		{TabLogs, "Logs"},
+		{TabGraph, "Graph"},
 		{Tab(99), "Unknown"},
 	}
 
@@ -137,6 +145,7 @@ func TestTabShortKey(t *testing.T) {
 		{TabDashboard, "1"},
 		{TabFeatures, "2"},
 		{TabLogs, "3"},
+		{TabGraph, "4"},
 		{Tab(99), "?"},
 	}
 
@@ -149,10 +158,11 @@ func TestTabShortKey(t *testing.T) {
 func TestAllTabs(t *testing.T) {
 	tabs := AllTabs()
 
-	require.Len(t, tabs, 3, "AllTabs should return 3 tabs")
+	require.Len(t, tabs, 4, "AllTabs should return 4 tabs")
 	assert.Equal(t, TabDashboard, tabs[0])
 	assert.Equal(t, TabFeatures, tabs[1])
 	assert.Equal(t, TabLogs, tabs[2])
+	assert.Equal(t, TabGraph, tabs[3])
 }
 
 func TestTabBarRenderWithDifferentActiveTabs(t *testing.T) {
@@ -168,6 +178,7 @@ func TestTabBarRenderWithDifferentActiveTabs(t *testing.T) {
 		assert.Contains(t, rendered, "Dashboard")
 		assert.Contains(t, rendered, "Features")
 		assert.Contains(t, rendered, "Logs")
+		assert.Contains(t, rendered, "Graph")
 	}
 }
 
@@ -176,4 +187,5 @@ func TestTabConstants(t *testing.T) {
 	assert.Equal(t, Tab(0), TabDashboard)
 	assert.Equal(t, Tab(1), TabFeatures)
 	assert.Equal(t, Tab(2), TabLogs)
+	assert.Equal(t, Tab(3), TabGraph)
 }
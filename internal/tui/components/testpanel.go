@@ -0,0 +1,115 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mpjhorner/superralph/internal/testresult"
+)
+
+// TestPanel displays the results of the most recent test run
+type TestPanel struct {
+	Command     string
+	Passed      bool
+	Report      *testresult.Report // nil if the output wasn't recognized
+	HasResult   bool
+	MaxFailures int // maximum failing tests to display
+	Width       int
+	Title       string
+}
+
+// NewTestPanel creates a new test panel
+func NewTestPanel(width int) *TestPanel {
+	return &TestPanel{
+		MaxFailures: 5,
+		Width:       width,
+		Title:       "Tests",
+	}
+}
+
+// SetResult records a test run
+func (p *TestPanel) SetResult(command string, passed bool, report *testresult.Report) {
+	p.Command = command
+	p.Passed = passed
+	p.Report = report
+	p.HasResult = true
+}
+
+// Clear removes the recorded result
+func (p *TestPanel) Clear() {
+	p.Command = ""
+	p.Passed = false
+	p.Report = nil
+	p.HasResult = false
+}
+
+// Render returns the test panel as a string
+func (p *TestPanel) Render() string {
+	if !p.HasResult {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("245")).
+		Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	passStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+
+	contentWidth := p.Width - 6 // Account for border and indent
+
+	sb.WriteString(titleStyle.Render(p.Title))
+	sb.WriteString(" ")
+	sb.WriteString(mutedStyle.Render(truncateLine(p.Command, contentWidth-len(p.Title)-1)))
+	sb.WriteString("\n")
+
+	// Result line
+	status := passStyle.Render("● PASSED")
+	if !p.Passed {
+		status = failStyle.Render("✗ FAILED")
+	}
+	summary := "output not recognized"
+	if p.Report != nil {
+		summary = p.Report.Summary()
+	}
+	sb.WriteString(fmt.Sprintf(" %s %s\n", status, summary))
+
+	// Failing tests, with the first line of each excerpt
+	if p.Report != nil {
+		failures := p.Report.Failures
+		hidden := 0
+		if p.MaxFailures > 0 && len(failures) > p.MaxFailures {
+			hidden = len(failures) - p.MaxFailures
+			failures = failures[:p.MaxFailures]
+		}
+		for _, f := range failures {
+			sb.WriteString(" " + failStyle.Render("✗") + " " + truncateLine(f.String(), contentWidth-2) + "\n")
+			if first, _, _ := strings.Cut(f.Excerpt, "\n"); first != "" {
+				sb.WriteString("     " + mutedStyle.Render(truncateLine(first, contentWidth-4)) + "\n")
+			}
+		}
+		if hidden > 0 {
+			sb.WriteString(mutedStyle.Render(fmt.Sprintf("   ... and %d more", hidden)) + "\n")
+		}
+	}
+
+	// Render in a box
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("245")).
+		Width(p.Width - 2)
+
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// truncateLine shortens s to width characters, adding "..." if truncated
+func truncateLine(s string, width int) string {
+	if width < 4 || len(s) <= width {
+		return s
+	}
+	return s[:width-3] + "..."
+}
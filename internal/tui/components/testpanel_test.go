@@ -0,0 +1,77 @@
+package components
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/testresult"
+)
+
+func TestNewTestPanel(t *testing.T) {
+	p := NewTestPanel(80)
+
+	require.NotNil(t, p)
+	assert.Equal(t, 80, p.Width)
+	assert.Equal(t, 5, p.MaxFailures)
+	assert.False(t, p.HasResult)
+	assert.Empty(t, p.Render())
+}
+
+func TestTestPanelPassing(t *testing.T) {
+	p := NewTestPanel(80)
+	p.SetResult("go test ./...", true, &testresult.Report{Passed: 12, Skipped: 1})
+
+	view := p.Render()
+	assert.Contains(t, view, "go test ./...")
+	assert.Contains(t, view, "PASSED")
+	assert.Contains(t, view, "12 passed, 1 skipped")
+}
+
+func TestTestPanelFailures(t *testing.T) {
+	p := NewTestPanel(80)
+	p.SetResult("go test ./...", false, &testresult.Report{
+		Passed: 3,
+		Failed: 1,
+		Failures: []testresult.Failure{
+			{Name: "TestParse", Package: "app/parser", Excerpt: "parser_test.go:12: unexpected EOF\nmore detail"},
+		},
+	})
+
+	view := p.Render()
+	assert.Contains(t, view, "FAILED")
+	assert.Contains(t, view, "3 passed, 1 failed")
+	assert.Contains(t, view, "app/parser: TestParse")
+	assert.Contains(t, view, "parser_test.go:12: unexpected EOF")
+	assert.NotContains(t, view, "more detail")
+}
+
+func TestTestPanelMaxFailures(t *testing.T) {
+	p := NewTestPanel(80)
+	p.MaxFailures = 2
+
+	report := &testresult.Report{Failed: 4}
+	for i := 1; i <= 4; i++ {
+		report.Failures = append(report.Failures, testresult.Failure{Name: fmt.Sprintf("TestCase%d", i)})
+	}
+	p.SetResult("go test ./...", false, report)
+
+	view := p.Render()
+	assert.Contains(t, view, "TestCase2")
+	assert.NotContains(t, view, "TestCase3")
+	assert.Contains(t, view, "and 2 more")
+}
+
+func TestTestPanelUnrecognizedOutput(t *testing.T) {
+	p := NewTestPanel(80)
+	p.SetResult("./check.sh", false, nil)
+
+	view := p.Render()
+	assert.Contains(t, view, "FAILED")
+	assert.Contains(t, view, "output not recognized")
+
+	p.Clear()
+	assert.Empty(t, p.Render())
+}
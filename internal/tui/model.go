@@ -9,6 +9,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/mpjhorner/superralph/internal/coverage"
 	"github.com/mpjhorner/superralph/internal/orchestrator"
 	"github.com/mpjhorner/superralph/internal/prd"
 	"github.com/mpjhorner/superralph/internal/tui/components"
@@ -89,8 +90,11 @@ type Model struct {
 	Dashboard              *components.Dashboard
 	PhaseIndicator         *components.PhaseIndicator
 	ActionPanel            *components.ActionPanel
+	TestPanel              *components.TestPanel
+	CoveragePanel          *components.CoveragePanel
 	FeatureList            *components.FeatureList
 	InteractiveFeatureList *components.InteractiveFeatureList
+	DependencyTree         *components.DependencyTree
 	StepIndicator          *components.StepIndicator
 	Width                  int
 	Height                 int
@@ -175,6 +179,10 @@ func (m *Model) updateLayoutDimensions() {
 	m.ActionPanel.Width = mainColWidth
 	m.ActionPanel.Height = 8
 
+	// Test panel
+	m.TestPanel.Width = mainColWidth
+	m.CoveragePanel.Width = mainColWidth
+
 	// Phase and Step indicators
 	m.PhaseIndicator.Width = mainColWidth
 	m.StepIndicator.Width = mainColWidth
@@ -211,6 +219,9 @@ func (m *Model) updateLayoutDimensions() {
 		interactiveHeight = 10
 	}
 	m.InteractiveFeatureList.Resize(m.Width-4, interactiveHeight)
+
+	// Dependency tree (graph tab)
+	m.DependencyTree.Resize(m.Width-4, interactiveHeight)
 }
 
 // NewModel creates a new TUI model
@@ -227,6 +238,10 @@ func NewModel(p *prd.PRD, prdPath string, maxIterations int) Model {
 	interactiveFeatureList := components.NewInteractiveFeatureList(80, 20)
 	interactiveFeatureList.SetPRD(p, "")
 
+	// Initialize dependency tree (graph tab)
+	dependencyTree := components.NewDependencyTree(80, 20)
+	dependencyTree.SetPRD(p, "")
+
 	// Initialize dashboard
 	dashboard := components.NewDashboard(80, 20)
 	dashboard.SetPRD(p, prdPath)
@@ -256,8 +271,11 @@ func NewModel(p *prd.PRD, prdPath string, maxIterations int) Model {
 		Dashboard:              dashboard,
 		PhaseIndicator:         components.NewPhaseIndicator(),
 		ActionPanel:            components.NewActionPanel(80, 8),
+		TestPanel:              components.NewTestPanel(80),
+		CoveragePanel:          components.NewCoveragePanel(80),
 		FeatureList:            featureList,
 		InteractiveFeatureList: interactiveFeatureList,
+		DependencyTree:         dependencyTree,
 		StepIndicator:          components.NewStepIndicator(),
 		Width:                  80,
 		Height:                 24,
@@ -351,6 +369,16 @@ type (
 	FileDiffMsg struct {
 		Diff *orchestrator.FileDiff
 	}
+
+	// TestResultMsg signals the parsed results of a test run
+	TestResultMsg struct {
+		Run *orchestrator.TestRun
+	}
+
+	// CoverageMsg signals the result of the coverage gate
+	CoverageMsg struct {
+		Result *coverage.Comparison
+	}
 )
 
 // Init initializes the model
@@ -420,7 +448,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.OnDebug != nil {
 				m.OnDebug(m.DebugMode)
 			}
-		case "1", "2", "3":
+		case "1", "2", "3", "4":
 			// Tab switching via number keys
 			tab := components.TabFromKey(msg.String())
 			if tab >= 0 {
@@ -444,6 +472,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Scroll down in log tab
 			if m.ActiveTab == components.TabLogs {
 				m.LogTab.ScrollDown(1)
+			} else if m.ActiveTab == components.TabGraph {
+				m.DependencyTree.ScrollDown(1)
 			} else if m.ActiveTab == components.TabFeatures {
 				var cmd tea.Cmd
 				m.InteractiveFeatureList, cmd = m.InteractiveFeatureList.Update(msg)
@@ -453,6 +483,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Scroll up in log tab
 			if m.ActiveTab == components.TabLogs {
 				m.LogTab.ScrollUp(1)
+			} else if m.ActiveTab == components.TabGraph {
+				m.DependencyTree.ScrollUp(1)
 			} else if m.ActiveTab == components.TabFeatures {
 				var cmd tea.Cmd
 				m.InteractiveFeatureList, cmd = m.InteractiveFeatureList.Update(msg)
@@ -480,11 +512,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Page up in log tab
 			if m.ActiveTab == components.TabLogs {
 				m.LogTab.ScrollUp(10)
+			} else if m.ActiveTab == components.TabGraph {
+				m.DependencyTree.ScrollUp(10)
 			}
 		case "pgdown", "ctrl+d":
 			// Page down in log tab
 			if m.ActiveTab == components.TabLogs {
 				m.LogTab.ScrollDown(10)
+			} else if m.ActiveTab == components.TabGraph {
+				m.DependencyTree.ScrollDown(10)
 			}
 		default:
 			// Pass other key messages to InteractiveFeatureList when on Features tab
@@ -553,6 +589,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.CurrentFeature != nil {
 			m.FeatureList.UpdateFromPRD(m.PRD, m.CurrentFeature.ID)
 			m.InteractiveFeatureList.SetPRD(m.PRD, m.CurrentFeature.ID)
+			m.DependencyTree.SetPRD(m.PRD, m.CurrentFeature.ID)
 		}
 		// Sync with dashboard
 		m.Dashboard.SetIteration(msg.Iteration, m.MaxIterations)
@@ -579,6 +616,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.FeatureList.UpdateFromPRD(m.PRD, currentFeatureID)
 		m.InteractiveFeatureList.SetPRD(m.PRD, currentFeatureID)
+		m.DependencyTree.SetPRD(m.PRD, currentFeatureID)
 		// Sync with dashboard
 		m.Dashboard.UpdateStats(msg.Stats)
 
@@ -638,6 +676,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Diff != nil {
 			m.addDiffToLog(msg.Diff)
 		}
+
+	case TestResultMsg:
+		if msg.Run != nil {
+			m.TestPanel.SetResult(msg.Run.Command, msg.Run.Passed, msg.Run.Report)
+		}
+
+	case CoverageMsg:
+		if msg.Result != nil {
+			m.CoveragePanel.SetResult(msg.Result)
+		}
 	}
 
 	return m, nil
@@ -680,6 +728,8 @@ func (m Model) View() string {
 		b.WriteString(m.renderFeaturesTab())
 	case components.TabLogs:
 		b.WriteString(m.renderLogsTab())
+	case components.TabGraph:
+		b.WriteString(m.renderGraphTab())
 	}
 
 	// Help (always visible)
@@ -729,6 +779,18 @@ func (m Model) renderDashboardTab() string {
 		leftCol.WriteString("\n")
 	}
 
+	// Test results (after the first test run)
+	if m.TestPanel.HasResult {
+		leftCol.WriteString(m.TestPanel.Render())
+		leftCol.WriteString("\n")
+	}
+
+	// Coverage gate result, or the recorded baseline
+	if m.CoveragePanel.HasContent() {
+		leftCol.WriteString(m.CoveragePanel.Render())
+		leftCol.WriteString("\n")
+	}
+
 	// Build right column (feature list - compact view)
 	m.FeatureList.Width = featureListWidth
 	featureListHeight := 12
@@ -787,6 +849,12 @@ func (m Model) renderLogsTab() string {
 	return m.LogTab.Render()
 }
 
+// renderGraphTab renders the dependency tree
+func (m Model) renderGraphTab() string {
+	m.DependencyTree.Resize(m.Width-4, m.Height-10)
+	return m.DependencyTree.Render()
+}
+
 func (m Model) renderHeader() string {
 	title := lipgloss.NewStyle().
 		Bold(true).
@@ -809,7 +877,11 @@ func (m Model) renderProgress() string {
 	var b strings.Builder
 	b.WriteString(BoldStyle.Render("Progress: "))
 	b.WriteString(pb.Render())
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if counts := components.StatusCounts(stats); counts != "" {
+		b.WriteString(MutedStyle.Render(counts) + "\n")
+	}
+	b.WriteString("\n")
 
 	// Get responsive mini progress bar width
 	miniWidth := m.GetMiniProgressBarWidth()
@@ -817,8 +889,7 @@ func (m Model) renderProgress() string {
 	// On compact screens, show simplified breakdown (priority only)
 	if m.IsCompact() {
 		b.WriteString(MutedStyle.Render("By Priority:") + "\n")
-		priorities := prd.ValidPriorities()
-		for _, pri := range priorities {
+		for _, pri := range stats.Priorities {
 			ps := stats.ByPriority[pri]
 			mini := components.NewMiniProgressBar(ps.Passing, ps.Total, miniWidth)
 			b.WriteString(fmt.Sprintf("  %-8s %s %d/%d\n", pri, mini.Render(), ps.Passing, ps.Total))
@@ -827,8 +898,8 @@ func (m Model) renderProgress() string {
 	}
 
 	// On wider screens, show both category and priority breakdown side by side
-	categories := prd.ValidCategories()
-	priorities := prd.ValidPriorities()
+	categories := stats.Categories
+	priorities := stats.Priorities
 	maxRows := len(categories)
 	if len(priorities) > maxRows {
 		maxRows = len(priorities)
@@ -952,7 +1023,7 @@ func (m Model) renderHelp() string {
 	var keys []string
 
 	// Tab navigation
-	keys = append(keys, "[1-3/Tab] Switch tabs")
+	keys = append(keys, "[1-4/Tab] Switch tabs")
 
 	// Tab-specific help
 	switch m.ActiveTab {
@@ -963,6 +1034,8 @@ func (m Model) renderHelp() string {
 		} else {
 			keys = append(keys, "[a] Auto-scroll OFF")
 		}
+	case components.TabGraph:
+		keys = append(keys, "[j/k] Scroll")
 	case components.TabFeatures:
 		if m.InteractiveFeatureList.IsFiltering() {
 			keys = append(keys, "[Enter] Apply filter", "[Esc] Cancel")
@@ -8,7 +8,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/mpjhorner/superralph/internal/coverage"
+	"github.com/mpjhorner/superralph/internal/orchestrator"
 	"github.com/mpjhorner/superralph/internal/prd"
+	"github.com/mpjhorner/superralph/internal/testresult"
 	"github.com/mpjhorner/superralph/internal/tui/components"
 )
 
@@ -287,6 +290,47 @@ func TestModelViewWithActions(t *testing.T) {
 	assert.Contains(t, view, "Reading test.go", "View should contain action description")
 }
 
+func TestModelUpdateTestResult(t *testing.T) {
+	p := createTestPRD()
+	m := NewModel(p, "prd.json", 10)
+
+	assert.NotContains(t, m.View(), "TestLogin")
+
+	newModel, _ := m.Update(TestResultMsg{Run: &orchestrator.TestRun{
+		Command: "go test ./...",
+		Passed:  false,
+		Report: &testresult.Report{
+			Passed:   4,
+			Failed:   1,
+			Failures: []testresult.Failure{{Name: "TestLogin", Package: "app/auth"}},
+		},
+	}})
+	updated := newModel.(Model)
+
+	assert.True(t, updated.TestPanel.HasResult)
+	assert.False(t, updated.TestPanel.Passed)
+
+	view := updated.View()
+	assert.Contains(t, view, "4 passed, 1 failed")
+	assert.Contains(t, view, "app/auth: TestLogin")
+}
+
+func TestModelUpdateCoverage(t *testing.T) {
+	p := createTestPRD()
+	m := NewModel(p, "prd.json", 10)
+
+	baseline := &coverage.Baseline{Profile: &coverage.Profile{Packages: map[string]coverage.Counts{"app/auth": {Covered: 9, Total: 10}}}}
+	current := &coverage.Profile{Packages: map[string]coverage.Counts{"app/auth": {Covered: 5, Total: 10}}}
+
+	newModel, _ := m.Update(CoverageMsg{Result: coverage.Compare(baseline, current, 0.5)})
+	updated := newModel.(Model)
+
+	require.NotNil(t, updated.CoveragePanel.Result)
+	view := updated.View()
+	assert.Contains(t, view, "50.0% (-40.0 from 90.0%)")
+	assert.Contains(t, view, "app/auth 90.0% -> 50.0%")
+}
+
 func TestModelHelperMethods(t *testing.T) {
 	p := createTestPRD()
 	m := NewModel(p, "prd.json", 10)
@@ -480,7 +524,7 @@ func TestModelUpdateTabViaShiftTab(t *testing.T) {
 	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
 	m2 := newModel.(Model)
 
-	assert.Equal(t, components.TabLogs, m2.ActiveTab, "Shift+Tab should wrap to last tab")
+	assert.Equal(t, components.TabGraph, m2.ActiveTab, "Shift+Tab should wrap to last tab")
 }
 
 func TestModelUpdateTabChangeMsg(t *testing.T) {
@@ -0,0 +1,145 @@
+// Package verify runs the executable acceptance checks declared on PRD features.
+//
+// A feature may declare a feature-level verify command and per-step checks.
+// Each check is a shell command with an expected exit code and, optionally,
+// a pattern its output must match.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+// DefaultTimeout bounds how long a single check may run
+const DefaultTimeout = 5 * time.Minute
+
+// maxOutputLines limits how much check output is kept for reporting
+const maxOutputLines = 20
+
+// Result is the outcome of a single check
+type Result struct {
+	Check    prd.Check
+	Passed   bool
+	ExitCode int
+	Output   string // Last lines of combined output
+	Duration time.Duration
+	Reason   string // Why the check failed
+}
+
+// FeatureResult holds the results of every check on a feature
+type FeatureResult struct {
+	FeatureID string
+	Results   []Result
+}
+
+// Passed returns true if every check passed
+func (r *FeatureResult) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the checks that failed
+func (r *FeatureResult) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Runner runs checks from a project directory
+type Runner struct {
+	workDir string
+	Timeout time.Duration
+}
+
+// New creates a runner for the project in workDir
+func New(workDir string) *Runner {
+	return &Runner{
+		workDir: workDir,
+		Timeout: DefaultTimeout,
+	}
+}
+
+// RunCheck runs a single check
+func (r *Runner) RunCheck(ctx context.Context, c prd.Check) Result {
+	result := Result{Check: c}
+
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+	cmd.Dir = r.workDir
+	cmd.WaitDelay = time.Second // Don't wait on children that outlive the shell
+	output, err := cmd.CombinedOutput()
+	result.Duration = time.Since(start)
+	result.Output = tail(string(output), maxOutputLines)
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		result.Reason = fmt.Sprintf("timed out after %s", r.Timeout)
+		return result
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Reason = fmt.Sprintf("failed to run: %v", err)
+		return result
+	}
+
+	if result.ExitCode != c.ExitCode {
+		result.Reason = fmt.Sprintf("exited with code %d, expected %d", result.ExitCode, c.ExitCode)
+		return result
+	}
+
+	if c.Output != "" {
+		re, err := regexp.Compile(c.Output)
+		if err != nil {
+			result.Reason = fmt.Sprintf("invalid output pattern: %v", err)
+			return result
+		}
+		if !re.Match(output) {
+			result.Reason = fmt.Sprintf("output did not match /%s/", c.Output)
+			return result
+		}
+	}
+
+	result.Passed = true
+	return result
+}
+
+// RunFeature runs every check on a feature, stopping early if ctx is canceled
+func (r *Runner) RunFeature(ctx context.Context, f *prd.Feature) *FeatureResult {
+	result := &FeatureResult{FeatureID: f.ID}
+	for _, c := range f.AllChecks() {
+		if ctx.Err() != nil {
+			break
+		}
+		result.Results = append(result.Results, r.RunCheck(ctx, c))
+	}
+	return result
+}
+
+// tail keeps the last n lines of s
+func tail(s string, n int) string {
+	s = strings.TrimRight(s, "\n")
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return "...\n" + strings.Join(lines[len(lines)-n:], "\n")
+}
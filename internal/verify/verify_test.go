@@ -0,0 +1,80 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpjhorner/superralph/internal/prd"
+)
+
+func TestRunCheck(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "status.txt"), []byte("service: healthy\n"), 0644))
+	runner := New(dir)
+
+	tests := []struct {
+		name   string
+		check  prd.Check
+		passed bool
+		exit   int
+		reason string
+	}{
+		{"exit zero", prd.Check{Command: "true"}, true, 0, ""},
+		{"unexpected exit", prd.Check{Command: "exit 3"}, false, 3, "exited with code 3, expected 0"},
+		{"expected nonzero exit", prd.Check{Command: "exit 2", ExitCode: 2}, true, 2, ""},
+		{"output matches", prd.Check{Command: "cat status.txt", Output: `service: \w+`}, true, 0, ""},
+		{"output does not match", prd.Check{Command: "cat status.txt", Output: "degraded"}, false, 0, "output did not match /degraded/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runner.RunCheck(context.Background(), tt.check)
+			assert.Equal(t, tt.passed, result.Passed)
+			assert.Equal(t, tt.exit, result.ExitCode)
+			assert.Equal(t, tt.reason, result.Reason)
+		})
+	}
+}
+
+func TestRunCheckTimeout(t *testing.T) {
+	runner := New(t.TempDir())
+	runner.Timeout = 50 * time.Millisecond
+
+	result := runner.RunCheck(context.Background(), prd.Check{Command: "sleep 5"})
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Reason, "timed out")
+}
+
+func TestRunFeature(t *testing.T) {
+	runner := New(t.TempDir())
+	f := &prd.Feature{
+		ID:     "feat-001",
+		Steps:  []string{"Create file", "Print greeting"},
+		Verify: &prd.Check{Command: "echo hello", Output: "hello"},
+		Checks: []prd.Check{
+			{Step: 1, Command: "true"},
+			{Step: 2, Command: "echo bye", Output: "hello"},
+		},
+	}
+
+	result := runner.RunFeature(context.Background(), f)
+	assert.Equal(t, "feat-001", result.FeatureID)
+	require.Len(t, result.Results, 3)
+	assert.False(t, result.Passed())
+
+	failed := result.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, 2, failed[0].Check.Step)
+	assert.Equal(t, "bye", failed[0].Output)
+}
+
+func TestTail(t *testing.T) {
+	assert.Equal(t, "a\nb", tail("a\nb\n", 3))
+	assert.Equal(t, "...\nc\nd", tail("a\nb\nc\nd\n", 2))
+}